@@ -0,0 +1,359 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type adminSearchUsersQuery struct {
+	Query    string `form:"query" binding:"required"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// adminSearchUsers handles GET request, returns the users whose username,
+// email or full name contains the query
+func (server *Server) adminSearchUsers(ctx *gin.Context) {
+	var req adminSearchUsersQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.recordAdminAction(ctx, db.AdminActionSearchUsers, db.AdminTargetUser, req.Query) {
+		return
+	}
+
+	users, err := server.store.SearchUsers(ctx, db.SearchUsersParams{
+		Query:  req.Query,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := make([]userResponse, 0, len(users))
+	for _, user := range users {
+		res = append(res, newUserResponse(user))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type adminAccountURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// adminFreezeAccount handles POST request, freezes the account, so no money can leave it
+func (server *Server) adminFreezeAccount(ctx *gin.Context) {
+	server.adminSetAccountFrozen(ctx, true)
+}
+
+// adminUnfreezeAccount handles POST request, unfreezes the account
+func (server *Server) adminUnfreezeAccount(ctx *gin.Context) {
+	server.adminSetAccountFrozen(ctx, false)
+}
+
+func (server *Server) adminSetAccountFrozen(ctx *gin.Context, frozen bool) {
+	var uri adminAccountURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if db.IsSystemAccountOwner(account.Owner) {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrSystemAccount))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	account, err = server.store.SetAccountFrozenTx(ctx, db.SetAccountFrozenTxParams{
+		AdminUsername: authPayload.Username,
+		AccountID:     account.ID,
+		Frozen:        frozen,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, account)
+}
+
+type adminBlockSessionURI struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// adminBlockSession handles POST request, blocks a session of any user
+func (server *Server) adminBlockSession(ctx *gin.Context) {
+	var uri adminBlockSessionURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	session, err := server.store.GetSession(ctx, uuid.MustParse(uri.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	session, err = server.store.AdminBlockSessionTx(ctx, db.AdminBlockSessionTxParams{
+		AdminUsername: authPayload.Username,
+		SessionID:     session.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newSessionResponse(session))
+}
+
+type adminListAccountTransfersQuery struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// adminListAccountTransfers handles GET request, returns the transfers
+// sent from or received by any account
+func (server *Server) adminListAccountTransfers(ctx *gin.Context) {
+	var uri adminAccountURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req adminListAccountTransfersQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	targetID := strconv.FormatInt(account.ID, 10)
+	if !server.recordAdminAction(ctx, db.AdminActionListAccountTransfers, db.AdminTargetAccount, targetID) {
+		return
+	}
+
+	transfers, err := server.store.ListTransfers(ctx, db.ListTransfersParams{
+		FromAccountID: account.ID,
+		ToAccountID:   account.ID,
+		Limit:         req.PageSize,
+		Offset:        (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
+
+type adminUserURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type userLockoutResponse struct {
+	Username     string    `json:"username"`
+	Failures     int32     `json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+	LockedUntil  time.Time `json:"locked_until"`
+	IsLocked     bool      `json:"is_locked"`
+}
+
+func newUserLockoutResponse(username string, failure db.LoginFailure) userLockoutResponse {
+	return userLockoutResponse{
+		Username:     username,
+		Failures:     failure.Failures,
+		LastFailedAt: failure.LastFailedAt,
+		LockedUntil:  failure.LockedUntil,
+		IsLocked:     time.Now().Before(failure.LockedUntil),
+	}
+}
+
+// adminGetUserLockout handles GET request, returns the failed logins of a username
+// and whether its logins are locked
+func (server *Server) adminGetUserLockout(ctx *gin.Context) {
+	var uri adminUserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.recordAdminAction(ctx, db.AdminActionGetUserLockout, db.AdminTargetUser, uri.Username) {
+		return
+	}
+
+	// usernames without failed logins have no record
+	failure, err := server.store.GetLoginFailure(ctx, db.GetLoginFailureParams{
+		KeyType: db.LoginKeyUsername,
+		Key:     uri.Username,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserLockoutResponse(uri.Username, failure))
+}
+
+// adminUnlockUser handles DELETE request, clears the failed logins of a username,
+// so it can log in again right away
+func (server *Server) adminUnlockUser(ctx *gin.Context) {
+	var uri adminUserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	err := server.store.AdminUnlockUserTx(ctx, db.AdminUnlockUserTxParams{
+		AdminUsername: authPayload.Username,
+		Username:      uri.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// recordAdminAction writes an action that only reads data to the audit trail of the admins.
+// It writes the error response and returns false when the action cannot be recorded.
+func (server *Server) recordAdminAction(ctx *gin.Context, action, targetType, targetID string) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	_, err := server.store.CreateAdminAction(ctx, db.CreateAdminActionParams{
+		AdminUsername: authPayload.Username,
+		Action:        action,
+		TargetType:    targetType,
+		TargetID:      targetID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+type adminListAuditEventsQuery struct {
+	Actor      *string    `form:"actor"`
+	Action     *string    `form:"action"`
+	TargetType *string    `form:"target_type"`
+	TargetID   *string    `form:"target_id"`
+	RequestID  *string    `form:"request_id"`
+	FromTime   *time.Time `form:"from_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	ToTime     *time.Time `form:"to_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	PageID     int32      `form:"page_id" binding:"required,min=1"`
+	PageSize   int32      `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// adminListAuditEvents handles GET request, returns the events of the audit log
+// that match the filters, the most recent first
+func (server *Server) adminListAuditEvents(ctx *gin.Context) {
+	var req adminListAuditEventsQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.FromTime != nil && req.ToTime != nil && !req.FromTime.Before(*req.ToTime) {
+		err := errors.New("to_time must be after from_time")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var targetID string
+	if req.TargetID != nil {
+		targetID = *req.TargetID
+	}
+	if !server.recordAdminAction(ctx, db.AdminActionListAuditEvents, db.AdminTargetAuditLog, targetID) {
+		return
+	}
+
+	params := db.ListAuditEventsParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+	if req.Actor != nil {
+		params.Actor = sql.NullString{String: *req.Actor, Valid: true}
+	}
+	if req.Action != nil {
+		params.Action = sql.NullString{String: *req.Action, Valid: true}
+	}
+	if req.TargetType != nil {
+		params.TargetType = sql.NullString{String: *req.TargetType, Valid: true}
+	}
+	if req.TargetID != nil {
+		params.TargetID = sql.NullString{String: *req.TargetID, Valid: true}
+	}
+	if req.RequestID != nil {
+		params.RequestID = sql.NullString{String: *req.RequestID, Valid: true}
+	}
+	if req.FromTime != nil {
+		params.FromTime = sql.NullTime{Time: *req.FromTime, Valid: true}
+	}
+	if req.ToTime != nil {
+		params.ToTime = sql.NullTime{Time: *req.ToTime, Valid: true}
+	}
+
+	events, err := server.store.ListAuditEvents(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, events)
+}
+
+// adminVerifyAuditChain handles GET request, recomputes the hash chain of the audit log
+// and reports the first event that was changed or whose predecessor was removed
+func (server *Server) adminVerifyAuditChain(ctx *gin.Context) {
+	if !server.recordAdminAction(ctx, db.AdminActionVerifyAuditChain, db.AdminTargetAuditLog, "") {
+		return
+	}
+
+	report, err := server.store.VerifyAuditChain(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
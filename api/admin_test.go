@@ -0,0 +1,962 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/aalug/bank-go/db/mock"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAdminSearchUsersAPI(t *testing.T) {
+	admin, _ := generateRandomUser(t)
+	user, _ := generateRandomUser(t)
+
+	type Query struct {
+		query    string
+		pageID   int
+		pageSize int
+	}
+
+	testCases := []struct {
+		name          string
+		query         Query
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: Query{query: user.Username, pageID: 1, pageSize: 5},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminAction(gomock.Any(), gomock.Eq(db.CreateAdminActionParams{
+						AdminUsername: admin.Username,
+						Action:        db.AdminActionSearchUsers,
+						TargetType:    db.AdminTargetUser,
+						TargetID:      user.Username,
+					})).
+					Times(1).
+					Return(db.AdminAction{}, nil)
+				store.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Eq(db.SearchUsersParams{
+						Query:  user.Username,
+						Limit:  5,
+						Offset: 0,
+					})).
+					Times(1).
+					Return([]db.User{user}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var users []userResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &users)
+				require.NoError(t, err)
+				require.Len(t, users, 1)
+				require.Equal(t, user.Username, users[0].Username)
+			},
+		},
+		{
+			name:  "Customer",
+			query: Query{query: user.Username, pageID: 1, pageSize: 5},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminAction(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "Missing Query",
+			query: Query{pageID: 1, pageSize: 5},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminAction(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "Audit Internal Server Error",
+			query: Query{query: user.Username, pageID: 1, pageSize: 5},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminAction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdminAction{}, sql.ErrConnDone)
+				store.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/admin/users", nil)
+			require.NoError(t, err)
+
+			q := req.URL.Query()
+			if tc.query.query != "" {
+				q.Add("query", tc.query.query)
+			}
+			q.Add("page_id", strconv.Itoa(tc.query.pageID))
+			q.Add("page_size", strconv.Itoa(tc.query.pageSize))
+			req.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminFreezeAccountAPI(t *testing.T) {
+	admin, _ := generateRandomUser(t)
+	user, _ := generateRandomUser(t)
+	account := generateRandomAccount(user.Username)
+	systemAccount := generateRandomAccount(db.CashSystemAccountOwner)
+
+	frozenAccount := account
+	frozenAccount.IsFrozen = true
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					SetAccountFrozenTx(gomock.Any(), gomock.Eq(db.SetAccountFrozenTxParams{
+						AdminUsername: admin.Username,
+						AccountID:     account.ID,
+						Frozen:        true,
+					})).
+					Times(1).
+					Return(frozenAccount, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, frozenAccount)
+			},
+		},
+		{
+			name:      "Customer",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					SetAccountFrozenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "Not Found",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					SetAccountFrozenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "System Account",
+			accountID: systemAccount.ID,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(systemAccount.ID)).
+					Times(1).
+					Return(systemAccount, nil)
+				store.EXPECT().
+					SetAccountFrozenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "Internal Server Error",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					SetAccountFrozenTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "Invalid ID",
+			accountID: 0,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/accounts/%d/freeze", tc.accountID)
+			req, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminBlockSessionAPI(t *testing.T) {
+	admin, _ := generateRandomUser(t)
+	user, _ := generateRandomUser(t)
+	session := db.Session{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		RefreshToken: utils.RandomString(32),
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+
+	blockedSession := session
+	blockedSession.IsBlocked = true
+
+	testCases := []struct {
+		name          string
+		sessionID     string
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			sessionID: session.ID.String(),
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					AdminBlockSessionTx(gomock.Any(), gomock.Eq(db.AdminBlockSessionTxParams{
+						AdminUsername: admin.Username,
+						SessionID:     session.ID,
+					})).
+					Times(1).
+					Return(blockedSession, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res map[string]any
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, true, res["is_blocked"])
+				require.NotContains(t, res, "refresh_token")
+			},
+		},
+		{
+			name:      "Not Found",
+			sessionID: session.ID.String(),
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+				store.EXPECT().
+					AdminBlockSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "Customer",
+			sessionID: session.ID.String(),
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "Invalid ID",
+			sessionID: "invalid",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/sessions/%s/block", tc.sessionID)
+			req, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminListAccountTransfersAPI(t *testing.T) {
+	admin, _ := generateRandomUser(t)
+	user1, _ := generateRandomUser(t)
+	user2, _ := generateRandomUser(t)
+	account := generateRandomAccount(user1.Username)
+	otherAccount := generateRandomAccount(user2.Username)
+
+	transfers := []db.Transfer{
+		{ID: utils.RandomInt(1, 1000), FromAccountID: account.ID, ToAccountID: otherAccount.ID, Amount: 10},
+		{ID: utils.RandomInt(1, 1000), FromAccountID: otherAccount.ID, ToAccountID: account.ID, Amount: 20},
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CreateAdminAction(gomock.Any(), gomock.Eq(db.CreateAdminActionParams{
+						AdminUsername: admin.Username,
+						Action:        db.AdminActionListAccountTransfers,
+						TargetType:    db.AdminTargetAccount,
+						TargetID:      strconv.FormatInt(account.ID, 10),
+					})).
+					Times(1).
+					Return(db.AdminAction{}, nil)
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Eq(db.ListTransfersParams{
+						FromAccountID: account.ID,
+						ToAccountID:   account.ID,
+						Limit:         5,
+						Offset:        0,
+					})).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotTransfers []db.Transfer
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotTransfers)
+				require.NoError(t, err)
+				require.Equal(t, transfers, gotTransfers)
+			},
+		},
+		{
+			name:      "Not Found",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateAdminAction(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "Customer",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/accounts/%d/transfers?page_id=1&page_size=5", tc.accountID)
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminUserLockoutAPI(t *testing.T) {
+	admin, _ := generateRandomUser(t)
+	user, _ := generateRandomUser(t)
+
+	failure := db.LoginFailure{
+		KeyType:      db.LoginKeyUsername,
+		Key:          user.Username,
+		Failures:     5,
+		LastFailedAt: time.Now(),
+		LockedUntil:  time.Now().Add(15 * time.Minute),
+	}
+
+	testCases := []struct {
+		name          string
+		method        string
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Get Locked",
+			method: http.MethodGet,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminAction(gomock.Any(), gomock.Eq(db.CreateAdminActionParams{
+						AdminUsername: admin.Username,
+						Action:        db.AdminActionGetUserLockout,
+						TargetType:    db.AdminTargetUser,
+						TargetID:      user.Username,
+					})).
+					Times(1)
+				store.EXPECT().
+					GetLoginFailure(gomock.Any(), gomock.Eq(db.GetLoginFailureParams{
+						KeyType: db.LoginKeyUsername,
+						Key:     user.Username,
+					})).
+					Times(1).
+					Return(failure, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res userLockoutResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, res.Username)
+				require.Equal(t, failure.Failures, res.Failures)
+				require.True(t, res.IsLocked)
+			},
+		},
+		{
+			name:   "Get Without Failures",
+			method: http.MethodGet,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminAction(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					GetLoginFailure(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginFailure{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res userLockoutResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, res.Username)
+				require.Zero(t, res.Failures)
+				require.False(t, res.IsLocked)
+			},
+		},
+		{
+			name:   "Unlock",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminUnlockUserTx(gomock.Any(), gomock.Eq(db.AdminUnlockUserTxParams{
+						AdminUsername: admin.Username,
+						Username:      user.Username,
+					})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:   "Unlock Internal Error",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdminUnlockUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "Customer",
+			method: http.MethodGet,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginFailure(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/users/%s/lockout", user.Username)
+			req, err := http.NewRequest(tc.method, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminListAuditEventsAPI(t *testing.T) {
+	admin, _ := generateRandomUser(t)
+	user, _ := generateRandomUser(t)
+
+	events := []db.AuditEvent{
+		{
+			ID:         utils.RandomInt(1, 1000),
+			Actor:      user.Username,
+			Action:     db.AuditActionUpdateUser,
+			TargetType: db.AuditTargetUser,
+			TargetID:   user.Username,
+			Before:     json.RawMessage(`{"email":"old@example.com"}`),
+			After:      json.RawMessage(`{"email":"new@example.com"}`),
+			Hash:       utils.RandomString(64),
+			CreatedAt:  time.Now().UTC().Truncate(time.Second),
+		},
+	}
+
+	fromTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	toTime := fromTime.Add(24 * time.Hour)
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			query: fmt.Sprintf("actor=%s&target_type=%s&target_id=%s&from_time=%s&to_time=%s&page_id=1&page_size=5",
+				user.Username, db.AuditTargetUser, user.Username, fromTime.Format(time.RFC3339), toTime.Format(time.RFC3339)),
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminAction(gomock.Any(), gomock.Eq(db.CreateAdminActionParams{
+						AdminUsername: admin.Username,
+						Action:        db.AdminActionListAuditEvents,
+						TargetType:    db.AdminTargetAuditLog,
+						TargetID:      user.Username,
+					})).
+					Times(1).
+					Return(db.AdminAction{}, nil)
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.ListAuditEventsParams) ([]db.AuditEvent, error) {
+						require.Equal(t, sql.NullString{String: user.Username, Valid: true}, arg.Actor)
+						require.False(t, arg.Action.Valid)
+						require.Equal(t, sql.NullString{String: db.AuditTargetUser, Valid: true}, arg.TargetType)
+						require.Equal(t, sql.NullString{String: user.Username, Valid: true}, arg.TargetID)
+						require.False(t, arg.RequestID.Valid)
+						require.True(t, arg.FromTime.Valid)
+						require.True(t, fromTime.Equal(arg.FromTime.Time))
+						require.True(t, arg.ToTime.Valid)
+						require.True(t, toTime.Equal(arg.ToTime.Time))
+						require.Equal(t, int32(5), arg.Limit)
+						require.Equal(t, int32(0), arg.Offset)
+						return events, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotEvents []db.AuditEvent
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotEvents)
+				require.NoError(t, err)
+				require.Len(t, gotEvents, 1)
+				require.Equal(t, events[0].ID, gotEvents[0].ID)
+				require.JSONEq(t, string(events[0].Before), string(gotEvents[0].Before))
+				require.JSONEq(t, string(events[0].After), string(gotEvents[0].After))
+			},
+		},
+		{
+			name: "Invalid Time Range",
+			query: fmt.Sprintf("from_time=%s&to_time=%s&page_id=1&page_size=5",
+				toTime.Format(time.RFC3339), fromTime.Format(time.RFC3339)),
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminAction(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "Invalid Page Size",
+			query: "page_id=1&page_size=100",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "Customer",
+			query: "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/admin/audit_events?" + tc.query
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAdminVerifyAuditChainAPI(t *testing.T) {
+	admin, _ := generateRandomUser(t)
+	user, _ := generateRandomUser(t)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Broken Chain",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminAction(gomock.Any(), gomock.Eq(db.CreateAdminActionParams{
+						AdminUsername: admin.Username,
+						Action:        db.AdminActionVerifyAuditChain,
+						TargetType:    db.AdminTargetAuditLog,
+					})).
+					Times(1).
+					Return(db.AdminAction{}, nil)
+				store.EXPECT().
+					VerifyAuditChain(gomock.Any()).
+					Times(1).
+					Return(db.AuditChainReport{CheckedAt: time.Now(), Checked: 3, BrokenAtID: 3}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var report db.AuditChainReport
+				err := json.Unmarshal(recorder.Body.Bytes(), &report)
+				require.NoError(t, err)
+				require.False(t, report.Valid)
+				require.Equal(t, int64(3), report.Checked)
+				require.Equal(t, int64(3), report.BrokenAtID)
+			},
+		},
+		{
+			name: "Internal Error",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAdminAction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdminAction{}, nil)
+				store.EXPECT().
+					VerifyAuditChain(gomock.Any()).
+					Times(1).
+					Return(db.AuditChainReport{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "Customer",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyAuditChain(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/admin/audit_events/verify", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
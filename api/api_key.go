@@ -0,0 +1,345 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+var errInvalidAPIKey = errors.New("invalid API key")
+
+type apiKeyResponse struct {
+	ID         int64      `json:"id"`
+	Prefix     string     `json:"prefix"`
+	Owner      string     `json:"owner"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	AccountIDs []int64    `json:"account_ids"`
+	CreatedBy  string     `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newAPIKeyResponse(apiKey db.APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         apiKey.ID,
+		Prefix:     apiKey.Prefix,
+		Owner:      apiKey.Owner,
+		Name:       apiKey.Name,
+		Scopes:     apiKey.Scopes,
+		AccountIDs: apiKey.AccountIds,
+		CreatedBy:  apiKey.CreatedBy,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		LastUsedIP: apiKey.LastUsedIp,
+		RevokedAt:  apiKey.RevokedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+type createAPIKeyRequest struct {
+	Name       string     `json:"name" binding:"required,max=100"`
+	Scopes     []string   `json:"scopes" binding:"required,min=1,unique,dive,scope"`
+	AccountIDs []int64    `json:"account_ids" binding:"omitempty,unique,dive,min=1"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+type createAPIKeyResponse struct {
+	// Key is returned only once, only its hash is stored
+	Key    string         `json:"key"`
+	APIKey apiKeyResponse `json:"api_key"`
+}
+
+// createAPIKey handles POST request, creates an API key of the authenticated user
+func (server *Server) createAPIKey(ctx *gin.Context) {
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	server.issueAPIKey(ctx, authPayload.Username, req, false)
+}
+
+// adminCreateAPIKey handles POST request, creates an API key of any user
+func (server *Server) adminCreateAPIKey(ctx *gin.Context) {
+	var uri adminUserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	server.issueAPIKey(ctx, uri.Username, req, true)
+}
+
+// issueAPIKey creates an API key of the owner and writes the response.
+// The admin scope is granted only to admins and the allowed accounts must belong to the owner.
+func (server *Server) issueAPIKey(ctx *gin.Context, owner string, req createAPIKeyRequest, byAdmin bool) {
+	user, err := server.store.GetUser(ctx, owner)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if scope == token.ScopeAdmin && user.Role != utils.AdminRole {
+			err := errors.New("admin scope can be granted only to admins")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		err := errors.New("expires_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	for _, accountID := range req.AccountIDs {
+		account, err := server.store.GetAccount(ctx, accountID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("account [%d] not found", accountID)))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if account.Owner != owner {
+			err := fmt.Errorf("account [%d] does not belong to the owner of the key", accountID)
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+	}
+
+	key, prefix, secret, err := utils.GenerateAPIKey()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	params := db.CreateAPIKeyParams{
+		Prefix:       prefix,
+		HashedSecret: utils.HashToken(secret),
+		Owner:        owner,
+		Name:         req.Name,
+		Scopes:       req.Scopes,
+		AccountIds:   req.AccountIDs,
+		CreatedBy:    authPayload.Username,
+		ExpiresAt:    req.ExpiresAt,
+	}
+	if params.AccountIds == nil {
+		params.AccountIds = []int64{}
+	}
+
+	var apiKey db.APIKey
+	if byAdmin {
+		apiKey, err = server.store.AdminCreateAPIKeyTx(ctx, params)
+	} else {
+		apiKey, err = server.store.CreateAPIKeyTx(ctx, params)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createAPIKeyResponse{
+		Key:    key,
+		APIKey: newAPIKeyResponse(apiKey),
+	})
+}
+
+type listAPIKeysRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listAPIKeys handles GET request, returns the API keys of the authenticated user
+func (server *Server) listAPIKeys(ctx *gin.Context) {
+	var req listAPIKeysRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	apiKeys, err := server.store.ListAPIKeys(ctx, db.ListAPIKeysParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := make([]apiKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		res = append(res, newAPIKeyResponse(apiKey))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type apiKeyURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// revokeAPIKey handles DELETE request, revokes an API key of the authenticated user
+func (server *Server) revokeAPIKey(ctx *gin.Context) {
+	var uri apiKeyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	apiKey, err := server.store.GetAPIKey(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if apiKey.Owner != authPayload.Username {
+		err := errors.New("API key does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// a key that is already revoked is left as it is
+	_, err = server.store.RevokeAPIKeyTx(ctx, apiKey.ID)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// adminRevokeAPIKey handles DELETE request, revokes an API key of any user
+func (server *Server) adminRevokeAPIKey(ctx *gin.Context) {
+	var uri apiKeyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	apiKey, err := server.store.GetAPIKey(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if apiKey.RevokedAt != nil {
+		ctx.JSON(http.StatusNoContent, nil)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	_, err = server.store.AdminRevokeAPIKeyTx(ctx, db.AdminRevokeAPIKeyTxParams{
+		AdminUsername: authPayload.Username,
+		APIKeyID:      apiKey.ID,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// authenticateAPIKey returns the payload of a valid API key and records its use.
+// It aborts the request with the error response and returns false otherwise.
+func authenticateAPIKey(ctx *gin.Context, store db.Store, key string) (*token.Payload, bool) {
+	prefix, secret, err := utils.ParseAPIKey(key)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errInvalidAPIKey))
+		return nil, false
+	}
+
+	apiKey, err := store.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errInvalidAPIKey))
+			return nil, false
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(secret)), []byte(apiKey.HashedSecret)) != 1 {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errInvalidAPIKey))
+		return nil, false
+	}
+
+	if apiKey.RevokedAt != nil {
+		err := errors.New("API key has been revoked")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		return nil, false
+	}
+
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		err := errors.New("API key has expired")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		return nil, false
+	}
+
+	// the last use is only informative, a failure to record it does not fail the request
+	err = store.TouchAPIKey(ctx, db.TouchAPIKeyParams{
+		ID:       apiKey.ID,
+		ClientIp: ctx.ClientIP(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot record the use of API key",
+			slog.Int64("api_key_id", apiKey.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	payload := &token.Payload{
+		Username:   apiKey.Owner,
+		Role:       apiKey.OwnerRole,
+		IssuedAt:   apiKey.CreatedAt,
+		APIKeyID:   apiKey.ID,
+		Scopes:     apiKey.Scopes,
+		AccountIDs: apiKey.AccountIds,
+	}
+	if apiKey.ExpiresAt != nil {
+		payload.ExpiredAt = *apiKey.ExpiresAt
+	}
+
+	return payload, true
+}
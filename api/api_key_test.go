@@ -0,0 +1,544 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/aalug/bank-go/db/mock"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreateAPIKeyAPI(t *testing.T) {
+	user, _ := generateRandomUser(t)
+	user.Role = utils.CustomerRole
+	account := generateRandomAccount(user.Username)
+	otherAccount := generateRandomAccount(utils.RandomOwner())
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name":        "back office",
+				"scopes":      []string{token.ScopeAccountsRead, token.ScopeTransfersWrite},
+				"account_ids": []int64{account.ID},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CreateAPIKeyTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAPIKeyParams) (db.APIKey, error) {
+						require.Equal(t, user.Username, arg.Owner)
+						require.Equal(t, user.Username, arg.CreatedBy)
+						require.Equal(t, []int64{account.ID}, arg.AccountIds)
+						require.NotEmpty(t, arg.Prefix)
+						require.NotEmpty(t, arg.HashedSecret)
+						return db.APIKey{
+							ID:           1,
+							Prefix:       arg.Prefix,
+							HashedSecret: arg.HashedSecret,
+							Owner:        arg.Owner,
+							Name:         arg.Name,
+							Scopes:       arg.Scopes,
+							AccountIds:   arg.AccountIds,
+							CreatedBy:    arg.CreatedBy,
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res createAPIKeyResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+
+				prefix, _, err := utils.ParseAPIKey(res.Key)
+				require.NoError(t, err)
+				require.Equal(t, prefix, res.APIKey.Prefix)
+				require.NotContains(t, recorder.Body.String(), "hashed_secret")
+			},
+		},
+		{
+			name: "Unsupported Scope",
+			body: gin.H{
+				"name":   "back office",
+				"scopes": []string{"accounts:delete"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAPIKeyTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Admin Scope Of Customer",
+			body: gin.H{
+				"name":   "back office",
+				"scopes": []string{token.ScopeAdmin},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateAPIKeyTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Account Of Other User",
+			body: gin.H{
+				"name":        "back office",
+				"scopes":      []string{token.ScopeAccountsRead},
+				"account_ids": []int64{otherAccount.ID},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).
+					Times(1).
+					Return(otherAccount, nil)
+				store.EXPECT().
+					CreateAPIKeyTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Expiry In The Past",
+			body: gin.H{
+				"name":       "back office",
+				"scopes":     []string{token.ScopeAccountsRead},
+				"expires_at": time.Now().Add(-time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateAPIKeyTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api_keys", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestRevokeAPIKeyAPI(t *testing.T) {
+	user, _ := generateRandomUser(t)
+	apiKey, _ := generateRandomAPIKey(t, user.Username)
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(apiKey, nil)
+				store.EXPECT().
+					RevokeAPIKeyTx(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "Already Revoked",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(apiKey, nil)
+				store.EXPECT().
+					RevokeAPIKeyTx(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(db.APIKey{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "Key Of Other User",
+			username: "other_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(apiKey, nil)
+				store.EXPECT().
+					RevokeAPIKeyTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "Not Found",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(db.APIKey{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/api_keys/%d", apiKey.ID), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	user, _ := generateRandomUser(t)
+	account := generateRandomAccount(user.Username)
+	otherAccount := generateRandomAccount(user.Username)
+	otherAccount.ID = account.ID + 1
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		setupKey      func(apiKey *db.GetAPIKeyByPrefixRow)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			method:   http.MethodGet,
+			url:      fmt.Sprintf("/accounts/%d", account.ID),
+			setupKey: func(apiKey *db.GetAPIKeyByPrefixRow) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TouchAPIKey(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Account Not Allowed",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", otherAccount.ID),
+			setupKey: func(apiKey *db.GetAPIKeyByPrefixRow) {
+				apiKey.AccountIds = []int64{account.ID}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TouchAPIKey(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).
+					Times(1).
+					Return(otherAccount, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "Missing Scope",
+			method: http.MethodPost,
+			url:    "/transfers",
+			setupKey: func(apiKey *db.GetAPIKeyByPrefixRow) {
+				apiKey.Scopes = []string{token.ScopeAccountsRead}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TouchAPIKey(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "Route Without Scope",
+			method:   http.MethodPost,
+			url:      "/api_keys",
+			setupKey: func(apiKey *db.GetAPIKeyByPrefixRow) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TouchAPIKey(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					CreateAPIKeyTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "Revoked",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			setupKey: func(apiKey *db.GetAPIKeyByPrefixRow) {
+				revokedAt := time.Now()
+				apiKey.RevokedAt = &revokedAt
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TouchAPIKey(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "Expired",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			setupKey: func(apiKey *db.GetAPIKeyByPrefixRow) {
+				expiresAt := time.Now().Add(-time.Minute)
+				apiKey.ExpiresAt = &expiresAt
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "Wrong Secret",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			setupKey: func(apiKey *db.GetAPIKeyByPrefixRow) {
+				apiKey.HashedSecret = utils.HashToken("wrong secret")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			apiKey, key := generateRandomAPIKey(t, user.Username)
+			row := db.GetAPIKeyByPrefixRow{
+				ID:           apiKey.ID,
+				Prefix:       apiKey.Prefix,
+				HashedSecret: apiKey.HashedSecret,
+				Owner:        apiKey.Owner,
+				Scopes:       apiKey.Scopes,
+				AccountIds:   apiKey.AccountIds,
+				OwnerRole:    utils.CustomerRole,
+			}
+			tc.setupKey(&row)
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetAPIKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).
+				Times(1).
+				Return(row, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewReader([]byte("{}")))
+			require.NoError(t, err)
+
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeAPIKey, key))
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestAdminCreateAPIKeyAPI(t *testing.T) {
+	admin, _ := generateRandomUser(t)
+	admin.Role = utils.AdminRole
+	user, _ := generateRandomUser(t)
+	user.Role = utils.CustomerRole
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		AdminCreateAPIKeyTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.CreateAPIKeyParams) (db.APIKey, error) {
+			require.Equal(t, user.Username, arg.Owner)
+			require.Equal(t, admin.Username, arg.CreatedBy)
+			return db.APIKey{ID: 1, Prefix: arg.Prefix, Owner: arg.Owner, CreatedBy: arg.CreatedBy}, nil
+		})
+	store.EXPECT().
+		CreateAPIKeyTx(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{
+		"name":   "reconciliation",
+		"scopes": []string{token.ScopeTransfersRead},
+	})
+	require.NoError(t, err)
+
+	url := fmt.Sprintf("/admin/users/%s/api_keys", user.Username)
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addRoleAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	body, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+
+	var res createAPIKeyResponse
+	err = json.Unmarshal(body, &res)
+	require.NoError(t, err)
+	require.NotEmpty(t, res.Key)
+	require.Equal(t, admin.Username, res.APIKey.CreatedBy)
+}
+
+// generateRandomAPIKey generates a random API key of the owner with the read and write scopes
+// and returns it with the key that is sent in requests
+func generateRandomAPIKey(t *testing.T, owner string) (db.APIKey, string) {
+	key, prefix, secret, err := utils.GenerateAPIKey()
+	require.NoError(t, err)
+
+	return db.APIKey{
+		ID:           utils.RandomInt(1, 1000),
+		Prefix:       prefix,
+		HashedSecret: utils.HashToken(secret),
+		Owner:        owner,
+		Name:         utils.RandomString(8),
+		Scopes: []string{
+			token.ScopeAccountsRead,
+			token.ScopeAccountsWrite,
+			token.ScopeTransfersRead,
+			token.ScopeTransfersWrite,
+		},
+		AccountIds: []int64{},
+		CreatedBy:  owner,
+		CreatedAt:  time.Now(),
+	}, key
+}
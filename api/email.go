@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/mail"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	errEmailAlreadyVerified = errors.New("email address is already verified")
+	errEmailNotVerified     = errors.New("email address must be verified before transferring money")
+)
+
+// sendVerificationEmail sends the user a link that verifies their current email address
+func (server *Server) sendVerificationEmail(ctx context.Context, user db.User) error {
+	emailToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+
+	_, err = server.store.CreateEmailTokenTx(ctx, db.CreateEmailTokenParams{
+		HashedToken: utils.HashToken(emailToken),
+		Username:    user.Username,
+		Purpose:     db.EmailTokenVerifyEmail,
+		Email:       user.Email,
+		ExpiresAt:   time.Now().Add(server.config.EmailVerificationDuration),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := strings.TrimSuffix(server.config.AppURL, "/") + "/users/verify_email?token=" + url.QueryEscape(emailToken)
+	msg := mail.NewVerifyEmailMessage(user.Email, user.FullName, link, server.config.EmailVerificationDuration)
+
+	return server.mailer.Send(ctx, msg)
+}
+
+// resendVerificationEmail handles POST request, sends the authenticated user a new verification link.
+// The links sent before stop working.
+func (server *Server) resendVerificationEmail(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if user.IsEmailVerified {
+		ctx.JSON(http.StatusConflict, errorResponse(errEmailAlreadyVerified))
+		return
+	}
+
+	err = server.sendVerificationEmail(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+type verifyEmailRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// verifyEmail handles GET request, the link sent in the verification email.
+// It marks the address the link was sent to as verified.
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.VerifyEmailTx(ctx, utils.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidEmailToken) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type requestPasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// requestPasswordReset handles POST request, sends a password reset token to the email address.
+// The response is the same whether a user has the address or not, so it cannot be used
+// to find out who has an account.
+func (server *Server) requestPasswordReset(ctx *gin.Context) {
+	var req requestPasswordResetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusAccepted, nil)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resetToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.CreateEmailTokenTx(ctx, db.CreateEmailTokenParams{
+		HashedToken: utils.HashToken(resetToken),
+		Username:    user.Username,
+		Purpose:     db.EmailTokenResetPassword,
+		Email:       user.Email,
+		ExpiresAt:   time.Now().Add(server.config.PasswordResetDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	msg := mail.NewPasswordResetMessage(user.Email, user.FullName, resetToken, server.config.PasswordResetDuration)
+	err = server.mailer.Send(ctx, msg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, nil)
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// resetPassword handles POST request, sets a new password with the token from the password reset email.
+// All tokens of the user issued before are revoked, so every session has to log in again.
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		HashedToken:    utils.HashToken(req.Token),
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidEmailToken) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.revocations.RevokeUserTokens(user.Username, user.PasswordChangedAt)
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// validEmailVerified checks that the user verified their email address.
+// It writes the error response and returns false otherwise.
+func (server *Server) validEmailVerified(ctx *gin.Context, username string) bool {
+	user, err := server.store.GetUser(ctx, username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if !user.IsEmailVerified {
+		ctx.JSON(http.StatusForbidden, errorResponse(errEmailNotVerified))
+		return false
+	}
+
+	return true
+}
+
+// logEmailError logs an email that could not be sent, for requests that succeed without it
+func logEmailError(ctx context.Context, username string, err error) {
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot send verification email",
+			slog.String("email_user", username),
+			slog.String("error", err.Error()),
+		)
+	}
+}
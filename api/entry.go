@@ -0,0 +1,103 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type listAccountEntriesURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listAccountEntriesQuery struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+type statementLineResponse struct {
+	ID                    int64     `json:"id"`
+	AccountID             int64     `json:"account_id"`
+	Amount                int64     `json:"amount"`
+	Kind                  string    `json:"kind"`
+	TransferID            *int64    `json:"transfer_id"`
+	CounterpartyAccountID *int64    `json:"counterparty_account_id"`
+	CounterpartyOwner     *string   `json:"counterparty_owner"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+// newStatementLineResponse converts db.ListAccountStatementRow to statementLineResponse
+func newStatementLineResponse(row db.ListAccountStatementRow) statementLineResponse {
+	res := statementLineResponse{
+		ID:         row.ID,
+		AccountID:  row.AccountID,
+		Amount:     row.Amount,
+		Kind:       row.Kind,
+		TransferID: row.TransferID,
+		CreatedAt:  row.CreatedAt,
+	}
+
+	if row.CounterpartyAccountID.Valid {
+		res.CounterpartyAccountID = &row.CounterpartyAccountID.Int64
+	}
+
+	if row.CounterpartyOwner.Valid {
+		res.CounterpartyOwner = &row.CounterpartyOwner.String
+	}
+
+	return res
+}
+
+// listAccountEntries handles GET request, returns the statement of the account:
+// its entries with the transfer and the counterparty that produced them
+func (server *Server) listAccountEntries(ctx *gin.Context) {
+	var uri listAccountEntriesURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listAccountEntriesQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username || !authPayload.AllowsAccount(account.ID) {
+		err := errors.New("account does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	rows, err := server.store.ListAccountStatement(ctx, db.ListAccountStatementParams{
+		AccountID: account.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := make([]statementLineResponse, len(rows))
+	for i, row := range rows {
+		res[i] = newStatementLineResponse(row)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
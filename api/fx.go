@@ -0,0 +1,167 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/fx"
+	"github.com/aalug/bank-go/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+)
+
+type createQuoteRequest struct {
+	FromCurrency string `json:"from_currency" binding:"required,currency"`
+	ToCurrency   string `json:"to_currency" binding:"required,currency,nefield=FromCurrency"`
+	// Amount is optional, when it is set the response contains the converted amount
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+type quoteResponse struct {
+	ID           uuid.UUID `json:"id"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	Rate         string    `json:"rate"`
+	Spread       string    `json:"spread"`
+	Amount       int64     `json:"amount,omitempty"`
+	ToAmount     int64     `json:"to_amount,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// createQuote handles POST request, locks the exchange rate between two currencies
+func (server *Server) createQuote(ctx *gin.Context) {
+	var req createQuoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	quote, err := server.store.CreateQuoteTx(ctx, db.CreateQuoteTxParams{
+		Username:     authPayload.Username,
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+		Spread:       server.config.FXSpread,
+		Duration:     server.config.FXQuoteDuration,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrFXRateNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := quoteResponse{
+		ID:           quote.ID,
+		FromCurrency: quote.FromCurrency,
+		ToCurrency:   quote.ToCurrency,
+		Rate:         quote.Rate,
+		Spread:       quote.Spread,
+		ExpiresAt:    quote.ExpiresAt,
+	}
+
+	if req.Amount > 0 {
+		rsp.Amount = req.Amount
+		rsp.ToAmount, err = quote.Convert(req.Amount)
+		if err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type fxTransferRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	QuoteID       string `json:"quote_id" binding:"required,uuid"`
+	// TOTPCode is required from users with two-factor authentication for amounts from the step-up amount
+	TOTPCode string `json:"totp_code" binding:"omitempty,len=6,numeric"`
+}
+
+// createFXTransfer handles POST request, transfers money between accounts
+// of different currencies using the rate locked by a quote
+func (server *Server) createFXTransfer(ctx *gin.Context) {
+	var req fxTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	quoteID, err := uuid.Parse(req.QuoteID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	quote, err := server.store.GetFXQuote(ctx, quoteID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if quote.Username != authPayload.Username {
+		err := errors.New("quote does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, quote.FromCurrency)
+	if !valid {
+		return
+	}
+
+	if fromAccount.Owner != authPayload.Username || !authPayload.AllowsAccount(fromAccount.ID) {
+		err := errors.New("from account does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	_, valid = server.validAccount(ctx, req.ToAccountID, quote.ToCurrency)
+	if !valid {
+		return
+	}
+
+	if !server.validEmailVerified(ctx, authPayload.Username) {
+		return
+	}
+
+	if !server.validStepUp(ctx, authPayload.Username, req.Amount, req.TOTPCode) {
+		return
+	}
+
+	result, err := server.store.FXTransferTx(ctx, db.FXTransferTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		QuoteID:       quoteID,
+		Username:      authPayload.Username,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds),
+			errors.Is(err, db.ErrAccountFrozen),
+			errors.Is(err, db.ErrFXQuoteExpired),
+			errors.Is(err, db.ErrFXQuoteUsed),
+			errors.Is(err, fx.ErrAmountTooSmall),
+			errors.Is(err, fx.ErrAmountTooLarge):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
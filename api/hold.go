@@ -0,0 +1,198 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+// createHold handles POST request, authorizes a hold that reserves the amount
+// on the from account until it is captured, voided or expires
+func (server *Server) createHold(ctx *gin.Context) {
+	var req transferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username || !authPayload.AllowsAccount(fromAccount.ID) {
+		err := errors.New("from account does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.validEmailVerified(ctx, authPayload.Username) {
+		return
+	}
+
+	if !server.validStepUp(ctx, authPayload.Username, req.Amount, req.TOTPCode) {
+		return
+	}
+
+	result, err := server.store.AuthorizeTx(ctx, db.AuthorizeTxParams{
+		AccountID:   req.FromAccountID,
+		ToAccountID: req.ToAccountID,
+		Amount:      req.Amount,
+		Duration:    server.config.HoldDuration,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, result)
+}
+
+type holdURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getHold handles GET request, returns the hold with given ID
+func (server *Server) getHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, valid := server.ownHold(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+type captureHoldRequest struct {
+	// Amount is optional, the whole hold is captured when it is omitted
+	Amount int64 `json:"amount" binding:"min=0"`
+	// TOTPCode is required from users with two-factor authentication for amounts from the step-up amount
+	TOTPCode string `json:"totp_code" binding:"omitempty,len=6,numeric"`
+}
+
+// captureHold handles POST request, moves the held amount, or a part of it,
+// to the recipient of the hold and releases the rest
+func (server *Server) captureHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the body is optional
+	var req captureHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, valid := server.ownHold(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !server.validEmailVerified(ctx, authPayload.Username) {
+		return
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if !server.validStepUp(ctx, authPayload.Username, amount, req.TOTPCode) {
+		return
+	}
+
+	result, err := server.store.CaptureTx(ctx, db.CaptureTxParams{
+		HoldID: uri.ID,
+		Amount: req.Amount,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrHoldNotAuthorized) ||
+			errors.Is(err, db.ErrHoldExpired) ||
+			errors.Is(err, db.ErrCaptureExceedsHold) ||
+			errors.Is(err, db.ErrInsufficientFunds) ||
+			errors.Is(err, db.ErrAccountFrozen) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// voidHold handles POST request, releases the hold without moving money
+func (server *Server) voidHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.ownHold(ctx, uri.ID); !valid {
+		return
+	}
+
+	result, err := server.store.VoidTx(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrHoldNotAuthorized) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// ownHold returns the hold with given ID if the held account belongs to the authenticated user,
+// otherwise it writes the error response
+func (server *Server) ownHold(ctx *gin.Context, id int64) (db.Hold, bool) {
+	hold, err := server.store.GetHold(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return hold, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hold, false
+	}
+
+	account, err := server.store.GetAccount(ctx, hold.AccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hold, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username || !authPayload.AllowsAccount(account.ID) {
+		err := errors.New("hold does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return hold, false
+	}
+
+	return hold, true
+}
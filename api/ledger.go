@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ledgerRequest struct {
+	AccountID int64  `json:"account_id" binding:"required,min=1"`
+	Amount    int64  `json:"amount" binding:"required,gt=0"`
+	Currency  string `json:"currency" binding:"required,currency"`
+	Channel   string `json:"channel" binding:"required,oneof=cash settlement"`
+}
+
+// createDeposit handles POST request of an admin, puts money into a customer account
+func (server *Server) createDeposit(ctx *gin.Context) {
+	var req ledgerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.validAccount(ctx, req.AccountID, req.Currency); !valid {
+		return
+	}
+
+	result, err := server.store.DepositTx(ctx, db.DepositTxParams{
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+		Channel:   req.Channel,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// createWithdrawal handles POST request of an admin, takes money out of a customer account
+func (server *Server) createWithdrawal(ctx *gin.Context) {
+	var req ledgerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.AccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	// money leaves the bank only for customers who verified their email address
+	if !server.validEmailVerified(ctx, account.Owner) {
+		return
+	}
+
+	result, err := server.store.WithdrawTx(ctx, db.WithdrawTxParams{
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+		Channel:   req.Channel,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,449 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	mockdb "github.com/aalug/bank-go/db/mock"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreateDepositAPI(t *testing.T) {
+	var amount int64 = 10
+
+	user, _ := generateRandomUser(t)
+	admin, _ := generateRandomUser(t)
+	account := generateRandomAccount(user.Username)
+	systemAccount := generateRandomAccount(db.CashSystemAccountOwner)
+	systemAccount.Currency = account.Currency
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+				"channel":    db.CashChannel,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				params := db.DepositTxParams{
+					AccountID: account.ID,
+					Amount:    amount,
+					Channel:   db.CashChannel,
+				}
+
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Eq(params)).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Customer",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+				"channel":    db.CashChannel,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "No Authorization",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+				"channel":    db.CashChannel,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "System Account",
+			body: gin.H{
+				"account_id": systemAccount.ID,
+				"amount":     amount,
+				"currency":   systemAccount.Currency,
+				"channel":    db.CashChannel,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(systemAccount.ID)).
+					Times(1).
+					Return(systemAccount, nil)
+
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Channel",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+				"channel":    "invalid",
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Account Not Found",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+				"channel":    db.SettlementChannel,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "DepositTx Error",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+				"channel":    db.CashChannel,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, sql.ErrTxDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/admin/deposits"
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, req)
+
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCreateWithdrawalAPI(t *testing.T) {
+	var amount int64 = 10
+
+	user, _ := generateRandomUser(t)
+	admin, _ := generateRandomUser(t)
+	user.IsEmailVerified = true
+	account := generateRandomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+				"channel":    db.SettlementChannel,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				params := db.WithdrawTxParams{
+					AccountID: account.ID,
+					Amount:    amount,
+					Channel:   db.SettlementChannel,
+				}
+
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Eq(params)).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Email Not Verified",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+				"channel":    db.SettlementChannel,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				unverifiedUser := user
+				unverifiedUser.IsEmailVerified = false
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(unverifiedUser, nil)
+
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Customer",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+				"channel":    db.SettlementChannel,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Insufficient Funds",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+				"channel":    db.CashChannel,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "Negative Amount",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     -amount,
+				"currency":   account.Currency,
+				"channel":    db.CashChannel,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "WithdrawTx Error",
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     amount,
+				"currency":   account.Currency,
+				"channel":    db.CashChannel,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, admin.Username, utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, sql.ErrTxDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				AnyTimes().
+				Return(user, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/admin/withdrawals"
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, req)
+
+			tc.checkResponse(recorder)
+		})
+	}
+}
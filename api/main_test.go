@@ -2,6 +2,7 @@ package api
 
 import (
 	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/mail"
 	"github.com/aalug/bank-go/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -12,11 +13,21 @@ import (
 
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := utils.Config{
-		TokenSymmetricKey:   utils.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:         utils.RandomString(32),
+		AccessTokenDuration:       time.Minute,
+		RefreshTokenDuration:      time.Hour,
+		IdempotencyKeyDuration:    time.Hour,
+		LoginFailureThreshold:     5,
+		LoginIPFailureThreshold:   50,
+		LoginLockoutDuration:      time.Minute,
+		MFAChallengeDuration:      time.Minute,
+		OAuthCodeDuration:         time.Minute,
+		OAuthRefreshTokenDuration: time.Hour,
+		// above the amounts of the other tests, so only the step-up tests need a code
+		StepUpTransferAmount: 1000000,
 	}
 
-	server, err := NewServer(config, store)
+	server, err := NewServer(config, store, mail.NewMemorySender())
 	require.NoError(t, err)
 
 	return server
@@ -3,20 +3,64 @@ package api
 import (
 	"errors"
 	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/logger"
+	"github.com/aalug/bank-go/metrics"
 	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
 	"github.com/gin-gonic/gin"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 )
 
 const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
+	authorizationTypeAPIKey = "apikey"
 	authorizationPayloadKey = "authorization_payload"
 )
 
-// AuthMiddleware creates a gin middleware for authorization
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// routeScopes is the scope an API key or an OAuth client needs for every route it can be used with.
+// Routes that are not listed here, like managing users, sessions, API keys and OAuth clients,
+// take only access tokens of users.
+var routeScopes = map[string]string{
+	"GET /accounts/:id":                     token.ScopeAccountsRead,
+	"GET /accounts":                         token.ScopeAccountsRead,
+	"GET /accounts/:id/entries":             token.ScopeAccountsRead,
+	"POST /accounts":                        token.ScopeAccountsWrite,
+	"DELETE /accounts/:id":                  token.ScopeAccountsWrite,
+	"GET /holds/:id":                        token.ScopeTransfersRead,
+	"GET /scheduled_transfers/:id":          token.ScopeTransfersRead,
+	"GET /scheduled_transfers":              token.ScopeTransfersRead,
+	"GET /scheduled_transfers/:id/runs":     token.ScopeTransfersRead,
+	"POST /transfers":                       token.ScopeTransfersWrite,
+	"POST /transfers/:id/reverse":           token.ScopeTransfersWrite,
+	"POST /holds":                           token.ScopeTransfersWrite,
+	"POST /holds/:id/capture":               token.ScopeTransfersWrite,
+	"POST /holds/:id/void":                  token.ScopeTransfersWrite,
+	"POST /scheduled_transfers":             token.ScopeTransfersWrite,
+	"PATCH /scheduled_transfers/:id":        token.ScopeTransfersWrite,
+	"DELETE /scheduled_transfers/:id":       token.ScopeTransfersWrite,
+	"POST /fx/quotes":                       token.ScopeTransfersWrite,
+	"POST /fx/transfers":                    token.ScopeTransfersWrite,
+	"GET /admin/users":                      token.ScopeAdmin,
+	"GET /admin/users/:username/lockout":    token.ScopeAdmin,
+	"DELETE /admin/users/:username/lockout": token.ScopeAdmin,
+	"POST /admin/accounts/:id/freeze":       token.ScopeAdmin,
+	"POST /admin/accounts/:id/unfreeze":     token.ScopeAdmin,
+	"GET /admin/accounts/:id/transfers":     token.ScopeAdmin,
+	"POST /admin/sessions/:id/block":        token.ScopeAdmin,
+	"GET /admin/audit_events":               token.ScopeAdmin,
+	"GET /admin/audit_events/verify":        token.ScopeAdmin,
+	"POST /admin/deposits":                  token.ScopeAdmin,
+	"POST /admin/withdrawals":               token.ScopeAdmin,
+}
+
+// AuthMiddleware creates a gin middleware for authorization.
+// It accepts bearer access tokens and API keys, the ones limited by scopes need the scope of the route.
+func authMiddleware(tokenMaker token.Maker, revocations *token.RevocationList, store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 
@@ -34,6 +78,17 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		}
 
 		authorizationType := strings.ToLower(fields[0])
+		if authorizationType == authorizationTypeAPIKey {
+			payload, ok := authenticateAPIKey(ctx, store, fields[1])
+			if !ok || !authorizeRouteScope(ctx, payload) {
+				return
+			}
+
+			setAuthorizationPayload(ctx, payload)
+			ctx.Next()
+			return
+		}
+
 		if authorizationType != authorizationTypeBearer {
 			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
@@ -47,7 +102,125 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 			return
 		}
 
-		ctx.Set(authorizationPayloadKey, payload)
+		err = revocations.Check(payload)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		if payload.IsScoped() && !authorizeRouteScope(ctx, payload) {
+			return
+		}
+
+		setAuthorizationPayload(ctx, payload)
+		ctx.Next()
+	}
+}
+
+// authorizeRouteScope checks if the payload of an API key or an OAuth client has the scope of the route.
+// It aborts the request with the error response and returns false otherwise.
+func authorizeRouteScope(ctx *gin.Context, payload *token.Payload) bool {
+	scope, ok := routeScopes[ctx.Request.Method+" "+ctx.FullPath()]
+	if !ok {
+		err := errors.New("this route cannot be used with an API key or an OAuth access token")
+		ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+
+	if !payload.HasScope(scope) {
+		err := fmt.Errorf("the %s scope was not granted", scope)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// setAuthorizationPayload makes the payload of the verified token available to the handlers
+// and sets its user as the user of the request in the logs and the audit log
+func setAuthorizationPayload(ctx *gin.Context, payload *token.Payload) {
+	ctx.Set(authorizationPayloadKey, payload)
+	logger.SetUsername(ctx.Request.Context(), payload.Username)
+	setAuditActor(ctx, payload.Username)
+}
+
+// loggerMiddleware creates a gin middleware that logs every request with its route,
+// status code, latency and user. The request ID is accepted from or generated into
+// the X-Request-ID header and returned in the response.
+func loggerMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		startTime := time.Now()
+
+		requestID := logger.AcceptRequestID(ctx.GetHeader(logger.RequestIDHeader))
+		reqCtx, _ := logger.WithRequest(ctx.Request.Context(), requestID)
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+		ctx.Header(logger.RequestIDHeader, requestID)
+
+		ctx.Next()
+
+		statusCode := ctx.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("protocol", "http"),
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", ctx.FullPath()),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status_code", statusCode),
+			slog.String("status_text", http.StatusText(statusCode)),
+			slog.Duration("latency", time.Since(startTime)),
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", ctx.Errors.String()))
+		}
+
+		slog.LogAttrs(ctx.Request.Context(), logger.HTTPStatusLevel(statusCode), "received an HTTP request", attrs...)
+	}
+}
+
+// metricsMiddleware creates a gin middleware that records the rate, the errors
+// and the duration of every request by its route
+func metricsMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		startTime := time.Now()
+
+		ctx.Next()
+
+		metrics.ObserveHTTP(ctx.Request.Method, ctx.FullPath(), ctx.Writer.Status(), time.Since(startTime))
+	}
+}
+
+// auditMiddleware creates a gin middleware that puts the audit info of the request into its context.
+// It must run after loggerMiddleware, which sets the request ID. The actor is set by authMiddleware,
+// requests that are not authenticated are recorded as anonymous.
+func auditMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		info := db.AuditInfo{
+			RequestID: logger.RequestID(ctx.Request.Context()),
+			ClientIP:  ctx.ClientIP(),
+			UserAgent: ctx.Request.UserAgent(),
+		}
+		ctx.Request = ctx.Request.WithContext(db.WithAuditInfo(ctx.Request.Context(), info))
+		ctx.Next()
+	}
+}
+
+// setAuditActor sets the actor of the audit info of the request
+func setAuditActor(ctx *gin.Context, actor string) {
+	info := db.AuditInfoFromContext(ctx.Request.Context())
+	info.Actor = actor
+	ctx.Request = ctx.Request.WithContext(db.WithAuditInfo(ctx.Request.Context(), info))
+}
+
+// adminMiddleware creates a gin middleware that lets only admins through.
+// It must run after authMiddleware.
+func adminMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if authPayload.Role != utils.AdminRole {
+			err := errors.New("admin role is required")
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
 		ctx.Next()
 	}
 }
@@ -2,7 +2,10 @@ package api
 
 import (
 	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/logger"
 	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 	"net/http"
@@ -19,7 +22,19 @@ func addAuthorization(
 	username string,
 	duration time.Duration,
 ) {
-	tkn, payload, err := tokenMaker.CreateToken(username, duration)
+	addRoleAuthorization(t, request, tokenMaker, authorizationType, username, utils.CustomerRole, duration)
+}
+
+func addRoleAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker token.Maker,
+	authorizationType string,
+	username string,
+	role string,
+	duration time.Duration,
+) {
+	tkn, payload, err := tokenMaker.CreateToken(username, role, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -84,7 +99,7 @@ func TestAuthMiddleware(t *testing.T) {
 			authPath := "/auth"
 			server.router.GET(
 				authPath,
-				authMiddleware(server.tokenMaker),
+				authMiddleware(server.tokenMaker, server.revocations, server.store),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
@@ -100,3 +115,221 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddlewareRevokedToken(t *testing.T) {
+	server := newTestServer(t, nil)
+	authPath := "/auth"
+	server.router.GET(
+		authPath,
+		authMiddleware(server.tokenMaker, server.revocations, server.store),
+		func(ctx *gin.Context) {
+			ctx.JSON(http.StatusOK, gin.H{})
+		},
+	)
+
+	request := func(t *testing.T, username string) (*token.Payload, func() int) {
+		tkn, payload, err := server.tokenMaker.CreateToken(username, utils.CustomerRole, time.Minute)
+		require.NoError(t, err)
+
+		return payload, func() int {
+			recorder := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			req.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, tkn))
+			server.router.ServeHTTP(recorder, req)
+			return recorder.Code
+		}
+	}
+
+	// revoked by id
+	payload, send := request(t, "user1")
+	require.Equal(t, http.StatusOK, send())
+
+	server.revocations.RevokeToken(payload)
+	require.Equal(t, http.StatusUnauthorized, send())
+
+	_, send = request(t, "user1")
+	require.Equal(t, http.StatusOK, send())
+
+	// revoked by a password change of the user
+	_, sendOld := request(t, "user2")
+	_, sendOther := request(t, "user3")
+	server.revocations.RevokeUserTokens("user2", time.Now())
+
+	_, sendNew := request(t, "user2")
+	require.Equal(t, http.StatusUnauthorized, sendOld())
+	require.Equal(t, http.StatusOK, sendNew())
+	require.Equal(t, http.StatusOK, sendOther())
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addRoleAuthorization(t, r, maker, authorizationTypeBearer, "admin", utils.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Customer",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, "user", time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "No authorization header",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil) // nil because for middleware tests db is not needed
+			adminPath := "/admin-only"
+			server.router.GET(
+				adminPath,
+				authMiddleware(server.tokenMaker, server.revocations, server.store),
+				adminMiddleware(),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, adminPath, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		authenticated bool
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		actor         string
+	}{
+		{
+			name:          "Authenticated",
+			authenticated: true,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, "user", time.Minute)
+			},
+			actor: "user",
+		},
+		{
+			name:      "Anonymous",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {},
+			actor:     db.AuditActorAnonymous,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil) // nil because for middleware tests db is not needed
+			auditPath := "/audit"
+
+			var info db.AuditInfo
+			handlers := []gin.HandlerFunc{
+				func(ctx *gin.Context) {
+					// the store gets the gin context, so the info must be readable from it
+					info = db.AuditInfoFromContext(ctx)
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			}
+			if tc.authenticated {
+				handlers = append([]gin.HandlerFunc{authMiddleware(server.tokenMaker, server.revocations, server.store)}, handlers...)
+			}
+			server.router.GET(auditPath, handlers...)
+
+			recorder := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, auditPath, nil)
+			require.NoError(t, err)
+			req.Header.Set(logger.RequestIDHeader, "request-id")
+			req.Header.Set("User-Agent", "test-agent")
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(recorder, req)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			require.Equal(t, tc.actor, info.Actor)
+			require.Equal(t, "request-id", info.RequestID)
+			require.Equal(t, "test-agent", info.UserAgent)
+		})
+	}
+}
+
+func TestLoggerMiddlewareRequestID(t *testing.T) {
+	server := newTestServer(t, nil) // nil because for middleware tests db is not needed
+	logPath := "/log"
+
+	var requestID string
+	server.router.GET(logPath, func(ctx *gin.Context) {
+		requestID = logger.RequestID(ctx)
+		ctx.JSON(http.StatusOK, gin.H{})
+	})
+
+	// accepted from the client
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, logPath, nil)
+	require.NoError(t, err)
+	req.Header.Set(logger.RequestIDHeader, "request-id")
+
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "request-id", requestID)
+	require.Equal(t, "request-id", recorder.Header().Get(logger.RequestIDHeader))
+
+	// generated when the client does not send one
+	recorder = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodGet, logPath, nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotEmpty(t, requestID)
+	require.NotEqual(t, "request-id", requestID)
+	require.Equal(t, requestID, recorder.Header().Get(logger.RequestIDHeader))
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	server := newTestServer(t, nil) // nil because for middleware tests db is not needed
+	server.router.GET("/metrics_test/:id", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusTeapot, gin.H{})
+	})
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/metrics_test/12", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusTeapot, recorder.Code)
+
+	// requests are labeled with their route, not their path
+	recorder = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodGet, "/metrics", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), `bank_http_requests_total{code="418",method="GET",route="/metrics_test/:id"} 1`)
+	require.NotContains(t, recorder.Body.String(), "/metrics_test/12")
+}
@@ -0,0 +1,693 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// error codes of the OAuth token and revocation endpoints, RFC 6749 section 5.2
+const (
+	oauthErrorInvalidRequest = "invalid_request"
+	oauthErrorInvalidClient  = "invalid_client"
+	oauthErrorInvalidGrant   = "invalid_grant"
+	oauthErrorServerError    = "server_error"
+)
+
+// oauthErrorResponse returns the error in the format of the OAuth token and revocation endpoints
+func oauthErrorResponse(code string, err error) gin.H {
+	return gin.H{
+		"error":             code,
+		"error_description": err.Error(),
+	}
+}
+
+type oauthClientResponse struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Owner          string    `json:"owner"`
+	RedirectURIs   []string  `json:"redirect_uris"`
+	Scopes         []string  `json:"scopes"`
+	IsConfidential bool      `json:"is_confidential"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func newOAuthClientResponse(client db.OAuthClient) oauthClientResponse {
+	return oauthClientResponse{
+		ID:             client.ID,
+		Name:           client.Name,
+		Owner:          client.Owner,
+		RedirectURIs:   client.RedirectUris,
+		Scopes:         client.Scopes,
+		IsConfidential: client.HashedSecret != "",
+		CreatedAt:      client.CreatedAt,
+	}
+}
+
+type createOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required,min=1,max=10,unique,dive,url"`
+	Scopes       []string `json:"scopes" binding:"required,min=1,unique,dive,scope"`
+	// Confidential clients get a secret, public clients like mobile apps cannot keep one
+	Confidential bool `json:"confidential"`
+}
+
+type createOAuthClientResponse struct {
+	// ClientSecret is returned only once and only to confidential clients, only its hash is stored
+	ClientSecret string              `json:"client_secret,omitempty"`
+	Client       oauthClientResponse `json:"client"`
+}
+
+// createOAuthClient handles POST request, registers an OAuth client (a third-party app)
+// owned by the authenticated user
+func (server *Server) createOAuthClient(ctx *gin.Context) {
+	var req createOAuthClientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if scope == token.ScopeAdmin {
+			err := errors.New("admin scope cannot be granted to OAuth clients")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	for _, redirectURI := range req.RedirectURIs {
+		u, err := url.Parse(redirectURI)
+		if err != nil || u.Fragment != "" {
+			err := fmt.Errorf("redirect URI %s must not contain a fragment", redirectURI)
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	clientID, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var clientSecret, hashedSecret string
+	if req.Confidential {
+		clientSecret, err = utils.GenerateSecureToken(32)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		hashedSecret = utils.HashToken(clientSecret)
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	client, err := server.store.CreateOAuthClientTx(ctx, db.CreateOAuthClientParams{
+		ID:           clientID,
+		HashedSecret: hashedSecret,
+		Name:         req.Name,
+		Owner:        authPayload.Username,
+		RedirectUris: req.RedirectURIs,
+		Scopes:       req.Scopes,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createOAuthClientResponse{
+		ClientSecret: clientSecret,
+		Client:       newOAuthClientResponse(client),
+	})
+}
+
+type listOAuthClientsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listOAuthClients handles GET request, returns the OAuth clients registered by the authenticated user
+func (server *Server) listOAuthClients(ctx *gin.Context) {
+	var req listOAuthClientsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	clients, err := server.store.ListOAuthClients(ctx, db.ListOAuthClientsParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := make([]oauthClientResponse, 0, len(clients))
+	for _, client := range clients {
+		res = append(res, newOAuthClientResponse(client))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// oauthAuthorizeRequest - the parameters of an authorization request, RFC 6749 section 4.1.1.
+// Only the S256 PKCE method is supported, and PKCE is required from every client
+type oauthAuthorizeRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type" binding:"required,eq=code"`
+	ClientID            string `form:"client_id" json:"client_id" binding:"required"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri" binding:"required"`
+	Scope               string `form:"scope" json:"scope" binding:"required"`
+	State               string `form:"state" json:"state" binding:"max=500"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge" binding:"required,len=43"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method" binding:"required,eq=S256"`
+}
+
+type oauthConsentRequestResponse struct {
+	Client oauthClientResponse `json:"client"`
+	Scopes []string            `json:"scopes"`
+	// Consented is true if the user has already granted all the scopes to the client,
+	// so the consent screen can be skipped
+	Consented bool `json:"consented"`
+}
+
+// getOAuthAuthorization handles GET request, checks an authorization request
+// and returns what the authenticated user is asked to consent to
+func (server *Server) getOAuthAuthorization(ctx *gin.Context) {
+	var req oauthAuthorizeRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	client, scopes, ok := server.validOAuthAuthorizeRequest(ctx, req)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	consented := false
+	consent, err := server.store.GetOAuthConsent(ctx, db.GetOAuthConsentParams{
+		Username: authPayload.Username,
+		ClientID: client.ID,
+	})
+	if err == nil {
+		consented = token.HasScopes(consent.Scopes, scopes)
+	} else if err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, oauthConsentRequestResponse{
+		Client:    newOAuthClientResponse(client),
+		Scopes:    scopes,
+		Consented: consented,
+	})
+}
+
+type authorizeOAuthClientRequest struct {
+	oauthAuthorizeRequest
+	Approve bool `json:"approve"`
+}
+
+type authorizeOAuthClientResponse struct {
+	// RedirectTo is the redirect URI of the client with the authorization code
+	// or the error, the user agent is sent there
+	RedirectTo string `json:"redirect_to"`
+}
+
+// authorizeOAuthClient handles POST request, records the decision of the authenticated user.
+// If the user approves, the consent is stored and an authorization code is issued.
+func (server *Server) authorizeOAuthClient(ctx *gin.Context) {
+	var req authorizeOAuthClientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	client, scopes, ok := server.validOAuthAuthorizeRequest(ctx, req.oauthAuthorizeRequest)
+	if !ok {
+		return
+	}
+
+	params := map[string]string{"state": req.State}
+	if !req.Approve {
+		params["error"] = "access_denied"
+		ctx.JSON(http.StatusOK, authorizeOAuthClientResponse{
+			RedirectTo: oauthRedirectURI(req.RedirectURI, params),
+		})
+		return
+	}
+
+	code, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	_, err = server.store.AuthorizeOAuthClientTx(ctx, db.CreateOAuthAuthorizationCodeParams{
+		HashedCode:    utils.HashToken(code),
+		ClientID:      client.ID,
+		Username:      authPayload.Username,
+		RedirectUri:   req.RedirectURI,
+		Scopes:        scopes,
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     time.Now().Add(server.config.OAuthCodeDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	params["code"] = code
+	ctx.JSON(http.StatusOK, authorizeOAuthClientResponse{
+		RedirectTo: oauthRedirectURI(req.RedirectURI, params),
+	})
+}
+
+// validOAuthAuthorizeRequest checks the client, the redirect URI and the scopes of an authorization request
+// and returns the client and the requested scopes. It writes the error response and returns false otherwise.
+// Errors are not sent to the redirect URI, which may not belong to the client.
+func (server *Server) validOAuthAuthorizeRequest(ctx *gin.Context, req oauthAuthorizeRequest) (db.OAuthClient, []string, bool) {
+	client, err := server.store.GetOAuthClient(ctx, req.ClientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("unknown client")))
+			return client, nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return client, nil, false
+	}
+
+	// redirect URIs are compared exactly, so codes are never sent anywhere else
+	registered := false
+	for _, redirectURI := range client.RedirectUris {
+		if redirectURI == req.RedirectURI {
+			registered = true
+			break
+		}
+	}
+	if !registered {
+		err := errors.New("redirect URI is not registered for the client")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return client, nil, false
+	}
+
+	scopes := token.ParseScope(req.Scope)
+	if len(scopes) == 0 || !token.HasScopes(client.Scopes, scopes) {
+		err := errors.New("the client cannot ask for the requested scopes")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return client, nil, false
+	}
+
+	return client, scopes, true
+}
+
+// oauthRedirectURI adds the non-empty parameters to the query of the redirect URI
+func oauthRedirectURI(redirectURI string, params map[string]string) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := u.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// oauthTokenRequest - the form of an access token request, RFC 6749 sections 4.1.3 and 6.
+// Clients may send their credentials with HTTP Basic authentication instead
+type oauthTokenRequest struct {
+	GrantType    string `form:"grant_type" binding:"required,oneof=authorization_code refresh_token"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type oauthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+// oauthToken handles POST request, exchanges an authorization code or a refresh token
+// of an OAuth client for an access token limited to the scopes the user granted
+func (server *Server) oauthToken(ctx *gin.Context) {
+	// tokens must not be cached, RFC 6749 section 5.1
+	ctx.Header("Cache-Control", "no-store")
+
+	var req oauthTokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidRequest, err))
+		return
+	}
+
+	client, ok := server.authenticateOAuthClient(ctx, req.ClientID, req.ClientSecret)
+	if !ok {
+		return
+	}
+
+	var refreshToken db.OAuthRefreshToken
+	var rawRefreshToken string
+	if req.GrantType == "authorization_code" {
+		refreshToken, rawRefreshToken, ok = server.exchangeOAuthCode(ctx, client, req)
+	} else {
+		refreshToken, rawRefreshToken, ok = server.rotateOAuthRefreshToken(ctx, client, req)
+	}
+	if !ok {
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, refreshToken.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+		return
+	}
+
+	accessToken, _, err := server.tokenMaker.CreateClientToken(
+		user.Username,
+		user.Role,
+		client.ID,
+		refreshToken.Scopes,
+		server.config.AccessTokenDuration,
+	)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, oauthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(server.config.AccessTokenDuration.Seconds()),
+		RefreshToken: rawRefreshToken,
+		Scope:        token.FormatScope(refreshToken.Scopes),
+	})
+}
+
+// exchangeOAuthCode checks an authorization code and its PKCE verifier and exchanges the code
+// for a refresh token. It writes the error response and returns false otherwise.
+func (server *Server) exchangeOAuthCode(ctx *gin.Context, client db.OAuthClient, req oauthTokenRequest) (db.OAuthRefreshToken, string, bool) {
+	var refreshToken db.OAuthRefreshToken
+
+	if req.Code == "" || req.RedirectURI == "" || req.CodeVerifier == "" {
+		err := errors.New("code, redirect_uri and code_verifier are required")
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidRequest, err))
+		return refreshToken, "", false
+	}
+
+	code, err := server.store.GetOAuthAuthorizationCode(ctx, utils.HashToken(req.Code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, errors.New("invalid authorization code")))
+			return refreshToken, "", false
+		}
+		ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+		return refreshToken, "", false
+	}
+
+	if code.ClientID != client.ID {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, errors.New("invalid authorization code")))
+		return refreshToken, "", false
+	}
+
+	// a code used twice may have been stolen, so the tokens issued for it are revoked, RFC 6749 section 4.1.2
+	if code.UsedAt != nil {
+		_, err = server.store.RevokeOAuthRefreshTokens(ctx, db.RevokeOAuthRefreshTokensParams{
+			Username: code.Username,
+			ClientID: code.ClientID,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+			return refreshToken, "", false
+		}
+
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, db.ErrOAuthCodeUsed))
+		return refreshToken, "", false
+	}
+
+	if time.Now().After(code.ExpiresAt) {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, errors.New("authorization code has expired")))
+		return refreshToken, "", false
+	}
+
+	if code.RedirectUri != req.RedirectURI {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, errors.New("redirect URI does not match the authorization request")))
+		return refreshToken, "", false
+	}
+
+	if !utils.CheckPKCE(req.CodeVerifier, code.CodeChallenge) {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, errors.New("invalid code verifier")))
+		return refreshToken, "", false
+	}
+
+	rawRefreshToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+		return refreshToken, "", false
+	}
+
+	refreshToken, err = server.store.ExchangeOAuthCodeTx(ctx, db.ExchangeOAuthCodeTxParams{
+		CodeID:             code.ID,
+		HashedRefreshToken: utils.HashToken(rawRefreshToken),
+		ExpiresAt:          time.Now().Add(server.config.OAuthRefreshTokenDuration),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrOAuthCodeUsed) {
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, err))
+			return refreshToken, "", false
+		}
+		ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+		return refreshToken, "", false
+	}
+
+	return refreshToken, rawRefreshToken, true
+}
+
+// rotateOAuthRefreshToken exchanges a refresh token of the client for a new one.
+// It writes the error response and returns false if the token cannot be used.
+func (server *Server) rotateOAuthRefreshToken(ctx *gin.Context, client db.OAuthClient, req oauthTokenRequest) (db.OAuthRefreshToken, string, bool) {
+	var refreshToken db.OAuthRefreshToken
+
+	if req.RefreshToken == "" {
+		err := errors.New("refresh_token is required")
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidRequest, err))
+		return refreshToken, "", false
+	}
+
+	refreshToken, err := server.store.GetOAuthRefreshToken(ctx, utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, errors.New("invalid refresh token")))
+			return refreshToken, "", false
+		}
+		ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+		return refreshToken, "", false
+	}
+
+	if refreshToken.ClientID != client.ID {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, errors.New("invalid refresh token")))
+		return refreshToken, "", false
+	}
+
+	if time.Now().After(refreshToken.ExpiresAt) {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, errors.New("refresh token has expired")))
+		return refreshToken, "", false
+	}
+
+	rawRefreshToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+		return refreshToken, "", false
+	}
+
+	refreshToken, err = server.store.RotateOAuthRefreshTokenTx(ctx, db.RotateOAuthRefreshTokenTxParams{
+		ID:             refreshToken.ID,
+		NewHashedToken: utils.HashToken(rawRefreshToken),
+		ExpiresAt:      time.Now().Add(server.config.OAuthRefreshTokenDuration),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrOAuthRefreshTokenReused) {
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, err))
+			return refreshToken, "", false
+		}
+		ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+		return refreshToken, "", false
+	}
+
+	return refreshToken, rawRefreshToken, true
+}
+
+// authenticateOAuthClient returns the client of the request. Credentials sent with HTTP Basic
+// authentication take precedence over the ones in the form, and confidential clients must send their secret.
+// It writes the error response and returns false if the client cannot be authenticated.
+func (server *Server) authenticateOAuthClient(ctx *gin.Context, clientID string, clientSecret string) (db.OAuthClient, bool) {
+	if id, secret, ok := ctx.Request.BasicAuth(); ok {
+		clientID, clientSecret = id, secret
+	}
+
+	errInvalidClient := errors.New("client authentication failed")
+	if clientID == "" {
+		ctx.JSON(http.StatusUnauthorized, oauthErrorResponse(oauthErrorInvalidClient, errInvalidClient))
+		return db.OAuthClient{}, false
+	}
+
+	client, err := server.store.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, oauthErrorResponse(oauthErrorInvalidClient, errInvalidClient))
+			return client, false
+		}
+		ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+		return client, false
+	}
+
+	if client.HashedSecret != "" &&
+		subtle.ConstantTimeCompare([]byte(utils.HashToken(clientSecret)), []byte(client.HashedSecret)) != 1 {
+		ctx.JSON(http.StatusUnauthorized, oauthErrorResponse(oauthErrorInvalidClient, errInvalidClient))
+		return client, false
+	}
+
+	return client, true
+}
+
+// oauthRevokeRequest - the form of a token revocation request, RFC 7009 section 2.1
+type oauthRevokeRequest struct {
+	Token         string `form:"token" binding:"required"`
+	TokenTypeHint string `form:"token_type_hint" binding:"omitempty,oneof=access_token refresh_token"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// oauthRevoke handles POST request, revokes an access or refresh token issued to the client.
+// Unknown tokens and tokens of other clients are ignored, so the response is the same for all of them.
+func (server *Server) oauthRevoke(ctx *gin.Context) {
+	var req oauthRevokeRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidRequest, err))
+		return
+	}
+
+	client, ok := server.authenticateOAuthClient(ctx, req.ClientID, req.ClientSecret)
+	if !ok {
+		return
+	}
+
+	refreshToken, err := server.store.GetOAuthRefreshToken(ctx, utils.HashToken(req.Token))
+	if err == nil {
+		if refreshToken.ClientID == client.ID {
+			_, err = server.store.RevokeOAuthRefreshToken(ctx, refreshToken.ID)
+			if err != nil && err != sql.ErrNoRows {
+				ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+				return
+			}
+		}
+
+		ctx.Status(http.StatusOK)
+		return
+	}
+	if err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+		return
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(req.Token)
+	if err == nil && payload.ClientID == client.ID {
+		err = server.store.RevokeToken(ctx, db.RevokeTokenParams{
+			ID:        payload.ID,
+			Username:  payload.Username,
+			ExpiresAt: payload.ExpiredAt,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(oauthErrorServerError, err))
+			return
+		}
+
+		server.revocations.RevokeToken(payload)
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+type listOAuthConsentsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listOAuthConsents handles GET request, returns the OAuth clients the authenticated user
+// has granted access to and the granted scopes
+func (server *Server) listOAuthConsents(ctx *gin.Context) {
+	var req listOAuthConsentsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	consents, err := server.store.ListOAuthConsents(ctx, db.ListOAuthConsentsParams{
+		Username: authPayload.Username,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, consents)
+}
+
+type oauthConsentURI struct {
+	ClientID string `uri:"client_id" binding:"required"`
+}
+
+// revokeOAuthConsent handles DELETE request, withdraws the consent of the authenticated user
+// to a client and revokes the refresh tokens of the client, so it can no longer get access tokens
+func (server *Server) revokeOAuthConsent(ctx *gin.Context) {
+	var uri oauthConsentURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	err := server.store.RevokeOAuthConsentTx(ctx, db.DeleteOAuthConsentParams{
+		Username: authPayload.Username,
+		ClientID: uri.ClientID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
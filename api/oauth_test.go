@@ -0,0 +1,959 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/aalug/bank-go/db/mock"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateOAuthClientAPI(t *testing.T) {
+	user, _ := generateRandomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name":          "budget app",
+				"redirect_uris": []string{"https://example.com/callback"},
+				"scopes":        []string{token.ScopeAccountsRead},
+				"confidential":  true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOAuthClientTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateOAuthClientParams) (db.OAuthClient, error) {
+						require.Equal(t, user.Username, arg.Owner)
+						require.NotEmpty(t, arg.ID)
+						require.NotEmpty(t, arg.HashedSecret)
+						return db.OAuthClient{
+							ID:           arg.ID,
+							HashedSecret: arg.HashedSecret,
+							Name:         arg.Name,
+							Owner:        arg.Owner,
+							RedirectUris: arg.RedirectUris,
+							Scopes:       arg.Scopes,
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res createOAuthClientResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.NotEmpty(t, res.ClientSecret)
+				require.True(t, res.Client.IsConfidential)
+				require.NotContains(t, recorder.Body.String(), "hashed_secret")
+			},
+		},
+		{
+			name: "Public Client",
+			body: gin.H{
+				"name":          "mobile app",
+				"redirect_uris": []string{"https://example.com/callback"},
+				"scopes":        []string{token.ScopeAccountsRead},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOAuthClientTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateOAuthClientParams) (db.OAuthClient, error) {
+						require.Empty(t, arg.HashedSecret)
+						return db.OAuthClient{ID: arg.ID, Owner: arg.Owner}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res createOAuthClientResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Empty(t, res.ClientSecret)
+				require.False(t, res.Client.IsConfidential)
+			},
+		},
+		{
+			name: "Admin Scope",
+			body: gin.H{
+				"name":          "budget app",
+				"redirect_uris": []string{"https://example.com/callback"},
+				"scopes":        []string{token.ScopeAdmin},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOAuthClientTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Redirect URI With Fragment",
+			body: gin.H{
+				"name":          "budget app",
+				"redirect_uris": []string{"https://example.com/callback#code"},
+				"scopes":        []string{token.ScopeAccountsRead},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOAuthClientTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Redirect URI",
+			body: gin.H{
+				"name":          "budget app",
+				"redirect_uris": []string{"callback"},
+				"scopes":        []string{token.ScopeAccountsRead},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOAuthClientTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/clients", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestAuthorizeOAuthClientAPI(t *testing.T) {
+	user, _ := generateRandomUser(t)
+	client, _ := generateRandomOAuthClient(t, utils.RandomOwner())
+	verifier := utils.RandomString(64)
+
+	validBody := func() gin.H {
+		return gin.H{
+			"response_type":         "code",
+			"client_id":             client.ID,
+			"redirect_uri":          client.RedirectUris[0],
+			"scope":                 token.ScopeAccountsRead,
+			"state":                 "xyz",
+			"code_challenge":        utils.PKCEChallenge(verifier),
+			"code_challenge_method": "S256",
+			"approve":               true,
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		setupBody     func(body gin.H)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			setupBody: func(body gin.H) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).
+					Times(1).
+					Return(client, nil)
+				store.EXPECT().
+					AuthorizeOAuthClientTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateOAuthAuthorizationCodeParams) (db.OAuthAuthorizationCode, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, client.ID, arg.ClientID)
+						require.Equal(t, []string{token.ScopeAccountsRead}, arg.Scopes)
+						require.Equal(t, utils.PKCEChallenge(verifier), arg.CodeChallenge)
+						return db.OAuthAuthorizationCode{ID: 1}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				redirectTo := requireOAuthRedirect(t, recorder, client.RedirectUris[0])
+				require.NotEmpty(t, redirectTo.Query().Get("code"))
+				require.Equal(t, "xyz", redirectTo.Query().Get("state"))
+			},
+		},
+		{
+			name: "Denied",
+			setupBody: func(body gin.H) {
+				body["approve"] = false
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).
+					Times(1).
+					Return(client, nil)
+				store.EXPECT().
+					AuthorizeOAuthClientTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				redirectTo := requireOAuthRedirect(t, recorder, client.RedirectUris[0])
+				require.Equal(t, "access_denied", redirectTo.Query().Get("error"))
+				require.Empty(t, redirectTo.Query().Get("code"))
+			},
+		},
+		{
+			name: "Unregistered Redirect URI",
+			setupBody: func(body gin.H) {
+				body["redirect_uri"] = "https://attacker.example.com/callback"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).
+					Times(1).
+					Return(client, nil)
+				store.EXPECT().
+					AuthorizeOAuthClientTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Scope Not Allowed For Client",
+			setupBody: func(body gin.H) {
+				body["scope"] = token.ScopeAccountsRead + " " + token.ScopeAccountsWrite
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).
+					Times(1).
+					Return(client, nil)
+				store.EXPECT().
+					AuthorizeOAuthClientTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Unknown Client",
+			setupBody: func(body gin.H) {
+				body["client_id"] = "unknown"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Eq("unknown")).
+					Times(1).
+					Return(db.OAuthClient{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "Plain Code Challenge",
+			setupBody: func(body gin.H) {
+				body["code_challenge"] = verifier[:43]
+				body["code_challenge_method"] = "plain"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body := validBody()
+			tc.setupBody(body)
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/authorize", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetOAuthAuthorizationAPI(t *testing.T) {
+	user, _ := generateRandomUser(t)
+	client, _ := generateRandomOAuthClient(t, utils.RandomOwner())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).
+		Times(1).
+		Return(client, nil)
+	store.EXPECT().
+		GetOAuthConsent(gomock.Any(), gomock.Eq(db.GetOAuthConsentParams{
+			Username: user.Username,
+			ClientID: client.ID,
+		})).
+		Times(1).
+		Return(db.OAuthConsent{Scopes: []string{token.ScopeAccountsRead, token.ScopeTransfersWrite}}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {client.ID},
+		"redirect_uri":          {client.RedirectUris[0]},
+		"scope":                 {token.ScopeAccountsRead},
+		"code_challenge":        {utils.PKCEChallenge(utils.RandomString(64))},
+		"code_challenge_method": {"S256"},
+	}
+	request, err := http.NewRequest(http.MethodGet, "/oauth/authorize?"+query.Encode(), nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res oauthConsentRequestResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &res)
+	require.NoError(t, err)
+	require.Equal(t, client.ID, res.Client.ID)
+	require.Equal(t, []string{token.ScopeAccountsRead}, res.Scopes)
+	// the scope was granted before, so the user does not have to be asked again
+	require.True(t, res.Consented)
+}
+
+func TestOAuthTokenAPI(t *testing.T) {
+	user, _ := generateRandomUser(t)
+	client, clientSecret := generateRandomOAuthClient(t, utils.RandomOwner())
+	verifier := utils.RandomString(64)
+	code := utils.RandomString(32)
+
+	authorizationCode := db.OAuthAuthorizationCode{
+		ID:            1,
+		HashedCode:    utils.HashToken(code),
+		ClientID:      client.ID,
+		Username:      user.Username,
+		RedirectUri:   client.RedirectUris[0],
+		Scopes:        []string{token.ScopeAccountsRead},
+		CodeChallenge: utils.PKCEChallenge(verifier),
+		ExpiresAt:     time.Now().Add(time.Minute),
+	}
+	usedAt := time.Now()
+	usedCode := authorizationCode
+	usedCode.UsedAt = &usedAt
+
+	refreshToken := utils.RandomString(32)
+	storedRefreshToken := db.OAuthRefreshToken{
+		ID:          2,
+		HashedToken: utils.HashToken(refreshToken),
+		ClientID:    client.ID,
+		Username:    user.Username,
+		Scopes:      []string{token.ScopeAccountsRead},
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	codeForm := func() url.Values {
+		return url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {code},
+			"redirect_uri":  {client.RedirectUris[0]},
+			"code_verifier": {verifier},
+			"client_id":     {client.ID},
+			"client_secret": {clientSecret},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		form          func() url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder, server *Server)
+	}{
+		{
+			name: "Authorization Code",
+			form: codeForm,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthAuthorizationCode(gomock.Any(), gomock.Eq(utils.HashToken(code))).
+					Times(1).
+					Return(authorizationCode, nil)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.ExchangeOAuthCodeTxParams) (db.OAuthRefreshToken, error) {
+						require.Equal(t, authorizationCode.ID, arg.CodeID)
+						return db.OAuthRefreshToken{
+							HashedToken: arg.HashedRefreshToken,
+							ClientID:    client.ID,
+							Username:    user.Username,
+							Scopes:      authorizationCode.Scopes,
+						}, nil
+					})
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+
+				var res oauthTokenResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, "Bearer", res.TokenType)
+				require.Equal(t, token.ScopeAccountsRead, res.Scope)
+				require.NotEmpty(t, res.RefreshToken)
+
+				payload, err := server.tokenMaker.VerifyToken(res.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, payload.Username)
+				require.Equal(t, client.ID, payload.ClientID)
+				require.Equal(t, []string{token.ScopeAccountsRead}, payload.Scopes)
+			},
+		},
+		{
+			name: "Wrong Code Verifier",
+			form: func() url.Values {
+				form := codeForm()
+				form.Set("code_verifier", utils.RandomString(64))
+				return form
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthAuthorizationCode(gomock.Any(), gomock.Eq(utils.HashToken(code))).
+					Times(1).
+					Return(authorizationCode, nil)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, server *Server) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorInvalidGrant)
+			},
+		},
+		{
+			name: "Redirect URI Mismatch",
+			form: func() url.Values {
+				form := codeForm()
+				form.Set("redirect_uri", "https://example.com/other")
+				return form
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthAuthorizationCode(gomock.Any(), gomock.Eq(utils.HashToken(code))).
+					Times(1).
+					Return(authorizationCode, nil)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, server *Server) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorInvalidGrant)
+			},
+		},
+		{
+			name: "Code Used Twice",
+			form: codeForm,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthAuthorizationCode(gomock.Any(), gomock.Eq(utils.HashToken(code))).
+					Times(1).
+					Return(usedCode, nil)
+				store.EXPECT().
+					RevokeOAuthRefreshTokens(gomock.Any(), gomock.Eq(db.RevokeOAuthRefreshTokensParams{
+						Username: user.Username,
+						ClientID: client.ID,
+					})).
+					Times(1)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, server *Server) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorInvalidGrant)
+			},
+		},
+		{
+			name: "Wrong Client Secret",
+			form: func() url.Values {
+				form := codeForm()
+				form.Set("client_secret", utils.RandomString(32))
+				return form
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthAuthorizationCode(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, server *Server) {
+				requireOAuthError(t, recorder, http.StatusUnauthorized, oauthErrorInvalidClient)
+			},
+		},
+		{
+			name: "Refresh Token",
+			form: func() url.Values {
+				return url.Values{
+					"grant_type":    {"refresh_token"},
+					"refresh_token": {refreshToken},
+					"client_id":     {client.ID},
+					"client_secret": {clientSecret},
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthRefreshToken(gomock.Any(), gomock.Eq(utils.HashToken(refreshToken))).
+					Times(1).
+					Return(storedRefreshToken, nil)
+				store.EXPECT().
+					RotateOAuthRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.RotateOAuthRefreshTokenTxParams) (db.OAuthRefreshToken, error) {
+						require.Equal(t, storedRefreshToken.ID, arg.ID)
+						rotated := storedRefreshToken
+						rotated.HashedToken = arg.NewHashedToken
+						return rotated, nil
+					})
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res oauthTokenResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.NotEqual(t, refreshToken, res.RefreshToken)
+			},
+		},
+		{
+			name: "Refresh Token Reused",
+			form: func() url.Values {
+				return url.Values{
+					"grant_type":    {"refresh_token"},
+					"refresh_token": {refreshToken},
+					"client_id":     {client.ID},
+					"client_secret": {clientSecret},
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthRefreshToken(gomock.Any(), gomock.Eq(utils.HashToken(refreshToken))).
+					Times(1).
+					Return(storedRefreshToken, nil)
+				store.EXPECT().
+					RotateOAuthRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OAuthRefreshToken{}, db.ErrOAuthRefreshTokenReused)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, server *Server) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorInvalidGrant)
+			},
+		},
+		{
+			name: "Unsupported Grant Type",
+			form: func() url.Values {
+				return url.Values{
+					"grant_type": {"password"},
+					"client_id":  {client.ID},
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOAuthClient(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, server *Server) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorInvalidRequest)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().
+				GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).
+				AnyTimes().
+				Return(client, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request := newOAuthFormRequest(t, "/oauth/token", tc.form())
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder, server)
+		})
+	}
+}
+
+func TestOAuthRevokeAPI(t *testing.T) {
+	user, _ := generateRandomUser(t)
+	client, clientSecret := generateRandomOAuthClient(t, utils.RandomOwner())
+
+	t.Run("Access Token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mockdb.NewMockStore(ctrl)
+		server := newTestServer(t, store)
+
+		accessToken, payload, err := server.tokenMaker.CreateClientToken(user.Username, user.Role, client.ID, []string{token.ScopeAccountsRead}, time.Minute)
+		require.NoError(t, err)
+
+		store.EXPECT().
+			GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).
+			Times(1).
+			Return(client, nil)
+		store.EXPECT().
+			GetOAuthRefreshToken(gomock.Any(), gomock.Eq(utils.HashToken(accessToken))).
+			Times(1).
+			Return(db.OAuthRefreshToken{}, sql.ErrNoRows)
+		store.EXPECT().
+			RevokeToken(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ any, arg db.RevokeTokenParams) error {
+				require.Equal(t, payload.ID, arg.ID)
+				require.Equal(t, user.Username, arg.Username)
+				require.WithinDuration(t, payload.ExpiredAt, arg.ExpiresAt, time.Second)
+				return nil
+			})
+
+		recorder := httptest.NewRecorder()
+		request := newOAuthFormRequest(t, "/oauth/revoke", url.Values{"token": {accessToken}})
+		request.SetBasicAuth(client.ID, clientSecret)
+		server.router.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.ErrorIs(t, server.revocations.Check(payload), token.ErrRevokedToken)
+	})
+
+	t.Run("Refresh Token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		refreshToken := utils.RandomString(32)
+		storedRefreshToken := db.OAuthRefreshToken{
+			ID:          1,
+			HashedToken: utils.HashToken(refreshToken),
+			ClientID:    client.ID,
+			Username:    user.Username,
+		}
+
+		store := mockdb.NewMockStore(ctrl)
+		store.EXPECT().
+			GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).
+			Times(1).
+			Return(client, nil)
+		store.EXPECT().
+			GetOAuthRefreshToken(gomock.Any(), gomock.Eq(utils.HashToken(refreshToken))).
+			Times(1).
+			Return(storedRefreshToken, nil)
+		store.EXPECT().
+			RevokeOAuthRefreshToken(gomock.Any(), gomock.Eq(storedRefreshToken.ID)).
+			Times(1)
+
+		server := newTestServer(t, store)
+		recorder := httptest.NewRecorder()
+
+		request := newOAuthFormRequest(t, "/oauth/revoke", url.Values{
+			"token":           {refreshToken},
+			"token_type_hint": {"refresh_token"},
+			"client_id":       {client.ID},
+			"client_secret":   {clientSecret},
+		})
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("Token Of Other Client", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mockdb.NewMockStore(ctrl)
+		server := newTestServer(t, store)
+
+		accessToken, payload, err := server.tokenMaker.CreateClientToken(user.Username, user.Role, "other", []string{token.ScopeAccountsRead}, time.Minute)
+		require.NoError(t, err)
+
+		store.EXPECT().
+			GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).
+			Times(1).
+			Return(client, nil)
+		store.EXPECT().
+			GetOAuthRefreshToken(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.OAuthRefreshToken{}, sql.ErrNoRows)
+		store.EXPECT().
+			RevokeToken(gomock.Any(), gomock.Any()).
+			Times(0)
+
+		recorder := httptest.NewRecorder()
+		request := newOAuthFormRequest(t, "/oauth/revoke", url.Values{"token": {accessToken}})
+		request.SetBasicAuth(client.ID, clientSecret)
+		server.router.ServeHTTP(recorder, request)
+
+		// the response does not tell the client whether the token exists
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.NoError(t, server.revocations.Check(payload))
+	})
+}
+
+func TestOAuthAccessToken(t *testing.T) {
+	user, _ := generateRandomUser(t)
+	account := generateRandomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Missing Scope",
+			method: http.MethodPost,
+			url:    "/transfers",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "Route Without Scope",
+			method: http.MethodPost,
+			url:    "/oauth/clients",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOAuthClientTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			accessToken, _, err := server.tokenMaker.CreateClientToken(user.Username, user.Role, utils.RandomString(16), []string{token.ScopeAccountsRead}, time.Minute)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewReader([]byte("{}")))
+			require.NoError(t, err)
+
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestRevokeOAuthConsentAPI(t *testing.T) {
+	user, _ := generateRandomUser(t)
+	clientID := utils.RandomString(16)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeOAuthConsentTx(gomock.Any(), gomock.Eq(db.DeleteOAuthConsentParams{
+						Username: user.Username,
+						ClientID: clientID,
+					})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "Not Found",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeOAuthConsentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, "/oauth/consents/"+clientID, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+// generateRandomOAuthClient generates a random confidential OAuth client of the owner
+// that can ask for the accounts:read and transfers:write scopes, and returns it with its secret
+func generateRandomOAuthClient(t *testing.T, owner string) (db.OAuthClient, string) {
+	secret, err := utils.GenerateSecureToken(32)
+	require.NoError(t, err)
+
+	return db.OAuthClient{
+		ID:           utils.RandomString(16),
+		HashedSecret: utils.HashToken(secret),
+		Name:         utils.RandomString(8),
+		Owner:        owner,
+		RedirectUris: []string{"https://example.com/callback"},
+		Scopes:       []string{token.ScopeAccountsRead, token.ScopeTransfersWrite},
+		CreatedAt:    time.Now(),
+	}, secret
+}
+
+// newOAuthFormRequest creates a form-encoded POST request, like the ones sent by OAuth clients
+func newOAuthFormRequest(t *testing.T, path string, form url.Values) *http.Request {
+	request, err := http.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return request
+}
+
+// requireOAuthRedirect checks that the response redirects to the redirect URI and returns the parsed redirect
+func requireOAuthRedirect(t *testing.T, recorder *httptest.ResponseRecorder, redirectURI string) *url.URL {
+	var res authorizeOAuthClientResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &res)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(res.RedirectTo, redirectURI+"?"))
+
+	redirectTo, err := url.Parse(res.RedirectTo)
+	require.NoError(t, err)
+	return redirectTo
+}
+
+// requireOAuthError checks the status and the error code of an OAuth error response
+func requireOAuthError(t *testing.T, recorder *httptest.ResponseRecorder, status int, code string) {
+	require.Equal(t, status, recorder.Code)
+
+	var res map[string]string
+	err := json.Unmarshal(recorder.Body.Bytes(), &res)
+	require.NoError(t, err)
+	require.Equal(t, code, res["error"])
+	require.NotEmpty(t, res["error_description"])
+}
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// errScheduledTransferCompleted is returned when a completed scheduled transfer is updated
+var errScheduledTransferCompleted = errors.New("scheduled transfer is completed")
+
+type createScheduledTransferRequest struct {
+	FromAccountID int64     `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64     `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64     `json:"amount" binding:"required,gt=0"`
+	Currency      string    `json:"currency" binding:"required,currency"`
+	Recurrence    string    `json:"recurrence" binding:"required,oneof=once daily weekly monthly"`
+	StartAt       time.Time `json:"start_at" binding:"required"`
+	// TOTPCode is required from users with two-factor authentication for amounts from the step-up amount
+	TOTPCode string `json:"totp_code" binding:"omitempty,len=6,numeric"`
+}
+
+// createScheduledTransfer handles POST request, schedules a transfer for a future date,
+// once or repeatedly
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.StartAt.After(time.Now()) {
+		err := errors.New("start_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username || !authPayload.AllowsAccount(fromAccount.ID) {
+		err := errors.New("from account does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.validEmailVerified(ctx, authPayload.Username) {
+		return
+	}
+
+	if !server.validStepUp(ctx, authPayload.Username, req.Amount, req.TOTPCode) {
+		return
+	}
+
+	arg := db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Recurrence:    req.Recurrence,
+		StartAt:       req.StartAt,
+	}
+	// the worker checks before every run that the key still allows the transfer
+	if authPayload.IsAPIKey() {
+		arg.APIKeyID = &authPayload.APIKeyID
+	}
+
+	scheduledTransfer, err := server.store.CreateScheduledTransferTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, scheduledTransfer)
+}
+
+type scheduledTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getScheduledTransfer handles GET request, returns the scheduled transfer with given ID
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.ownScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+type listScheduledTransfersRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listScheduledTransfers handles GET request, returns scheduled transfers of the authenticated user
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// an API key sees only the scheduled transfers from the accounts it is allowed to act on
+	allowed := scheduledTransfers[:0]
+	for _, scheduledTransfer := range scheduledTransfers {
+		if authPayload.AllowsAccount(scheduledTransfer.FromAccountID) {
+			allowed = append(allowed, scheduledTransfer)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, allowed)
+}
+
+type updateScheduledTransferRequest struct {
+	Amount     *int64     `json:"amount" binding:"omitempty,gt=0"`
+	Recurrence *string    `json:"recurrence" binding:"omitempty,oneof=once daily weekly monthly"`
+	NextRunAt  *time.Time `json:"next_run_at"`
+	// Status suspends or resumes the scheduled transfer,
+	// resuming it also resets the count of failed runs
+	Status *string `json:"status" binding:"omitempty,oneof=active suspended"`
+	// TOTPCode is required from users with two-factor authentication when the updated
+	// transfer of the step-up amount or more can run again
+	TOTPCode string `json:"totp_code" binding:"omitempty,len=6,numeric"`
+}
+
+// updateScheduledTransfer handles PATCH request, updates the scheduled transfer with given ID
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.NextRunAt != nil && !req.NextRunAt.After(time.Now()) {
+		err := errors.New("next_run_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.ownScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if scheduledTransfer.Status == db.ScheduledTransferCompleted {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(errScheduledTransferCompleted))
+		return
+	}
+
+	// only suspending the transfer needs no step-up
+	if req.Amount != nil || req.Recurrence != nil || req.NextRunAt != nil ||
+		(req.Status != nil && *req.Status == db.ScheduledTransferActive) {
+		amount := scheduledTransfer.Amount
+		if req.Amount != nil {
+			amount = *req.Amount
+		}
+		if !server.validStepUp(ctx, scheduledTransfer.Owner, amount, req.TOTPCode) {
+			return
+		}
+	}
+
+	params := db.UpdateScheduledTransferParams{
+		ID: uri.ID,
+	}
+	if req.Amount != nil {
+		params.Amount = sql.NullInt64{Int64: *req.Amount, Valid: true}
+	}
+	if req.Recurrence != nil {
+		params.Recurrence = sql.NullString{String: *req.Recurrence, Valid: true}
+	}
+	if req.NextRunAt != nil {
+		params.NextRunAt = sql.NullTime{Time: *req.NextRunAt, Valid: true}
+	}
+	if req.Status != nil {
+		params.Status = sql.NullString{String: *req.Status, Valid: true}
+	}
+
+	scheduledTransfer, err := server.store.UpdateScheduledTransferTx(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+// deleteScheduledTransfer handles DELETE request, deletes the scheduled transfer with given ID
+// together with the history of its runs
+func (server *Server) deleteScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.ownScheduledTransfer(ctx, uri.ID); !valid {
+		return
+	}
+
+	err := server.store.DeleteScheduledTransferTx(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+type listScheduledTransferRunsQuery struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// listScheduledTransferRuns handles GET request, returns the outcomes of the runs
+// of the scheduled transfer, the most recent first
+func (server *Server) listScheduledTransferRuns(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var query listScheduledTransferRunsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.ownScheduledTransfer(ctx, uri.ID); !valid {
+		return
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: uri.ID,
+		Limit:               query.PageSize,
+		Offset:              (query.PageID - 1) * query.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+// ownScheduledTransfer checks if the scheduled transfer exists
+// and belongs to the authenticated user
+func (server *Server) ownScheduledTransfer(ctx *gin.Context, id int64) (db.ScheduledTransfer, bool) {
+	scheduledTransfer, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return scheduledTransfer, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return scheduledTransfer, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if scheduledTransfer.Owner != authPayload.Username || !authPayload.AllowsAccount(scheduledTransfer.FromAccountID) {
+		err := errors.New("scheduled transfer does not belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return scheduledTransfer, false
+	}
+
+	return scheduledTransfer, true
+}
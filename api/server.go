@@ -1,8 +1,11 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/mail"
+	"github.com/aalug/bank-go/metrics"
 	"github.com/aalug/bank-go/token"
 	"github.com/aalug/bank-go/utils"
 	"github.com/gin-gonic/gin"
@@ -13,15 +16,22 @@ import (
 
 // Server serves HTTP  requests for the service
 type Server struct {
-	config     utils.Config
-	store      db.Store
-	tokenMaker token.Maker
-	router     *gin.Engine
+	config      utils.Config
+	store       db.Store
+	tokenMaker  token.Maker
+	revocations *token.RevocationList
+	mailer      mail.Sender
+	router      *gin.Engine
 }
 
 // NewServer creates a new HTTP server and setup routing
-func NewServer(config utils.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+func NewServer(config utils.Config, store db.Store, mailer mail.Sender) (*Server, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	tokenMaker, err := token.NewMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker:  %w", err)
 	}
@@ -29,6 +39,9 @@ func NewServer(config utils.Config, store db.Store) (*Server, error) {
 		config:     config,
 		store:      store,
 		tokenMaker: tokenMaker,
+		// refresh tokens outlive access tokens, so their revocations are kept the longest
+		revocations: token.NewRevocationList(db.NewRevocationStore(store), config.RefreshTokenDuration),
+		mailer:      mailer,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
@@ -36,37 +49,126 @@ func NewServer(config utils.Config, store db.Store) (*Server, error) {
 		if err != nil {
 			log.Fatal("failed to register validation")
 		}
+		err = v.RegisterValidation("scope", validScope)
+		if err != nil {
+			log.Fatal("failed to register validation")
+		}
 	}
 
-	server.setupRouter()
+	err = server.setupRouter()
+	if err != nil {
+		return nil, fmt.Errorf("cannot set up router: %w", err)
+	}
 
 	return server, nil
 }
 
 // setupRouter set up the HTTP routing
-func (server *Server) setupRouter() {
-	router := gin.Default()
+func (server *Server) setupRouter() error {
+	router := gin.New()
+	// the client IP is taken from X-Forwarded-For only behind these proxies, by default
+	// it is the address of the connection, so clients cannot pick the key of the IP lockout
+	err := router.SetTrustedProxies(server.config.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	// the store reads the request ID and the audit info from the context of the request
+	router.ContextWithFallback = true
+	router.Use(loggerMiddleware(), metricsMiddleware(), gin.Recovery(), auditMiddleware())
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// users
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
+	router.POST("/users/login/mfa", server.verifyLoginMFA)
+	router.GET("/users/verify_email", server.verifyEmail)
+	router.POST("/users/password_reset", server.requestPasswordReset)
+	router.POST("/users/password_reset/confirm", server.resetPassword)
 
 	// tokens/sessions
 	router.POST("/tokens/renew", server.renewAccessToken)
+	router.GET("/.well-known/jwks.json", gin.WrapH(token.JWKSHandler(server.tokenMaker)))
+
+	// OAuth clients authenticate with their own credentials
+	router.POST("/oauth/token", server.oauthToken)
+	router.POST("/oauth/revoke", server.oauthRevoke)
 
 	// --- routes that require authentication ---
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.revocations, server.store))
+
+	// email verification
+	authRoutes.POST("/users/verify_email/resend", server.resendVerificationEmail)
+
+	// API keys
+	authRoutes.POST("/api_keys", server.createAPIKey)
+	authRoutes.GET("/api_keys", server.listAPIKeys)
+	authRoutes.DELETE("/api_keys/:id", server.revokeAPIKey)
+
+	// OAuth clients and consents
+	authRoutes.POST("/oauth/clients", server.createOAuthClient)
+	authRoutes.GET("/oauth/clients", server.listOAuthClients)
+	authRoutes.GET("/oauth/authorize", server.getOAuthAuthorization)
+	authRoutes.POST("/oauth/authorize", server.authorizeOAuthClient)
+	authRoutes.GET("/oauth/consents", server.listOAuthConsents)
+	authRoutes.DELETE("/oauth/consents/:client_id", server.revokeOAuthConsent)
+
+	// two-factor authentication
+	authRoutes.POST("/users/totp", server.enrollTOTP)
+	authRoutes.POST("/users/totp/confirm", server.confirmTOTP)
 
 	// accounts
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/accounts/:id", server.getAccount)
 	authRoutes.GET("/accounts", server.listAccounts)
 	authRoutes.DELETE("/accounts/:id", server.deleteAccount)
+	authRoutes.GET("/accounts/:id/entries", server.listAccountEntries)
 
 	// transactions
 	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+
+	// holds
+	authRoutes.POST("/holds", server.createHold)
+	authRoutes.GET("/holds/:id", server.getHold)
+	authRoutes.POST("/holds/:id/capture", server.captureHold)
+	authRoutes.POST("/holds/:id/void", server.voidHold)
+
+	// scheduled transfers
+	authRoutes.POST("/scheduled_transfers", server.createScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers/:id", server.getScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers", server.listScheduledTransfers)
+	authRoutes.PATCH("/scheduled_transfers/:id", server.updateScheduledTransfer)
+	authRoutes.DELETE("/scheduled_transfers/:id", server.deleteScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers/:id/runs", server.listScheduledTransferRuns)
+
+	// foreign exchange
+	authRoutes.POST("/fx/quotes", server.createQuote)
+	authRoutes.POST("/fx/transfers", server.createFXTransfer)
+
+	// --- routes that require the admin role ---
+	adminRoutes := router.Group("/admin").Use(authMiddleware(server.tokenMaker, server.revocations, server.store), adminMiddleware())
+
+	adminRoutes.GET("/users", server.adminSearchUsers)
+	adminRoutes.GET("/users/:username/lockout", server.adminGetUserLockout)
+	adminRoutes.DELETE("/users/:username/lockout", server.adminUnlockUser)
+	adminRoutes.POST("/accounts/:id/freeze", server.adminFreezeAccount)
+	adminRoutes.POST("/accounts/:id/unfreeze", server.adminUnfreezeAccount)
+	adminRoutes.GET("/accounts/:id/transfers", server.adminListAccountTransfers)
+	adminRoutes.POST("/sessions/:id/block", server.adminBlockSession)
+	adminRoutes.POST("/users/:username/api_keys", server.adminCreateAPIKey)
+	adminRoutes.DELETE("/api_keys/:id", server.adminRevokeAPIKey)
+	adminRoutes.GET("/audit_events", server.adminListAuditEvents)
+	adminRoutes.GET("/audit_events/verify", server.adminVerifyAuditChain)
+	adminRoutes.POST("/deposits", server.createDeposit)
+	adminRoutes.POST("/withdrawals", server.createWithdrawal)
 
 	server.router = router
+	return nil
+}
+
+// RefreshRevokedTokens keeps the list of revoked tokens up to date until the context is cancelled
+func (server *Server) RefreshRevokedTokens(ctx context.Context) {
+	server.revocations.Start(ctx, server.config.RevocationRefreshInterval)
 }
 
 // Start runs the HTTP server on a given address
@@ -7,6 +7,7 @@ import (
 	mockdb "github.com/aalug/bank-go/db/mock"
 	db "github.com/aalug/bank-go/db/sqlc"
 	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
@@ -31,6 +32,7 @@ func TestRenewAccessTokenAPI(t *testing.T) {
 			body: func(server *Server) (gin.H, db.Session, *token.Payload) {
 				refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 					user.Username,
+					user.Role,
 					time.Minute,
 				)
 				require.NoError(t, err)
@@ -52,9 +54,107 @@ func TestRenewAccessTokenAPI(t *testing.T) {
 					GetSession(gomock.Any(), gomock.Eq(refreshPayload.ID)).
 					Times(1).
 					Return(session, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.RotateSessionTxParams) (db.Session, error) {
+						require.Equal(t, session.ID, arg.SessionID)
+						require.NotEqual(t, session.RefreshToken, arg.RefreshToken)
+						return db.Session{ID: arg.NewSessionID, RefreshToken: arg.RefreshToken, ExpiresAt: arg.ExpiresAt}, nil
+					})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res renewAccessTokenResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.NotEmpty(t, res.AccessToken)
+				require.NotEmpty(t, res.RefreshToken)
+				require.NotEqual(t, uuid.Nil, res.SessionID)
+			},
+		},
+		{
+			name: "Refresh Token Reused",
+			body: func(server *Server) (gin.H, db.Session, *token.Payload) {
+				refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
+					user.Username,
+					user.Role,
+					time.Minute,
+				)
+				require.NoError(t, err)
+				rotatedAt := time.Now()
+				session := db.Session{
+					ID:           refreshPayload.ID,
+					Username:     user.Username,
+					RefreshToken: refreshToken,
+					IsBlocked:    false,
+					ExpiresAt:    time.Now().Add(time.Minute),
+					FamilyID:     uuid.New(),
+					RotatedAt:    &rotatedAt,
+				}
+				return gin.H{
+					"refresh_token": refreshToken,
+				}, session, refreshPayload
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session, refreshPayload *token.Payload) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(refreshPayload.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, db.ErrRefreshTokenReused)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Rotate Session Internal Error",
+			body: func(server *Server) (gin.H, db.Session, *token.Payload) {
+				refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
+					user.Username,
+					user.Role,
+					time.Minute,
+				)
+				require.NoError(t, err)
+				session := db.Session{
+					ID:           refreshPayload.ID,
+					Username:     user.Username,
+					RefreshToken: refreshToken,
+					IsBlocked:    false,
+					ExpiresAt:    time.Now().Add(time.Minute),
+				}
+				return gin.H{
+					"refresh_token": refreshToken,
+				}, session, refreshPayload
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session, refreshPayload *token.Payload) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(refreshPayload.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
@@ -62,6 +162,7 @@ func TestRenewAccessTokenAPI(t *testing.T) {
 			body: func(server *Server) (gin.H, db.Session, *token.Payload) {
 				refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 					user.Username,
+					user.Role,
 					time.Minute,
 				)
 				require.NoError(t, err)
@@ -92,6 +193,7 @@ func TestRenewAccessTokenAPI(t *testing.T) {
 			body: func(server *Server) (gin.H, db.Session, *token.Payload) {
 				refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 					user.Username,
+					user.Role,
 					time.Minute,
 				)
 				require.NoError(t, err)
@@ -123,6 +225,7 @@ func TestRenewAccessTokenAPI(t *testing.T) {
 			body: func(server *Server) (gin.H, db.Session, *token.Payload) {
 				refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 					user.Username,
+					user.Role,
 					time.Minute,
 				)
 				require.NoError(t, err)
@@ -154,6 +257,7 @@ func TestRenewAccessTokenAPI(t *testing.T) {
 			body: func(server *Server) (gin.H, db.Session, *token.Payload) {
 				refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 					user.Username,
+					user.Role,
 					time.Minute,
 				)
 				require.NoError(t, err)
@@ -184,6 +288,7 @@ func TestRenewAccessTokenAPI(t *testing.T) {
 			body: func(server *Server) (gin.H, db.Session, *token.Payload) {
 				refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 					user.Username,
+					user.Role,
 					time.Minute,
 				)
 				require.NoError(t, err)
@@ -240,3 +345,68 @@ func TestRenewAccessTokenAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestRenewAccessTokenRoleAPI(t *testing.T) {
+	user, _ := generateRandomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		utils.CustomerRole,
+		time.Minute,
+	)
+	require.NoError(t, err)
+	session := db.Session{
+		ID:           refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		ExpiresAt:    time.Now().Add(time.Minute),
+		FamilyID:     refreshPayload.ID,
+	}
+
+	// the user was made an admin after the login
+	admin := user
+	admin.Role = utils.AdminRole
+
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Eq(refreshPayload.ID)).
+		Times(1).
+		Return(session, nil)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(admin, nil)
+	store.EXPECT().
+		RotateSessionTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.RotateSessionTxParams) (db.Session, error) {
+			// the store caps the expiry at the one of the login session
+			return db.Session{ID: arg.NewSessionID, RefreshToken: arg.RefreshToken, ExpiresAt: session.ExpiresAt}, nil
+		})
+
+	data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/tokens/renew", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res renewAccessTokenResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &res)
+	require.NoError(t, err)
+	require.WithinDuration(t, session.ExpiresAt, res.RefreshTokenExpiresAt, time.Second)
+
+	for _, tokenString := range []string{res.AccessToken, res.RefreshToken} {
+		payload, err := server.tokenMaker.VerifyToken(tokenString)
+		require.NoError(t, err)
+		require.Equal(t, utils.AdminRole, payload.Role)
+	}
+}
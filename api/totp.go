@@ -0,0 +1,362 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/metrics"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+var (
+	errInvalidTOTPCode     = errors.New("invalid two-factor authentication code")
+	errInvalidMFAChallenge = errors.New("invalid or expired MFA challenge")
+)
+
+type enrollTOTPResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURL string `json:"otpauth_url"`
+}
+
+// enrollTOTP handles POST request, generates a new TOTP secret for the authenticated user.
+// Two-factor authentication is enabled once the secret is confirmed with a code.
+func (server *Server) enrollTOTP(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	secret, otpauthURL, err := utils.GenerateTOTPKey(authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// a pending enrollment is replaced, an enabled one is not
+	_, err = server.store.CreateUserTOTP(ctx, db.CreateUserTOTPParams{
+		Username: authPayload.Username,
+		Secret:   secret,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrTOTPAlreadyEnabled))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollTOTPResponse{
+		Secret:     secret,
+		OtpauthURL: otpauthURL,
+	})
+}
+
+type confirmTOTPRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type confirmTOTPResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirmTOTP handles POST request, enables two-factor authentication after the user
+// proved they set up the secret, and returns the recovery codes. They are shown only once.
+func (server *Server) confirmTOTP(ctx *gin.Context) {
+	var req confirmTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	userTOTP, err := server.store.GetUserTOTP(ctx, authPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if userTOTP.ConfirmedAt != nil {
+		ctx.JSON(http.StatusConflict, errorResponse(db.ErrTOTPAlreadyEnabled))
+		return
+	}
+
+	step, ok := utils.ValidateTOTP(userTOTP.Secret, req.Code, time.Now())
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidTOTPCode))
+		return
+	}
+
+	recoveryCodes, err := utils.GenerateRecoveryCodes(utils.RecoveryCodeCount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	hashedCodes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashedCodes[i] = utils.HashRecoveryCode(code)
+	}
+
+	_, err = server.store.ConfirmTOTPTx(ctx, db.ConfirmTOTPTxParams{
+		Username:            authPayload.Username,
+		Step:                step,
+		HashedRecoveryCodes: hashedCodes,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrTOTPCodeUsed):
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidTOTPCode))
+			return
+		case errors.Is(err, db.ErrTOTPAlreadyEnabled):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, confirmTOTPResponse{RecoveryCodes: recoveryCodes})
+}
+
+type verifyLoginMFARequest struct {
+	MFAChallengeToken string `json:"mfa_challenge_token" binding:"required"`
+	// exactly one of the codes must be given
+	TOTPCode     string `json:"totp_code" binding:"omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" binding:"omitempty,max=32"`
+}
+
+// verifyLoginMFA handles POST request, completes the login of a user with two-factor
+// authentication with a TOTP code or a recovery code and the challenge token of the first step
+func (server *Server) verifyLoginMFA(ctx *gin.Context) {
+	var req verifyLoginMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if (req.TOTPCode == "") == (req.RecoveryCode == "") {
+		err := errors.New("exactly one of totp_code and recovery_code must be given")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	challenge, err := server.store.GetMFAChallengeByToken(ctx, utils.HashToken(req.MFAChallengeToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidMFAChallenge))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if challenge.UsedAt != nil || time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= utils.MaxMFAAttempts {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidMFAChallenge))
+		return
+	}
+
+	clientIP := ctx.ClientIP()
+
+	// the codes are guessed no faster than passwords
+	locks, err := server.store.ListLoginLocks(ctx, db.ListLoginLocksParams{
+		Username: challenge.Username,
+		ClientIp: clientIP,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if len(locks) > 0 {
+		metrics.LoginFailed(metrics.LoginFailureLocked)
+		setRetryAfter(ctx, locks[0].LockedUntil)
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(errTooManyLoginAttempts))
+		return
+	}
+
+	var valid bool
+	if req.TOTPCode != "" {
+		var userTOTP db.UserTOTP
+		userTOTP, err = server.store.GetUserTOTP(ctx, challenge.Username)
+		if err == nil {
+			valid, err = server.useTOTPCode(ctx, userTOTP, req.TOTPCode)
+		}
+	} else {
+		valid, err = server.useRecoveryCode(ctx, challenge.Username, req.RecoveryCode)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !valid {
+		_, err = server.store.IncrementMFAChallengeAttempts(ctx, challenge.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		_, err = server.store.RecordLoginFailureTx(ctx, db.RecordLoginFailureTxParams{
+			Username:          challenge.Username,
+			ClientIP:          clientIP,
+			UsernameThreshold: server.config.LoginFailureThreshold,
+			IPThreshold:       server.config.LoginIPFailureThreshold,
+			LockoutDuration:   server.config.LoginLockoutDuration,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		metrics.LoginFailed(metrics.LoginFailureInvalidCode)
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidTOTPCode))
+		return
+	}
+
+	// a challenge completes one login, even when the same token is sent twice at once
+	_, err = server.store.UseMFAChallenge(ctx, challenge.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidMFAChallenge))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, challenge.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res, err := server.completeLogin(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// useTOTPCode checks the code against the enabled TOTP secret of the user.
+// A code is accepted once, so a code seen by someone else cannot be used after the user.
+func (server *Server) useTOTPCode(ctx context.Context, userTOTP db.UserTOTP, code string) (bool, error) {
+	if userTOTP.ConfirmedAt == nil {
+		return false, nil
+	}
+
+	step, ok := utils.ValidateTOTP(userTOTP.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	_, err := server.store.UseTOTPStep(ctx, db.UseTOTPStepParams{
+		Username: userTOTP.Username,
+		Step:     step,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// useRecoveryCode marks an unused recovery code of the user as used
+func (server *Server) useRecoveryCode(ctx context.Context, username string, code string) (bool, error) {
+	_, err := server.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		Username:   username,
+		HashedCode: utils.HashRecoveryCode(code),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// validStepUp checks that a transfer of the given amount comes with a fresh TOTP code
+// when it reaches the step-up amount and the user has two-factor authentication enabled.
+// It writes the error response and returns false otherwise.
+func (server *Server) validStepUp(ctx *gin.Context, username string, amount int64, totpCode string) bool {
+	if server.config.StepUpTransferAmount <= 0 || amount < server.config.StepUpTransferAmount {
+		return true
+	}
+
+	userTOTP, err := server.store.GetUserTOTP(ctx, username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return true
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if userTOTP.ConfirmedAt == nil {
+		return true
+	}
+
+	if totpCode == "" {
+		err := fmt.Errorf("transfers of %d or more require totp_code", server.config.StepUpTransferAmount)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+
+	clientIP := ctx.ClientIP()
+
+	// step-up codes count against the same lockout as the codes of a login
+	locks, err := server.store.ListLoginLocks(ctx, db.ListLoginLocksParams{
+		Username: username,
+		ClientIp: clientIP,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if len(locks) > 0 {
+		setRetryAfter(ctx, locks[0].LockedUntil)
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(errTooManyLoginAttempts))
+		return false
+	}
+
+	valid, err := server.useTOTPCode(ctx, userTOTP, totpCode)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if !valid {
+		_, err = server.store.RecordLoginFailureTx(ctx, db.RecordLoginFailureTxParams{
+			Username:          username,
+			ClientIP:          clientIP,
+			UsernameThreshold: server.config.LoginFailureThreshold,
+			IPThreshold:       server.config.LoginIPFailureThreshold,
+			LockoutDuration:   server.config.LoginLockoutDuration,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+
+		ctx.JSON(http.StatusForbidden, errorResponse(errInvalidTOTPCode))
+		return false
+	}
+
+	return true
+}
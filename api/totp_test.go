@@ -0,0 +1,1334 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	mockdb "github.com/aalug/bank-go/db/mock"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEnrollTOTPAPI(t *testing.T) {
+	user, _ := generateRandomUser(t)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTOTP(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateUserTOTPParams) (db.UserTOTP, error) {
+						require.Equal(t, user.Username, arg.Username)
+						return db.UserTOTP{Username: arg.Username, Secret: arg.Secret}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res enrollTOTPResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.NotEmpty(t, res.Secret)
+				require.Contains(t, res.OtpauthURL, "otpauth://totp/")
+				require.Contains(t, res.OtpauthURL, res.Secret)
+			},
+		},
+		{
+			name: "Already Enabled",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTOTP(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTOTP{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Server Error",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTOTP(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTOTP{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "No Authorization",
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/users/totp"
+			req, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, req)
+
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestConfirmTOTPAPI(t *testing.T) {
+	user, _ := generateRandomUser(t)
+	pendingTOTP, code := generateRandomUserTOTP(t, user.Username)
+	pendingTOTP.ConfirmedAt = nil
+
+	confirmedAt := time.Now()
+	enabledTOTP := pendingTOTP
+	enabledTOTP.ConfirmedAt = &confirmedAt
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"code": code,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pendingTOTP, nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ConfirmTOTPTxParams) (db.UserTOTP, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, time.Now().Unix()/30, arg.Step, 1)
+						require.Len(t, arg.HashedRecoveryCodes, utils.RecoveryCodeCount)
+						return enabledTOTP, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res confirmTOTPResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Len(t, res.RecoveryCodes, utils.RecoveryCodeCount)
+			},
+		},
+		{
+			name: "Wrong Code",
+			body: gin.H{
+				"code": wrongTOTPCode(code),
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pendingTOTP, nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Code Already Used",
+			body: gin.H{
+				"code": code,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pendingTOTP, nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTOTP{}, db.ErrTOTPCodeUsed)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Already Enabled",
+			body: gin.H{
+				"code": code,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(enabledTOTP, nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Not Enrolled",
+			body: gin.H{
+				"code": code,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTOTP{}, sql.ErrNoRows)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Code",
+			body: gin.H{
+				"code": "12ab",
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/users/totp/confirm"
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, req)
+
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestVerifyLoginMFAAPI(t *testing.T) {
+	user, _ := generateRandomUser(t)
+	userTOTP, code := generateRandomUserTOTP(t, user.Username)
+	clientIP := "203.0.113.7"
+
+	challengeToken, err := utils.GenerateSecureToken(32)
+	require.NoError(t, err)
+
+	challenge := db.MFAChallenge{
+		ID:          uuid.New(),
+		HashedToken: utils.HashToken(challengeToken),
+		Username:    user.Username,
+		ClientIp:    clientIP,
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+
+	expiredChallenge := challenge
+	expiredChallenge.ExpiresAt = time.Now().Add(-time.Second)
+
+	usedAt := time.Now()
+	usedChallenge := challenge
+	usedChallenge.UsedAt = &usedAt
+
+	exhaustedChallenge := challenge
+	exhaustedChallenge.Attempts = utils.MaxMFAAttempts
+
+	recoveryCode := "abcd-efgh-ijkl-mnop"
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK TOTP Code",
+			body: gin.H{
+				"mfa_challenge_token": challengeToken,
+				"totp_code":           code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMFAChallengeByToken(gomock.Any(), gomock.Eq(challenge.HashedToken)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Eq(db.ListLoginLocksParams{
+						Username: user.Username,
+						ClientIp: clientIP,
+					})).
+					Times(1).
+					Return([]db.LoginFailure{}, nil)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UseTOTPStepParams) (db.UserTOTP, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, time.Now().Unix()/30, arg.Step, 1)
+						return userTOTP, nil
+					})
+				store.EXPECT().
+					UseMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(usedChallenge, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res loginUserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.NotEmpty(t, res.AccessToken)
+				require.NotEmpty(t, res.RefreshToken)
+				require.Equal(t, user.Username, res.User.Username)
+			},
+		},
+		{
+			name: "OK Recovery Code",
+			body: gin.H{
+				"mfa_challenge_token": challengeToken,
+				"recovery_code":       recoveryCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMFAChallengeByToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginFailure{}, nil)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Eq(db.UseRecoveryCodeParams{
+						Username:   user.Username,
+						HashedCode: utils.HashRecoveryCode(recoveryCode),
+					})).
+					Times(1)
+				store.EXPECT().
+					UseMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(usedChallenge, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Wrong Code",
+			body: gin.H{
+				"mfa_challenge_token": challengeToken,
+				"totp_code":           wrongTOTPCode(code),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMFAChallengeByToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginFailure{}, nil)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					IncrementMFAChallengeAttempts(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Eq(db.RecordLoginFailureTxParams{
+						Username:          user.Username,
+						ClientIP:          clientIP,
+						UsernameThreshold: 5,
+						IPThreshold:       50,
+						LockoutDuration:   time.Minute,
+					})).
+					Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Code Already Used",
+			body: gin.H{
+				"mfa_challenge_token": challengeToken,
+				"totp_code":           code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMFAChallengeByToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginFailure{}, nil)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTOTP{}, sql.ErrNoRows)
+				store.EXPECT().
+					IncrementMFAChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Unknown Recovery Code",
+			body: gin.H{
+				"mfa_challenge_token": challengeToken,
+				"recovery_code":       recoveryCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMFAChallengeByToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginFailure{}, nil)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TotpRecoveryCode{}, sql.ErrNoRows)
+				store.EXPECT().
+					IncrementMFAChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Locked",
+			body: gin.H{
+				"mfa_challenge_token": challengeToken,
+				"totp_code":           code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMFAChallengeByToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginFailure{{
+						KeyType:     db.LoginKeyUsername,
+						Key:         user.Username,
+						LockedUntil: time.Now().Add(time.Minute),
+					}}, nil)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "60", recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "Challenge Not Found",
+			body: gin.H{
+				"mfa_challenge_token": challengeToken,
+				"totp_code":           code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMFAChallengeByToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MFAChallenge{}, sql.ErrNoRows)
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Challenge Expired",
+			body: gin.H{
+				"mfa_challenge_token": challengeToken,
+				"totp_code":           code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMFAChallengeByToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(expiredChallenge, nil)
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Challenge Used",
+			body: gin.H{
+				"mfa_challenge_token": challengeToken,
+				"totp_code":           code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMFAChallengeByToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(usedChallenge, nil)
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Too Many Attempts",
+			body: gin.H{
+				"mfa_challenge_token": challengeToken,
+				"totp_code":           code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMFAChallengeByToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(exhaustedChallenge, nil)
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Both Codes",
+			body: gin.H{
+				"mfa_challenge_token": challengeToken,
+				"totp_code":           code,
+				"recovery_code":       recoveryCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMFAChallengeByToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "No Code",
+			body: gin.H{
+				"mfa_challenge_token": challengeToken,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMFAChallengeByToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/users/login/mfa"
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			req.RemoteAddr = clientIP + ":54321"
+			// set by the client, so it must not be used as the client IP
+			req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+			server.router.ServeHTTP(recorder, req)
+
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCreateTransferStepUpAPI(t *testing.T) {
+	var amount int64 = 1000000
+
+	user1, _ := generateRandomUser(t)
+	user1.IsEmailVerified = true
+	user2, _ := generateRandomUser(t)
+	userTOTP, code := generateRandomUserTOTP(t, user1.Username)
+
+	account1 := generateRandomAccount(user1.Username)
+	account2 := generateRandomAccount(user2.Username)
+	account1.Currency = utils.EUR
+	account2.Currency = utils.EUR
+
+	idempotencyKey := utils.RandomString(32)
+
+	testCases := []struct {
+		name           string
+		body           gin.H
+		idempotencyKey string
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponse  func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+				"totp_code":       code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Two-Factor Authentication Disabled",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(db.UserTOTP{}, sql.ErrNoRows)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Below Step-Up Amount",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount - 1,
+				"currency":        utils.EUR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Code Missing",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Wrong Code",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+				"totp_code":       wrongTOTPCode(code),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Code Already Used",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+				"totp_code":       code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTOTP{}, sql.ErrNoRows)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Locked",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+				"totp_code":       code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginFailure{{
+						KeyType:     db.LoginKeyUsername,
+						Key:         user1.Username,
+						LockedUntil: time.Now().Add(time.Minute),
+					}}, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "60", recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "OK With Idempotency Key",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+				"totp_code":       code,
+			},
+			idempotencyKey: idempotencyKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{
+						Username: user1.Username,
+						Key:      idempotencyKey,
+					})).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					IdempotentTransferTx(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Retry With Same Idempotency Key And Code",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+				"totp_code":       code,
+			},
+			idempotencyKey: idempotencyKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Username:  user1.Username,
+						Key:       idempotencyKey,
+						ExpiresAt: time.Now().Add(time.Hour),
+					}, nil)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					IdempotentTransferTx(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Expired Idempotency Key",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+				"totp_code":       code,
+			},
+			idempotencyKey: idempotencyKey,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Username:  user1.Username,
+						Key:       idempotencyKey,
+						ExpiresAt: time.Now().Add(-time.Minute),
+					}, nil)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTOTP{}, sql.ErrNoRows)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					IdempotentTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+				AnyTimes().
+				Return(account1, nil)
+			store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+				AnyTimes().
+				Return(account2, nil)
+			tc.buildStubs(store)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user1.Username)).
+				AnyTimes().
+				Return(user1, nil)
+			store.EXPECT().
+				ListLoginLocks(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				Return([]db.LoginFailure{}, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/transfers"
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			if tc.idempotencyKey != "" {
+				req.Header.Set(idempotencyKeyHeader, tc.idempotencyKey)
+			}
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, req)
+
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestHoldAndScheduledTransferStepUpAPI(t *testing.T) {
+	var amount int64 = 1000000
+
+	user1, _ := generateRandomUser(t)
+	user1.IsEmailVerified = true
+	user2, _ := generateRandomUser(t)
+	userTOTP, code := generateRandomUserTOTP(t, user1.Username)
+
+	account1 := generateRandomAccount(user1.Username)
+	account2 := generateRandomAccount(user2.Username)
+	account1.Currency = utils.EUR
+	account2.Currency = utils.EUR
+
+	hold := generateRandomHold(account1, account2)
+	hold.Amount = amount
+
+	scheduledTransfer := generateRandomScheduledTransfer(account1, account2)
+	scheduledTransfer.Amount = amount
+
+	startAt := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Create Hold Code Missing",
+			method: http.MethodPost,
+			url:    "/holds",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					AuthorizeTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "Create Hold OK",
+			method: http.MethodPost,
+			url:    "/holds",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+				"totp_code":       code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					AuthorizeTx(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:   "Capture Hold Code Missing",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/holds/%d/capture", hold.ID),
+			body:   gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetHold(gomock.Any(), gomock.Eq(hold.ID)).
+					Times(1).
+					Return(hold, nil)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					CaptureTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "Capture Part Below Step-Up Amount",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/holds/%d/capture", hold.ID),
+			body:   gin.H{"amount": amount - 1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetHold(gomock.Any(), gomock.Eq(hold.ID)).
+					Times(1).
+					Return(hold, nil)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CaptureTx(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Create Scheduled Transfer Code Missing",
+			method: http.MethodPost,
+			url:    "/scheduled_transfers",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+				"recurrence":      db.RecurrenceOnce,
+				"start_at":        startAt,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					CreateScheduledTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "Update Scheduled Transfer Code Missing",
+			method: http.MethodPatch,
+			url:    fmt.Sprintf("/scheduled_transfers/%d", scheduledTransfer.ID),
+			body:   gin.H{"next_run_at": startAt},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(scheduledTransfer, nil)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(userTOTP, nil)
+				store.EXPECT().
+					UpdateScheduledTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "Suspend Scheduled Transfer Without Code",
+			method: http.MethodPatch,
+			url:    fmt.Sprintf("/scheduled_transfers/%d", scheduledTransfer.ID),
+			body:   gin.H{"status": db.ScheduledTransferSuspended},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).
+					Times(1).
+					Return(scheduledTransfer, nil)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					UpdateScheduledTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(scheduledTransfer, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+				AnyTimes().
+				Return(account1, nil)
+			store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+				AnyTimes().
+				Return(account2, nil)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user1.Username)).
+				AnyTimes().
+				Return(user1, nil)
+			tc.buildStubs(store)
+			store.EXPECT().
+				ListLoginLocks(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				Return([]db.LoginFailure{}, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(tc.method, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, req)
+
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+// generateRandomUserTOTP generates an enabled TOTP secret of the user and its current code
+func generateRandomUserTOTP(t *testing.T, username string) (db.UserTOTP, string) {
+	secret, _, err := utils.GenerateTOTPKey(username)
+	require.NoError(t, err)
+
+	code, err := utils.TOTPCode(secret, time.Now())
+	require.NoError(t, err)
+
+	confirmedAt := time.Now()
+	return db.UserTOTP{
+		Username:    username,
+		Secret:      secret,
+		ConfirmedAt: &confirmedAt,
+	}, code
+}
+
+// wrongTOTPCode returns a code that differs from the given one in every digit
+func wrongTOTPCode(code string) string {
+	wrong := []byte(code)
+	for i := range wrong {
+		wrong[i] = '0' + (wrong[i]-'0'+5)%10
+	}
+	return string(wrong)
+}
@@ -7,7 +7,14 @@ import (
 	db "github.com/aalug/bank-go/db/sqlc"
 	"github.com/aalug/bank-go/token"
 	"github.com/gin-gonic/gin"
+	"io"
 	"net/http"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
 )
 
 type transferRequest struct {
@@ -15,6 +22,8 @@ type transferRequest struct {
 	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
 	Amount        int64  `json:"amount" binding:"required,gt=0"`
 	Currency      string `json:"currency" binding:"required,currency"`
+	// TOTPCode is required from users with two-factor authentication for amounts from the step-up amount
+	TOTPCode string `json:"totp_code" binding:"omitempty,len=6,numeric"`
 }
 
 // createAccount handles POST request, creates new account
@@ -25,13 +34,20 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
+	idempotencyKey := ctx.GetHeader(idempotencyKeyHeader)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		err := fmt.Errorf("%s header must be at most %d characters long", idempotencyKeyHeader, maxIdempotencyKeyLength)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
 	if !valid {
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if fromAccount.Owner != authPayload.Username {
+	if fromAccount.Owner != authPayload.Username || !authPayload.AllowsAccount(fromAccount.ID) {
 		err := errors.New("from account does not belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -42,14 +58,46 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
+	if !server.validEmailVerified(ctx, authPayload.Username) {
+		return
+	}
+
+	replay, valid := server.validIdempotencyReplay(ctx, authPayload.Username, idempotencyKey)
+	if !valid {
+		return
+	}
+
+	// a retry of a committed transfer is replayed without a step-up,
+	// the code sent with it was already used by the first request
+	if !replay && !server.validStepUp(ctx, authPayload.Username, req.Amount, req.TOTPCode) {
+		return
+	}
+
 	arg := db.TransferTxParams{
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
 	}
 
-	result, err := server.store.TransferTx(ctx, arg)
+	var result db.TransferTxResult
+	var err error
+	if idempotencyKey != "" {
+		result, err = server.store.IdempotentTransferTx(ctx, db.IdempotentTransferTxParams{
+			TransferTxParams: arg,
+			Username:         authPayload.Username,
+			IdempotencyKey:   idempotencyKey,
+			KeyDuration:      server.config.IdempotencyKeyDuration,
+		})
+	} else {
+		result, err = server.store.TransferTx(ctx, arg)
+	}
 	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) ||
+			errors.Is(err, db.ErrAccountFrozen) ||
+			errors.Is(err, db.ErrIdempotencyKeyReused) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -57,6 +105,106 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, result)
 }
 
+type transferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reverseTransferRequest struct {
+	// Amount is optional, the whole remaining amount is reversed when it is omitted
+	Amount int64 `json:"amount" binding:"min=0"`
+}
+
+// reverseTransfer handles POST request, moves the money of a transfer, or a part of it,
+// back to the sender. Only the recipient of the transfer can reverse it.
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var uri transferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the body is optional
+	var req reverseTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if toAccount.Owner != authPayload.Username || !authPayload.AllowsAccount(toAccount.ID) {
+		err := errors.New("only the recipient of the transfer can reverse it")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if !server.validEmailVerified(ctx, authPayload.Username) {
+		return
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: uri.ID,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrTransferIsReversal) ||
+			errors.Is(err, db.ErrTransferAlreadyReversed) ||
+			errors.Is(err, db.ErrReversalExceedsTransfer) ||
+			errors.Is(err, db.ErrFXTransferNotReversible) ||
+			errors.Is(err, db.ErrInsufficientFunds) ||
+			errors.Is(err, db.ErrAccountFrozen) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrSystemAccount) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// validIdempotencyReplay checks if the idempotency key already holds the result
+// of a committed transfer of the user, so the request will be replayed
+func (server *Server) validIdempotencyReplay(ctx *gin.Context, username string, idempotencyKey string) (bool, bool) {
+	if idempotencyKey == "" {
+		return false, true
+	}
+
+	key, err := server.store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: username,
+		Key:      idempotencyKey,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, true
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false, false
+	}
+
+	return key.ExpiresAt.After(time.Now()), true
+}
+
 // validAccount checks if account exists and has the right currency
 func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountID)
@@ -70,6 +218,11 @@ func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency s
 		return account, false
 	}
 
+	if db.IsSystemAccountOwner(account.Owner) {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrSystemAccount))
+		return account, false
+	}
+
 	if account.Currency != currency {
 		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
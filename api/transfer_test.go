@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	mockdb "github.com/aalug/bank-go/db/mock"
 	db "github.com/aalug/bank-go/db/sqlc"
 	"github.com/aalug/bank-go/token"
@@ -21,6 +22,7 @@ func TestCreateTransferAPI(t *testing.T) {
 	var amount int64 = 10
 
 	user1, _ := generateRandomUser(t)
+	user1.IsEmailVerified = true
 	user2, _ := generateRandomUser(t)
 	user3, _ := generateRandomUser(t)
 
@@ -33,12 +35,15 @@ func TestCreateTransferAPI(t *testing.T) {
 	account2eur.Currency = utils.EUR
 	account3usd.Currency = utils.USD
 
+	idempotencyKey := utils.RandomString(32)
+
 	testCases := []struct {
-		name          string
-		body          gin.H
-		setupAuth     func(t *testing.T, r *http.Request, maker token.Maker)
-		buildStubs    func(store *mockdb.MockStore)
-		checkResponse func(recorder *httptest.ResponseRecorder)
+		name           string
+		body           gin.H
+		idempotencyKey string
+		setupAuth      func(t *testing.T, r *http.Request, maker token.Maker)
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponse  func(recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
@@ -76,6 +81,42 @@ func TestCreateTransferAPI(t *testing.T) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "Email Not Verified",
+			body: gin.H{
+				"from_account_id": account1eur.ID,
+				"to_account_id":   account2eur.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1eur.ID)).
+					Times(1).
+					Return(account1eur, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2eur.ID)).
+					Times(1).
+					Return(account2eur, nil)
+
+				unverifiedUser := user1
+				unverifiedUser.IsEmailVerified = false
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(unverifiedUser, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "From Account Not Found",
 			body: gin.H{
@@ -322,6 +363,153 @@ func TestCreateTransferAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "Insufficient Funds",
+			body: gin.H{
+				"from_account_id": account1eur.ID,
+				"to_account_id":   account2eur.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+			},
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1eur.ID)).
+					Times(1).
+					Return(account1eur, nil)
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2eur.ID)).
+					Times(1).
+					Return(account2eur, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "OK With Idempotency Key",
+			body: gin.H{
+				"from_account_id": account1eur.ID,
+				"to_account_id":   account2eur.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+			},
+			idempotencyKey: idempotencyKey,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1eur.ID)).
+					Times(1).
+					Return(account1eur, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2eur.ID)).
+					Times(1).
+					Return(account2eur, nil)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{
+						Username: user1.Username,
+						Key:      idempotencyKey,
+					})).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+
+				params := db.IdempotentTransferTxParams{
+					TransferTxParams: db.TransferTxParams{
+						FromAccountID: account1eur.ID,
+						ToAccountID:   account2eur.ID,
+						Amount:        amount,
+					},
+					Username:       user1.Username,
+					IdempotencyKey: idempotencyKey,
+					KeyDuration:    time.Hour,
+				}
+
+				store.EXPECT().
+					IdempotentTransferTx(gomock.Any(), gomock.Eq(params)).
+					Times(1)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Idempotency Key Reused",
+			body: gin.H{
+				"from_account_id": account1eur.ID,
+				"to_account_id":   account2eur.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+			},
+			idempotencyKey: idempotencyKey,
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1eur.ID)).
+					Times(1).
+					Return(account1eur, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2eur.ID)).
+					Times(1).
+					Return(account2eur, nil)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Username:  user1.Username,
+						Key:       idempotencyKey,
+						ExpiresAt: time.Now().Add(time.Hour),
+					}, nil)
+
+				store.EXPECT().
+					IdempotentTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrIdempotencyKeyReused)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "Idempotency Key Too Long",
+			body: gin.H{
+				"from_account_id": account1eur.ID,
+				"to_account_id":   account2eur.ID,
+				"amount":          amount,
+				"currency":        utils.EUR,
+			},
+			idempotencyKey: utils.RandomString(maxIdempotencyKeyLength + 1),
+			setupAuth: func(t *testing.T, r *http.Request, maker token.Maker) {
+				addAuthorization(t, r, maker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					IdempotentTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 	}
 	for i := range testCases {
 		tc := testCases[i]
@@ -332,6 +520,10 @@ func TestCreateTransferAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user1.Username)).
+				AnyTimes().
+				Return(user1, nil)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -343,6 +535,10 @@ func TestCreateTransferAPI(t *testing.T) {
 			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
 			require.NoError(t, err)
 
+			if tc.idempotencyKey != "" {
+				req.Header.Set(idempotencyKeyHeader, tc.idempotencyKey)
+			}
+
 			tc.setupAuth(t, req, server.tokenMaker)
 
 			server.router.ServeHTTP(recorder, req)
@@ -351,3 +547,254 @@ func TestCreateTransferAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestReverseTransferAPI(t *testing.T) {
+	user1, _ := generateRandomUser(t)
+	user2, _ := generateRandomUser(t)
+	user2.IsEmailVerified = true
+
+	account1 := generateRandomAccount(user1.Username)
+	account2 := generateRandomAccount(user2.Username)
+
+	transfer := db.Transfer{
+		ID:            utils.RandomInt(1, 1000),
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	}
+
+	testCases := []struct {
+		name          string
+		transferID    int64
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK Full Reversal",
+			transferID: transfer.ID,
+			body:       gin.H{},
+			username:   user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(account2, nil)
+
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Eq(db.ReverseTransferTxParams{TransferID: transfer.ID})).
+					Times(1).
+					Return(db.ReverseTransferTxResult{OriginalTransfer: transfer}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "Email Not Verified",
+			transferID: transfer.ID,
+			body:       gin.H{},
+			username:   user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(account2, nil)
+
+				unverifiedUser := user2
+				unverifiedUser.IsEmailVerified = false
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user2.Username)).
+					Times(1).
+					Return(unverifiedUser, nil)
+
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:       "OK Partial Refund",
+			transferID: transfer.ID,
+			body:       gin.H{"amount": 30},
+			username:   user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(account2, nil)
+
+				params := db.ReverseTransferTxParams{
+					TransferID: transfer.ID,
+					Amount:     30,
+				}
+
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Eq(params)).
+					Times(1).
+					Return(db.ReverseTransferTxResult{OriginalTransfer: transfer}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "Already Reversed",
+			transferID: transfer.ID,
+			body:       gin.H{},
+			username:   user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(account2, nil)
+
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrTransferAlreadyReversed)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:       "Sent By System Account",
+			transferID: transfer.ID,
+			body:       gin.H{},
+			username:   user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(account2, nil)
+
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrSystemAccount)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:       "Sender Cannot Reverse",
+			transferID: transfer.ID,
+			body:       gin.H{},
+			username:   user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(account2, nil)
+
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "Not Found",
+			transferID: transfer.ID,
+			body:       gin.H{},
+			username:   user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(db.Transfer{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "Invalid ID",
+			transferID: 0,
+			body:       gin.H{},
+			username:   user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user2.Username)).
+				AnyTimes().
+				Return(user2, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/transfers/%d/reverse", tc.transferID)
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, req)
+
+			tc.checkResponse(recorder)
+		})
+	}
+}
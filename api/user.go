@@ -2,12 +2,16 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/metrics"
 	"github.com/aalug/bank-go/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -22,6 +26,8 @@ type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
+	Role              string    `json:"role"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -32,6 +38,8 @@ func newUserResponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		IsEmailVerified:   user.IsEmailVerified,
+		Role:              user.Role,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
@@ -57,7 +65,9 @@ func (server *Server) createUser(ctx *gin.Context) {
 		Email:          req.Email,
 	}
 
-	user, err := server.store.CreateUser(ctx, params)
+	// a new user creates the account on their own behalf
+	setAuditActor(ctx, req.Username)
+	user, err := server.store.CreateUserTx(ctx, params)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
@@ -69,17 +79,29 @@ func (server *Server) createUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+
+	// the user can ask for another email if this one is not sent
+	logEmailError(ctx, user.Username, server.sendVerificationEmail(ctx, user))
+
 	res := newUserResponse(user)
 
 	ctx.JSON(http.StatusCreated, res)
 }
 
+var (
+	errInvalidCredentials   = errors.New("invalid username or password")
+	errTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+)
+
 type loginUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum"`
 	Password string `json:"password" binding:"required,min=6"`
 }
 
 type loginUserResponse struct {
+	// MFARequired is always false here, users with two-factor authentication
+	// get an mfaChallengeResponse from the first login step instead
+	MFARequired           bool         `json:"mfa_required"`
 	SessionID             uuid.UUID    `json:"session_id"`
 	AccessToken           string       `json:"access_token"`
 	AccessTokenExpiresAt  time.Time    `json:"access_token_expires_at"`
@@ -95,35 +117,137 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	clientIP := ctx.ClientIP()
+
+	// refuse the login without checking the password while the username or the IP is locked
+	locks, err := server.store.ListLoginLocks(ctx, db.ListLoginLocksParams{
+		Username: req.Username,
+		ClientIp: clientIP,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if len(locks) > 0 {
+		metrics.LoginFailed(metrics.LoginFailureLocked)
+		setRetryAfter(ctx, locks[0].LockedUntil)
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(errTooManyLoginAttempts))
+		return
+	}
+
 	user, err := server.store.GetUser(ctx, req.Username)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// unknown usernames and wrong passwords get the same response
+	if err == sql.ErrNoRows {
+		err = utils.CheckPasswordOfUnknownUser(req.Password)
+	} else {
+		err = utils.CheckPassword(req.Password, user.HashedPassword)
+	}
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		_, err = server.store.RecordLoginFailureTx(ctx, db.RecordLoginFailureTxParams{
+			Username:          req.Username,
+			ClientIP:          clientIP,
+			UsernameThreshold: server.config.LoginFailureThreshold,
+			IPThreshold:       server.config.LoginIPFailureThreshold,
+			LockoutDuration:   server.config.LoginLockoutDuration,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 			return
 		}
+
+		metrics.LoginFailed(metrics.LoginFailureInvalidCredentials)
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
+		return
+	}
+
+	// users with two-factor authentication get the tokens after the second step
+	userTOTP, err := server.store.GetUserTOTP(ctx, user.Username)
+	if err != nil && err != sql.ErrNoRows {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	err = utils.CheckPassword(req.Password, user.HashedPassword)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if err == nil && userTOTP.ConfirmedAt != nil {
+		res, err := server.createMFAChallenge(ctx, user.Username)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, res)
 		return
 	}
 
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	res, err := server.completeLogin(ctx, user)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
+	ctx.JSON(http.StatusOK, res)
+}
+
+type mfaChallengeResponse struct {
+	MFARequired           bool      `json:"mfa_required"`
+	MFAChallengeToken     string    `json:"mfa_challenge_token"`
+	MFAChallengeExpiresAt time.Time `json:"mfa_challenge_expires_at"`
+}
+
+// createMFAChallenge starts the second login step of a user with two-factor authentication.
+// Only the hash of the challenge token is stored.
+func (server *Server) createMFAChallenge(ctx *gin.Context, username string) (mfaChallengeResponse, error) {
+	challengeToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return mfaChallengeResponse{}, err
+	}
+
+	challenge, err := server.store.CreateMFAChallenge(ctx, db.CreateMFAChallengeParams{
+		ID:          uuid.New(),
+		HashedToken: utils.HashToken(challengeToken),
+		Username:    username,
+		ClientIp:    ctx.ClientIP(),
+		UserAgent:   ctx.Request.UserAgent(),
+		ExpiresAt:   time.Now().Add(server.config.MFAChallengeDuration),
+	})
+	if err != nil {
+		return mfaChallengeResponse{}, err
+	}
+
+	return mfaChallengeResponse{
+		MFARequired:           true,
+		MFAChallengeToken:     challengeToken,
+		MFAChallengeExpiresAt: challenge.ExpiresAt,
+	}, nil
+}
+
+// completeLogin clears the failed logins of the user and creates the tokens and the session
+func (server *Server) completeLogin(ctx *gin.Context, user db.User) (loginUserResponse, error) {
+	err := server.store.DeleteLoginFailure(ctx, db.DeleteLoginFailureParams{
+		KeyType: db.LoginKeyUsername,
+		Key:     user.Username,
+	})
+	if err != nil {
+		return loginUserResponse{}, err
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, server.config.AccessTokenDuration)
+	if err != nil {
+		return loginUserResponse{}, err
+	}
+
 	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
+		user.Role,
 		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return loginUserResponse{}, err
 	}
 
 	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
@@ -136,18 +260,22 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		ExpiresAt:    refreshPayload.ExpiredAt,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return loginUserResponse{}, err
 	}
 
-	res := loginUserResponse{
+	metrics.LoginSucceeded()
+	return loginUserResponse{
 		SessionID:             session.ID,
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
 		RefreshToken:          refreshToken,
 		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
 		User:                  newUserResponse(user),
-	}
+	}, nil
+}
 
-	ctx.JSON(http.StatusOK, res)
+// setRetryAfter tells the client how many seconds to wait before the lock is lifted
+func setRetryAfter(ctx *gin.Context, lockedUntil time.Time) {
+	retryAfter := math.Ceil(time.Until(lockedUntil).Seconds())
+	ctx.Header("Retry-After", strconv.Itoa(int(retryAfter)))
 }
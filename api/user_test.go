@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -17,6 +18,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 type eqCreateUserParamsMatcher struct {
@@ -72,9 +74,12 @@ func TestCreateUserAPI(t *testing.T) {
 				}
 
 				store.EXPECT().
-					CreateUser(gomock.Any(), EqCreateUserParams(params, password)).
+					CreateUserTx(gomock.Any(), EqCreateUserParams(params, password)).
 					Times(1).
 					Return(user, nil)
+				store.EXPECT().
+					CreateEmailTokenTx(gomock.Any(), gomock.Any()).
+					Times(1)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusCreated, recorder.Code)
@@ -91,7 +96,7 @@ func TestCreateUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrConnDone)
 			},
@@ -109,7 +114,7 @@ func TestCreateUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, &pq.Error{Code: "23505"})
 			},
@@ -127,7 +132,7 @@ func TestCreateUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -144,7 +149,7 @@ func TestCreateUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -161,7 +166,7 @@ func TestCreateUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -198,6 +203,7 @@ func TestCreateUserAPI(t *testing.T) {
 
 func TestLoginUserAPI(t *testing.T) {
 	user, password := generateRandomUser(t)
+	clientIP := "203.0.113.7"
 
 	testCases := []struct {
 		name          string
@@ -212,10 +218,31 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Eq(db.ListLoginLocksParams{
+						Username: user.Username,
+						ClientIp: clientIP,
+					})).
+					Times(1).
+					Return([]db.LoginFailure{}, nil)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTOTP{}, sql.ErrNoRows)
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Eq(db.DeleteLoginFailureParams{
+						KeyType: db.LoginKeyUsername,
+						Key:     user.Username,
+					})).
+					Times(1).
+					Return(nil)
 				store.EXPECT().
 					CreateSession(gomock.Any(), gomock.Any()).
 					Times(1)
@@ -225,22 +252,143 @@ func TestLoginUserAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "Not Found",
+			name: "MFA Required",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				confirmedAt := time.Now()
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginFailure{}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTOTP{Username: user.Username, ConfirmedAt: &confirmedAt}, nil)
+				store.EXPECT().
+					CreateMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateMFAChallengeParams) (db.MFAChallenge, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, clientIP, arg.ClientIp)
+						return db.MFAChallenge{ID: arg.ID, Username: arg.Username, ExpiresAt: arg.ExpiresAt}, nil
+					})
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res mfaChallengeResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.True(t, res.MFARequired)
+				require.NotEmpty(t, res.MFAChallengeToken)
+				require.NotContains(t, recorder.Body.String(), "access_token")
+			},
+		},
+		{
+			name: "User Not Found",
 			body: gin.H{
 				"username": user.Username,
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginFailure{}, nil)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Eq(db.RecordLoginFailureTxParams{
+						Username:          user.Username,
+						ClientIP:          clientIP,
+						UsernameThreshold: 5,
+						IPThreshold:       50,
+						LockoutDuration:   time.Minute,
+					})).
+					Times(1)
 				store.EXPECT().
 					CreateSession(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.JSONEq(t, `{"error": "invalid username or password"}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "Wrong Password",
+			body: gin.H{
+				"username": user.Username,
+				"password": "wrong password",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginFailure{}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Eq(db.RecordLoginFailureTxParams{
+						Username:          user.Username,
+						ClientIP:          clientIP,
+						UsernameThreshold: 5,
+						IPThreshold:       50,
+						LockoutDuration:   time.Minute,
+					})).
+					Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.JSONEq(t, `{"error": "invalid username or password"}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "Locked",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginFailure{{
+						KeyType:     db.LoginKeyUsername,
+						Key:         user.Username,
+						Failures:    5,
+						LockedUntil: time.Now().Add(10 * time.Minute),
+					}}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "600", recorder.Header().Get("Retry-After"))
 			},
 		},
 		{
@@ -250,6 +398,10 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginFailure{}, nil)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -262,6 +414,30 @@ func TestLoginUserAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "Record Login Failure Error",
+			body: gin.H{
+				"username": user.Username,
+				"password": "wrong password",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginFailure{}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
 		{
 			name: "Invalid Username",
 			body: gin.H{
@@ -269,6 +445,9 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(0)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(0)
@@ -287,6 +466,9 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": "abc",
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginLocks(gomock.Any(), gomock.Any()).
+					Times(0)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(0)
@@ -318,6 +500,9 @@ func TestLoginUserAPI(t *testing.T) {
 			url := "/users/login"
 			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
 			require.NoError(t, err)
+			req.RemoteAddr = clientIP + ":54321"
+			// set by the client, so it must not be used as the client IP
+			req.Header.Set("X-Forwarded-For", "198.51.100.1")
 
 			server.router.ServeHTTP(recorder, req)
 
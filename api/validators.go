@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/aalug/bank-go/token"
 	"github.com/aalug/bank-go/utils"
 	"github.com/go-playground/validator/v10"
 )
@@ -11,3 +12,10 @@ var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
 	}
 	return false
 }
+
+var validScope validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if scope, ok := fieldLevel.Field().Interface().(string); ok {
+		return token.IsSupportedScope(scope)
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+// Command bankctl runs maintenance tasks against the bank database.
+//
+// Usage:
+//
+//	bankctl reconcile [-config dir]
+//	bankctl fx-load -file path [-config dir]
+//	bankctl token-key -alg algorithm -id key-id [-dir dir]
+//
+// reconcile verifies the ledger invariants and prints a JSON report to the standard output.
+// It exits with status 1 when the ledger has drifted and with status 2 when the check could not run.
+//
+// fx-load loads the euro reference rates from an ECB XML or CSV file
+// (eurofxref-daily.xml, eurofxref.csv) into the fx_rates table.
+//
+// token-key generates a private key for the v4.public, EdDSA or RS256 token algorithm
+// and writes it to <dir>/<key-id>.pem, ready to be used as TOKEN_SIGNING_KEY_ID.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/fx"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	_ "github.com/lib/pq"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const (
+	exitDrift = 1
+	exitError = 2
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(exitError)
+	}
+
+	switch flag.Arg(0) {
+	case "reconcile":
+		os.Exit(runReconcile(flag.Args()[1:]))
+	case "fx-load":
+		os.Exit(runFXLoad(flag.Args()[1:]))
+	case "token-key":
+		os.Exit(runTokenKey(flag.Args()[1:]))
+	default:
+		usage()
+		os.Exit(exitError)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: bankctl <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  reconcile  verify the ledger invariants and print a JSON report")
+	fmt.Fprintln(os.Stderr, "  fx-load    load exchange rates from an ECB XML or CSV file")
+	fmt.Fprintln(os.Stderr, "  token-key  generate a private key for signing tokens")
+}
+
+// openStore loads the config from the directory and connects to the database
+func openStore(configPath string) (db.Store, *sql.DB, error) {
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot load env file: %w", err)
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot connect to the db: %w", err)
+	}
+
+	return db.NewStore(conn), conn, nil
+}
+
+// runReconcile runs the reconcile command and returns the exit code
+func runReconcile(args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	configPath := flags.String("config", ".", "directory with the app.env file")
+	_ = flags.Parse(args)
+
+	store, conn, err := openStore(*configPath)
+	if err != nil {
+		log.Print(err)
+		return exitError
+	}
+	defer conn.Close()
+
+	report, err := store.Reconcile(context.Background())
+	if err != nil {
+		log.Print("cannot reconcile the ledger: ", err)
+		return exitError
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Print("cannot write the report: ", err)
+		return exitError
+	}
+
+	if !report.Balanced {
+		return exitDrift
+	}
+
+	return 0
+}
+
+// runFXLoad runs the fx-load command and returns the exit code
+func runFXLoad(args []string) int {
+	flags := flag.NewFlagSet("fx-load", flag.ExitOnError)
+	configPath := flags.String("config", ".", "directory with the app.env file")
+	file := flags.String("file", "", "ECB rates file, .xml or .csv")
+	_ = flags.Parse(args)
+
+	if *file == "" {
+		flags.Usage()
+		return exitError
+	}
+
+	rates, err := fx.LoadFile(*file)
+	if err != nil {
+		log.Print("cannot read the rates file: ", err)
+		return exitError
+	}
+
+	store, conn, err := openStore(*configPath)
+	if err != nil {
+		log.Print(err)
+		return exitError
+	}
+	defer conn.Close()
+
+	loaded, err := store.LoadFXRatesTx(context.Background(), rates)
+	if err != nil {
+		log.Print("cannot load the rates: ", err)
+		return exitError
+	}
+
+	log.Printf("loaded %d rates published on %s", len(loaded), rates.PublishedOn.Format("2006-01-02"))
+	return 0
+}
+
+// runTokenKey runs the token-key command and returns the exit code
+func runTokenKey(args []string) int {
+	flags := flag.NewFlagSet("token-key", flag.ExitOnError)
+	algorithm := flags.String("alg", token.AlgorithmEdDSA, "token algorithm, v4.public, EdDSA or RS256")
+	keyID := flags.String("id", "", "id of the key, the name of the key file")
+	dir := flags.String("dir", "keys", "directory the key file is written to")
+	_ = flags.Parse(args)
+
+	if *keyID == "" {
+		flags.Usage()
+		return exitError
+	}
+
+	privateKey, err := token.GenerateKey(*algorithm)
+	if err != nil {
+		log.Print("cannot generate the key: ", err)
+		return exitError
+	}
+
+	data, err := token.EncodePrivateKey(privateKey)
+	if err != nil {
+		log.Print("cannot encode the key: ", err)
+		return exitError
+	}
+
+	err = os.MkdirAll(*dir, 0o700)
+	if err != nil {
+		log.Print("cannot create the keys directory: ", err)
+		return exitError
+	}
+
+	path := filepath.Join(*dir, *keyID+".pem")
+
+	// O_EXCL - never overwrite a key that may still be needed to verify tokens
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		log.Print("cannot create the key file: ", err)
+		return exitError
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	if err != nil {
+		log.Print("cannot write the key file: ", err)
+		return exitError
+	}
+
+	log.Printf("%s key written to %s", *algorithm, path)
+	return 0
+}
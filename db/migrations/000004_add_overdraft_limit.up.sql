@@ -0,0 +1,10 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "overdraft_limit" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "overdraft_limit_non_negative" CHECK ("overdraft_limit" >= 0);
+
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "balance_within_overdraft_limit" CHECK ("balance" >= -"overdraft_limit");
+
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'how far below zero the balance may go';
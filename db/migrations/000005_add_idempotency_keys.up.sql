@@ -0,0 +1,19 @@
+CREATE TABLE "idempotency_keys"
+(
+    "username"     varchar     NOT NULL,
+    "key"          varchar     NOT NULL,
+    "request_hash" varchar     NOT NULL,
+    "response"     jsonb       NOT NULL DEFAULT '{}',
+    "expires_at"   timestamptz NOT NULL,
+    "created_at"   timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("username", "key")
+);
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'sha256 of the request the key was first used with';
+
+COMMENT ON COLUMN "idempotency_keys"."response" IS 'serialized result returned on replay';
+
+ALTER TABLE "idempotency_keys"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,16 @@
+DELETE
+FROM "entries"
+WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" IN ('bank_cash', 'bank_settlement'));
+
+DELETE
+FROM "transfers"
+WHERE "from_account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" IN ('bank_cash', 'bank_settlement'))
+   OR "to_account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" IN ('bank_cash', 'bank_settlement'));
+
+DELETE
+FROM "accounts"
+WHERE "owner" IN ('bank_cash', 'bank_settlement');
+
+DELETE
+FROM "users"
+WHERE "username" IN ('bank_cash', 'bank_settlement');
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- system users own the internal ledger accounts, they can never log in
+INSERT INTO "users"
+    ("username", "hashed_password", "full_name", "email")
+VALUES ('bank_cash', '!', 'Bank Cash', 'cash@system.bank-go'),
+       ('bank_settlement', '!', 'Bank Settlement', 'settlement@system.bank-go');
+
+-- one cash and one settlement account per supported currency,
+-- they mirror customer deposits and withdrawals so they need an unlimited overdraft
+INSERT INTO "accounts"
+    ("owner", "balance", "currency", "overdraft_limit")
+VALUES ('bank_cash', 0, 'USD', 9223372036854775807),
+       ('bank_cash', 0, 'EUR', 9223372036854775807),
+       ('bank_cash', 0, 'CAD', 9223372036854775807),
+       ('bank_cash', 0, 'PLN', 9223372036854775807),
+       ('bank_settlement', 0, 'USD', 9223372036854775807),
+       ('bank_settlement', 0, 'EUR', 9223372036854775807),
+       ('bank_settlement', 0, 'CAD', 9223372036854775807),
+       ('bank_settlement', 0, 'PLN', 9223372036854775807);
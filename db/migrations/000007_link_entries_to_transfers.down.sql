@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "entries"
+    DROP COLUMN IF EXISTS "kind";
+
+ALTER TABLE IF EXISTS "entries"
+    DROP COLUMN IF EXISTS "transfer_id";
\ No newline at end of file
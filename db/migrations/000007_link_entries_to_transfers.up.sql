@@ -0,0 +1,36 @@
+ALTER TABLE "entries"
+    ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries"
+    ADD COLUMN "kind" varchar NOT NULL DEFAULT 'transfer';
+
+ALTER TABLE "entries"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer that produced the entry, if any';
+
+COMMENT ON COLUMN "entries"."kind" IS 'operation that produced the entry, e.g. transfer, deposit or withdrawal';
+
+-- link existing entries, a transfer and both of its entries were created
+-- in one transaction so they share the same created_at
+UPDATE "entries" AS e
+SET "transfer_id" = t."id"
+FROM "transfers" AS t
+WHERE e."transfer_id" IS NULL
+  AND e."created_at" = t."created_at"
+  AND ((e."account_id" = t."from_account_id" AND e."amount" = -t."amount")
+    OR (e."account_id" = t."to_account_id" AND e."amount" = t."amount"));
+
+-- transfers from and to the system accounts are deposits and withdrawals
+UPDATE "entries" AS e
+SET "kind" = CASE WHEN t."from_account_id" = s."id" THEN 'deposit' ELSE 'withdrawal' END
+FROM "transfers" AS t,
+     "accounts" AS s
+WHERE e."transfer_id" = t."id"
+  AND s."owner" IN ('bank_cash', 'bank_settlement')
+  AND (t."from_account_id" = s."id" OR t."to_account_id" = s."id");
+
+ALTER TABLE "entries"
+    ALTER COLUMN "kind" DROP DEFAULT;
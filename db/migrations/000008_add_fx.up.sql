@@ -0,0 +1,69 @@
+CREATE TABLE "fx_rates"
+(
+    "currency"     varchar         PRIMARY KEY,
+    "rate"         numeric(20, 10) NOT NULL,
+    "published_on" date            NOT NULL,
+    "updated_at"   timestamptz     NOT NULL DEFAULT (now()),
+    CONSTRAINT "fx_rate_positive" CHECK ("rate" > 0)
+);
+
+CREATE TABLE "fx_quotes"
+(
+    "id"            uuid PRIMARY KEY,
+    "username"      varchar         NOT NULL,
+    "from_currency" varchar         NOT NULL,
+    "to_currency"   varchar         NOT NULL,
+    "rate"          numeric(20, 10) NOT NULL,
+    "spread"        numeric(10, 6)  NOT NULL,
+    "is_used"       boolean         NOT NULL DEFAULT false,
+    "expires_at"    timestamptz     NOT NULL,
+    "created_at"    timestamptz     NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "transfers"
+    ADD COLUMN "to_amount" bigint;
+
+ALTER TABLE "transfers"
+    ADD COLUMN "fx_quote_id" uuid;
+
+ALTER TABLE "transfers"
+    ADD COLUMN "fx_rate" numeric(20, 10);
+
+ALTER TABLE "transfers"
+    ADD COLUMN "fx_spread" numeric(10, 6);
+
+COMMENT ON COLUMN "fx_rates"."rate" IS 'units of the currency for one euro, as published by the ECB';
+
+COMMENT ON COLUMN "fx_quotes"."rate" IS 'mid-market units of to_currency for one unit of from_currency';
+
+COMMENT ON COLUMN "fx_quotes"."spread" IS 'fraction of the converted amount kept by the bank';
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited in the currency of to_account, set only for cross-currency transfers';
+
+COMMENT ON COLUMN "transfers"."fx_rate" IS 'rate of the quote used for a cross-currency transfer';
+
+COMMENT ON COLUMN "transfers"."fx_spread" IS 'spread of the quote used for a cross-currency transfer';
+
+CREATE INDEX ON "fx_quotes" ("username");
+
+ALTER TABLE "fx_quotes"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfers"
+    ADD FOREIGN KEY ("fx_quote_id") REFERENCES "fx_quotes" ("id");
+
+ALTER TABLE "transfers"
+    ADD CONSTRAINT "fx_quote_used_once" UNIQUE ("fx_quote_id");
+
+-- the fx system accounts hold the bank's currency positions,
+-- they take the source currency and pay out the target currency of cross-currency transfers
+INSERT INTO "users"
+    ("username", "hashed_password", "full_name", "email")
+VALUES ('bank_fx', '!', 'Bank FX', 'fx@system.bank-go');
+
+INSERT INTO "accounts"
+    ("owner", "balance", "currency", "overdraft_limit")
+VALUES ('bank_fx', 0, 'USD', 9223372036854775807),
+       ('bank_fx', 0, 'EUR', 9223372036854775807),
+       ('bank_fx', 0, 'CAD', 9223372036854775807),
+       ('bank_fx', 0, 'PLN', 9223372036854775807);
@@ -0,0 +1,62 @@
+CREATE TABLE "scheduled_transfers"
+(
+    "id"              bigserial PRIMARY KEY,
+    "owner"           varchar     NOT NULL,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "recurrence"      varchar     NOT NULL,
+    "start_at"        timestamptz NOT NULL,
+    "next_run_at"     timestamptz NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'active',
+    "failure_count"   int         NOT NULL DEFAULT 0,
+    "claimed_until"   timestamptz,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    CONSTRAINT "scheduled_transfer_amount_positive" CHECK ("amount" > 0),
+    CONSTRAINT "scheduled_transfer_recurrence" CHECK ("recurrence" IN ('once', 'daily', 'weekly', 'monthly')),
+    CONSTRAINT "scheduled_transfer_status" CHECK ("status" IN ('active', 'suspended', 'completed'))
+);
+
+CREATE TABLE "scheduled_transfer_runs"
+(
+    "id"                    bigserial PRIMARY KEY,
+    "scheduled_transfer_id" bigint      NOT NULL,
+    "scheduled_for"         timestamptz NOT NULL,
+    "transfer_id"           bigint,
+    "succeeded"             boolean     NOT NULL,
+    "error_message"         varchar     NOT NULL DEFAULT '',
+    "created_at"            timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "scheduled_transfers"."recurrence" IS 'once, daily, weekly or monthly';
+
+COMMENT ON COLUMN "scheduled_transfers"."start_at" IS 'first run, later runs keep its time and day of the month';
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active, suspended after repeated failures, or completed';
+
+COMMENT ON COLUMN "scheduled_transfers"."failure_count" IS 'failed runs in a row';
+
+COMMENT ON COLUMN "scheduled_transfers"."claimed_until" IS 'set while a worker executes the transfer';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."scheduled_for" IS 'next_run_at of the scheduled transfer at the time of the run';
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "next_run_at");
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfer_runs"
+    ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "scheduled_transfer_runs"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
\ No newline at end of file
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS "holds";
+
+ALTER TABLE "accounts"
+    DROP CONSTRAINT IF EXISTS "balance_within_overdraft_limit";
+
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "balance_within_overdraft_limit" CHECK ("balance" >= -"overdraft_limit");
+
+ALTER TABLE "accounts"
+    DROP COLUMN IF EXISTS "available_balance";
+
+ALTER TABLE "accounts"
+    DROP COLUMN IF EXISTS "held_amount";
\ No newline at end of file
@@ -0,0 +1,55 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "held_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts"
+    ADD COLUMN "available_balance" bigint NOT NULL GENERATED ALWAYS AS ("balance" - "held_amount") STORED;
+
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "held_amount_non_negative" CHECK ("held_amount" >= 0);
+
+ALTER TABLE "accounts"
+    DROP CONSTRAINT "balance_within_overdraft_limit";
+
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "balance_within_overdraft_limit" CHECK ("balance" - "held_amount" >= -"overdraft_limit");
+
+COMMENT ON COLUMN "accounts"."held_amount" IS 'sum of authorized holds on the account';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'balance less the held amount';
+
+CREATE TABLE "holds"
+(
+    "id"              bigserial PRIMARY KEY,
+    "account_id"      bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'authorized',
+    "captured_amount" bigint      NOT NULL DEFAULT 0,
+    "transfer_id"     bigint,
+    "expires_at"      timestamptz NOT NULL,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    CONSTRAINT "hold_amount_positive" CHECK ("amount" > 0),
+    CONSTRAINT "hold_captured_amount" CHECK ("captured_amount" >= 0 AND "captured_amount" <= "amount"),
+    CONSTRAINT "hold_status" CHECK ("status" IN ('authorized', 'captured', 'voided', 'expired'))
+);
+
+COMMENT ON COLUMN "holds"."account_id" IS 'account whose funds are reserved';
+
+COMMENT ON COLUMN "holds"."to_account_id" IS 'account that receives the money when the hold is captured';
+
+COMMENT ON COLUMN "holds"."status" IS 'authorized, captured, voided or expired';
+
+COMMENT ON COLUMN "holds"."transfer_id" IS 'transfer created by the capture';
+
+CREATE INDEX ON "holds" ("account_id");
+
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+ALTER TABLE "holds"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE "transfers"
+    DROP COLUMN IF EXISTS "refunded_amount";
+
+ALTER TABLE "transfers"
+    DROP COLUMN IF EXISTS "reversed_transfer_id";
\ No newline at end of file
@@ -0,0 +1,17 @@
+ALTER TABLE "transfers"
+    ADD COLUMN "reversed_transfer_id" bigint;
+
+ALTER TABLE "transfers"
+    ADD COLUMN "refunded_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers"
+    ADD CONSTRAINT "refunded_amount_within_amount" CHECK ("refunded_amount" >= 0 AND "refunded_amount" <= "amount");
+
+COMMENT ON COLUMN "transfers"."reversed_transfer_id" IS 'transfer this one reverses, set only for reversals and refunds';
+
+COMMENT ON COLUMN "transfers"."refunded_amount" IS 'sum of the reversals of this transfer';
+
+CREATE INDEX ON "transfers" ("reversed_transfer_id");
+
+ALTER TABLE "transfers"
+    ADD FOREIGN KEY ("reversed_transfer_id") REFERENCES "transfers" ("id");
\ No newline at end of file
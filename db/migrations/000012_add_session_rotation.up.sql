@@ -0,0 +1,25 @@
+ALTER TABLE "sessions"
+    ADD COLUMN "family_id" uuid;
+
+UPDATE "sessions"
+SET "family_id" = "id";
+
+ALTER TABLE "sessions"
+    ALTER COLUMN "family_id" SET NOT NULL;
+
+ALTER TABLE "sessions"
+    ADD COLUMN "parent_id" uuid;
+
+ALTER TABLE "sessions"
+    ADD COLUMN "rotated_at" timestamptz;
+
+COMMENT ON COLUMN "sessions"."family_id" IS 'id of the session created at login, shared by all sessions rotated from it';
+
+COMMENT ON COLUMN "sessions"."parent_id" IS 'session whose refresh token was exchanged for this one';
+
+COMMENT ON COLUMN "sessions"."rotated_at" IS 'set once the refresh token has been exchanged, presenting it again means it was reused';
+
+CREATE INDEX ON "sessions" ("family_id");
+
+ALTER TABLE "sessions"
+    ADD FOREIGN KEY ("parent_id") REFERENCES "sessions" ("id");
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "admin_actions";
+
+ALTER TABLE "accounts"
+    DROP COLUMN IF EXISTS "is_frozen";
+
+ALTER TABLE "users"
+    DROP COLUMN IF EXISTS "role";
\ No newline at end of file
@@ -0,0 +1,27 @@
+ALTER TABLE "users"
+    ADD COLUMN "role" varchar NOT NULL DEFAULT 'customer';
+
+ALTER TABLE "users"
+    ADD CONSTRAINT "supported_role" CHECK ("role" IN ('customer', 'admin'));
+
+ALTER TABLE "accounts"
+    ADD COLUMN "is_frozen" boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "accounts"."is_frozen" IS 'no money can leave a frozen account';
+
+CREATE TABLE "admin_actions"
+(
+    "id"             bigserial PRIMARY KEY,
+    "admin_username" varchar     NOT NULL,
+    "action"         varchar     NOT NULL,
+    "target_type"    varchar     NOT NULL,
+    "target_id"      varchar     NOT NULL,
+    "created_at"     timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "admin_actions" ("admin_username");
+
+CREATE INDEX ON "admin_actions" ("target_type", "target_id");
+
+ALTER TABLE "admin_actions"
+    ADD FOREIGN KEY ("admin_username") REFERENCES "users" ("username");
\ No newline at end of file
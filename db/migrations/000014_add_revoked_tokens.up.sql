@@ -0,0 +1,28 @@
+CREATE TABLE "revoked_tokens"
+(
+    "id"         uuid PRIMARY KEY,
+    "username"   varchar     NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "user_token_revocations"
+(
+    "username"      varchar PRIMARY KEY,
+    "issued_before" timestamptz NOT NULL,
+    "created_at"    timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "revoked_tokens" ("expires_at");
+
+COMMENT ON COLUMN "revoked_tokens"."id" IS 'id of the token payload';
+
+COMMENT ON COLUMN "revoked_tokens"."expires_at" IS 'expiry of the token, the row can be deleted afterwards';
+
+COMMENT ON COLUMN "user_token_revocations"."issued_before" IS 'tokens of the user issued before this time are revoked';
+
+ALTER TABLE "revoked_tokens"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "user_token_revocations"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
\ No newline at end of file
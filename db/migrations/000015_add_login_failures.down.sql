@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "login_failures";
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE "login_failures"
+(
+    "key_type"       varchar     NOT NULL,
+    "key"            varchar     NOT NULL,
+    "failures"       integer     NOT NULL,
+    "last_failed_at" timestamptz NOT NULL,
+    "locked_until"   timestamptz NOT NULL,
+    PRIMARY KEY ("key_type", "key")
+);
+
+COMMENT ON COLUMN "login_failures"."key_type" IS 'username or ip';
+
+COMMENT ON COLUMN "login_failures"."key" IS 'username or client ip, unknown usernames are tracked too';
+
+COMMENT ON COLUMN "login_failures"."failures" IS 'failed logins in a row, reset once the lockout duration passes without a failure';
+
+COMMENT ON COLUMN "login_failures"."locked_until" IS 'no login attempts are checked before this time';
\ No newline at end of file
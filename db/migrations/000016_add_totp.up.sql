@@ -0,0 +1,53 @@
+CREATE TABLE "user_totp"
+(
+    "username"       varchar PRIMARY KEY,
+    "secret"         varchar     NOT NULL,
+    "confirmed_at"   timestamptz,
+    "last_used_step" bigint      NOT NULL DEFAULT 0,
+    "created_at"     timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "totp_recovery_codes"
+(
+    "id"          bigserial PRIMARY KEY,
+    "username"    varchar     NOT NULL,
+    "hashed_code" varchar     NOT NULL,
+    "used_at"     timestamptz,
+    "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "mfa_challenges"
+(
+    "id"           uuid PRIMARY KEY,
+    "hashed_token" varchar     NOT NULL,
+    "username"     varchar     NOT NULL,
+    "attempts"     integer     NOT NULL DEFAULT 0,
+    "client_ip"    varchar     NOT NULL,
+    "user_agent"   varchar     NOT NULL,
+    "expires_at"   timestamptz NOT NULL,
+    "used_at"      timestamptz,
+    "created_at"   timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "totp_recovery_codes" ("username", "hashed_code");
+
+CREATE UNIQUE INDEX ON "mfa_challenges" ("hashed_token");
+
+COMMENT ON COLUMN "user_totp"."confirmed_at" IS 'two-factor authentication is enabled once the enrollment is confirmed with a code';
+
+COMMENT ON COLUMN "user_totp"."last_used_step" IS 'time step of the last accepted code, codes of this or earlier steps are refused';
+
+COMMENT ON COLUMN "totp_recovery_codes"."hashed_code" IS 'sha256 of the normalized one-time recovery code';
+
+COMMENT ON COLUMN "mfa_challenges"."hashed_token" IS 'sha256 of the challenge token returned by the first login step';
+
+COMMENT ON COLUMN "mfa_challenges"."attempts" IS 'failed attempts at the second login step';
+
+ALTER TABLE "user_totp"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "totp_recovery_codes"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "mfa_challenges"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
\ No newline at end of file
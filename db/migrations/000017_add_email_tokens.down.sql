@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "email_tokens";
+
+ALTER TABLE "users"
+    DROP COLUMN IF EXISTS "is_email_verified";
\ No newline at end of file
@@ -0,0 +1,34 @@
+-- users that signed up before verification was required keep transferring money,
+-- only new users start unverified
+ALTER TABLE "users"
+    ADD COLUMN "is_email_verified" boolean NOT NULL DEFAULT true;
+
+ALTER TABLE "users"
+    ALTER COLUMN "is_email_verified" SET DEFAULT false;
+
+COMMENT ON COLUMN "users"."is_email_verified" IS 'users can transfer money only after verifying their email address';
+
+CREATE TABLE "email_tokens"
+(
+    "id"           bigserial PRIMARY KEY,
+    "hashed_token" varchar     NOT NULL,
+    "username"     varchar     NOT NULL,
+    "purpose"      varchar     NOT NULL,
+    "email"        varchar     NOT NULL,
+    "expires_at"   timestamptz NOT NULL,
+    "used_at"      timestamptz,
+    "created_at"   timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "email_tokens" ("hashed_token");
+
+CREATE INDEX ON "email_tokens" ("username", "purpose");
+
+COMMENT ON COLUMN "email_tokens"."hashed_token" IS 'sha256 of the token sent in the email';
+
+COMMENT ON COLUMN "email_tokens"."purpose" IS 'verify_email or reset_password';
+
+COMMENT ON COLUMN "email_tokens"."email" IS 'address the token was sent to';
+
+ALTER TABLE "email_tokens"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
\ No newline at end of file
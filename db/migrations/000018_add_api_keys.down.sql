@@ -0,0 +1,4 @@
+ALTER TABLE "scheduled_transfers"
+    DROP COLUMN IF EXISTS "api_key_id";
+
+DROP TABLE IF EXISTS "api_keys";
\ No newline at end of file
@@ -0,0 +1,45 @@
+CREATE TABLE "api_keys"
+(
+    "id"            bigserial PRIMARY KEY,
+    "prefix"        varchar     NOT NULL,
+    "hashed_secret" varchar     NOT NULL,
+    "owner"         varchar     NOT NULL,
+    "name"          varchar     NOT NULL,
+    "scopes"        varchar[]   NOT NULL,
+    "account_ids"   bigint[]    NOT NULL DEFAULT '{}',
+    "created_by"    varchar     NOT NULL,
+    "expires_at"    timestamptz,
+    "last_used_at"  timestamptz,
+    "last_used_ip"  varchar     NOT NULL DEFAULT '',
+    "revoked_at"    timestamptz,
+    "created_at"    timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "api_keys" ("prefix");
+
+CREATE INDEX ON "api_keys" ("owner");
+
+COMMENT ON COLUMN "api_keys"."prefix" IS 'public part of the key that it is looked up by';
+
+COMMENT ON COLUMN "api_keys"."hashed_secret" IS 'sha256 of the secret part of the key';
+
+COMMENT ON COLUMN "api_keys"."scopes" IS 'what the key can be used for, for example accounts:read or transfers:write';
+
+COMMENT ON COLUMN "api_keys"."account_ids" IS 'accounts of the owner the key can act on, empty for all of them';
+
+COMMENT ON COLUMN "api_keys"."created_by" IS 'the owner or an admin';
+
+ALTER TABLE "api_keys"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "api_keys"
+    ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+-- a transfer scheduled with an API key runs only while the key still allows it
+ALTER TABLE "scheduled_transfers"
+    ADD COLUMN "api_key_id" bigint;
+
+COMMENT ON COLUMN "scheduled_transfers"."api_key_id" IS 'API key the transfer was scheduled with, its limits are checked before every run';
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("api_key_id") REFERENCES "api_keys" ("id");
@@ -0,0 +1,91 @@
+CREATE TABLE "oauth_clients"
+(
+    "id"            varchar PRIMARY KEY,
+    "hashed_secret" varchar     NOT NULL DEFAULT '',
+    "name"          varchar     NOT NULL,
+    "owner"         varchar     NOT NULL,
+    "redirect_uris" varchar[]   NOT NULL,
+    "scopes"        varchar[]   NOT NULL,
+    "created_at"    timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "oauth_consents"
+(
+    "username"   varchar     NOT NULL,
+    "client_id"  varchar     NOT NULL,
+    "scopes"     varchar[]   NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "updated_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("username", "client_id")
+);
+
+CREATE TABLE "oauth_authorization_codes"
+(
+    "id"             bigserial PRIMARY KEY,
+    "hashed_code"    varchar     NOT NULL,
+    "client_id"      varchar     NOT NULL,
+    "username"       varchar     NOT NULL,
+    "redirect_uri"   varchar     NOT NULL,
+    "scopes"         varchar[]   NOT NULL,
+    "code_challenge" varchar     NOT NULL,
+    "expires_at"     timestamptz NOT NULL,
+    "used_at"        timestamptz,
+    "created_at"     timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "oauth_refresh_tokens"
+(
+    "id"           bigserial PRIMARY KEY,
+    "hashed_token" varchar     NOT NULL,
+    "client_id"    varchar     NOT NULL,
+    "username"     varchar     NOT NULL,
+    "scopes"       varchar[]   NOT NULL,
+    "expires_at"   timestamptz NOT NULL,
+    "revoked_at"   timestamptz,
+    "created_at"   timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "oauth_clients" ("owner");
+
+CREATE UNIQUE INDEX ON "oauth_authorization_codes" ("hashed_code");
+
+CREATE UNIQUE INDEX ON "oauth_refresh_tokens" ("hashed_token");
+
+CREATE INDEX ON "oauth_refresh_tokens" ("username", "client_id");
+
+COMMENT ON COLUMN "oauth_clients"."id" IS 'public client_id of the app';
+
+COMMENT ON COLUMN "oauth_clients"."hashed_secret" IS 'sha256 of the client secret, empty for public clients';
+
+COMMENT ON COLUMN "oauth_clients"."owner" IS 'user that registered the app';
+
+COMMENT ON COLUMN "oauth_clients"."scopes" IS 'the most the app can ask users for';
+
+COMMENT ON COLUMN "oauth_consents"."scopes" IS 'scopes the user has granted to the app';
+
+COMMENT ON COLUMN "oauth_authorization_codes"."hashed_code" IS 'sha256 of the code sent to the redirect URI';
+
+COMMENT ON COLUMN "oauth_authorization_codes"."code_challenge" IS 'S256 PKCE challenge the code verifier is checked against';
+
+COMMENT ON COLUMN "oauth_refresh_tokens"."hashed_token" IS 'sha256 of the refresh token';
+
+ALTER TABLE "oauth_clients"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "oauth_consents"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "oauth_consents"
+    ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("id");
+
+ALTER TABLE "oauth_authorization_codes"
+    ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("id");
+
+ALTER TABLE "oauth_authorization_codes"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "oauth_refresh_tokens"
+    ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("id");
+
+ALTER TABLE "oauth_refresh_tokens"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
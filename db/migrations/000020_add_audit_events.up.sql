@@ -0,0 +1,52 @@
+CREATE TABLE "audit_events"
+(
+    "id"          bigserial PRIMARY KEY,
+    "actor"       varchar     NOT NULL,
+    "action"      varchar     NOT NULL,
+    "target_type" varchar     NOT NULL,
+    "target_id"   varchar     NOT NULL,
+    "before"      json,
+    "after"       json,
+    "request_id"  varchar     NOT NULL DEFAULT '',
+    "client_ip"   varchar     NOT NULL DEFAULT '',
+    "user_agent"  varchar     NOT NULL DEFAULT '',
+    "prev_hash"   varchar     NOT NULL,
+    "hash"        varchar     NOT NULL,
+    "created_at"  timestamptz NOT NULL
+);
+
+CREATE INDEX ON "audit_events" ("actor");
+
+CREATE INDEX ON "audit_events" ("target_type", "target_id");
+
+CREATE INDEX ON "audit_events" ("created_at");
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'user that made the change, system for background jobs';
+
+COMMENT ON COLUMN "audit_events"."before" IS 'state of the target before the change, null if it was created';
+
+COMMENT ON COLUMN "audit_events"."after" IS 'state of the target after the change, null if it was deleted';
+
+COMMENT ON COLUMN "audit_events"."prev_hash" IS 'hash of the previous event, empty for the first one';
+
+COMMENT ON COLUMN "audit_events"."hash" IS 'sha256 of the event and prev_hash, any change to a past event breaks the chain';
+
+-- the log is append-only, events cannot be changed or removed
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE
+    ON "audit_events"
+    FOR EACH ROW
+EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE
+    ON "audit_events"
+    FOR EACH STATEMENT
+EXECUTE FUNCTION audit_events_append_only();
@@ -10,6 +10,13 @@ FROM accounts
 WHERE id = $1
 LIMIT 1;
 
+-- name: GetAccountByOwnerAndCurrency :one
+SELECT *
+FROM accounts
+WHERE owner = $1
+  AND currency = $2
+LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT *
 FROM accounts
@@ -35,6 +42,31 @@ SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
+-- name: AddAccountHeldAmount :one
+UPDATE accounts
+SET held_amount = held_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CaptureAccountHold :one
+UPDATE accounts
+SET balance     = balance - sqlc.arg(amount),
+    held_amount = held_amount - sqlc.arg(held_amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = sqlc.arg(overdraft_limit)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: SetAccountFrozen :one
+UPDATE accounts
+SET is_frozen = sqlc.arg(is_frozen)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: DeleteAccount :exec
 DELETE
 FROM accounts
@@ -0,0 +1,13 @@
+-- name: CreateAdminAction :one
+INSERT INTO admin_actions
+    (admin_username, action, target_type, target_id)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListAdminActionsByTarget :many
+SELECT *
+FROM admin_actions
+WHERE target_type = $1
+  AND target_id = $2
+ORDER BY id DESC
+LIMIT $3 OFFSET $4;
\ No newline at end of file
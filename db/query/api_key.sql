@@ -0,0 +1,45 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (prefix,
+                      hashed_secret,
+                      owner,
+                      name,
+                      scopes,
+                      account_ids,
+                      created_by,
+                      expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetAPIKey :one
+SELECT *
+FROM api_keys
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetAPIKeyByPrefix :one
+SELECT api_keys.*, users.role AS owner_role
+FROM api_keys
+         JOIN users ON users.username = api_keys.owner
+WHERE api_keys.prefix = $1
+LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT *
+FROM api_keys
+WHERE owner = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = $1
+  AND revoked_at IS NULL
+RETURNING *;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = now(),
+    last_used_ip = sqlc.arg(client_ip)
+WHERE id = sqlc.arg(id)
+  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
\ No newline at end of file
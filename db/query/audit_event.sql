@@ -0,0 +1,35 @@
+-- name: LockAuditLog :exec
+-- held until the end of the transaction, so events are chained one at a time
+SELECT pg_advisory_xact_lock(hashtext('audit_events'));
+
+-- name: GetLastAuditEventHash :one
+SELECT hash
+FROM audit_events
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events
+(actor, action, target_type, target_id, before, after, request_id, client_ip, user_agent, prev_hash, hash, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING *;
+
+-- name: ListAuditEvents :many
+SELECT *
+FROM audit_events
+WHERE (sqlc.narg('actor')::varchar IS NULL OR actor = sqlc.narg('actor'))
+  AND (sqlc.narg('action')::varchar IS NULL OR action = sqlc.narg('action'))
+  AND (sqlc.narg('target_type')::varchar IS NULL OR target_type = sqlc.narg('target_type'))
+  AND (sqlc.narg('target_id')::varchar IS NULL OR target_id = sqlc.narg('target_id'))
+  AND (sqlc.narg('request_id')::varchar IS NULL OR request_id = sqlc.narg('request_id'))
+  AND (sqlc.narg('from_time')::timestamptz IS NULL OR created_at >= sqlc.narg('from_time'))
+  AND (sqlc.narg('to_time')::timestamptz IS NULL OR created_at < sqlc.narg('to_time'))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListAuditEventsAfter :many
+SELECT *
+FROM audit_events
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
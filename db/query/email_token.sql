@@ -0,0 +1,20 @@
+-- name: CreateEmailToken :one
+INSERT INTO email_tokens (hashed_token, username, purpose, email, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UseEmailToken :one
+UPDATE email_tokens
+SET used_at = now()
+WHERE hashed_token = $1
+  AND purpose = $2
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING *;
+
+-- name: InvalidateEmailTokens :exec
+UPDATE email_tokens
+SET used_at = now()
+WHERE username = $1
+  AND purpose = $2
+  AND used_at IS NULL;
\ No newline at end of file
@@ -1,7 +1,7 @@
 -- name: CreateEntry :one
 INSERT INTO entries
-    (account_id, amount)
-VALUES ($1, $2)
+    (account_id, amount, transfer_id, kind)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: GetEntry :one
@@ -15,4 +15,23 @@ SELECT *
 FROM entries
 WHERE account_id = $1
 ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: ListTransferEntries :many
+SELECT *
+FROM entries
+WHERE transfer_id = $1
+ORDER BY id;
+
+-- name: ListAccountStatement :many
+SELECT e.*,
+       c.id    AS counterparty_account_id,
+       c.owner AS counterparty_owner
+FROM entries AS e
+         LEFT JOIN transfers AS t ON t.id = e.transfer_id
+         LEFT JOIN accounts AS c ON c.id = CASE
+                                               WHEN t.from_account_id = e.account_id THEN t.to_account_id
+                                               ELSE t.from_account_id END
+WHERE e.account_id = $1
+ORDER BY e.id
 LIMIT $2 OFFSET $3;
\ No newline at end of file
@@ -0,0 +1,44 @@
+-- name: UpsertFXRate :one
+INSERT INTO fx_rates
+    (currency, rate, published_on)
+VALUES ($1, $2, $3)
+ON CONFLICT (currency) DO UPDATE
+    SET rate         = excluded.rate,
+        published_on = excluded.published_on,
+        updated_at   = now()
+RETURNING *;
+
+-- name: GetFXRate :one
+SELECT *
+FROM fx_rates
+WHERE currency = $1
+LIMIT 1;
+
+-- name: ListFXRates :many
+SELECT *
+FROM fx_rates
+ORDER BY currency;
+
+-- name: CreateFXQuote :one
+INSERT INTO fx_quotes
+    (id, username, from_currency, to_currency, rate, spread, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetFXQuote :one
+SELECT *
+FROM fx_quotes
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetFXQuoteForUpdate :one
+SELECT *
+FROM fx_quotes
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: MarkFXQuoteUsed :one
+UPDATE fx_quotes
+SET is_used = true
+WHERE id = $1
+RETURNING *;
\ No newline at end of file
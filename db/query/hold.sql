@@ -0,0 +1,49 @@
+-- name: CreateHold :one
+INSERT INTO holds
+    (account_id, to_account_id, amount, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetHold :one
+SELECT *
+FROM holds
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT *
+FROM holds
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: ListHolds :many
+SELECT *
+FROM holds
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CaptureHold :one
+UPDATE holds
+SET status          = 'captured',
+    captured_amount = sqlc.arg(captured_amount),
+    transfer_id     = sqlc.arg(transfer_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateHoldStatus :one
+UPDATE holds
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ExpireHolds :many
+UPDATE holds
+SET status = 'expired'
+WHERE id IN (SELECT id
+             FROM holds
+             WHERE status = 'authorized'
+               AND expires_at <= now()
+             ORDER BY id
+             LIMIT sqlc.arg(max_count) FOR UPDATE SKIP LOCKED)
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,38 @@
+-- name: RecordLoginFailure :one
+INSERT INTO login_failures (key_type, key, failures, last_failed_at, locked_until)
+VALUES ($1, $2, 1, now(), now())
+ON CONFLICT (key_type, key) DO UPDATE
+    SET failures       = CASE
+                             WHEN login_failures.last_failed_at <
+                                  now() - make_interval(secs => sqlc.arg(reset_after_seconds)::float8) THEN 1
+                             ELSE login_failures.failures + 1
+        END,
+        last_failed_at = EXCLUDED.last_failed_at
+RETURNING *;
+
+-- name: SetLoginBackoff :one
+UPDATE login_failures
+SET locked_until = last_failed_at + make_interval(secs => sqlc.arg(backoff_seconds)::float8)
+WHERE key_type = $1
+  AND key = $2
+RETURNING *;
+
+-- name: GetLoginFailure :one
+SELECT *
+FROM login_failures
+WHERE key_type = $1
+  AND key = $2
+LIMIT 1;
+
+-- name: ListLoginLocks :many
+SELECT *
+FROM login_failures
+WHERE ((key_type = 'username' AND key = sqlc.arg(username)) OR (key_type = 'ip' AND key = sqlc.arg(client_ip)))
+  AND locked_until > now()
+ORDER BY locked_until DESC;
+
+-- name: DeleteLoginFailure :exec
+DELETE
+FROM login_failures
+WHERE key_type = $1
+  AND key = $2;
\ No newline at end of file
@@ -0,0 +1,110 @@
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (id,
+                           hashed_secret,
+                           name,
+                           owner,
+                           redirect_uris,
+                           scopes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetOAuthClient :one
+SELECT *
+FROM oauth_clients
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListOAuthClients :many
+SELECT *
+FROM oauth_clients
+WHERE owner = $1
+ORDER BY created_at
+LIMIT $2 OFFSET $3;
+
+-- name: UpsertOAuthConsent :one
+INSERT INTO oauth_consents (username, client_id, scopes)
+VALUES ($1, $2, $3)
+ON CONFLICT (username, client_id) DO UPDATE
+    SET scopes     = ARRAY(SELECT DISTINCT unnest(oauth_consents.scopes || EXCLUDED.scopes) ORDER BY 1),
+        updated_at = now()
+RETURNING *;
+
+-- name: GetOAuthConsent :one
+SELECT *
+FROM oauth_consents
+WHERE username = $1
+  AND client_id = $2
+LIMIT 1;
+
+-- name: ListOAuthConsents :many
+SELECT *
+FROM oauth_consents
+WHERE username = $1
+ORDER BY created_at
+LIMIT $2 OFFSET $3;
+
+-- name: DeleteOAuthConsent :one
+DELETE
+FROM oauth_consents
+WHERE username = $1
+  AND client_id = $2
+RETURNING *;
+
+-- name: CreateOAuthAuthorizationCode :one
+INSERT INTO oauth_authorization_codes (hashed_code,
+                                       client_id,
+                                       username,
+                                       redirect_uri,
+                                       scopes,
+                                       code_challenge,
+                                       expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetOAuthAuthorizationCode :one
+SELECT *
+FROM oauth_authorization_codes
+WHERE hashed_code = $1
+LIMIT 1;
+
+-- name: UseOAuthAuthorizationCode :one
+UPDATE oauth_authorization_codes
+SET used_at = now()
+WHERE id = $1
+  AND used_at IS NULL
+RETURNING *;
+
+-- name: CreateOAuthRefreshToken :one
+INSERT INTO oauth_refresh_tokens (hashed_token,
+                                  client_id,
+                                  username,
+                                  scopes,
+                                  expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetOAuthRefreshToken :one
+SELECT *
+FROM oauth_refresh_tokens
+WHERE hashed_token = $1
+LIMIT 1;
+
+-- name: GetOAuthRefreshTokenForUpdate :one
+SELECT *
+FROM oauth_refresh_tokens
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: RevokeOAuthRefreshToken :one
+UPDATE oauth_refresh_tokens
+SET revoked_at = now()
+WHERE id = $1
+  AND revoked_at IS NULL
+RETURNING *;
+
+-- name: RevokeOAuthRefreshTokens :execrows
+UPDATE oauth_refresh_tokens
+SET revoked_at = now()
+WHERE username = $1
+  AND client_id = $2
+  AND revoked_at IS NULL;
\ No newline at end of file
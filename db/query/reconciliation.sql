@@ -0,0 +1,46 @@
+-- name: ListAccountBalanceDrifts :many
+SELECT a.id,
+       a.owner,
+       a.currency,
+       a.balance,
+       COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts AS a
+         LEFT JOIN entries AS e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListUnbalancedTransfers :many
+SELECT t.id,
+       t.from_account_id,
+       t.to_account_id,
+       t.amount,
+       COUNT(e.id) AS entries_count
+FROM transfers AS t
+         LEFT JOIN entries AS e ON e.transfer_id = t.id
+GROUP BY t.id
+HAVING COUNT(e.id) <> CASE WHEN t.to_amount IS NULL THEN 2 ELSE 4 END
+    OR COUNT(e.id) FILTER (WHERE e.account_id = t.from_account_id AND e.amount = -t.amount) <> 1
+    OR COUNT(e.id) FILTER (WHERE e.account_id = t.to_account_id AND e.amount = COALESCE(t.to_amount, t.amount)) <> 1
+ORDER BY t.id;
+
+-- name: ListHeldAmountDrifts :many
+SELECT a.id,
+       a.owner,
+       a.currency,
+       a.held_amount,
+       COALESCE(SUM(h.amount), 0)::bigint AS holds_total
+FROM accounts AS a
+         LEFT JOIN holds AS h ON h.account_id = a.id AND h.status = 'authorized'
+GROUP BY a.id
+HAVING a.held_amount <> COALESCE(SUM(h.amount), 0)
+ORDER BY a.id;
+
+-- name: ListCurrencyTotals :many
+SELECT a.currency,
+       COUNT(e.id)                        AS entries_count,
+       COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts AS a
+         LEFT JOIN entries AS e ON e.account_id = a.id
+GROUP BY a.currency
+ORDER BY a.currency;
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- name: RevokeToken :exec
+INSERT INTO revoked_tokens (id, username, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: ListRevokedTokens :many
+SELECT *
+FROM revoked_tokens
+WHERE expires_at > now();
+
+-- name: DeleteExpiredRevokedTokens :execrows
+DELETE
+FROM revoked_tokens
+WHERE expires_at <= now();
+
+-- name: RevokeUserTokens :one
+INSERT INTO user_token_revocations (username, issued_before)
+VALUES ($1, $2)
+ON CONFLICT (username) DO UPDATE
+    SET issued_before = GREATEST(user_token_revocations.issued_before, EXCLUDED.issued_before)
+RETURNING *;
+
+-- name: ListUserTokenRevocations :many
+SELECT *
+FROM user_token_revocations
+WHERE issued_before > sqlc.arg(issued_after);
\ No newline at end of file
@@ -0,0 +1,74 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers
+    (owner, from_account_id, to_account_id, amount, recurrence, start_at, next_run_at, api_key_id)
+VALUES (sqlc.arg(owner), sqlc.arg(from_account_id), sqlc.arg(to_account_id), sqlc.arg(amount),
+        sqlc.arg(recurrence), sqlc.arg(start_at), sqlc.arg(start_at), sqlc.narg(api_key_id))
+RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT *
+FROM scheduled_transfers
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT *
+FROM scheduled_transfers
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: ListScheduledTransfers :many
+SELECT *
+FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET amount        = COALESCE(sqlc.narg('amount'), amount),
+    recurrence    = COALESCE(sqlc.narg('recurrence'), recurrence),
+    next_run_at   = COALESCE(sqlc.narg('next_run_at'), next_run_at),
+    status        = COALESCE(sqlc.narg('status'), status),
+    failure_count = CASE WHEN sqlc.narg('status')::varchar = 'active' THEN 0 ELSE failure_count END
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: DeleteScheduledTransfer :exec
+DELETE
+FROM scheduled_transfers
+WHERE id = $1;
+
+-- name: ClaimDueScheduledTransfers :many
+UPDATE scheduled_transfers
+SET claimed_until = sqlc.arg(claimed_until)::timestamptz
+WHERE id IN (SELECT id
+             FROM scheduled_transfers
+             WHERE status = 'active'
+               AND next_run_at <= now()
+               AND (claimed_until IS NULL OR claimed_until <= now())
+             ORDER BY next_run_at
+             LIMIT sqlc.arg(max_count) FOR UPDATE SKIP LOCKED)
+RETURNING *;
+
+-- name: FinishScheduledTransferRun :one
+UPDATE scheduled_transfers
+SET next_run_at   = sqlc.arg(next_run_at),
+    status        = sqlc.arg(status),
+    failure_count = sqlc.arg(failure_count),
+    claimed_until = NULL
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs
+    (scheduled_transfer_id, scheduled_for, transfer_id, succeeded, error_message)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT *
+FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
\ No newline at end of file
@@ -5,12 +5,59 @@ INSERT INTO sessions (id,
                       user_agent,
                       client_ip,
                       is_blocked,
-                      expires_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+                      expires_at,
+                      family_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $1)
+RETURNING *;
+
+-- name: CreateRotatedSession :one
+INSERT INTO sessions (id,
+                      username,
+                      refresh_token,
+                      user_agent,
+                      client_ip,
+                      expires_at,
+                      family_id,
+                      parent_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: GetSession :one
 SELECT *
 FROM sessions
 WHERE id = $1
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: GetSessionForUpdate :one
+SELECT *
+FROM sessions
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: ListActiveSessions :many
+SELECT *
+FROM sessions
+WHERE username = $1
+  AND is_blocked = false
+  AND rotated_at IS NULL
+  AND expires_at > now()
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: BlockSession :one
+UPDATE sessions
+SET is_blocked = true
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkSessionRotated :one
+UPDATE sessions
+SET rotated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: BlockSessionFamily :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1
+  AND is_blocked = false;
\ No newline at end of file
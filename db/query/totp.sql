@@ -0,0 +1,77 @@
+-- name: CreateUserTOTP :one
+INSERT INTO user_totp (username, secret)
+VALUES ($1, $2)
+ON CONFLICT (username) DO UPDATE
+    SET secret         = EXCLUDED.secret,
+        last_used_step = 0,
+        created_at     = now()
+WHERE user_totp.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetUserTOTP :one
+SELECT *
+FROM user_totp
+WHERE username = $1
+LIMIT 1;
+
+-- name: ConfirmUserTOTP :one
+UPDATE user_totp
+SET confirmed_at = now()
+WHERE username = $1
+  AND confirmed_at IS NULL
+RETURNING *;
+
+-- name: UseTOTPStep :one
+UPDATE user_totp
+SET last_used_step = sqlc.arg(step)
+WHERE username = sqlc.arg(username)
+  AND last_used_step < sqlc.arg(step)
+RETURNING *;
+
+-- name: CreateRecoveryCode :one
+INSERT INTO totp_recovery_codes (username, hashed_code)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE
+FROM totp_recovery_codes
+WHERE username = $1;
+
+-- name: UseRecoveryCode :one
+UPDATE totp_recovery_codes
+SET used_at = now()
+WHERE username = $1
+  AND hashed_code = $2
+  AND used_at IS NULL
+RETURNING *;
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT count(*)
+FROM totp_recovery_codes
+WHERE username = $1
+  AND used_at IS NULL;
+
+-- name: CreateMFAChallenge :one
+INSERT INTO mfa_challenges (id, hashed_token, username, client_ip, user_agent, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetMFAChallengeByToken :one
+SELECT *
+FROM mfa_challenges
+WHERE hashed_token = $1
+LIMIT 1;
+
+-- name: IncrementMFAChallengeAttempts :one
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: UseMFAChallenge :one
+UPDATE mfa_challenges
+SET used_at = now()
+WHERE id = $1
+  AND used_at IS NULL
+RETURNING *;
\ No newline at end of file
@@ -4,12 +4,36 @@ INSERT INTO transfers
 VALUES ($1, $2, $3)
 RETURNING *;
 
+-- name: CreateFXTransfer :one
+INSERT INTO transfers
+    (from_account_id, to_account_id, amount, to_amount, fx_quote_id, fx_rate, fx_spread)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: CreateReversalTransfer :one
+INSERT INTO transfers
+    (from_account_id, to_account_id, amount, reversed_transfer_id)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
 -- name: GetTransfer :one
 SELECT *
 FROM transfers
 WHERE id = $1
 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT *
+FROM transfers
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: AddTransferRefundedAmount :one
+UPDATE transfers
+SET refunded_amount = refunded_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: ListTransfers :many
 SELECT *
 FROM transfers
@@ -10,11 +10,42 @@ FROM users
 WHERE username = $1
 LIMIT 1;
 
+-- name: GetUserForUpdate :one
+SELECT *
+FROM users
+WHERE username = $1
+LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: GetUserByEmail :one
+SELECT *
+FROM users
+WHERE email = $1
+LIMIT 1;
+
 -- name: UpdateUser :one
 UPDATE users
 SET hashed_password     = COALESCE(sqlc.narg('hashed_password'), hashed_password),
     password_changed_at = COALESCE(sqlc.narg('password_changed_at'), password_changed_at),
     full_name           = COALESCE(sqlc.narg('full_name'), full_name),
+    -- a new address has to be verified again
+    is_email_verified   = is_email_verified AND COALESCE(sqlc.narg('email'), email) = email,
     email               = COALESCE(sqlc.narg('email'), email)
 WHERE username = sqlc.arg('username')
 RETURNING *;
+
+-- name: VerifyUserEmail :one
+UPDATE users
+SET is_email_verified = true
+WHERE username = $1
+  AND email = $2
+RETURNING *;
+
+
+-- name: SearchUsers :many
+SELECT *
+FROM users
+WHERE username ILIKE '%' || sqlc.arg(query)::text || '%'
+   OR email ILIKE '%' || sqlc.arg(query)::text || '%'
+   OR full_name ILIKE '%' || sqlc.arg(query)::text || '%'
+ORDER BY username
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
\ No newline at end of file
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"strconv"
+)
+
+// CreateAccountTx creates an account and records it in the audit log
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var result Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionCreateAccount,
+			TargetType: AuditTargetAccount,
+			TargetID:   strconv.FormatInt(result.ID, 10),
+			After:      result,
+		})
+	})
+
+	return result, err
+}
+
+// DeleteAccountTx deletes an account and records its last state in the audit log.
+// It returns sql.ErrNoRows if the account does not exist, accounts with a history
+// cannot be deleted and return a foreign key violation.
+func (store *SQLStore) DeleteAccountTx(ctx context.Context, id int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteAccount(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionDeleteAccount,
+			TargetType: AuditTargetAccount,
+			TargetID:   strconv.FormatInt(id, 10),
+			Before:     account,
+		})
+	})
+}
@@ -69,6 +69,47 @@ func TestUpdateAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
 }
 
+// TestUpdateAccountBelowOverdraftLimit tests that the database rejects balances below the overdraft limit
+func TestUpdateAccountBelowOverdraftLimit(t *testing.T) {
+	account1 := createRandomAccount(t)
+	require.Zero(t, account1.OverdraftLimit)
+
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account1.ID,
+		Balance: -1,
+	})
+	require.Error(t, err)
+	require.True(t, IsInsufficientFunds(err))
+
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account1.ID,
+		Amount: -account1.Balance - 1,
+	})
+	require.Error(t, err)
+	require.True(t, IsInsufficientFunds(err))
+}
+
+// TestUpdateAccountOverdraftLimit tests the update account overdraft limit function
+func TestUpdateAccountOverdraftLimit(t *testing.T) {
+	account1 := createRandomAccount(t)
+	params := UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: utils.RandomAmount(),
+	}
+
+	account2, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, account2.ID)
+	require.Equal(t, params.OverdraftLimit, account2.OverdraftLimit)
+
+	account2, err = testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account1.ID,
+		Balance: -params.OverdraftLimit,
+	})
+	require.NoError(t, err)
+	require.Equal(t, -params.OverdraftLimit, account2.Balance)
+}
+
 // TestDeleteAccount tests the delete account function
 func TestDeleteAccount(t *testing.T) {
 	account1 := createRandomAccount(t)
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strconv"
+)
+
+// all actions recorded in the audit trail of the admins
+const (
+	AdminActionSearchUsers          = "search_users"
+	AdminActionFreezeAccount        = "freeze_account"
+	AdminActionUnfreezeAccount      = "unfreeze_account"
+	AdminActionBlockSession         = "block_session"
+	AdminActionListAccountTransfers = "list_account_transfers"
+	AdminActionGetUserLockout       = "get_user_lockout"
+	AdminActionUnlockUser           = "unlock_user"
+	AdminActionCreateAPIKey         = "create_api_key"
+	AdminActionRevokeAPIKey         = "revoke_api_key"
+	AdminActionListAuditEvents      = "list_audit_events"
+	AdminActionVerifyAuditChain     = "verify_audit_chain"
+)
+
+// all types of the targets of admin actions
+const (
+	AdminTargetUser     = "user"
+	AdminTargetAccount  = "account"
+	AdminTargetSession  = "session"
+	AdminTargetAPIKey   = "api_key"
+	AdminTargetAuditLog = "audit_log"
+)
+
+// SetAccountFrozenTxParams contains the parameters of the set account frozen transaction.
+type SetAccountFrozenTxParams struct {
+	AdminUsername string `json:"admin_username"`
+	AccountID     int64  `json:"account_id"`
+	Frozen        bool   `json:"frozen"`
+}
+
+// SetAccountFrozenTx freezes or unfreezes an account
+// and records the action in the audit trail of the admins
+func (store *SQLStore) SetAccountFrozenTx(ctx context.Context, arg SetAccountFrozenTxParams) (Account, error) {
+	var result Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.SetAccountFrozen(ctx, SetAccountFrozenParams{
+			ID:       arg.AccountID,
+			IsFrozen: arg.Frozen,
+		})
+		if err != nil {
+			return err
+		}
+
+		action, auditAction := AdminActionUnfreezeAccount, AuditActionUnfreezeAccount
+		if arg.Frozen {
+			action, auditAction = AdminActionFreezeAccount, AuditActionFreezeAccount
+		}
+
+		_, err = q.CreateAdminAction(ctx, CreateAdminActionParams{
+			AdminUsername: arg.AdminUsername,
+			Action:        action,
+			TargetType:    AdminTargetAccount,
+			TargetID:      strconv.FormatInt(arg.AccountID, 10),
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     auditAction,
+			TargetType: AuditTargetAccount,
+			TargetID:   strconv.FormatInt(arg.AccountID, 10),
+			Before:     before,
+			After:      result,
+		})
+	})
+
+	return result, err
+}
+
+// AdminBlockSessionTxParams contains the parameters of the admin block session transaction.
+type AdminBlockSessionTxParams struct {
+	AdminUsername string    `json:"admin_username"`
+	SessionID     uuid.UUID `json:"session_id"`
+}
+
+// AdminBlockSessionTx blocks a session of any user
+// and records the action in the audit trail of the admins
+func (store *SQLStore) AdminBlockSessionTx(ctx context.Context, arg AdminBlockSessionTxParams) (Session, error) {
+	var result Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.CreateAdminAction(ctx, CreateAdminActionParams{
+			AdminUsername: arg.AdminUsername,
+			Action:        AdminActionBlockSession,
+			TargetType:    AdminTargetSession,
+			TargetID:      arg.SessionID.String(),
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = auditedBlockSession(ctx, q, arg.SessionID)
+		return err
+	})
+
+	return result, err
+}
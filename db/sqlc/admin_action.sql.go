@@ -0,0 +1,94 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.18.0
+// source: admin_action.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createAdminAction = `-- name: CreateAdminAction :one
+INSERT INTO admin_actions
+    (admin_username, action, target_type, target_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id, admin_username, action, target_type, target_id, created_at
+`
+
+type CreateAdminActionParams struct {
+	AdminUsername string `json:"admin_username"`
+	Action        string `json:"action"`
+	TargetType    string `json:"target_type"`
+	TargetID      string `json:"target_id"`
+}
+
+func (q *Queries) CreateAdminAction(ctx context.Context, arg CreateAdminActionParams) (AdminAction, error) {
+	row := q.db.QueryRowContext(ctx, createAdminAction,
+		arg.AdminUsername,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+	)
+	var i AdminAction
+	err := row.Scan(
+		&i.ID,
+		&i.AdminUsername,
+		&i.Action,
+		&i.TargetType,
+		&i.TargetID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAdminActionsByTarget = `-- name: ListAdminActionsByTarget :many
+SELECT id, admin_username, action, target_type, target_id, created_at
+FROM admin_actions
+WHERE target_type = $1
+  AND target_id = $2
+ORDER BY id DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListAdminActionsByTargetParams struct {
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	Limit      int32  `json:"limit"`
+	Offset     int32  `json:"offset"`
+}
+
+func (q *Queries) ListAdminActionsByTarget(ctx context.Context, arg ListAdminActionsByTargetParams) ([]AdminAction, error) {
+	rows, err := q.db.QueryContext(ctx, listAdminActionsByTarget,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AdminAction{}
+	for rows.Next() {
+		var i AdminAction
+		if err := rows.Scan(
+			&i.ID,
+			&i.AdminUsername,
+			&i.Action,
+			&i.TargetType,
+			&i.TargetID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSetAccountFrozenTx(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	account1 := createAccountInCurrency(t, utils.USD, 100)
+	account2 := createAccountInCurrency(t, utils.USD, 100)
+
+	account, err := store.SetAccountFrozenTx(context.Background(), SetAccountFrozenTxParams{
+		AdminUsername: admin.Username,
+		AccountID:     account1.ID,
+		Frozen:        true,
+	})
+	require.NoError(t, err)
+	require.True(t, account.IsFrozen)
+
+	actions, err := testQueries.ListAdminActionsByTarget(context.Background(), ListAdminActionsByTargetParams{
+		TargetType: AdminTargetAccount,
+		TargetID:   strconv.FormatInt(account1.ID, 10),
+		Limit:      10,
+		Offset:     0,
+	})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	require.Equal(t, admin.Username, actions[0].AdminUsername)
+	require.Equal(t, AdminActionFreezeAccount, actions[0].Action)
+
+	// no money can leave a frozen account
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.AuthorizeTx(context.Background(), AuthorizeTxParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      10,
+		Duration:    time.Hour,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	// but it can still receive money
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	account, err = store.SetAccountFrozenTx(context.Background(), SetAccountFrozenTxParams{
+		AdminUsername: admin.Username,
+		AccountID:     account1.ID,
+		Frozen:        false,
+	})
+	require.NoError(t, err)
+	require.False(t, account.IsFrozen)
+	require.Equal(t, int64(110), account.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+}
+
+func TestAdminBlockSessionTx(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username, time.Now().Add(time.Hour))
+
+	blockedSession, err := store.AdminBlockSessionTx(context.Background(), AdminBlockSessionTxParams{
+		AdminUsername: admin.Username,
+		SessionID:     session.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, blockedSession.IsBlocked)
+
+	actions, err := testQueries.ListAdminActionsByTarget(context.Background(), ListAdminActionsByTargetParams{
+		TargetType: AdminTargetSession,
+		TargetID:   session.ID.String(),
+		Limit:      10,
+		Offset:     0,
+	})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	require.Equal(t, AdminActionBlockSession, actions[0].Action)
+}
+
+func TestSearchUsers(t *testing.T) {
+	user := createRandomUser(t)
+
+	users, err := testQueries.SearchUsers(context.Background(), SearchUsersParams{
+		Query:  user.Email,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, user.Username, users[0].Username)
+	require.Equal(t, utils.CustomerRole, users[0].Role)
+}
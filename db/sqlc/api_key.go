@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"strconv"
+)
+
+// CreateAPIKeyTx creates an API key and records it in the audit log
+func (store *SQLStore) CreateAPIKeyTx(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error) {
+	var result APIKey
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result, err = q.CreateAPIKey(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionCreateAPIKey,
+			TargetType: AuditTargetAPIKey,
+			TargetID:   strconv.FormatInt(result.ID, 10),
+			After:      result,
+		})
+	})
+
+	return result, err
+}
+
+// AdminCreateAPIKeyTx creates an API key for any user
+// and records the action in the audit trail of the admins
+func (store *SQLStore) AdminCreateAPIKeyTx(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error) {
+	var result APIKey
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result, err = q.CreateAPIKey(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateAdminAction(ctx, CreateAdminActionParams{
+			AdminUsername: arg.CreatedBy,
+			Action:        AdminActionCreateAPIKey,
+			TargetType:    AdminTargetAPIKey,
+			TargetID:      strconv.FormatInt(result.ID, 10),
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionCreateAPIKey,
+			TargetType: AuditTargetAPIKey,
+			TargetID:   strconv.FormatInt(result.ID, 10),
+			After:      result,
+		})
+	})
+
+	return result, err
+}
+
+// RevokeAPIKeyTx revokes an API key and records it in the audit log.
+// It returns sql.ErrNoRows if the key is already revoked.
+func (store *SQLStore) RevokeAPIKeyTx(ctx context.Context, id int64) (APIKey, error) {
+	var result APIKey
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result, err = auditedRevokeAPIKey(ctx, q, id)
+		return err
+	})
+
+	return result, err
+}
+
+// AdminRevokeAPIKeyTxParams contains the parameters of the admin revoke API key transaction.
+type AdminRevokeAPIKeyTxParams struct {
+	AdminUsername string `json:"admin_username"`
+	APIKeyID      int64  `json:"api_key_id"`
+}
+
+// AdminRevokeAPIKeyTx revokes an API key of any user
+// and records the action in the audit trail of the admins
+func (store *SQLStore) AdminRevokeAPIKeyTx(ctx context.Context, arg AdminRevokeAPIKeyTxParams) (APIKey, error) {
+	var result APIKey
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.CreateAdminAction(ctx, CreateAdminActionParams{
+			AdminUsername: arg.AdminUsername,
+			Action:        AdminActionRevokeAPIKey,
+			TargetType:    AdminTargetAPIKey,
+			TargetID:      strconv.FormatInt(arg.APIKeyID, 10),
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = auditedRevokeAPIKey(ctx, q, arg.APIKeyID)
+		return err
+	})
+
+	return result, err
+}
+
+// auditedRevokeAPIKey revokes an API key using the given queries and records it in the audit log
+func auditedRevokeAPIKey(ctx context.Context, q *Queries, id int64) (APIKey, error) {
+	before, err := q.GetAPIKey(ctx, id)
+	if err != nil {
+		return before, err
+	}
+
+	result, err := q.RevokeAPIKey(ctx, id)
+	if err != nil {
+		return result, err
+	}
+
+	err = recordAuditEvent(ctx, q, auditChange{
+		Action:     AuditActionRevokeAPIKey,
+		TargetType: AuditTargetAPIKey,
+		TargetID:   strconv.FormatInt(id, 10),
+		Before:     before,
+		After:      result,
+	})
+	return result, err
+}
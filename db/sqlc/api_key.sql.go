@@ -0,0 +1,240 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.18.0
+// source: api_key.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (prefix,
+                      hashed_secret,
+                      owner,
+                      name,
+                      scopes,
+                      account_ids,
+                      created_by,
+                      expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, prefix, hashed_secret, owner, name, scopes, account_ids, created_by, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+`
+
+type CreateAPIKeyParams struct {
+	Prefix       string     `json:"prefix"`
+	HashedSecret string     `json:"hashed_secret"`
+	Owner        string     `json:"owner"`
+	Name         string     `json:"name"`
+	Scopes       []string   `json:"scopes"`
+	AccountIds   []int64    `json:"account_ids"`
+	CreatedBy    string     `json:"created_by"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error) {
+	row := q.db.QueryRowContext(ctx, createAPIKey,
+		arg.Prefix,
+		arg.HashedSecret,
+		arg.Owner,
+		arg.Name,
+		pq.Array(arg.Scopes),
+		pq.Array(arg.AccountIds),
+		arg.CreatedBy,
+		arg.ExpiresAt,
+	)
+	var i APIKey
+	err := row.Scan(
+		&i.ID,
+		&i.Prefix,
+		&i.HashedSecret,
+		&i.Owner,
+		&i.Name,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.AccountIds),
+		&i.CreatedBy,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAPIKey = `-- name: GetAPIKey :one
+SELECT id, prefix, hashed_secret, owner, name, scopes, account_ids, created_by, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+FROM api_keys
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetAPIKey(ctx context.Context, id int64) (APIKey, error) {
+	row := q.db.QueryRowContext(ctx, getAPIKey, id)
+	var i APIKey
+	err := row.Scan(
+		&i.ID,
+		&i.Prefix,
+		&i.HashedSecret,
+		&i.Owner,
+		&i.Name,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.AccountIds),
+		&i.CreatedBy,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAPIKeyByPrefix = `-- name: GetAPIKeyByPrefix :one
+SELECT api_keys.id, api_keys.prefix, api_keys.hashed_secret, api_keys.owner, api_keys.name, api_keys.scopes, api_keys.account_ids, api_keys.created_by, api_keys.expires_at, api_keys.last_used_at, api_keys.last_used_ip, api_keys.revoked_at, api_keys.created_at, users.role AS owner_role
+FROM api_keys
+         JOIN users ON users.username = api_keys.owner
+WHERE api_keys.prefix = $1
+LIMIT 1
+`
+
+type GetAPIKeyByPrefixRow struct {
+	ID           int64      `json:"id"`
+	Prefix       string     `json:"prefix"`
+	HashedSecret string     `json:"hashed_secret"`
+	Owner        string     `json:"owner"`
+	Name         string     `json:"name"`
+	Scopes       []string   `json:"scopes"`
+	AccountIds   []int64    `json:"account_ids"`
+	CreatedBy    string     `json:"created_by"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	LastUsedIp   string     `json:"last_used_ip"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	OwnerRole    string     `json:"owner_role"`
+}
+
+func (q *Queries) GetAPIKeyByPrefix(ctx context.Context, prefix string) (GetAPIKeyByPrefixRow, error) {
+	row := q.db.QueryRowContext(ctx, getAPIKeyByPrefix, prefix)
+	var i GetAPIKeyByPrefixRow
+	err := row.Scan(
+		&i.ID,
+		&i.Prefix,
+		&i.HashedSecret,
+		&i.Owner,
+		&i.Name,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.AccountIds),
+		&i.CreatedBy,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RevokedAt,
+		&i.CreatedAt,
+		&i.OwnerRole,
+	)
+	return i, err
+}
+
+const listAPIKeys = `-- name: ListAPIKeys :many
+SELECT id, prefix, hashed_secret, owner, name, scopes, account_ids, created_by, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+FROM api_keys
+WHERE owner = $1
+ORDER BY id
+LIMIT $2 OFFSET $3
+`
+
+type ListAPIKeysParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListAPIKeys(ctx context.Context, arg ListAPIKeysParams) ([]APIKey, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIKeys, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []APIKey{}
+	for rows.Next() {
+		var i APIKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Prefix,
+			&i.HashedSecret,
+			&i.Owner,
+			&i.Name,
+			pq.Array(&i.Scopes),
+			pq.Array(&i.AccountIds),
+			&i.CreatedBy,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.LastUsedIp,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = $1
+  AND revoked_at IS NULL
+RETURNING id, prefix, hashed_secret, owner, name, scopes, account_ids, created_by, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+`
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, id int64) (APIKey, error) {
+	row := q.db.QueryRowContext(ctx, revokeAPIKey, id)
+	var i APIKey
+	err := row.Scan(
+		&i.ID,
+		&i.Prefix,
+		&i.HashedSecret,
+		&i.Owner,
+		&i.Name,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.AccountIds),
+		&i.CreatedBy,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const touchAPIKey = `-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = now(),
+    last_used_ip = $1
+WHERE id = $2
+  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+`
+
+type TouchAPIKeyParams struct {
+	ClientIp string `json:"client_ip"`
+	ID       int64  `json:"id"`
+}
+
+func (q *Queries) TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error {
+	_, err := q.db.ExecContext(ctx, touchAPIKey, arg.ClientIp, arg.ID)
+	return err
+}
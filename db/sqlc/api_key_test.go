@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func createRandomAPIKey(t *testing.T, owner string) APIKey {
+	_, prefix, secret, err := utils.GenerateAPIKey()
+	require.NoError(t, err)
+
+	expiresAt := time.Now().Add(time.Hour).UTC()
+	arg := CreateAPIKeyParams{
+		Prefix:       prefix,
+		HashedSecret: utils.HashToken(secret),
+		Owner:        owner,
+		Name:         utils.RandomString(8),
+		Scopes:       []string{"accounts:read", "transfers:write"},
+		AccountIds:   []int64{},
+		CreatedBy:    owner,
+		ExpiresAt:    &expiresAt,
+	}
+
+	apiKey, err := testQueries.CreateAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, apiKey.ID)
+	require.Equal(t, arg.Prefix, apiKey.Prefix)
+	require.Equal(t, arg.HashedSecret, apiKey.HashedSecret)
+	require.Equal(t, arg.Owner, apiKey.Owner)
+	require.Equal(t, arg.Scopes, apiKey.Scopes)
+	require.Empty(t, apiKey.AccountIds)
+	require.WithinDuration(t, expiresAt, *apiKey.ExpiresAt, time.Second)
+	require.Nil(t, apiKey.LastUsedAt)
+	require.Nil(t, apiKey.RevokedAt)
+
+	return apiKey
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomAPIKey(t, user.Username)
+}
+
+func TestGetAPIKeyByPrefix(t *testing.T) {
+	user := createRandomUser(t)
+	apiKey := createRandomAPIKey(t, user.Username)
+
+	row, err := testQueries.GetAPIKeyByPrefix(context.Background(), apiKey.Prefix)
+	require.NoError(t, err)
+	require.Equal(t, apiKey.ID, row.ID)
+	require.Equal(t, apiKey.HashedSecret, row.HashedSecret)
+	require.Equal(t, user.Role, row.OwnerRole)
+
+	_, err = testQueries.GetAPIKeyByPrefix(context.Background(), "unknown")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTouchAPIKey(t *testing.T) {
+	user := createRandomUser(t)
+	apiKey := createRandomAPIKey(t, user.Username)
+
+	err := testQueries.TouchAPIKey(context.Background(), TouchAPIKeyParams{
+		ID:       apiKey.ID,
+		ClientIp: "127.0.0.1",
+	})
+	require.NoError(t, err)
+
+	apiKey, err = testQueries.GetAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	require.NotNil(t, apiKey.LastUsedAt)
+	require.Equal(t, "127.0.0.1", apiKey.LastUsedIp)
+
+	// the key is not written again right after its last use
+	err = testQueries.TouchAPIKey(context.Background(), TouchAPIKeyParams{
+		ID:       apiKey.ID,
+		ClientIp: "127.0.0.2",
+	})
+	require.NoError(t, err)
+
+	touched, err := testQueries.GetAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1", touched.LastUsedIp)
+}
+
+func TestListAPIKeys(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomAPIKey(t, user.Username)
+	}
+	createRandomAPIKey(t, createRandomUser(t).Username)
+
+	apiKeys, err := testQueries.ListAPIKeys(context.Background(), ListAPIKeysParams{
+		Owner:  user.Username,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, apiKeys, 3)
+	for _, apiKey := range apiKeys {
+		require.Equal(t, user.Username, apiKey.Owner)
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	user := createRandomUser(t)
+	apiKey := createRandomAPIKey(t, user.Username)
+
+	revoked, err := testQueries.RevokeAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+
+	// a revoked key cannot be revoked again
+	_, err = testQueries.RevokeAPIKey(context.Background(), apiKey.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestAdminAPIKeyTx(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	user := createRandomUser(t)
+
+	_, prefix, secret, err := utils.GenerateAPIKey()
+	require.NoError(t, err)
+
+	apiKey, err := store.AdminCreateAPIKeyTx(context.Background(), CreateAPIKeyParams{
+		Prefix:       prefix,
+		HashedSecret: utils.HashToken(secret),
+		Owner:        user.Username,
+		Name:         utils.RandomString(8),
+		Scopes:       []string{"accounts:read"},
+		AccountIds:   []int64{},
+		CreatedBy:    admin.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, apiKey.Owner)
+	require.Equal(t, admin.Username, apiKey.CreatedBy)
+
+	apiKey, err = store.AdminRevokeAPIKeyTx(context.Background(), AdminRevokeAPIKeyTxParams{
+		AdminUsername: admin.Username,
+		APIKeyID:      apiKey.ID,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, apiKey.RevokedAt)
+
+	actions, err := testQueries.ListAdminActionsByTarget(context.Background(), ListAdminActionsByTargetParams{
+		TargetType: AdminTargetAPIKey,
+		TargetID:   strconv.FormatInt(apiKey.ID, 10),
+		Limit:      5,
+		Offset:     0,
+	})
+	require.NoError(t, err)
+	require.Len(t, actions, 2)
+}
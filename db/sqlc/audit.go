@@ -0,0 +1,303 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// all actions recorded in the audit log
+const (
+	AuditActionCreateUser              = "create_user"
+	AuditActionUpdateUser              = "update_user"
+	AuditActionVerifyEmail             = "verify_email"
+	AuditActionResetPassword           = "reset_password"
+	AuditActionEnableTOTP              = "enable_totp"
+	AuditActionUnlockUser              = "unlock_user"
+	AuditActionCreateAccount           = "create_account"
+	AuditActionDeleteAccount           = "delete_account"
+	AuditActionFreezeAccount           = "freeze_account"
+	AuditActionUnfreezeAccount         = "unfreeze_account"
+	AuditActionCreateTransfer          = "create_transfer"
+	AuditActionDeposit                 = "deposit"
+	AuditActionWithdraw                = "withdraw"
+	AuditActionReverseTransfer         = "reverse_transfer"
+	AuditActionAuthorizeHold           = "authorize_hold"
+	AuditActionCaptureHold             = "capture_hold"
+	AuditActionVoidHold                = "void_hold"
+	AuditActionExpireHold              = "expire_hold"
+	AuditActionBlockSession            = "block_session"
+	AuditActionCreateAPIKey            = "create_api_key"
+	AuditActionRevokeAPIKey            = "revoke_api_key"
+	AuditActionCreateOAuthClient       = "create_oauth_client"
+	AuditActionGrantOAuthConsent       = "grant_oauth_consent"
+	AuditActionRevokeOAuthConsent      = "revoke_oauth_consent"
+	AuditActionCreateScheduledTransfer = "create_scheduled_transfer"
+	AuditActionUpdateScheduledTransfer = "update_scheduled_transfer"
+	AuditActionDeleteScheduledTransfer = "delete_scheduled_transfer"
+)
+
+// all types of the targets of audit events
+const (
+	AuditTargetUser              = "user"
+	AuditTargetAccount           = "account"
+	AuditTargetTransfer          = "transfer"
+	AuditTargetHold              = "hold"
+	AuditTargetSession           = "session"
+	AuditTargetAPIKey            = "api_key"
+	AuditTargetOAuthClient       = "oauth_client"
+	AuditTargetOAuthConsent      = "oauth_consent"
+	AuditTargetScheduledTransfer = "scheduled_transfer"
+)
+
+const (
+	// AuditActorSystem is the actor of changes made without a request, e.g. by background jobs
+	AuditActorSystem = "system"
+	// AuditActorAnonymous is the actor of changes requested without an access token,
+	// e.g. with a token sent by email
+	AuditActorAnonymous = "anonymous"
+)
+
+// sensitiveAuditFields are never written to the before and after states of audit events
+var sensitiveAuditFields = []string{"hashed_password", "hashed_secret", "refresh_token", "secret"}
+
+// AuditInfo describes who made a change and from where, it is recorded with every audit event
+type AuditInfo struct {
+	Actor     string
+	RequestID string
+	ClientIP  string
+	UserAgent string
+}
+
+type auditInfoKey struct{}
+
+// WithAuditInfo returns a copy of the context that carries the audit info of the request
+func WithAuditInfo(ctx context.Context, info AuditInfo) context.Context {
+	return context.WithValue(ctx, auditInfoKey{}, info)
+}
+
+// AuditInfoFromContext returns the audit info of the request.
+// Changes made outside of requests are recorded as made by the system.
+func AuditInfoFromContext(ctx context.Context) AuditInfo {
+	info, ok := ctx.Value(auditInfoKey{}).(AuditInfo)
+	if !ok {
+		return AuditInfo{Actor: AuditActorSystem}
+	}
+
+	if info.Actor == "" {
+		info.Actor = AuditActorAnonymous
+	}
+
+	return info
+}
+
+// auditChange is a change of a single target recorded in the audit log.
+// Before is nil for created targets and After is nil for deleted ones.
+type auditChange struct {
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     any
+	After      any
+}
+
+// recordAuditEvent appends the change to the audit log, chained to the previous event.
+// It must run in the transaction of the change and after all its other writes:
+// the lock it takes serializes the audit log and is held until the transaction ends.
+func recordAuditEvent(ctx context.Context, q *Queries, change auditChange) error {
+	before, err := auditState(change.Before)
+	if err != nil {
+		return fmt.Errorf("cannot encode the state before the change: %w", err)
+	}
+
+	after, err := auditState(change.After)
+	if err != nil {
+		return fmt.Errorf("cannot encode the state after the change: %w", err)
+	}
+
+	err = q.LockAuditLog(ctx)
+	if err != nil {
+		return err
+	}
+
+	prevHash, err := q.GetLastAuditEventHash(ctx)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	info := AuditInfoFromContext(ctx)
+	event := CreateAuditEventParams{
+		Actor:      info.Actor,
+		Action:     change.Action,
+		TargetType: change.TargetType,
+		TargetID:   change.TargetID,
+		Before:     before,
+		After:      after,
+		RequestID:  info.RequestID,
+		ClientIp:   info.ClientIP,
+		UserAgent:  info.UserAgent,
+		PrevHash:   prevHash,
+		// Postgres keeps microseconds, the hash must match the stored time
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	event.Hash, err = hashAuditEvent(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateAuditEvent(ctx, event)
+	return err
+}
+
+// recordTransferEvent appends a created transfer to the audit log
+func recordTransferEvent(ctx context.Context, q *Queries, action string, transfer Transfer) error {
+	return recordAuditEvent(ctx, q, auditChange{
+		Action:     action,
+		TargetType: AuditTargetTransfer,
+		TargetID:   strconv.FormatInt(transfer.ID, 10),
+		After:      transfer,
+	})
+}
+
+// recordHoldEvent records a change of the status of a hold, before is nil for authorized holds
+func recordHoldEvent(ctx context.Context, q *Queries, action string, before any, after Hold) error {
+	return recordAuditEvent(ctx, q, auditChange{
+		Action:     action,
+		TargetType: AuditTargetHold,
+		TargetID:   strconv.FormatInt(after.ID, 10),
+		Before:     before,
+		After:      after,
+	})
+}
+
+// auditState returns the JSON encoded state of a target without its sensitive fields
+func auditState(state any) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		// not an object, there is nothing to remove
+		return data, nil
+	}
+
+	for _, field := range sensitiveAuditFields {
+		delete(fields, field)
+	}
+
+	return json.Marshal(fields)
+}
+
+// hashAuditEvent returns the hex encoded sha256 hash of the event, which includes the hash of the previous one
+func hashAuditEvent(event CreateAuditEventParams) (string, error) {
+	data, err := json.Marshal(struct {
+		PrevHash   string `json:"prev_hash"`
+		Actor      string `json:"actor"`
+		Action     string `json:"action"`
+		TargetType string `json:"target_type"`
+		TargetID   string `json:"target_id"`
+		Before     string `json:"before"`
+		After      string `json:"after"`
+		RequestID  string `json:"request_id"`
+		ClientIP   string `json:"client_ip"`
+		UserAgent  string `json:"user_agent"`
+		CreatedAt  string `json:"created_at"`
+	}{
+		PrevHash:   event.PrevHash,
+		Actor:      event.Actor,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Before:     string(event.Before),
+		After:      string(event.After),
+		RequestID:  event.RequestID,
+		ClientIP:   event.ClientIp,
+		UserAgent:  event.UserAgent,
+		CreatedAt:  event.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AuditChainReport describes the integrity of the audit log
+type AuditChainReport struct {
+	CheckedAt time.Time `json:"checked_at"`
+	// Checked is the number of events that were verified
+	Checked int64 `json:"checked"`
+	// Valid is true when every event matches its hash and links to the event before it
+	Valid bool `json:"valid"`
+	// BrokenAtID is the ID of the first event that was changed, or whose predecessor was removed
+	BrokenAtID int64 `json:"broken_at_id,omitempty"`
+}
+
+// auditChainBatchSize is how many events are read at once when the chain is verified
+const auditChainBatchSize = 1000
+
+// VerifyAuditChain recomputes the hash of every audit event in order and checks
+// that each event links to the one before it
+func (store *SQLStore) VerifyAuditChain(ctx context.Context) (AuditChainReport, error) {
+	report := AuditChainReport{
+		CheckedAt: time.Now(),
+		Valid:     true,
+	}
+
+	var lastID int64
+	prevHash := ""
+	for {
+		events, err := store.ListAuditEventsAfter(ctx, ListAuditEventsAfterParams{
+			ID:    lastID,
+			Limit: auditChainBatchSize,
+		})
+		if err != nil {
+			return report, fmt.Errorf("cannot list audit events: %w", err)
+		}
+
+		for _, event := range events {
+			hash, err := hashAuditEvent(CreateAuditEventParams{
+				Actor:      event.Actor,
+				Action:     event.Action,
+				TargetType: event.TargetType,
+				TargetID:   event.TargetID,
+				Before:     event.Before,
+				After:      event.After,
+				RequestID:  event.RequestID,
+				ClientIp:   event.ClientIp,
+				UserAgent:  event.UserAgent,
+				PrevHash:   event.PrevHash,
+				CreatedAt:  event.CreatedAt,
+			})
+			if err != nil {
+				return report, err
+			}
+
+			report.Checked++
+			if event.PrevHash != prevHash || event.Hash != hash {
+				report.Valid = false
+				report.BrokenAtID = event.ID
+				return report, nil
+			}
+
+			prevHash = event.Hash
+			lastID = event.ID
+		}
+
+		if len(events) < auditChainBatchSize {
+			return report, nil
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCreateUserTxRecordsAuditEvent(t *testing.T) {
+	store := NewStore(testDB)
+
+	hashedPassword, err := utils.HashPassword(utils.RandomString(6))
+	require.NoError(t, err)
+
+	username := utils.RandomOwner()
+	info := AuditInfo{
+		Actor:     username,
+		RequestID: utils.RandomString(16),
+		ClientIP:  "127.0.0.1",
+		UserAgent: "test-agent",
+	}
+	ctx := WithAuditInfo(context.Background(), info)
+
+	user, err := store.CreateUserTx(ctx, CreateUserParams{
+		Username:       username,
+		HashedPassword: hashedPassword,
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		RequestID: sql.NullString{String: info.RequestID, Valid: true},
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	event := events[0]
+	require.Equal(t, info.Actor, event.Actor)
+	require.Equal(t, AuditActionCreateUser, event.Action)
+	require.Equal(t, AuditTargetUser, event.TargetType)
+	require.Equal(t, user.Username, event.TargetID)
+	require.Nil(t, event.Before)
+	require.Equal(t, info.ClientIP, event.ClientIp)
+	require.Equal(t, info.UserAgent, event.UserAgent)
+	require.Len(t, event.Hash, 64)
+
+	var after map[string]any
+	err = json.Unmarshal(event.After, &after)
+	require.NoError(t, err)
+	require.Equal(t, user.Email, after["email"])
+	require.NotContains(t, after, "hashed_password")
+}
+
+func TestUpdateUserTxRecordsAuditEvent(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	requestID := utils.RandomString(16)
+	ctx := WithAuditInfo(context.Background(), AuditInfo{Actor: user.Username, RequestID: requestID})
+
+	newEmail := utils.RandomEmail()
+	_, err := store.UpdateUserTx(ctx, UpdateUserParams{
+		Username: user.Username,
+		Email:    sql.NullString{String: newEmail, Valid: true},
+	})
+	require.NoError(t, err)
+
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		RequestID: sql.NullString{String: requestID, Valid: true},
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionUpdateUser, events[0].Action)
+
+	var before, after map[string]any
+	require.NoError(t, json.Unmarshal(events[0].Before, &before))
+	require.NoError(t, json.Unmarshal(events[0].After, &after))
+	require.Equal(t, user.Email, before["email"])
+	require.Equal(t, newEmail, after["email"])
+}
+
+func TestHoldTxsRecordAuditEvents(t *testing.T) {
+	store := NewStore(testDB)
+
+	holdEvents := func(hold Hold) []AuditEvent {
+		events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+			TargetType: sql.NullString{String: AuditTargetHold, Valid: true},
+			TargetID:   sql.NullString{String: strconv.FormatInt(hold.ID, 10), Valid: true},
+			Limit:      10,
+		})
+		require.NoError(t, err)
+		return events
+	}
+
+	voided, _, _ := createTestHold(t, store, 60, time.Hour)
+	_, err := store.VoidTx(context.Background(), voided.ID)
+	require.NoError(t, err)
+
+	expired, _, _ := createTestHold(t, store, 60, -time.Second)
+	for {
+		holds, err := store.ExpireHoldsTx(context.Background(), 100)
+		require.NoError(t, err)
+		if len(holds) == 0 {
+			break
+		}
+	}
+
+	testCases := []struct {
+		hold    Hold
+		actions []string
+	}{
+		{voided, []string{AuditActionAuthorizeHold, AuditActionVoidHold}},
+		{expired, []string{AuditActionAuthorizeHold, AuditActionExpireHold}},
+	}
+
+	for _, tc := range testCases {
+		events := holdEvents(tc.hold)
+		require.Len(t, events, len(tc.actions))
+
+		actions := make([]string, len(events))
+		for i, event := range events {
+			actions[i] = event.Action
+		}
+		require.ElementsMatch(t, tc.actions, actions)
+
+		for _, event := range events {
+			var after map[string]any
+			require.NoError(t, json.Unmarshal(event.After, &after))
+
+			if event.Action == AuditActionAuthorizeHold {
+				require.Nil(t, event.Before)
+				require.Equal(t, HoldAuthorized, after["status"])
+				continue
+			}
+
+			var before map[string]any
+			require.NoError(t, json.Unmarshal(event.Before, &before))
+			require.Equal(t, HoldAuthorized, before["status"])
+			require.NotEqual(t, HoldAuthorized, after["status"])
+		}
+	}
+}
+
+func TestAuditEventsAreAppendOnly(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, err := store.CreateAccountTx(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Currency: utils.RandomCurrency(),
+	})
+	require.NoError(t, err)
+
+	_, err = testDB.Exec("UPDATE audit_events SET actor = 'someone else'")
+	require.Error(t, err)
+
+	_, err = testDB.Exec("DELETE FROM audit_events")
+	require.Error(t, err)
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	store := NewStore(testDB)
+
+	// events without audit info are made by the system
+	account, err := store.CreateAccountTx(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Currency: utils.RandomCurrency(),
+	})
+	require.NoError(t, err)
+
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		TargetType: sql.NullString{String: AuditTargetAccount, Valid: true},
+		TargetID:   sql.NullString{String: strconv.FormatInt(account.ID, 10), Valid: true},
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActorSystem, events[0].Actor)
+
+	report, err := store.VerifyAuditChain(context.Background())
+	require.NoError(t, err)
+	require.True(t, report.Valid)
+	require.Zero(t, report.BrokenAtID)
+	require.NotZero(t, report.Checked)
+}
+
+func TestHashAuditEvent(t *testing.T) {
+	event := CreateAuditEventParams{
+		Actor:      utils.RandomOwner(),
+		Action:     AuditActionDeleteAccount,
+		TargetType: AuditTargetAccount,
+		TargetID:   "1",
+		Before:     json.RawMessage(`{"balance":0}`),
+		PrevHash:   utils.RandomString(64),
+		CreatedAt:  time.Now(),
+	}
+
+	hash, err := hashAuditEvent(event)
+	require.NoError(t, err)
+	require.Len(t, hash, 64)
+
+	// the hash does not depend on the time zone of the time
+	event.CreatedAt = event.CreatedAt.In(time.FixedZone("test", 3600))
+	sameHash, err := hashAuditEvent(event)
+	require.NoError(t, err)
+	require.Equal(t, hash, sameHash)
+
+	changed := event
+	changed.Before = json.RawMessage(`{"balance":100}`)
+	changedHash, err := hashAuditEvent(changed)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, changedHash)
+
+	relinked := event
+	relinked.PrevHash = utils.RandomString(64)
+	relinkedHash, err := hashAuditEvent(relinked)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, relinkedHash)
+}
+
+func TestAuditStateRemovesSensitiveFields(t *testing.T) {
+	state, err := auditState(User{Username: "user", HashedPassword: "secret"})
+	require.NoError(t, err)
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(state, &fields))
+	require.Equal(t, "user", fields["username"])
+	require.NotContains(t, fields, "hashed_password")
+
+	state, err = auditState(nil)
+	require.NoError(t, err)
+	require.Nil(t, state)
+}
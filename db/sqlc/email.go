@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// the purposes of the tokens sent by email
+const (
+	EmailTokenVerifyEmail   = "verify_email"
+	EmailTokenResetPassword = "reset_password"
+)
+
+// CreateEmailTokenTx creates a token to be sent by email.
+// The tokens of the same purpose sent to the user before can no longer be used.
+func (store *SQLStore) CreateEmailTokenTx(ctx context.Context, arg CreateEmailTokenParams) (EmailToken, error) {
+	var result EmailToken
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := q.InvalidateEmailTokens(ctx, InvalidateEmailTokensParams{
+			Username: arg.Username,
+			Purpose:  arg.Purpose,
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = q.CreateEmailToken(ctx, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// VerifyEmailTx uses an email verification token and marks the address it was sent to as verified
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, hashedToken string) (User, error) {
+	var result User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		emailToken, err := q.UseEmailToken(ctx, UseEmailTokenParams{
+			HashedToken: hashedToken,
+			Purpose:     EmailTokenVerifyEmail,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvalidEmailToken
+			}
+			return err
+		}
+
+		before, err := q.GetUserForUpdate(ctx, emailToken.Username)
+		if err != nil {
+			return err
+		}
+
+		// the token only verifies the address it was sent to
+		result, err = q.VerifyUserEmail(ctx, VerifyUserEmailParams{
+			Username: emailToken.Username,
+			Email:    emailToken.Email,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvalidEmailToken
+			}
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionVerifyEmail,
+			TargetType: AuditTargetUser,
+			TargetID:   result.Username,
+			Before:     before,
+			After:      result,
+		})
+	})
+
+	return result, err
+}
+
+// ResetPasswordTxParams contains the parameters of the reset password transaction.
+type ResetPasswordTxParams struct {
+	HashedToken    string `json:"hashed_token"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+// ResetPasswordTx uses a password reset token and sets the new password of the user.
+// Like any password change, it revokes all tokens of the user issued before it,
+// and it clears the failed logins of the user, who may have been locked out.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var result User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		emailToken, err := q.UseEmailToken(ctx, UseEmailTokenParams{
+			HashedToken: arg.HashedToken,
+			Purpose:     EmailTokenResetPassword,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvalidEmailToken
+			}
+			return err
+		}
+
+		before, err := q.GetUserForUpdate(ctx, emailToken.Username)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.UpdateUser(ctx, UpdateUserParams{
+			Username: emailToken.Username,
+			HashedPassword: sql.NullString{
+				String: arg.HashedPassword,
+				Valid:  true,
+			},
+			PasswordChangedAt: sql.NullTime{
+				Time:  time.Now(),
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		// a token sent to an address the user no longer has must not take over the account
+		if result.Email != emailToken.Email {
+			return ErrInvalidEmailToken
+		}
+
+		_, err = q.RevokeUserTokens(ctx, RevokeUserTokensParams{
+			Username:     result.Username,
+			IssuedBefore: result.PasswordChangedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteLoginFailure(ctx, DeleteLoginFailureParams{
+			KeyType: LoginKeyUsername,
+			Key:     result.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionResetPassword,
+			TargetType: AuditTargetUser,
+			TargetID:   result.Username,
+			Before:     before,
+			After:      result,
+		})
+	})
+
+	return result, err
+}
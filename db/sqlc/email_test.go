@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomEmailToken(t *testing.T, user User, purpose string) (EmailToken, string) {
+	token, err := utils.GenerateSecureToken(32)
+	require.NoError(t, err)
+
+	arg := CreateEmailTokenParams{
+		HashedToken: utils.HashToken(token),
+		Username:    user.Username,
+		Purpose:     purpose,
+		Email:       user.Email,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	emailToken, err := NewStore(testDB).CreateEmailTokenTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.HashedToken, emailToken.HashedToken)
+	require.Equal(t, arg.Username, emailToken.Username)
+	require.Equal(t, arg.Purpose, emailToken.Purpose)
+	require.Equal(t, arg.Email, emailToken.Email)
+	require.Nil(t, emailToken.UsedAt)
+
+	return emailToken, token
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	require.False(t, user.IsEmailVerified)
+
+	// only the last token sent works
+	_, oldToken := createRandomEmailToken(t, user, EmailTokenVerifyEmail)
+	_, token := createRandomEmailToken(t, user, EmailTokenVerifyEmail)
+
+	_, err := store.VerifyEmailTx(context.Background(), utils.HashToken(oldToken))
+	require.ErrorIs(t, err, ErrInvalidEmailToken)
+
+	// a token of another purpose does not verify the address
+	_, resetToken := createRandomEmailToken(t, user, EmailTokenResetPassword)
+	_, err = store.VerifyEmailTx(context.Background(), utils.HashToken(resetToken))
+	require.ErrorIs(t, err, ErrInvalidEmailToken)
+
+	verifiedUser, err := store.VerifyEmailTx(context.Background(), utils.HashToken(token))
+	require.NoError(t, err)
+	require.True(t, verifiedUser.IsEmailVerified)
+
+	// tokens are used once
+	_, err = store.VerifyEmailTx(context.Background(), utils.HashToken(token))
+	require.ErrorIs(t, err, ErrInvalidEmailToken)
+
+	// a new address has to be verified again
+	updatedUser, err := testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Username: user.Username,
+		Email: sql.NullString{
+			String: utils.RandomEmail(),
+			Valid:  true,
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, updatedUser.IsEmailVerified)
+}
+
+func TestVerifyEmailTxChangedEmail(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	_, token := createRandomEmailToken(t, user, EmailTokenVerifyEmail)
+
+	_, err := testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Username: user.Username,
+		Email: sql.NullString{
+			String: utils.RandomEmail(),
+			Valid:  true,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = store.VerifyEmailTx(context.Background(), utils.HashToken(token))
+	require.ErrorIs(t, err, ErrInvalidEmailToken)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	_, token := createRandomEmailToken(t, user, EmailTokenResetPassword)
+
+	hashedPassword, err := utils.HashPassword(utils.RandomString(8))
+	require.NoError(t, err)
+
+	updatedUser, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		HashedToken:    utils.HashToken(token),
+		HashedPassword: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, updatedUser.HashedPassword)
+	require.WithinDuration(t, time.Now(), updatedUser.PasswordChangedAt, time.Second)
+
+	revocations, err := testQueries.ListUserTokenRevocations(context.Background(), time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	var revoked bool
+	for _, revocation := range revocations {
+		if revocation.Username == user.Username {
+			revoked = true
+		}
+	}
+	require.True(t, revoked)
+
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		HashedToken:    utils.HashToken(token),
+		HashedPassword: hashedPassword,
+	})
+	require.ErrorIs(t, err, ErrInvalidEmailToken)
+}
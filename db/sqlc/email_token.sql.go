@@ -0,0 +1,96 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.18.0
+// source: email_token.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createEmailToken = `-- name: CreateEmailToken :one
+INSERT INTO email_tokens (hashed_token, username, purpose, email, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, hashed_token, username, purpose, email, expires_at, used_at, created_at
+`
+
+type CreateEmailTokenParams struct {
+	HashedToken string    `json:"hashed_token"`
+	Username    string    `json:"username"`
+	Purpose     string    `json:"purpose"`
+	Email       string    `json:"email"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateEmailToken(ctx context.Context, arg CreateEmailTokenParams) (EmailToken, error) {
+	row := q.db.QueryRowContext(ctx, createEmailToken,
+		arg.HashedToken,
+		arg.Username,
+		arg.Purpose,
+		arg.Email,
+		arg.ExpiresAt,
+	)
+	var i EmailToken
+	err := row.Scan(
+		&i.ID,
+		&i.HashedToken,
+		&i.Username,
+		&i.Purpose,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const invalidateEmailTokens = `-- name: InvalidateEmailTokens :exec
+UPDATE email_tokens
+SET used_at = now()
+WHERE username = $1
+  AND purpose = $2
+  AND used_at IS NULL
+`
+
+type InvalidateEmailTokensParams struct {
+	Username string `json:"username"`
+	Purpose  string `json:"purpose"`
+}
+
+func (q *Queries) InvalidateEmailTokens(ctx context.Context, arg InvalidateEmailTokensParams) error {
+	_, err := q.db.ExecContext(ctx, invalidateEmailTokens, arg.Username, arg.Purpose)
+	return err
+}
+
+const useEmailToken = `-- name: UseEmailToken :one
+UPDATE email_tokens
+SET used_at = now()
+WHERE hashed_token = $1
+  AND purpose = $2
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING id, hashed_token, username, purpose, email, expires_at, used_at, created_at
+`
+
+type UseEmailTokenParams struct {
+	HashedToken string `json:"hashed_token"`
+	Purpose     string `json:"purpose"`
+}
+
+func (q *Queries) UseEmailToken(ctx context.Context, arg UseEmailTokenParams) (EmailToken, error) {
+	row := q.db.QueryRowContext(ctx, useEmailToken, arg.HashedToken, arg.Purpose)
+	var i EmailToken
+	err := row.Scan(
+		&i.ID,
+		&i.HashedToken,
+		&i.Username,
+		&i.Purpose,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -12,6 +12,7 @@ func createRandomEntry(t *testing.T, account Account) Entry {
 	arg := CreateEntryParams{
 		AccountID: account.ID,
 		Amount:    utils.RandomAmount(),
+		Kind:      EntryKindDeposit,
 	}
 
 	entry, err := testQueries.CreateEntry(context.Background(), arg)
@@ -20,6 +21,8 @@ func createRandomEntry(t *testing.T, account Account) Entry {
 
 	require.Equal(t, arg.AccountID, entry.AccountID)
 	require.Equal(t, arg.Amount, entry.Amount)
+	require.Equal(t, arg.Kind, entry.Kind)
+	require.Nil(t, entry.TransferID)
 
 	require.NotZero(t, entry.ID)
 	require.NotZero(t, entry.CreatedAt)
@@ -70,3 +73,62 @@ func TestListEntries(t *testing.T) {
 		require.Equal(t, account.ID, entry.AccountID)
 	}
 }
+
+// TestListTransferEntries tests listing entries of a transfer
+func TestListTransferEntries(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	res, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance,
+	})
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListTransferEntries(context.Background(), &res.Transfer.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	require.Equal(t, res.FromEntry, entries[0])
+	require.Equal(t, res.ToEntry, entries[1])
+}
+
+// TestListAccountStatement tests listing entries of an account with their counterparties
+func TestListAccountStatement(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// an entry without a transfer has no counterparty
+	entry := createRandomEntry(t, account1)
+
+	res, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance,
+	})
+	require.NoError(t, err)
+
+	params := ListAccountStatementParams{
+		AccountID: account1.ID,
+		Limit:     5,
+		Offset:    0,
+	}
+
+	lines, err := testQueries.ListAccountStatement(context.Background(), params)
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+
+	require.Equal(t, entry.ID, lines[0].ID)
+	require.False(t, lines[0].CounterpartyAccountID.Valid)
+
+	require.Equal(t, res.FromEntry.ID, lines[1].ID)
+	require.Equal(t, EntryKindTransfer, lines[1].Kind)
+	require.Equal(t, res.Transfer.ID, *lines[1].TransferID)
+	require.Equal(t, account2.ID, lines[1].CounterpartyAccountID.Int64)
+	require.Equal(t, account2.Owner, lines[1].CounterpartyOwner.String)
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// serializationFailure and deadlockDetected are the SQLSTATE codes of errors
+	// after which a transaction can succeed when it is run again
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+
+	// balanceWithinOverdraftLimit is the name of the CHECK constraint
+	// that keeps an account's available balance above its negative overdraft limit
+	balanceWithinOverdraftLimit = "balance_within_overdraft_limit"
+)
+
+// ErrInsufficientFunds is returned when an operation would take an account's
+// available balance below the limit allowed by its overdraft
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrIdempotencyKeyReused is returned when an idempotency key
+// is used again with different request parameters
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used with different parameters")
+
+// ErrFXRateNotFound is returned when there is no exchange rate loaded for a currency
+var ErrFXRateNotFound = errors.New("exchange rate not found")
+
+// ErrFXQuoteExpired is returned when a quote is used after its expiration time
+var ErrFXQuoteExpired = errors.New("quote has expired")
+
+// ErrFXQuoteUsed is returned when a quote is used for a second transfer
+var ErrFXQuoteUsed = errors.New("quote has already been used")
+
+// ErrFXQuoteMismatch is returned when a quote does not belong to the user
+// or its currencies differ from the currencies of the accounts
+var ErrFXQuoteMismatch = errors.New("quote does not match the transfer")
+
+// ErrHoldNotAuthorized is returned when a hold that was already captured,
+// voided or expired is captured or voided again
+var ErrHoldNotAuthorized = errors.New("hold is no longer authorized")
+
+// ErrHoldExpired is returned when a hold is captured after its expiration time
+var ErrHoldExpired = errors.New("hold has expired")
+
+// ErrCaptureExceedsHold is returned when the captured amount is greater than the held amount
+var ErrCaptureExceedsHold = errors.New("captured amount exceeds the held amount")
+
+// ErrTransferIsReversal is returned when a reversal is reversed
+var ErrTransferIsReversal = errors.New("a reversal cannot be reversed")
+
+// ErrTransferAlreadyReversed is returned when the whole amount of a transfer was already reversed
+var ErrTransferAlreadyReversed = errors.New("transfer was already reversed")
+
+// ErrReversalExceedsTransfer is returned when a reversal is greater than
+// the amount of the transfer that was not reversed yet
+var ErrReversalExceedsTransfer = errors.New("reversed amount exceeds the remaining amount of the transfer")
+
+// ErrFXTransferNotReversible is returned when a cross-currency transfer is reversed
+var ErrFXTransferNotReversible = errors.New("cross-currency transfers cannot be reversed")
+
+// ErrAccountFrozen is returned when money is taken or held from a frozen account
+var ErrAccountFrozen = errors.New("account is frozen")
+
+// ErrSessionBlocked is returned when the refresh token of a blocked session is exchanged
+var ErrSessionBlocked = errors.New("session is blocked")
+
+// ErrRefreshTokenReused is returned when a refresh token that was already exchanged
+// for a new one is presented again. All the sessions of its family are blocked by then.
+var ErrRefreshTokenReused = errors.New("refresh token was already used")
+
+// ErrTOTPAlreadyEnabled is returned when two-factor authentication is enrolled
+// or confirmed again after it was enabled
+var ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+// ErrTOTPCodeUsed is returned when a TOTP code of the same or an earlier
+// time step than the last accepted one is presented
+var ErrTOTPCodeUsed = errors.New("code was already used")
+
+// ErrInvalidEmailToken is returned when an email token is unknown, expired or was already used,
+// or when the user changed the email address after the token was sent
+var ErrInvalidEmailToken = errors.New("invalid or expired token")
+
+// ErrOAuthCodeUsed is returned when an OAuth authorization code is exchanged a second time
+var ErrOAuthCodeUsed = errors.New("authorization code was already used")
+
+// ErrOAuthRefreshTokenReused is returned when an OAuth refresh token that was already revoked
+// or exchanged for a new one is presented again. All the refresh tokens the user gave the client are revoked by then.
+var ErrOAuthRefreshTokenReused = errors.New("refresh token was already used")
+
+// IsInsufficientFunds checks if the error was caused by the overdraft limit check
+func IsInsufficientFunds(err error) bool {
+	if errors.Is(err, ErrInsufficientFunds) {
+		return true
+	}
+
+	if pqErr, ok := err.(*pq.Error); ok {
+		return pqErr.Code.Name() == "check_violation" && pqErr.Constraint == balanceWithinOverdraftLimit
+	}
+
+	return false
+}
+
+// IsRetryableTxError checks if the transaction was aborted by Postgres
+// because of a deadlock or a serialization failure
+func IsRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+	}
+
+	return false
+}
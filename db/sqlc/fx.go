@@ -0,0 +1,298 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/aalug/bank-go/fx"
+	"github.com/google/uuid"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// EntryKindFX marks entries of cross-currency transfers
+const EntryKindFX = "fx"
+
+// CreateQuoteTxParams contains the parameters of the create quote transaction.
+type CreateQuoteTxParams struct {
+	Username     string `json:"username"`
+	FromCurrency string `json:"from_currency"`
+	ToCurrency   string `json:"to_currency"`
+	// Spread is the fraction of the converted amount kept by the bank, e.g. 0.005
+	Spread string `json:"spread"`
+	// Duration is how long the rate of the quote is locked for
+	Duration time.Duration `json:"duration"`
+}
+
+// CreateQuoteTx locks the current exchange rate between two currencies for the user.
+// The rate is calculated from the loaded euro reference rates
+// and returns ErrFXRateNotFound if any of them is missing.
+func (store *SQLStore) CreateQuoteTx(ctx context.Context, arg CreateQuoteTxParams) (FXQuote, error) {
+	var quote FXQuote
+
+	spread, err := fx.ParseSpread(arg.Spread)
+	if err != nil {
+		return quote, fmt.Errorf("invalid fx spread: %w", err)
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		fromRate, err := euroRate(ctx, q, arg.FromCurrency)
+		if err != nil {
+			return err
+		}
+
+		toRate, err := euroRate(ctx, q, arg.ToCurrency)
+		if err != nil {
+			return err
+		}
+
+		rate, err := fx.CrossRate(fromRate, toRate)
+		if err != nil {
+			return err
+		}
+
+		quote, err = q.CreateFXQuote(ctx, CreateFXQuoteParams{
+			ID:           uuid.New(),
+			Username:     arg.Username,
+			FromCurrency: arg.FromCurrency,
+			ToCurrency:   arg.ToCurrency,
+			Rate:         fx.FormatRate(rate),
+			Spread:       fx.FormatSpread(spread),
+			ExpiresAt:    time.Now().Add(arg.Duration),
+		})
+		return err
+	})
+
+	return quote, err
+}
+
+// euroRate returns the rate of the currency against the euro
+func euroRate(ctx context.Context, q *Queries, currency string) (*big.Rat, error) {
+	if currency == fx.BaseCurrency {
+		return big.NewRat(1, 1), nil
+	}
+
+	rate, err := q.GetFXRate(ctx, currency)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrFXRateNotFound, currency)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fx.ParseRate(rate.Rate)
+}
+
+// Convert converts the amount with the rate of the quote, keeping the spread
+func (quote FXQuote) Convert(amount int64) (int64, error) {
+	rate, err := fx.ParseRate(quote.Rate)
+	if err != nil {
+		return 0, err
+	}
+
+	spread, err := fx.ParseSpread(quote.Spread)
+	if err != nil {
+		return 0, err
+	}
+
+	return fx.Convert(amount, rate, spread)
+}
+
+// LoadFXRatesTx replaces the stored euro reference rates with the given ones
+func (store *SQLStore) LoadFXRatesTx(ctx context.Context, rates fx.Rates) ([]FXRate, error) {
+	var result []FXRate
+
+	currencies := make([]string, 0, len(rates.Rates))
+	for currency := range rates.Rates {
+		if currency != fx.BaseCurrency {
+			currencies = append(currencies, currency)
+		}
+	}
+	sort.Strings(currencies)
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		for _, currency := range currencies {
+			rate, err := q.UpsertFXRate(ctx, UpsertFXRateParams{
+				Currency:    currency,
+				Rate:        fx.FormatRate(rates.Rates[currency]),
+				PublishedOn: rates.PublishedOn,
+			})
+			if err != nil {
+				return err
+			}
+
+			result = append(result, rate)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// FXTransferTxParams contains the parameters of the cross-currency transfer transaction.
+type FXTransferTxParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// Amount is debited from the from account, in its currency
+	Amount   int64     `json:"amount"`
+	QuoteID  uuid.UUID `json:"quote_id"`
+	Username string    `json:"username"`
+}
+
+// FXTransferTx performs a money transfer between accounts of different currencies
+// using the rate locked by a quote. A quote can be used only once and only before it expires.
+// The amount is debited in the source currency and the converted amount, less the spread,
+// is credited in the target currency. The fx system accounts take the opposite side
+// of both legs, so that entries of every currency still sum up to zero.
+func (store *SQLStore) FXTransferTx(ctx context.Context, arg FXTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// lock the quote, so that concurrent transfers cannot use it twice
+		quote, err := q.GetFXQuoteForUpdate(ctx, arg.QuoteID)
+		if err != nil {
+			return err
+		}
+
+		if quote.Username != arg.Username {
+			return ErrFXQuoteMismatch
+		}
+		if quote.IsUsed {
+			return ErrFXQuoteUsed
+		}
+		if time.Now().After(quote.ExpiresAt) {
+			return ErrFXQuoteExpired
+		}
+
+		fromFXAccount, err := getFXAccount(ctx, q, arg.FromAccountID, quote.FromCurrency)
+		if err != nil {
+			return err
+		}
+
+		toFXAccount, err := getFXAccount(ctx, q, arg.ToAccountID, quote.ToCurrency)
+		if err != nil {
+			return err
+		}
+
+		toAmount, err := quote.Convert(arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = q.CreateFXTransfer(ctx, CreateFXTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			ToAmount:      &toAmount,
+			FXQuoteID:     &quote.ID,
+			FXRate:        &quote.Rate,
+			FXSpread:      &quote.Spread,
+		})
+		if err != nil {
+			return err
+		}
+
+		changes := []balanceChange{
+			{AccountID: arg.FromAccountID, Amount: -arg.Amount},
+			{AccountID: fromFXAccount.ID, Amount: arg.Amount},
+			{AccountID: toFXAccount.ID, Amount: -toAmount},
+			{AccountID: arg.ToAccountID, Amount: toAmount},
+		}
+
+		for _, change := range changes {
+			entry, err := q.CreateEntry(ctx, CreateEntryParams{
+				AccountID:  change.AccountID,
+				Amount:     change.Amount,
+				TransferID: &result.Transfer.ID,
+				Kind:       EntryKindFX,
+			})
+			if err != nil {
+				return err
+			}
+
+			switch change.AccountID {
+			case arg.FromAccountID:
+				result.FromEntry = entry
+			case arg.ToAccountID:
+				result.ToEntry = entry
+			}
+		}
+
+		accounts, err := addMoneyInOrder(ctx, q, changes)
+		if IsInsufficientFunds(err) {
+			return ErrInsufficientFunds
+		}
+		if err != nil {
+			return err
+		}
+		result.FromAccount = accounts[arg.FromAccountID]
+		result.ToAccount = accounts[arg.ToAccountID]
+
+		_, err = q.MarkFXQuoteUsed(ctx, quote.ID)
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(ctx, q, AuditActionCreateTransfer, result.Transfer)
+	})
+
+	observeTransfer(EntryKindFX, result, err)
+	return result, err
+}
+
+// getFXAccount checks that the customer account has the currency of the quote
+// and returns the fx system account of that currency
+func getFXAccount(ctx context.Context, q *Queries, accountID int64, currency string) (Account, error) {
+	account, err := q.GetAccount(ctx, accountID)
+	if err != nil {
+		return Account{}, err
+	}
+
+	if IsSystemAccountOwner(account.Owner) {
+		return Account{}, ErrSystemAccount
+	}
+
+	if account.Currency != currency {
+		return Account{}, ErrFXQuoteMismatch
+	}
+
+	return q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    FXSystemAccountOwner,
+		Currency: currency,
+	})
+}
+
+// balanceChange is an amount added to the balance of an account
+type balanceChange struct {
+	AccountID int64
+	Amount    int64
+}
+
+// addMoneyInOrder updates the balances of all accounts in the order of their IDs,
+// the same order addMoney uses, so that concurrent transactions do not deadlock
+func addMoneyInOrder(ctx context.Context, q *Queries, changes []balanceChange) (map[int64]Account, error) {
+	sorted := make([]balanceChange, len(changes))
+	copy(sorted, changes)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].AccountID < sorted[j].AccountID
+	})
+
+	accounts := make(map[int64]Account, len(sorted))
+	for _, change := range sorted {
+		account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     change.AccountID,
+			Amount: change.Amount,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := checkFrozen(account, change.Amount); err != nil {
+			return nil, err
+		}
+
+		accounts[account.ID] = account
+	}
+
+	return accounts, nil
+}
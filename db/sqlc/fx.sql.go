@@ -0,0 +1,209 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.18.0
+// source: fx.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createFXQuote = `-- name: CreateFXQuote :one
+INSERT INTO fx_quotes
+    (id, username, from_currency, to_currency, rate, spread, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, username, from_currency, to_currency, rate, spread, is_used, expires_at, created_at
+`
+
+type CreateFXQuoteParams struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	Rate         string    `json:"rate"`
+	Spread       string    `json:"spread"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateFXQuote(ctx context.Context, arg CreateFXQuoteParams) (FXQuote, error) {
+	row := q.db.QueryRowContext(ctx, createFXQuote,
+		arg.ID,
+		arg.Username,
+		arg.FromCurrency,
+		arg.ToCurrency,
+		arg.Rate,
+		arg.Spread,
+		arg.ExpiresAt,
+	)
+	var i FXQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Rate,
+		&i.Spread,
+		&i.IsUsed,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFXQuote = `-- name: GetFXQuote :one
+SELECT id, username, from_currency, to_currency, rate, spread, is_used, expires_at, created_at
+FROM fx_quotes
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetFXQuote(ctx context.Context, id uuid.UUID) (FXQuote, error) {
+	row := q.db.QueryRowContext(ctx, getFXQuote, id)
+	var i FXQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Rate,
+		&i.Spread,
+		&i.IsUsed,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFXQuoteForUpdate = `-- name: GetFXQuoteForUpdate :one
+SELECT id, username, from_currency, to_currency, rate, spread, is_used, expires_at, created_at
+FROM fx_quotes
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetFXQuoteForUpdate(ctx context.Context, id uuid.UUID) (FXQuote, error) {
+	row := q.db.QueryRowContext(ctx, getFXQuoteForUpdate, id)
+	var i FXQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Rate,
+		&i.Spread,
+		&i.IsUsed,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFXRate = `-- name: GetFXRate :one
+SELECT currency, rate, published_on, updated_at
+FROM fx_rates
+WHERE currency = $1
+LIMIT 1
+`
+
+func (q *Queries) GetFXRate(ctx context.Context, currency string) (FXRate, error) {
+	row := q.db.QueryRowContext(ctx, getFXRate, currency)
+	var i FXRate
+	err := row.Scan(
+		&i.Currency,
+		&i.Rate,
+		&i.PublishedOn,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listFXRates = `-- name: ListFXRates :many
+SELECT currency, rate, published_on, updated_at
+FROM fx_rates
+ORDER BY currency
+`
+
+func (q *Queries) ListFXRates(ctx context.Context) ([]FXRate, error) {
+	rows, err := q.db.QueryContext(ctx, listFXRates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FXRate{}
+	for rows.Next() {
+		var i FXRate
+		if err := rows.Scan(
+			&i.Currency,
+			&i.Rate,
+			&i.PublishedOn,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markFXQuoteUsed = `-- name: MarkFXQuoteUsed :one
+UPDATE fx_quotes
+SET is_used = true
+WHERE id = $1
+RETURNING id, username, from_currency, to_currency, rate, spread, is_used, expires_at, created_at
+`
+
+func (q *Queries) MarkFXQuoteUsed(ctx context.Context, id uuid.UUID) (FXQuote, error) {
+	row := q.db.QueryRowContext(ctx, markFXQuoteUsed, id)
+	var i FXQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Rate,
+		&i.Spread,
+		&i.IsUsed,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertFXRate = `-- name: UpsertFXRate :one
+INSERT INTO fx_rates
+    (currency, rate, published_on)
+VALUES ($1, $2, $3)
+ON CONFLICT (currency) DO UPDATE
+    SET rate         = excluded.rate,
+        published_on = excluded.published_on,
+        updated_at   = now()
+RETURNING currency, rate, published_on, updated_at
+`
+
+type UpsertFXRateParams struct {
+	Currency    string    `json:"currency"`
+	Rate        string    `json:"rate"`
+	PublishedOn time.Time `json:"published_on"`
+}
+
+func (q *Queries) UpsertFXRate(ctx context.Context, arg UpsertFXRateParams) (FXRate, error) {
+	row := q.db.QueryRowContext(ctx, upsertFXRate, arg.Currency, arg.Rate, arg.PublishedOn)
+	var i FXRate
+	err := row.Scan(
+		&i.Currency,
+		&i.Rate,
+		&i.PublishedOn,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"github.com/aalug/bank-go/fx"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// loadTestFXRates stores fixed euro reference rates
+func loadTestFXRates(t *testing.T, store Store) {
+	rates := fx.Rates{
+		PublishedOn: time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC),
+		Rates: map[string]*big.Rat{
+			utils.EUR: big.NewRat(1, 1),
+			utils.USD: big.NewRat(10866, 10000),
+			utils.PLN: big.NewRat(44388, 10000),
+			utils.CAD: big.NewRat(14415, 10000),
+		},
+	}
+
+	loaded, err := store.LoadFXRatesTx(context.Background(), rates)
+	require.NoError(t, err)
+	// the base currency is not stored
+	require.Len(t, loaded, 3)
+}
+
+// createAccountInCurrency creates an account of a new user in the given currency
+func createAccountInCurrency(t *testing.T, currency string, balance int64) Account {
+	user := createRandomUser(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
+func createTestQuote(t *testing.T, store Store, username, fromCurrency, toCurrency string, duration time.Duration) FXQuote {
+	quote, err := store.CreateQuoteTx(context.Background(), CreateQuoteTxParams{
+		Username:     username,
+		FromCurrency: fromCurrency,
+		ToCurrency:   toCurrency,
+		Spread:       "0.005",
+		Duration:     duration,
+	})
+	require.NoError(t, err)
+
+	return quote
+}
+
+func TestCreateQuoteTx(t *testing.T) {
+	store := NewStore(testDB)
+	loadTestFXRates(t, store)
+	user := createRandomUser(t)
+
+	quote := createTestQuote(t, store, user.Username, utils.EUR, utils.USD, time.Minute)
+	require.NotZero(t, quote.ID)
+	require.Equal(t, user.Username, quote.Username)
+	require.Equal(t, "1.0866000000", quote.Rate)
+	require.Equal(t, "0.005000", quote.Spread)
+	require.False(t, quote.IsUsed)
+	require.WithinDuration(t, time.Now().Add(time.Minute), quote.ExpiresAt, time.Second)
+
+	// cross rates are calculated from the euro rates
+	quote = createTestQuote(t, store, user.Username, utils.USD, utils.PLN, time.Minute)
+	require.Equal(t, "4.0850358918", quote.Rate)
+
+	_, err := store.CreateQuoteTx(context.Background(), CreateQuoteTxParams{
+		Username:     user.Username,
+		FromCurrency: utils.EUR,
+		ToCurrency:   "XYZ",
+		Spread:       "0.005",
+		Duration:     time.Minute,
+	})
+	require.ErrorIs(t, err, ErrFXRateNotFound)
+}
+
+func TestFXTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	loadTestFXRates(t, store)
+
+	amount := int64(10000)
+	account1 := createAccountInCurrency(t, utils.EUR, amount)
+	account2 := createAccountInCurrency(t, utils.USD, 0)
+	fxEUR := getSystemAccountFor(t, account1, FXSystemAccountOwner)
+	fxUSD := getSystemAccountFor(t, account2, FXSystemAccountOwner)
+
+	quote := createTestQuote(t, store, account1.Owner, utils.EUR, utils.USD, time.Minute)
+
+	arg := FXTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		QuoteID:       quote.ID,
+		Username:      account1.Owner,
+	}
+
+	res, err := store.FXTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// 10000 * 1.0866 * (1 - 0.005), rounded down
+	toAmount := int64(10811)
+
+	transfer := res.Transfer
+	require.Equal(t, account1.ID, transfer.FromAccountID)
+	require.Equal(t, account2.ID, transfer.ToAccountID)
+	require.Equal(t, amount, transfer.Amount)
+	require.NotNil(t, transfer.ToAmount)
+	require.Equal(t, toAmount, *transfer.ToAmount)
+	require.Equal(t, quote.ID, *transfer.FXQuoteID)
+	require.Equal(t, quote.Rate, *transfer.FXRate)
+	require.Equal(t, quote.Spread, *transfer.FXSpread)
+
+	require.Equal(t, -amount, res.FromEntry.Amount)
+	require.Equal(t, toAmount, res.ToEntry.Amount)
+	require.Equal(t, EntryKindFX, res.FromEntry.Kind)
+	require.Zero(t, res.FromAccount.Balance)
+	require.Equal(t, toAmount, res.ToAccount.Balance)
+
+	// the fx accounts take the opposite side of both legs
+	entries, err := testQueries.ListTransferEntries(context.Background(), &transfer.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	totals := make(map[int64]int64)
+	for _, entry := range entries {
+		totals[entry.AccountID] += entry.Amount
+	}
+	require.Equal(t, amount, totals[fxEUR.ID])
+	require.Equal(t, -toAmount, totals[fxUSD.ID])
+
+	// a quote can be used only once
+	_, err = store.FXTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrFXQuoteUsed)
+
+	usedQuote, err := testQueries.GetFXQuote(context.Background(), quote.ID)
+	require.NoError(t, err)
+	require.True(t, usedQuote.IsUsed)
+}
+
+func TestFXTransferTxInvalidQuote(t *testing.T) {
+	store := NewStore(testDB)
+	loadTestFXRates(t, store)
+
+	account1 := createAccountInCurrency(t, utils.EUR, 1000)
+	account2 := createAccountInCurrency(t, utils.USD, 0)
+	account3 := createAccountInCurrency(t, utils.PLN, 0)
+
+	expiredQuote := createTestQuote(t, store, account1.Owner, utils.EUR, utils.USD, -time.Minute)
+	_, err := store.FXTransferTx(context.Background(), FXTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		QuoteID:       expiredQuote.ID,
+		Username:      account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrFXQuoteExpired)
+
+	quote := createTestQuote(t, store, account1.Owner, utils.EUR, utils.USD, time.Minute)
+
+	// the quote is locked for other currencies
+	_, err = store.FXTransferTx(context.Background(), FXTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account3.ID,
+		Amount:        100,
+		QuoteID:       quote.ID,
+		Username:      account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrFXQuoteMismatch)
+
+	// and for other users
+	_, err = store.FXTransferTx(context.Background(), FXTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		QuoteID:       quote.ID,
+		Username:      account2.Owner,
+	})
+	require.ErrorIs(t, err, ErrFXQuoteMismatch)
+
+	// nothing is written when the account cannot cover the amount
+	_, err = store.FXTransferTx(context.Background(), FXTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1001,
+		QuoteID:       quote.ID,
+		Username:      account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	unusedQuote, err := testQueries.GetFXQuote(context.Background(), quote.ID)
+	require.NoError(t, err)
+	require.False(t, unusedQuote.IsUsed)
+}
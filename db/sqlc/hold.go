@@ -0,0 +1,268 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// statuses of a hold, only authorized holds reserve funds
+const (
+	HoldAuthorized = "authorized"
+	HoldCaptured   = "captured"
+	HoldVoided     = "voided"
+	HoldExpired    = "expired"
+)
+
+// AuthorizeTxParams contains the parameters of the authorize transaction.
+type AuthorizeTxParams struct {
+	AccountID   int64         `json:"account_id"`
+	ToAccountID int64         `json:"to_account_id"`
+	Amount      int64         `json:"amount"`
+	Duration    time.Duration `json:"duration"`
+}
+
+type HoldTxResult struct {
+	Hold    Hold    `json:"hold"`
+	Account Account `json:"account"`
+}
+
+// AuthorizeTx reserves the amount on the account without moving money.
+// The held amount lowers the account's available balance until the hold
+// is captured, voided or expires. It returns ErrInsufficientFunds
+// if the available balance would go below the overdraft limit.
+// The hold is recorded in the audit log.
+func (store *SQLStore) AuthorizeTx(ctx context.Context, arg AuthorizeTxParams) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID:   arg.AccountID,
+			ToAccountID: arg.ToAccountID,
+			Amount:      arg.Amount,
+			ExpiresAt:   time.Now().Add(arg.Duration),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:     arg.AccountID,
+			Amount: arg.Amount,
+		})
+		if IsInsufficientFunds(err) {
+			return ErrInsufficientFunds
+		}
+		if err != nil {
+			return err
+		}
+
+		err = checkFrozen(result.Account, -arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		return recordHoldEvent(ctx, q, AuditActionAuthorizeHold, nil, result.Hold)
+	})
+
+	return result, err
+}
+
+// CaptureTxParams contains the parameters of the capture transaction.
+type CaptureTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Amount can be lower than the held amount for a partial capture,
+	// zero captures the whole hold
+	Amount int64 `json:"amount"`
+}
+
+type CaptureTxResult struct {
+	Hold Hold `json:"hold"`
+	TransferTxResult
+}
+
+// CaptureTx turns an authorized hold into a transfer to the hold's recipient.
+// The whole hold is released, so after a partial capture
+// the rest of the held amount becomes available again.
+func (store *SQLStore) CaptureTx(ctx context.Context, arg CaptureTxParams) (CaptureTxResult, error) {
+	var result CaptureTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if hold.Status != HoldAuthorized {
+			return ErrHoldNotAuthorized
+		}
+		if !hold.ExpiresAt.After(time.Now()) {
+			return ErrHoldExpired
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		result.TransferTxResult, err = createTransferRecords(ctx, q, TransferTxParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+		}, EntryKindCapture)
+		if err != nil {
+			return err
+		}
+
+		// update the accounts in the order of their IDs, like addMoney does
+		if hold.AccountID < hold.ToAccountID {
+			result.FromAccount, err = debitCapturedHold(ctx, q, hold, amount)
+			if err != nil {
+				return err
+			}
+			result.ToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+				ID:     hold.ToAccountID,
+				Amount: amount,
+			})
+		} else {
+			result.ToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+				ID:     hold.ToAccountID,
+				Amount: amount,
+			})
+			if err != nil {
+				return err
+			}
+			result.FromAccount, err = debitCapturedHold(ctx, q, hold, amount)
+		}
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CaptureHold(ctx, CaptureHoldParams{
+			ID:             hold.ID,
+			CapturedAmount: amount,
+			TransferID:     &result.Transfer.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(ctx, q, AuditActionCaptureHold, result.Transfer)
+	})
+
+	observeTransfer(EntryKindCapture, result.TransferTxResult, err)
+	return result, err
+}
+
+// debitCapturedHold debits the captured amount and releases the whole hold in a single update,
+// so the available balance of the account never drops below the overdraft limit in between
+func debitCapturedHold(ctx context.Context, q *Queries, hold Hold, amount int64) (Account, error) {
+	account, err := q.CaptureAccountHold(ctx, CaptureAccountHoldParams{
+		ID:         hold.AccountID,
+		Amount:     amount,
+		HeldAmount: hold.Amount,
+	})
+	if IsInsufficientFunds(err) {
+		return account, ErrInsufficientFunds
+	}
+	if err != nil {
+		return account, err
+	}
+
+	return account, checkFrozen(account, -amount)
+}
+
+// VoidTx releases an authorized hold without moving money and records it in the audit log.
+func (store *SQLStore) VoidTx(ctx context.Context, holdID int64) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+
+		if hold.Status != HoldAuthorized {
+			return ErrHoldNotAuthorized
+		}
+
+		result.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+			ID:     hold.ID,
+			Status: HoldVoided,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:     hold.AccountID,
+			Amount: -hold.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordHoldEvent(ctx, q, AuditActionVoidHold, hold, result.Hold)
+	})
+
+	return result, err
+}
+
+// ExpireHoldsTx marks at most maxCount authorized holds past their expiration time as expired
+// and releases their amounts. Holds locked by another transaction are skipped.
+// Every expired hold is recorded in the audit log.
+func (store *SQLStore) ExpireHoldsTx(ctx context.Context, maxCount int32) ([]Hold, error) {
+	var holds []Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		holds, err = q.ExpireHolds(ctx, maxCount)
+		if err != nil {
+			return err
+		}
+
+		released := make(map[int64]int64)
+		for _, hold := range holds {
+			released[hold.AccountID] += hold.Amount
+		}
+
+		// release in the order of account IDs so that concurrent transactions do not deadlock
+		accountIDs := make([]int64, 0, len(released))
+		for accountID := range released {
+			accountIDs = append(accountIDs, accountID)
+		}
+		sort.Slice(accountIDs, func(i, j int) bool {
+			return accountIDs[i] < accountIDs[j]
+		})
+
+		for _, accountID := range accountIDs {
+			_, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+				ID:     accountID,
+				Amount: -released[accountID],
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, hold := range holds {
+			before := hold
+			before.Status = HoldAuthorized
+
+			err = recordHoldEvent(ctx, q, AuditActionExpireHold, before, hold)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return holds, err
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// createTestHold authorizes a hold between two new accounts
+func createTestHold(t *testing.T, store Store, amount int64, duration time.Duration) (Hold, Account, Account) {
+	account1 := createAccountInCurrency(t, utils.USD, 100)
+	account2 := createAccountInCurrency(t, utils.USD, 0)
+
+	result, err := store.AuthorizeTx(context.Background(), AuthorizeTxParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      amount,
+		Duration:    duration,
+	})
+	require.NoError(t, err)
+
+	require.NotZero(t, result.Hold.ID)
+	require.Equal(t, account1.ID, result.Hold.AccountID)
+	require.Equal(t, account2.ID, result.Hold.ToAccountID)
+	require.Equal(t, amount, result.Hold.Amount)
+	require.Equal(t, HoldAuthorized, result.Hold.Status)
+
+	// no money is moved, only the available balance goes down
+	require.Equal(t, account1.Balance, result.Account.Balance)
+	require.Equal(t, amount, result.Account.HeldAmount)
+	require.Equal(t, account1.Balance-amount, result.Account.AvailableBalance)
+
+	return result.Hold, result.Account, account2
+}
+
+func TestAuthorizeTx(t *testing.T) {
+	store := NewStore(testDB)
+	hold, account, toAccount := createTestHold(t, store, 60, time.Hour)
+
+	// the held amount is not available for another hold or transfer
+	_, err := store.AuthorizeTx(context.Background(), AuthorizeTxParams{
+		AccountID:   account.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      account.AvailableBalance + 1,
+		Duration:    time.Hour,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        account.Balance - hold.Amount + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestCaptureTx(t *testing.T) {
+	store := NewStore(testDB)
+	hold, account, toAccount := createTestHold(t, store, 60, time.Hour)
+
+	// partial capture releases the rest of the hold
+	result, err := store.CaptureTx(context.Background(), CaptureTxParams{
+		HoldID: hold.ID,
+		Amount: 40,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, HoldCaptured, result.Hold.Status)
+	require.Equal(t, int64(40), result.Hold.CapturedAmount)
+	require.NotNil(t, result.Hold.TransferID)
+	require.Equal(t, result.Transfer.ID, *result.Hold.TransferID)
+
+	require.Equal(t, int64(40), result.Transfer.Amount)
+	require.Equal(t, EntryKindCapture, result.FromEntry.Kind)
+	require.Equal(t, int64(-40), result.FromEntry.Amount)
+	require.Equal(t, int64(40), result.ToEntry.Amount)
+
+	require.Equal(t, account.Balance-40, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.HeldAmount)
+	require.Equal(t, account.Balance-40, result.FromAccount.AvailableBalance)
+	require.Equal(t, toAccount.Balance+40, result.ToAccount.Balance)
+
+	// a hold can be captured only once
+	_, err = store.CaptureTx(context.Background(), CaptureTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+}
+
+func TestCaptureTxExceedsHold(t *testing.T) {
+	store := NewStore(testDB)
+	hold, _, _ := createTestHold(t, store, 60, time.Hour)
+
+	_, err := store.CaptureTx(context.Background(), CaptureTxParams{
+		HoldID: hold.ID,
+		Amount: hold.Amount + 1,
+	})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+}
+
+func TestVoidTx(t *testing.T) {
+	store := NewStore(testDB)
+	hold, account, _ := createTestHold(t, store, 60, time.Hour)
+
+	result, err := store.VoidTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, HoldVoided, result.Hold.Status)
+	require.Equal(t, account.Balance, result.Account.Balance)
+	require.Zero(t, result.Account.HeldAmount)
+	require.Equal(t, account.Balance, result.Account.AvailableBalance)
+
+	_, err = store.VoidTx(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+
+	_, err = store.CaptureTx(context.Background(), CaptureTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+}
+
+func TestExpireHoldsTx(t *testing.T) {
+	store := NewStore(testDB)
+	hold, account, _ := createTestHold(t, store, 60, -time.Second)
+
+	_, err := store.CaptureTx(context.Background(), CaptureTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	// other tests may leave expired holds behind
+	for {
+		holds, err := store.ExpireHoldsTx(context.Background(), 100)
+		require.NoError(t, err)
+		if len(holds) == 0 {
+			break
+		}
+	}
+
+	hold, err = store.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldExpired, hold.Status)
+
+	account, err = store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.HeldAmount)
+	require.Equal(t, account.Balance, account.AvailableBalance)
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// owners of the internal ledger accounts, every supported currency
+// has one cash, one settlement and one fx account
+const (
+	CashSystemAccountOwner       = "bank_cash"
+	SettlementSystemAccountOwner = "bank_settlement"
+	FXSystemAccountOwner         = "bank_fx"
+)
+
+// channels through which money can enter or leave the bank
+const (
+	CashChannel       = "cash"
+	SettlementChannel = "settlement"
+)
+
+// systemAccountOwners maps every channel to the owner of its ledger accounts
+var systemAccountOwners = map[string]string{
+	CashChannel:       CashSystemAccountOwner,
+	SettlementChannel: SettlementSystemAccountOwner,
+}
+
+// IsSupportedChannel checks if money can be deposited or withdrawn through the channel
+func IsSupportedChannel(channel string) bool {
+	_, ok := systemAccountOwners[channel]
+	return ok
+}
+
+// ErrSystemAccount is returned when a customer operation targets an internal ledger account
+var ErrSystemAccount = errors.New("operation is not allowed on a system account")
+
+// IsSystemAccountOwner checks if the owner is one of the internal ledger account owners
+func IsSystemAccountOwner(owner string) bool {
+	switch owner {
+	case CashSystemAccountOwner, SettlementSystemAccountOwner, FXSystemAccountOwner:
+		return true
+	}
+	return false
+}
+
+// DepositTxParams contains the parameters of the deposit transaction.
+type DepositTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+	// Channel is the ledger the money comes from, CashChannel or SettlementChannel
+	Channel string `json:"channel"`
+}
+
+// DepositTx puts money into an account.
+// It is recorded as a transfer from the system account of the same currency,
+// so that entries of every currency always sum up to zero.
+func (store *SQLStore) DepositTx(ctx context.Context, arg DepositTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		systemAccount, err := getSystemAccount(ctx, q, arg.AccountID, arg.Channel)
+		if err != nil {
+			return err
+		}
+
+		result, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: systemAccount.ID,
+			ToAccountID:   arg.AccountID,
+			Amount:        arg.Amount,
+		}, EntryKindDeposit)
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(ctx, q, AuditActionDeposit, result.Transfer)
+	})
+
+	observeTransfer(EntryKindDeposit, result, err)
+	return result, err
+}
+
+// WithdrawTxParams contains the parameters of the withdraw transaction.
+type WithdrawTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+	// Channel is the ledger the money goes to, CashChannel or SettlementChannel
+	Channel string `json:"channel"`
+}
+
+// WithdrawTx takes money out of an account.
+// It is recorded as a transfer to the system account of the same currency
+// and returns ErrInsufficientFunds if the account's overdraft limit would be exceeded.
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg WithdrawTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		systemAccount, err := getSystemAccount(ctx, q, arg.AccountID, arg.Channel)
+		if err != nil {
+			return err
+		}
+
+		result, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: arg.AccountID,
+			ToAccountID:   systemAccount.ID,
+			Amount:        arg.Amount,
+		}, EntryKindWithdrawal)
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(ctx, q, AuditActionWithdraw, result.Transfer)
+	})
+
+	observeTransfer(EntryKindWithdrawal, result, err)
+	return result, err
+}
+
+// getSystemAccount returns the system account of the given channel
+// that has the same currency as the customer account
+func getSystemAccount(ctx context.Context, q *Queries, accountID int64, channel string) (Account, error) {
+	systemAccountOwner, ok := systemAccountOwners[channel]
+	if !ok {
+		return Account{}, fmt.Errorf("unsupported channel %s", channel)
+	}
+
+	account, err := q.GetAccount(ctx, accountID)
+	if err != nil {
+		return Account{}, err
+	}
+
+	if IsSystemAccountOwner(account.Owner) {
+		return Account{}, ErrSystemAccount
+	}
+
+	return q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    systemAccountOwner,
+		Currency: account.Currency,
+	})
+}
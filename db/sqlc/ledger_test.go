@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// getSystemAccountFor returns the system account of the owner with the same currency as the account
+func getSystemAccountFor(t *testing.T, account Account, owner string) Account {
+	systemAccount, err := testQueries.GetAccountByOwnerAndCurrency(context.Background(), GetAccountByOwnerAndCurrencyParams{
+		Owner:    owner,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+
+	return systemAccount
+}
+
+func TestDepositTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	cashAccount := getSystemAccountFor(t, account, CashSystemAccountOwner)
+	amount := utils.RandomAmount()
+
+	res, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+		Channel:   CashChannel,
+	})
+	require.NoError(t, err)
+
+	// the deposit is a transfer from the cash account
+	require.Equal(t, cashAccount.ID, res.Transfer.FromAccountID)
+	require.Equal(t, account.ID, res.Transfer.ToAccountID)
+	require.Equal(t, amount, res.Transfer.Amount)
+
+	require.Equal(t, -amount, res.FromEntry.Amount)
+	require.Equal(t, amount, res.ToEntry.Amount)
+	require.Equal(t, EntryKindDeposit, res.ToEntry.Kind)
+
+	require.Equal(t, account.Balance+amount, res.ToAccount.Balance)
+
+	updatedCashAccount, err := testQueries.GetAccount(context.Background(), cashAccount.ID)
+	require.NoError(t, err)
+	require.LessOrEqual(t, updatedCashAccount.Balance, cashAccount.Balance-amount)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	settlementAccount := getSystemAccountFor(t, account, SettlementSystemAccountOwner)
+
+	res, err := store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    account.Balance,
+		Channel:   SettlementChannel,
+	})
+	require.NoError(t, err)
+
+	// the withdrawal is a transfer to the settlement account
+	require.Equal(t, account.ID, res.Transfer.FromAccountID)
+	require.Equal(t, settlementAccount.ID, res.Transfer.ToAccountID)
+	require.Equal(t, account.Balance, res.Transfer.Amount)
+	require.Zero(t, res.FromAccount.Balance)
+	require.Equal(t, EntryKindWithdrawal, res.FromEntry.Kind)
+
+	// the account is empty now
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    1,
+		Channel:   SettlementChannel,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestDepositTxSystemAccount(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	cashAccount := getSystemAccountFor(t, account, CashSystemAccountOwner)
+
+	_, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: cashAccount.ID,
+		Amount:    utils.RandomAmount(),
+		Channel:   SettlementChannel,
+	})
+	require.ErrorIs(t, err, ErrSystemAccount)
+
+	_, err = store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    utils.RandomAmount(),
+		Channel:   "invalid",
+	})
+	require.Error(t, err)
+}
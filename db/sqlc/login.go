@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"github.com/aalug/bank-go/utils"
+	"time"
+)
+
+// the keys failed logins are counted by, they must match the key types used in ListLoginLocks
+const (
+	LoginKeyUsername = "username"
+	LoginKeyIP       = "ip"
+)
+
+// RecordLoginFailureTxParams contains the parameters of the record login failure transaction.
+type RecordLoginFailureTxParams struct {
+	Username string `json:"username"`
+	ClientIP string `json:"client_ip"`
+	// UsernameThreshold and IPThreshold are the numbers of failures in a row
+	// after which logins are locked for the lockout duration
+	UsernameThreshold int32         `json:"username_threshold"`
+	IPThreshold       int32         `json:"ip_threshold"`
+	LockoutDuration   time.Duration `json:"lockout_duration"`
+}
+
+// RecordLoginFailureTx counts a failed login for the username and for the client IP
+// and refuses further logins for each of them according to utils.LoginBackoff.
+// The counts start over once the lockout duration passes without a failure.
+func (store *SQLStore) RecordLoginFailureTx(ctx context.Context, arg RecordLoginFailureTxParams) ([]LoginFailure, error) {
+	var result []LoginFailure
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		result = nil
+
+		keys := []struct {
+			keyType   string
+			key       string
+			threshold int32
+		}{
+			{LoginKeyUsername, arg.Username, arg.UsernameThreshold},
+			{LoginKeyIP, arg.ClientIP, arg.IPThreshold},
+		}
+
+		for _, key := range keys {
+			if key.key == "" {
+				continue
+			}
+
+			failure, err := q.RecordLoginFailure(ctx, RecordLoginFailureParams{
+				KeyType:           key.keyType,
+				Key:               key.key,
+				ResetAfterSeconds: arg.LockoutDuration.Seconds(),
+			})
+			if err != nil {
+				return err
+			}
+
+			backoff := utils.LoginBackoff(failure.Failures, key.threshold, arg.LockoutDuration)
+			failure, err = q.SetLoginBackoff(ctx, SetLoginBackoffParams{
+				KeyType:        key.keyType,
+				Key:            key.key,
+				BackoffSeconds: backoff.Seconds(),
+			})
+			if err != nil {
+				return err
+			}
+
+			result = append(result, failure)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// AdminUnlockUserTxParams contains the parameters of the admin unlock user transaction.
+type AdminUnlockUserTxParams struct {
+	AdminUsername string `json:"admin_username"`
+	Username      string `json:"username"`
+}
+
+// AdminUnlockUserTx clears the failed logins of a username
+// and records the action in the audit trail of the admins
+func (store *SQLStore) AdminUnlockUserTx(ctx context.Context, arg AdminUnlockUserTxParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteLoginFailure(ctx, DeleteLoginFailureParams{
+			KeyType: LoginKeyUsername,
+			Key:     arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateAdminAction(ctx, CreateAdminActionParams{
+			AdminUsername: arg.AdminUsername,
+			Action:        AdminActionUnlockUser,
+			TargetType:    AdminTargetUser,
+			TargetID:      arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionUnlockUser,
+			TargetType: AuditTargetUser,
+			TargetID:   arg.Username,
+		})
+	})
+}
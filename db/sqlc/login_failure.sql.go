@@ -0,0 +1,154 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.18.0
+// source: login_failure.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deleteLoginFailure = `-- name: DeleteLoginFailure :exec
+DELETE
+FROM login_failures
+WHERE key_type = $1
+  AND key = $2
+`
+
+type DeleteLoginFailureParams struct {
+	KeyType string `json:"key_type"`
+	Key     string `json:"key"`
+}
+
+func (q *Queries) DeleteLoginFailure(ctx context.Context, arg DeleteLoginFailureParams) error {
+	_, err := q.db.ExecContext(ctx, deleteLoginFailure, arg.KeyType, arg.Key)
+	return err
+}
+
+const getLoginFailure = `-- name: GetLoginFailure :one
+SELECT key_type, key, failures, last_failed_at, locked_until
+FROM login_failures
+WHERE key_type = $1
+  AND key = $2
+LIMIT 1
+`
+
+type GetLoginFailureParams struct {
+	KeyType string `json:"key_type"`
+	Key     string `json:"key"`
+}
+
+func (q *Queries) GetLoginFailure(ctx context.Context, arg GetLoginFailureParams) (LoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, getLoginFailure, arg.KeyType, arg.Key)
+	var i LoginFailure
+	err := row.Scan(
+		&i.KeyType,
+		&i.Key,
+		&i.Failures,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const listLoginLocks = `-- name: ListLoginLocks :many
+SELECT key_type, key, failures, last_failed_at, locked_until
+FROM login_failures
+WHERE ((key_type = 'username' AND key = $1) OR (key_type = 'ip' AND key = $2))
+  AND locked_until > now()
+ORDER BY locked_until DESC
+`
+
+type ListLoginLocksParams struct {
+	Username string `json:"username"`
+	ClientIp string `json:"client_ip"`
+}
+
+func (q *Queries) ListLoginLocks(ctx context.Context, arg ListLoginLocksParams) ([]LoginFailure, error) {
+	rows, err := q.db.QueryContext(ctx, listLoginLocks, arg.Username, arg.ClientIp)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LoginFailure{}
+	for rows.Next() {
+		var i LoginFailure
+		if err := rows.Scan(
+			&i.KeyType,
+			&i.Key,
+			&i.Failures,
+			&i.LastFailedAt,
+			&i.LockedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordLoginFailure = `-- name: RecordLoginFailure :one
+INSERT INTO login_failures (key_type, key, failures, last_failed_at, locked_until)
+VALUES ($1, $2, 1, now(), now())
+ON CONFLICT (key_type, key) DO UPDATE
+    SET failures       = CASE
+                             WHEN login_failures.last_failed_at <
+                                  now() - make_interval(secs => $3::float8) THEN 1
+                             ELSE login_failures.failures + 1
+        END,
+        last_failed_at = EXCLUDED.last_failed_at
+RETURNING key_type, key, failures, last_failed_at, locked_until
+`
+
+type RecordLoginFailureParams struct {
+	KeyType           string  `json:"key_type"`
+	Key               string  `json:"key"`
+	ResetAfterSeconds float64 `json:"reset_after_seconds"`
+}
+
+func (q *Queries) RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, recordLoginFailure, arg.KeyType, arg.Key, arg.ResetAfterSeconds)
+	var i LoginFailure
+	err := row.Scan(
+		&i.KeyType,
+		&i.Key,
+		&i.Failures,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const setLoginBackoff = `-- name: SetLoginBackoff :one
+UPDATE login_failures
+SET locked_until = last_failed_at + make_interval(secs => $3::float8)
+WHERE key_type = $1
+  AND key = $2
+RETURNING key_type, key, failures, last_failed_at, locked_until
+`
+
+type SetLoginBackoffParams struct {
+	KeyType        string  `json:"key_type"`
+	Key            string  `json:"key"`
+	BackoffSeconds float64 `json:"backoff_seconds"`
+}
+
+func (q *Queries) SetLoginBackoff(ctx context.Context, arg SetLoginBackoffParams) (LoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, setLoginBackoff, arg.KeyType, arg.Key, arg.BackoffSeconds)
+	var i LoginFailure
+	err := row.Scan(
+		&i.KeyType,
+		&i.Key,
+		&i.Failures,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
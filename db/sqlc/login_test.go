@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRecordLoginFailureTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	// unknown usernames are tracked the same way as existing ones
+	username := utils.RandomOwner() + utils.RandomString(6)
+	clientIP := "198.51.100." + utils.RandomString(3)
+
+	arg := RecordLoginFailureTxParams{
+		Username:          username,
+		ClientIP:          clientIP,
+		UsernameThreshold: 3,
+		IPThreshold:       10,
+		LockoutDuration:   time.Hour,
+	}
+
+	// the first failure is not delayed
+	failures, err := store.RecordLoginFailureTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, failures, 2)
+	require.Equal(t, LoginKeyUsername, failures[0].KeyType)
+	require.Equal(t, username, failures[0].Key)
+	require.Equal(t, LoginKeyIP, failures[1].KeyType)
+	require.Equal(t, clientIP, failures[1].Key)
+	for _, failure := range failures {
+		require.Equal(t, int32(1), failure.Failures)
+		require.True(t, failure.LockedUntil.Equal(failure.LastFailedAt))
+	}
+
+	locks, err := store.ListLoginLocks(context.Background(), ListLoginLocksParams{
+		Username: username,
+		ClientIp: clientIP,
+	})
+	require.NoError(t, err)
+	require.Empty(t, locks)
+
+	// the next one backs off
+	failures, err = store.RecordLoginFailureTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), failures[0].Failures)
+	require.Equal(t, time.Second, failures[0].LockedUntil.Sub(failures[0].LastFailedAt))
+
+	locks, err = store.ListLoginLocks(context.Background(), ListLoginLocksParams{
+		Username: username,
+		ClientIp: clientIP,
+	})
+	require.NoError(t, err)
+	require.Len(t, locks, 2)
+
+	// and the threshold locks the username, but not the IP
+	failures, err = store.RecordLoginFailureTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), failures[0].Failures)
+	require.Equal(t, time.Hour, failures[0].LockedUntil.Sub(failures[0].LastFailedAt))
+	require.Equal(t, int32(3), failures[1].Failures)
+	require.Equal(t, 2*time.Second, failures[1].LockedUntil.Sub(failures[1].LastFailedAt))
+}
+
+func TestRecordLoginFailureTxWithoutClientIP(t *testing.T) {
+	store := NewStore(testDB)
+
+	failures, err := store.RecordLoginFailureTx(context.Background(), RecordLoginFailureTxParams{
+		Username:          utils.RandomOwner() + utils.RandomString(6),
+		UsernameThreshold: 3,
+		LockoutDuration:   time.Hour,
+	})
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	require.Equal(t, LoginKeyUsername, failures[0].KeyType)
+}
+
+func TestAdminUnlockUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	user := createRandomUser(t)
+
+	for i := 0; i < 3; i++ {
+		_, err := store.RecordLoginFailureTx(context.Background(), RecordLoginFailureTxParams{
+			Username:          user.Username,
+			UsernameThreshold: 3,
+			LockoutDuration:   time.Hour,
+		})
+		require.NoError(t, err)
+	}
+
+	locks, err := store.ListLoginLocks(context.Background(), ListLoginLocksParams{Username: user.Username})
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+
+	err = store.AdminUnlockUserTx(context.Background(), AdminUnlockUserTxParams{
+		AdminUsername: admin.Username,
+		Username:      user.Username,
+	})
+	require.NoError(t, err)
+
+	locks, err = store.ListLoginLocks(context.Background(), ListLoginLocksParams{Username: user.Username})
+	require.NoError(t, err)
+	require.Empty(t, locks)
+
+	actions, err := testQueries.ListAdminActionsByTarget(context.Background(), ListAdminActionsByTargetParams{
+		TargetType: AdminTargetUser,
+		TargetID:   user.Username,
+	})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	require.Equal(t, AdminActionUnlockUser, actions[0].Action)
+	require.Equal(t, admin.Username, actions[0].AdminUsername)
+}
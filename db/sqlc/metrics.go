@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/aalug/bank-go/metrics"
+)
+
+// transferFailureReasons maps the errors of the transfer transactions
+// to the reason label of the failed transfers metric
+var transferFailureReasons = []struct {
+	err    error
+	reason string
+}{
+	{ErrInsufficientFunds, "insufficient_funds"},
+	{ErrAccountFrozen, "account_frozen"},
+	{ErrSystemAccount, "system_account"},
+	{ErrIdempotencyKeyReused, "idempotency_key_reused"},
+	{ErrFXQuoteExpired, "quote_expired"},
+	{ErrFXQuoteUsed, "quote_used"},
+	{ErrFXQuoteMismatch, "quote_mismatch"},
+	{ErrHoldNotAuthorized, "hold_not_authorized"},
+	{ErrHoldExpired, "hold_expired"},
+	{ErrCaptureExceedsHold, "capture_exceeds_hold"},
+	{ErrTransferIsReversal, "transfer_is_reversal"},
+	{ErrTransferAlreadyReversed, "transfer_already_reversed"},
+	{ErrReversalExceedsTransfer, "reversal_exceeds_transfer"},
+	{ErrFXTransferNotReversible, "fx_transfer_not_reversible"},
+	{sql.ErrNoRows, "not_found"},
+	{context.Canceled, "canceled"},
+	{context.DeadlineExceeded, "deadline_exceeded"},
+}
+
+// transferFailureReason returns the reason label of a transfer that failed with err
+func transferFailureReason(err error) string {
+	for _, r := range transferFailureReasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+
+	if IsRetryableTxError(err) {
+		return "retries_exhausted"
+	}
+
+	return "internal"
+}
+
+// observeTransfer records the outcome of a transfer transaction of the given kind
+func observeTransfer(kind string, result TransferTxResult, err error) {
+	if err != nil {
+		metrics.TransferFailed(kind, transferFailureReason(err))
+		return
+	}
+
+	metrics.TransferCreated(kind, result.FromAccount.Currency, result.Transfer.Amount)
+}
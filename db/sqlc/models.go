@@ -5,17 +5,92 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type APIKey struct {
+	ID int64 `json:"id"`
+	// public part of the key that it is looked up by
+	Prefix string `json:"prefix"`
+	// sha256 of the secret part of the key
+	HashedSecret string `json:"hashed_secret"`
+	Owner        string `json:"owner"`
+	Name         string `json:"name"`
+	// what the key can be used for, for example accounts:read or transfers:write
+	Scopes []string `json:"scopes"`
+	// accounts of the owner the key can act on, empty for all of them
+	AccountIds []int64 `json:"account_ids"`
+	// the owner or an admin
+	CreatedBy  string     `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIp string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
 type Account struct {
 	ID        int64     `json:"id"`
 	Owner     string    `json:"owner"`
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// sum of authorized holds on the account
+	HeldAmount int64 `json:"held_amount"`
+	// balance less the held amount
+	AvailableBalance int64 `json:"available_balance"`
+	// no money can leave a frozen account
+	IsFrozen bool `json:"is_frozen"`
+}
+
+type AdminAction struct {
+	ID            int64     `json:"id"`
+	AdminUsername string    `json:"admin_username"`
+	Action        string    `json:"action"`
+	TargetType    string    `json:"target_type"`
+	TargetID      string    `json:"target_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// user that made the change, system for background jobs
+	Actor      string `json:"actor"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	// state of the target before the change, null if it was created
+	Before json.RawMessage `json:"before"`
+	// state of the target after the change, null if it was deleted
+	After     json.RawMessage `json:"after"`
+	RequestID string          `json:"request_id"`
+	ClientIp  string          `json:"client_ip"`
+	UserAgent string          `json:"user_agent"`
+	// hash of the previous event, empty for the first one
+	PrevHash string `json:"prev_hash"`
+	// sha256 of the event and prev_hash, any change to a past event breaks the chain
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type EmailToken struct {
+	ID int64 `json:"id"`
+	// sha256 of the token sent in the email
+	HashedToken string `json:"hashed_token"`
+	Username    string `json:"username"`
+	// verify_email or reset_password
+	Purpose string `json:"purpose"`
+	// address the token was sent to
+	Email     string       `json:"email"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
 }
 
 type Entry struct {
@@ -24,6 +99,177 @@ type Entry struct {
 	// can be positive or negative
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// transfer that produced the entry, if any
+	TransferID *int64 `json:"transfer_id"`
+	// operation that produced the entry, e.g. transfer, deposit or withdrawal
+	Kind string `json:"kind"`
+}
+
+type FXQuote struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	// mid-market units of to_currency for one unit of from_currency
+	Rate string `json:"rate"`
+	// fraction of the converted amount kept by the bank
+	Spread    string    `json:"spread"`
+	IsUsed    bool      `json:"is_used"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FXRate struct {
+	Currency string `json:"currency"`
+	// units of the currency for one euro, as published by the ECB
+	Rate        string    `json:"rate"`
+	PublishedOn time.Time `json:"published_on"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Hold struct {
+	ID int64 `json:"id"`
+	// account whose funds are reserved
+	AccountID int64 `json:"account_id"`
+	// account that receives the money when the hold is captured
+	ToAccountID int64 `json:"to_account_id"`
+	Amount      int64 `json:"amount"`
+	// authorized, captured, voided or expired
+	Status         string `json:"status"`
+	CapturedAmount int64  `json:"captured_amount"`
+	// transfer created by the capture
+	TransferID *int64    `json:"transfer_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Username string `json:"username"`
+	Key      string `json:"key"`
+	// sha256 of the request the key was first used with
+	RequestHash string `json:"request_hash"`
+	// serialized result returned on replay
+	Response  json.RawMessage `json:"response"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type LoginFailure struct {
+	// username or ip
+	KeyType string `json:"key_type"`
+	// username or client ip, unknown usernames are tracked too
+	Key string `json:"key"`
+	// failed logins in a row, reset once the lockout duration passes without a failure
+	Failures     int32     `json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+	// no login attempts are checked before this time
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+type MFAChallenge struct {
+	ID uuid.UUID `json:"id"`
+	// sha256 of the challenge token returned by the first login step
+	HashedToken string `json:"hashed_token"`
+	Username    string `json:"username"`
+	// failed attempts at the second login step
+	Attempts  int32      `json:"attempts"`
+	ClientIp  string     `json:"client_ip"`
+	UserAgent string     `json:"user_agent"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type OAuthAuthorizationCode struct {
+	ID int64 `json:"id"`
+	// sha256 of the code sent to the redirect URI
+	HashedCode  string   `json:"hashed_code"`
+	ClientID    string   `json:"client_id"`
+	Username    string   `json:"username"`
+	RedirectUri string   `json:"redirect_uri"`
+	Scopes      []string `json:"scopes"`
+	// S256 PKCE challenge the code verifier is checked against
+	CodeChallenge string     `json:"code_challenge"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	UsedAt        *time.Time `json:"used_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type OAuthClient struct {
+	// public client_id of the app
+	ID string `json:"id"`
+	// sha256 of the client secret, empty for public clients
+	HashedSecret string `json:"hashed_secret"`
+	Name         string `json:"name"`
+	// user that registered the app
+	Owner        string   `json:"owner"`
+	RedirectUris []string `json:"redirect_uris"`
+	// the most the app can ask users for
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OAuthConsent struct {
+	Username string `json:"username"`
+	ClientID string `json:"client_id"`
+	// scopes the user has granted to the app
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type OAuthRefreshToken struct {
+	ID int64 `json:"id"`
+	// sha256 of the refresh token
+	HashedToken string     `json:"hashed_token"`
+	ClientID    string     `json:"client_id"`
+	Username    string     `json:"username"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type RevokedToken struct {
+	// id of the token payload
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	// expiry of the token, the row can be deleted afterwards
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	// once, daily, weekly or monthly
+	Recurrence string `json:"recurrence"`
+	// first run, later runs keep its time and day of the month
+	StartAt   time.Time `json:"start_at"`
+	NextRunAt time.Time `json:"next_run_at"`
+	// active, suspended after repeated failures, or completed
+	Status string `json:"status"`
+	// failed runs in a row
+	FailureCount int32 `json:"failure_count"`
+	// set while a worker executes the transfer
+	ClaimedUntil *time.Time `json:"claimed_until"`
+	CreatedAt    time.Time  `json:"created_at"`
+	// API key the transfer was scheduled with, its limits are checked before every run
+	APIKeyID *int64 `json:"api_key_id"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64 `json:"id"`
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+	// next_run_at of the scheduled transfer at the time of the run
+	ScheduledFor time.Time `json:"scheduled_for"`
+	TransferID   *int64    `json:"transfer_id"`
+	Succeeded    bool      `json:"succeeded"`
+	ErrorMessage string    `json:"error_message"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type Session struct {
@@ -35,6 +281,21 @@ type Session struct {
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	CreatedAt    time.Time `json:"created_at"`
+	// id of the session created at login, shared by all sessions rotated from it
+	FamilyID uuid.UUID `json:"family_id"`
+	// session whose refresh token was exchanged for this one
+	ParentID *uuid.UUID `json:"parent_id"`
+	// set once the refresh token has been exchanged, presenting it again means it was reused
+	RotatedAt *time.Time `json:"rotated_at"`
+}
+
+type TotpRecoveryCode struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// sha256 of the normalized one-time recovery code
+	HashedCode string     `json:"hashed_code"`
+	UsedAt     *time.Time `json:"used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
 }
 
 type Transfer struct {
@@ -44,6 +305,17 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// amount credited in the currency of to_account, set only for cross-currency transfers
+	ToAmount  *int64     `json:"to_amount"`
+	FXQuoteID *uuid.UUID `json:"fx_quote_id"`
+	// rate of the quote used for a cross-currency transfer
+	FXRate *string `json:"fx_rate"`
+	// spread of the quote used for a cross-currency transfer
+	FXSpread *string `json:"fx_spread"`
+	// transfer this one reverses, set only for reversals and refunds
+	ReversedTransferID *int64 `json:"reversed_transfer_id"`
+	// sum of the reversals of this transfer
+	RefundedAmount int64 `json:"refunded_amount"`
 }
 
 type User struct {
@@ -53,4 +325,24 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Role              string    `json:"role"`
+	// users can transfer money only after verifying their email address
+	IsEmailVerified bool `json:"is_email_verified"`
+}
+
+type UserTOTP struct {
+	Username string `json:"username"`
+	Secret   string `json:"secret"`
+	// two-factor authentication is enabled once the enrollment is confirmed with a code
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	// time step of the last accepted code, codes of this or earlier steps are refused
+	LastUsedStep int64     `json:"last_used_step"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type UserTokenRevocation struct {
+	Username string `json:"username"`
+	// tokens of the user issued before this time are revoked
+	IssuedBefore time.Time `json:"issued_before"`
+	CreatedAt    time.Time `json:"created_at"`
 }
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// AuthorizeOAuthClientTx records the consent of the user to the scopes of the code
+// and creates the authorization code the client exchanges for tokens
+func (store *SQLStore) AuthorizeOAuthClientTx(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) (OAuthAuthorizationCode, error) {
+	var result OAuthAuthorizationCode
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var before any
+		consent, err := q.GetOAuthConsent(ctx, GetOAuthConsentParams{
+			Username: arg.Username,
+			ClientID: arg.ClientID,
+		})
+		if err == nil {
+			before = consent
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+
+		consent, err = q.UpsertOAuthConsent(ctx, UpsertOAuthConsentParams{
+			Username: arg.Username,
+			ClientID: arg.ClientID,
+			Scopes:   arg.Scopes,
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = q.CreateOAuthAuthorizationCode(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionGrantOAuthConsent,
+			TargetType: AuditTargetOAuthConsent,
+			TargetID:   oauthConsentTargetID(arg.Username, arg.ClientID),
+			Before:     before,
+			After:      consent,
+		})
+	})
+
+	return result, err
+}
+
+// ExchangeOAuthCodeTxParams contains the parameters of the exchange OAuth code transaction.
+type ExchangeOAuthCodeTxParams struct {
+	CodeID             int64     `json:"code_id"`
+	HashedRefreshToken string    `json:"hashed_refresh_token"`
+	ExpiresAt          time.Time `json:"expires_at"`
+}
+
+// ExchangeOAuthCodeTx uses an authorization code and creates a refresh token
+// with the client, the user and the scopes of the code.
+// A code that was already used returns ErrOAuthCodeUsed.
+func (store *SQLStore) ExchangeOAuthCodeTx(ctx context.Context, arg ExchangeOAuthCodeTxParams) (OAuthRefreshToken, error) {
+	var result OAuthRefreshToken
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		code, err := q.UseOAuthAuthorizationCode(ctx, arg.CodeID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrOAuthCodeUsed
+			}
+			return err
+		}
+
+		result, err = q.CreateOAuthRefreshToken(ctx, CreateOAuthRefreshTokenParams{
+			HashedToken: arg.HashedRefreshToken,
+			ClientID:    code.ClientID,
+			Username:    code.Username,
+			Scopes:      code.Scopes,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// RotateOAuthRefreshTokenTxParams contains the parameters of the rotate OAuth refresh token transaction.
+type RotateOAuthRefreshTokenTxParams struct {
+	ID             int64     `json:"id"`
+	NewHashedToken string    `json:"new_hashed_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// RotateOAuthRefreshTokenTx revokes a refresh token and creates its successor with the same client,
+// user and scopes. If the token was already revoked, it was used twice, so either the client
+// or an attacker holds a stolen copy. In that case every refresh token the user gave the client
+// is revoked and ErrOAuthRefreshTokenReused is returned.
+func (store *SQLStore) RotateOAuthRefreshTokenTx(ctx context.Context, arg RotateOAuthRefreshTokenTxParams) (OAuthRefreshToken, error) {
+	var result OAuthRefreshToken
+	var reused bool
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		reused = false
+
+		// concurrent rotations of the same token wait here
+		refreshToken, err := q.GetOAuthRefreshTokenForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if refreshToken.RevokedAt != nil {
+			// the tokens have to be revoked even though an error is returned,
+			// so the transaction is committed and the error reported after it
+			reused = true
+			_, err = q.RevokeOAuthRefreshTokens(ctx, RevokeOAuthRefreshTokensParams{
+				Username: refreshToken.Username,
+				ClientID: refreshToken.ClientID,
+			})
+			return err
+		}
+
+		_, err = q.RevokeOAuthRefreshToken(ctx, refreshToken.ID)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.CreateOAuthRefreshToken(ctx, CreateOAuthRefreshTokenParams{
+			HashedToken: arg.NewHashedToken,
+			ClientID:    refreshToken.ClientID,
+			Username:    refreshToken.Username,
+			Scopes:      refreshToken.Scopes,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if reused {
+		return OAuthRefreshToken{}, ErrOAuthRefreshTokenReused
+	}
+
+	return result, nil
+}
+
+// RevokeOAuthConsentTx deletes the consent of the user to the client
+// and revokes the refresh tokens the user gave it.
+// It returns sql.ErrNoRows if the user has not consented to the client.
+func (store *SQLStore) RevokeOAuthConsentTx(ctx context.Context, arg DeleteOAuthConsentParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		consent, err := q.DeleteOAuthConsent(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.RevokeOAuthRefreshTokens(ctx, RevokeOAuthRefreshTokensParams{
+			Username: arg.Username,
+			ClientID: arg.ClientID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionRevokeOAuthConsent,
+			TargetType: AuditTargetOAuthConsent,
+			TargetID:   oauthConsentTargetID(arg.Username, arg.ClientID),
+			Before:     consent,
+		})
+	})
+}
+
+// CreateOAuthClientTx registers an OAuth client and records it in the audit log
+func (store *SQLStore) CreateOAuthClientTx(ctx context.Context, arg CreateOAuthClientParams) (OAuthClient, error) {
+	var result OAuthClient
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result, err = q.CreateOAuthClient(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionCreateOAuthClient,
+			TargetType: AuditTargetOAuthClient,
+			TargetID:   result.ID,
+			After:      result,
+		})
+	})
+
+	return result, err
+}
+
+// oauthConsentTargetID returns the ID of a consent in the audit log, it is made of the user and the client
+func oauthConsentTargetID(username, clientID string) string {
+	return username + "/" + clientID
+}
@@ -0,0 +1,475 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.18.0
+// source: oauth.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const createOAuthAuthorizationCode = `-- name: CreateOAuthAuthorizationCode :one
+INSERT INTO oauth_authorization_codes (hashed_code,
+                                       client_id,
+                                       username,
+                                       redirect_uri,
+                                       scopes,
+                                       code_challenge,
+                                       expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, hashed_code, client_id, username, redirect_uri, scopes, code_challenge, expires_at, used_at, created_at
+`
+
+type CreateOAuthAuthorizationCodeParams struct {
+	HashedCode    string    `json:"hashed_code"`
+	ClientID      string    `json:"client_id"`
+	Username      string    `json:"username"`
+	RedirectUri   string    `json:"redirect_uri"`
+	Scopes        []string  `json:"scopes"`
+	CodeChallenge string    `json:"code_challenge"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateOAuthAuthorizationCode(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) (OAuthAuthorizationCode, error) {
+	row := q.db.QueryRowContext(ctx, createOAuthAuthorizationCode,
+		arg.HashedCode,
+		arg.ClientID,
+		arg.Username,
+		arg.RedirectUri,
+		pq.Array(arg.Scopes),
+		arg.CodeChallenge,
+		arg.ExpiresAt,
+	)
+	var i OAuthAuthorizationCode
+	err := row.Scan(
+		&i.ID,
+		&i.HashedCode,
+		&i.ClientID,
+		&i.Username,
+		&i.RedirectUri,
+		pq.Array(&i.Scopes),
+		&i.CodeChallenge,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createOAuthClient = `-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (id,
+                           hashed_secret,
+                           name,
+                           owner,
+                           redirect_uris,
+                           scopes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, hashed_secret, name, owner, redirect_uris, scopes, created_at
+`
+
+type CreateOAuthClientParams struct {
+	ID           string   `json:"id"`
+	HashedSecret string   `json:"hashed_secret"`
+	Name         string   `json:"name"`
+	Owner        string   `json:"owner"`
+	RedirectUris []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes"`
+}
+
+func (q *Queries) CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OAuthClient, error) {
+	row := q.db.QueryRowContext(ctx, createOAuthClient,
+		arg.ID,
+		arg.HashedSecret,
+		arg.Name,
+		arg.Owner,
+		pq.Array(arg.RedirectUris),
+		pq.Array(arg.Scopes),
+	)
+	var i OAuthClient
+	err := row.Scan(
+		&i.ID,
+		&i.HashedSecret,
+		&i.Name,
+		&i.Owner,
+		pq.Array(&i.RedirectUris),
+		pq.Array(&i.Scopes),
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createOAuthRefreshToken = `-- name: CreateOAuthRefreshToken :one
+INSERT INTO oauth_refresh_tokens (hashed_token,
+                                  client_id,
+                                  username,
+                                  scopes,
+                                  expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, hashed_token, client_id, username, scopes, expires_at, revoked_at, created_at
+`
+
+type CreateOAuthRefreshTokenParams struct {
+	HashedToken string    `json:"hashed_token"`
+	ClientID    string    `json:"client_id"`
+	Username    string    `json:"username"`
+	Scopes      []string  `json:"scopes"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateOAuthRefreshToken(ctx context.Context, arg CreateOAuthRefreshTokenParams) (OAuthRefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, createOAuthRefreshToken,
+		arg.HashedToken,
+		arg.ClientID,
+		arg.Username,
+		pq.Array(arg.Scopes),
+		arg.ExpiresAt,
+	)
+	var i OAuthRefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.HashedToken,
+		&i.ClientID,
+		&i.Username,
+		pq.Array(&i.Scopes),
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteOAuthConsent = `-- name: DeleteOAuthConsent :one
+DELETE
+FROM oauth_consents
+WHERE username = $1
+  AND client_id = $2
+RETURNING username, client_id, scopes, created_at, updated_at
+`
+
+type DeleteOAuthConsentParams struct {
+	Username string `json:"username"`
+	ClientID string `json:"client_id"`
+}
+
+func (q *Queries) DeleteOAuthConsent(ctx context.Context, arg DeleteOAuthConsentParams) (OAuthConsent, error) {
+	row := q.db.QueryRowContext(ctx, deleteOAuthConsent, arg.Username, arg.ClientID)
+	var i OAuthConsent
+	err := row.Scan(
+		&i.Username,
+		&i.ClientID,
+		pq.Array(&i.Scopes),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getOAuthAuthorizationCode = `-- name: GetOAuthAuthorizationCode :one
+SELECT id, hashed_code, client_id, username, redirect_uri, scopes, code_challenge, expires_at, used_at, created_at
+FROM oauth_authorization_codes
+WHERE hashed_code = $1
+LIMIT 1
+`
+
+func (q *Queries) GetOAuthAuthorizationCode(ctx context.Context, hashedCode string) (OAuthAuthorizationCode, error) {
+	row := q.db.QueryRowContext(ctx, getOAuthAuthorizationCode, hashedCode)
+	var i OAuthAuthorizationCode
+	err := row.Scan(
+		&i.ID,
+		&i.HashedCode,
+		&i.ClientID,
+		&i.Username,
+		&i.RedirectUri,
+		pq.Array(&i.Scopes),
+		&i.CodeChallenge,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOAuthClient = `-- name: GetOAuthClient :one
+SELECT id, hashed_secret, name, owner, redirect_uris, scopes, created_at
+FROM oauth_clients
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetOAuthClient(ctx context.Context, id string) (OAuthClient, error) {
+	row := q.db.QueryRowContext(ctx, getOAuthClient, id)
+	var i OAuthClient
+	err := row.Scan(
+		&i.ID,
+		&i.HashedSecret,
+		&i.Name,
+		&i.Owner,
+		pq.Array(&i.RedirectUris),
+		pq.Array(&i.Scopes),
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOAuthConsent = `-- name: GetOAuthConsent :one
+SELECT username, client_id, scopes, created_at, updated_at
+FROM oauth_consents
+WHERE username = $1
+  AND client_id = $2
+LIMIT 1
+`
+
+type GetOAuthConsentParams struct {
+	Username string `json:"username"`
+	ClientID string `json:"client_id"`
+}
+
+func (q *Queries) GetOAuthConsent(ctx context.Context, arg GetOAuthConsentParams) (OAuthConsent, error) {
+	row := q.db.QueryRowContext(ctx, getOAuthConsent, arg.Username, arg.ClientID)
+	var i OAuthConsent
+	err := row.Scan(
+		&i.Username,
+		&i.ClientID,
+		pq.Array(&i.Scopes),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getOAuthRefreshToken = `-- name: GetOAuthRefreshToken :one
+SELECT id, hashed_token, client_id, username, scopes, expires_at, revoked_at, created_at
+FROM oauth_refresh_tokens
+WHERE hashed_token = $1
+LIMIT 1
+`
+
+func (q *Queries) GetOAuthRefreshToken(ctx context.Context, hashedToken string) (OAuthRefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getOAuthRefreshToken, hashedToken)
+	var i OAuthRefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.HashedToken,
+		&i.ClientID,
+		&i.Username,
+		pq.Array(&i.Scopes),
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOAuthRefreshTokenForUpdate = `-- name: GetOAuthRefreshTokenForUpdate :one
+SELECT id, hashed_token, client_id, username, scopes, expires_at, revoked_at, created_at
+FROM oauth_refresh_tokens
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetOAuthRefreshTokenForUpdate(ctx context.Context, id int64) (OAuthRefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getOAuthRefreshTokenForUpdate, id)
+	var i OAuthRefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.HashedToken,
+		&i.ClientID,
+		&i.Username,
+		pq.Array(&i.Scopes),
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listOAuthClients = `-- name: ListOAuthClients :many
+SELECT id, hashed_secret, name, owner, redirect_uris, scopes, created_at
+FROM oauth_clients
+WHERE owner = $1
+ORDER BY created_at
+LIMIT $2 OFFSET $3
+`
+
+type ListOAuthClientsParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListOAuthClients(ctx context.Context, arg ListOAuthClientsParams) ([]OAuthClient, error) {
+	rows, err := q.db.QueryContext(ctx, listOAuthClients, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OAuthClient{}
+	for rows.Next() {
+		var i OAuthClient
+		if err := rows.Scan(
+			&i.ID,
+			&i.HashedSecret,
+			&i.Name,
+			&i.Owner,
+			pq.Array(&i.RedirectUris),
+			pq.Array(&i.Scopes),
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOAuthConsents = `-- name: ListOAuthConsents :many
+SELECT username, client_id, scopes, created_at, updated_at
+FROM oauth_consents
+WHERE username = $1
+ORDER BY created_at
+LIMIT $2 OFFSET $3
+`
+
+type ListOAuthConsentsParams struct {
+	Username string `json:"username"`
+	Limit    int32  `json:"limit"`
+	Offset   int32  `json:"offset"`
+}
+
+func (q *Queries) ListOAuthConsents(ctx context.Context, arg ListOAuthConsentsParams) ([]OAuthConsent, error) {
+	rows, err := q.db.QueryContext(ctx, listOAuthConsents, arg.Username, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OAuthConsent{}
+	for rows.Next() {
+		var i OAuthConsent
+		if err := rows.Scan(
+			&i.Username,
+			&i.ClientID,
+			pq.Array(&i.Scopes),
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeOAuthRefreshToken = `-- name: RevokeOAuthRefreshToken :one
+UPDATE oauth_refresh_tokens
+SET revoked_at = now()
+WHERE id = $1
+  AND revoked_at IS NULL
+RETURNING id, hashed_token, client_id, username, scopes, expires_at, revoked_at, created_at
+`
+
+func (q *Queries) RevokeOAuthRefreshToken(ctx context.Context, id int64) (OAuthRefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, revokeOAuthRefreshToken, id)
+	var i OAuthRefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.HashedToken,
+		&i.ClientID,
+		&i.Username,
+		pq.Array(&i.Scopes),
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const revokeOAuthRefreshTokens = `-- name: RevokeOAuthRefreshTokens :execrows
+UPDATE oauth_refresh_tokens
+SET revoked_at = now()
+WHERE username = $1
+  AND client_id = $2
+  AND revoked_at IS NULL
+`
+
+type RevokeOAuthRefreshTokensParams struct {
+	Username string `json:"username"`
+	ClientID string `json:"client_id"`
+}
+
+func (q *Queries) RevokeOAuthRefreshTokens(ctx context.Context, arg RevokeOAuthRefreshTokensParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeOAuthRefreshTokens, arg.Username, arg.ClientID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertOAuthConsent = `-- name: UpsertOAuthConsent :one
+INSERT INTO oauth_consents (username, client_id, scopes)
+VALUES ($1, $2, $3)
+ON CONFLICT (username, client_id) DO UPDATE
+    SET scopes     = ARRAY(SELECT DISTINCT unnest(oauth_consents.scopes || EXCLUDED.scopes) ORDER BY 1),
+        updated_at = now()
+RETURNING username, client_id, scopes, created_at, updated_at
+`
+
+type UpsertOAuthConsentParams struct {
+	Username string   `json:"username"`
+	ClientID string   `json:"client_id"`
+	Scopes   []string `json:"scopes"`
+}
+
+func (q *Queries) UpsertOAuthConsent(ctx context.Context, arg UpsertOAuthConsentParams) (OAuthConsent, error) {
+	row := q.db.QueryRowContext(ctx, upsertOAuthConsent, arg.Username, arg.ClientID, pq.Array(arg.Scopes))
+	var i OAuthConsent
+	err := row.Scan(
+		&i.Username,
+		&i.ClientID,
+		pq.Array(&i.Scopes),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const useOAuthAuthorizationCode = `-- name: UseOAuthAuthorizationCode :one
+UPDATE oauth_authorization_codes
+SET used_at = now()
+WHERE id = $1
+  AND used_at IS NULL
+RETURNING id, hashed_code, client_id, username, redirect_uri, scopes, code_challenge, expires_at, used_at, created_at
+`
+
+func (q *Queries) UseOAuthAuthorizationCode(ctx context.Context, id int64) (OAuthAuthorizationCode, error) {
+	row := q.db.QueryRowContext(ctx, useOAuthAuthorizationCode, id)
+	var i OAuthAuthorizationCode
+	err := row.Scan(
+		&i.ID,
+		&i.HashedCode,
+		&i.ClientID,
+		&i.Username,
+		&i.RedirectUri,
+		pq.Array(&i.Scopes),
+		&i.CodeChallenge,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
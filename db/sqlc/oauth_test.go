@@ -0,0 +1,179 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomOAuthClient(t *testing.T, owner string) OAuthClient {
+	arg := CreateOAuthClientParams{
+		ID:           utils.RandomString(16),
+		HashedSecret: utils.HashToken(utils.RandomString(32)),
+		Name:         utils.RandomString(8),
+		Owner:        owner,
+		RedirectUris: []string{"https://example.com/callback"},
+		Scopes:       []string{"accounts:read", "transfers:write"},
+	}
+
+	client, err := testQueries.CreateOAuthClient(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, client.ID)
+	require.Equal(t, arg.HashedSecret, client.HashedSecret)
+	require.Equal(t, arg.Owner, client.Owner)
+	require.Equal(t, arg.RedirectUris, client.RedirectUris)
+	require.Equal(t, arg.Scopes, client.Scopes)
+
+	return client
+}
+
+func createRandomOAuthCode(t *testing.T, store Store, client OAuthClient, username string, scopes []string) OAuthAuthorizationCode {
+	arg := CreateOAuthAuthorizationCodeParams{
+		HashedCode:    utils.HashToken(utils.RandomString(32)),
+		ClientID:      client.ID,
+		Username:      username,
+		RedirectUri:   client.RedirectUris[0],
+		Scopes:        scopes,
+		CodeChallenge: utils.RandomString(43),
+		ExpiresAt:     time.Now().Add(time.Minute),
+	}
+
+	code, err := store.AuthorizeOAuthClientTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, code.ID)
+	require.Equal(t, arg.HashedCode, code.HashedCode)
+	require.Equal(t, arg.Scopes, code.Scopes)
+	require.Nil(t, code.UsedAt)
+
+	return code
+}
+
+func TestListOAuthClients(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 2; i++ {
+		createRandomOAuthClient(t, user.Username)
+	}
+	createRandomOAuthClient(t, createRandomUser(t).Username)
+
+	clients, err := testQueries.ListOAuthClients(context.Background(), ListOAuthClientsParams{
+		Owner:  user.Username,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, clients, 2)
+}
+
+func TestAuthorizeOAuthClientTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t, createRandomUser(t).Username)
+
+	createRandomOAuthCode(t, store, client, user.Username, []string{"transfers:write"})
+	createRandomOAuthCode(t, store, client, user.Username, []string{"accounts:read", "transfers:write"})
+
+	// the consent grows with every authorization
+	consent, err := testQueries.GetOAuthConsent(context.Background(), GetOAuthConsentParams{
+		Username: user.Username,
+		ClientID: client.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"accounts:read", "transfers:write"}, consent.Scopes)
+}
+
+func TestExchangeOAuthCodeTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t, createRandomUser(t).Username)
+	code := createRandomOAuthCode(t, store, client, user.Username, []string{"accounts:read"})
+
+	arg := ExchangeOAuthCodeTxParams{
+		CodeID:             code.ID,
+		HashedRefreshToken: utils.HashToken(utils.RandomString(32)),
+		ExpiresAt:          time.Now().Add(time.Hour),
+	}
+	refreshToken, err := store.ExchangeOAuthCodeTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.HashedRefreshToken, refreshToken.HashedToken)
+	require.Equal(t, client.ID, refreshToken.ClientID)
+	require.Equal(t, user.Username, refreshToken.Username)
+	require.Equal(t, code.Scopes, refreshToken.Scopes)
+
+	// a code can be exchanged only once
+	arg.HashedRefreshToken = utils.HashToken(utils.RandomString(32))
+	_, err = store.ExchangeOAuthCodeTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrOAuthCodeUsed)
+}
+
+func TestRotateOAuthRefreshTokenTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t, createRandomUser(t).Username)
+	code := createRandomOAuthCode(t, store, client, user.Username, []string{"accounts:read"})
+
+	refreshToken, err := store.ExchangeOAuthCodeTx(context.Background(), ExchangeOAuthCodeTxParams{
+		CodeID:             code.ID,
+		HashedRefreshToken: utils.HashToken(utils.RandomString(32)),
+		ExpiresAt:          time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	rotated, err := store.RotateOAuthRefreshTokenTx(context.Background(), RotateOAuthRefreshTokenTxParams{
+		ID:             refreshToken.ID,
+		NewHashedToken: utils.HashToken(utils.RandomString(32)),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, refreshToken.ID, rotated.ID)
+	require.Equal(t, refreshToken.Scopes, rotated.Scopes)
+
+	old, err := testQueries.GetOAuthRefreshToken(context.Background(), refreshToken.HashedToken)
+	require.NoError(t, err)
+	require.NotNil(t, old.RevokedAt)
+
+	// the old token is reused, so its successor is revoked too
+	_, err = store.RotateOAuthRefreshTokenTx(context.Background(), RotateOAuthRefreshTokenTxParams{
+		ID:             refreshToken.ID,
+		NewHashedToken: utils.HashToken(utils.RandomString(32)),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrOAuthRefreshTokenReused)
+
+	rotated, err = testQueries.GetOAuthRefreshToken(context.Background(), rotated.HashedToken)
+	require.NoError(t, err)
+	require.NotNil(t, rotated.RevokedAt)
+}
+
+func TestRevokeOAuthConsentTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t, createRandomUser(t).Username)
+	code := createRandomOAuthCode(t, store, client, user.Username, []string{"accounts:read"})
+
+	refreshToken, err := store.ExchangeOAuthCodeTx(context.Background(), ExchangeOAuthCodeTxParams{
+		CodeID:             code.ID,
+		HashedRefreshToken: utils.HashToken(utils.RandomString(32)),
+		ExpiresAt:          time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	arg := DeleteOAuthConsentParams{
+		Username: user.Username,
+		ClientID: client.ID,
+	}
+	err = store.RevokeOAuthConsentTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetOAuthConsent(context.Background(), GetOAuthConsentParams(arg))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	refreshToken, err = testQueries.GetOAuthRefreshToken(context.Background(), refreshToken.HashedToken)
+	require.NoError(t, err)
+	require.NotNil(t, refreshToken.RevokedAt)
+
+	err = store.RevokeOAuthConsentTx(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -6,29 +6,137 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error)
+	AddTransferRefundedAmount(ctx context.Context, arg AddTransferRefundedAmountParams) (Transfer, error)
+	BlockSession(ctx context.Context, id uuid.UUID) (Session, error)
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error)
+	CaptureAccountHold(ctx context.Context, arg CaptureAccountHoldParams) (Account, error)
+	CaptureHold(ctx context.Context, arg CaptureHoldParams) (Hold, error)
+	ClaimDueScheduledTransfers(ctx context.Context, arg ClaimDueScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ConfirmUserTOTP(ctx context.Context, username string) (UserTOTP, error)
+	CountUnusedRecoveryCodes(ctx context.Context, username string) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAdminAction(ctx context.Context, arg CreateAdminActionParams) (AdminAction, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	CreateEmailToken(ctx context.Context, arg CreateEmailTokenParams) (EmailToken, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFXQuote(ctx context.Context, arg CreateFXQuoteParams) (FXQuote, error)
+	CreateFXTransfer(ctx context.Context, arg CreateFXTransferParams) (Transfer, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateMFAChallenge(ctx context.Context, arg CreateMFAChallengeParams) (MFAChallenge, error)
+	CreateOAuthAuthorizationCode(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) (OAuthAuthorizationCode, error)
+	CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OAuthClient, error)
+	CreateOAuthRefreshToken(ctx context.Context, arg CreateOAuthRefreshTokenParams) (OAuthRefreshToken, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (TotpRecoveryCode, error)
+	CreateReversalTransfer(ctx context.Context, arg CreateReversalTransferParams) (Transfer, error)
+	CreateRotatedSession(ctx context.Context, arg CreateRotatedSessionParams) (Session, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserTOTP(ctx context.Context, arg CreateUserTOTPParams) (UserTOTP, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) error
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+	DeleteLoginFailure(ctx context.Context, arg DeleteLoginFailureParams) error
+	DeleteOAuthConsent(ctx context.Context, arg DeleteOAuthConsentParams) (OAuthConsent, error)
+	DeleteRecoveryCodes(ctx context.Context, username string) error
+	DeleteScheduledTransfer(ctx context.Context, id int64) error
+	ExpireHolds(ctx context.Context, maxCount int32) ([]Hold, error)
+	FinishScheduledTransferRun(ctx context.Context, arg FinishScheduledTransferRunParams) (ScheduledTransfer, error)
+	GetAPIKey(ctx context.Context, id int64) (APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (GetAPIKeyByPrefixRow, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFXQuote(ctx context.Context, id uuid.UUID) (FXQuote, error)
+	GetFXQuoteForUpdate(ctx context.Context, id uuid.UUID) (FXQuote, error)
+	GetFXRate(ctx context.Context, currency string) (FXRate, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetLastAuditEventHash(ctx context.Context) (string, error)
+	GetLoginFailure(ctx context.Context, arg GetLoginFailureParams) (LoginFailure, error)
+	GetMFAChallengeByToken(ctx context.Context, hashedToken string) (MFAChallenge, error)
+	GetOAuthAuthorizationCode(ctx context.Context, hashedCode string) (OAuthAuthorizationCode, error)
+	GetOAuthClient(ctx context.Context, id string) (OAuthClient, error)
+	GetOAuthConsent(ctx context.Context, arg GetOAuthConsentParams) (OAuthConsent, error)
+	GetOAuthRefreshToken(ctx context.Context, hashedToken string) (OAuthRefreshToken, error)
+	GetOAuthRefreshTokenForUpdate(ctx context.Context, id int64) (OAuthRefreshToken, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSessionForUpdate(ctx context.Context, id uuid.UUID) (Session, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	GetUserTOTP(ctx context.Context, username string) (UserTOTP, error)
+	IncrementMFAChallengeAttempts(ctx context.Context, id uuid.UUID) (MFAChallenge, error)
+	InvalidateEmailTokens(ctx context.Context, arg InvalidateEmailTokensParams) error
+	ListAPIKeys(ctx context.Context, arg ListAPIKeysParams) ([]APIKey, error)
+	ListAccountBalanceDrifts(ctx context.Context) ([]ListAccountBalanceDriftsRow, error)
+	ListAccountStatement(ctx context.Context, arg ListAccountStatementParams) ([]ListAccountStatementRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListActiveSessions(ctx context.Context, arg ListActiveSessionsParams) ([]Session, error)
+	ListAdminActionsByTarget(ctx context.Context, arg ListAdminActionsByTargetParams) ([]AdminAction, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListAuditEventsAfter(ctx context.Context, arg ListAuditEventsAfterParams) ([]AuditEvent, error)
+	ListCurrencyTotals(ctx context.Context) ([]ListCurrencyTotalsRow, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListFXRates(ctx context.Context) ([]FXRate, error)
+	ListHeldAmountDrifts(ctx context.Context) ([]ListHeldAmountDriftsRow, error)
+	ListHolds(ctx context.Context, arg ListHoldsParams) ([]Hold, error)
+	ListLoginLocks(ctx context.Context, arg ListLoginLocksParams) ([]LoginFailure, error)
+	ListOAuthClients(ctx context.Context, arg ListOAuthClientsParams) ([]OAuthClient, error)
+	ListOAuthConsents(ctx context.Context, arg ListOAuthConsentsParams) ([]OAuthConsent, error)
+	ListRevokedTokens(ctx context.Context) ([]RevokedToken, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListTransferEntries(ctx context.Context, transferID *int64) ([]Entry, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUnbalancedTransfers(ctx context.Context) ([]ListUnbalancedTransfersRow, error)
+	ListUserTokenRevocations(ctx context.Context, issuedAfter time.Time) ([]UserTokenRevocation, error)
+	// held until the end of the transaction, so events are chained one at a time
+	LockAuditLog(ctx context.Context) error
+	MarkFXQuoteUsed(ctx context.Context, id uuid.UUID) (FXQuote, error)
+	MarkSessionRotated(ctx context.Context, id uuid.UUID) (Session, error)
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginFailure, error)
+	RevokeAPIKey(ctx context.Context, id int64) (APIKey, error)
+	RevokeOAuthRefreshToken(ctx context.Context, id int64) (OAuthRefreshToken, error)
+	RevokeOAuthRefreshTokens(ctx context.Context, arg RevokeOAuthRefreshTokensParams) (int64, error)
+	RevokeToken(ctx context.Context, arg RevokeTokenParams) error
+	RevokeUserTokens(ctx context.Context, arg RevokeUserTokensParams) (UserTokenRevocation, error)
+	SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error)
+	SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (Account, error)
+	SetLoginBackoff(ctx context.Context, arg SetLoginBackoffParams) (LoginFailure, error)
+	TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateHoldStatus(ctx context.Context, arg UpdateHoldStatusParams) (Hold, error)
+	UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) (IdempotencyKey, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpsertFXRate(ctx context.Context, arg UpsertFXRateParams) (FXRate, error)
+	UpsertOAuthConsent(ctx context.Context, arg UpsertOAuthConsentParams) (OAuthConsent, error)
+	UseEmailToken(ctx context.Context, arg UseEmailTokenParams) (EmailToken, error)
+	UseMFAChallenge(ctx context.Context, id uuid.UUID) (MFAChallenge, error)
+	UseOAuthAuthorizationCode(ctx context.Context, id int64) (OAuthAuthorizationCode, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (TotpRecoveryCode, error)
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (UserTOTP, error)
+	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ReconciliationReport describes the state of the ledger invariants
+type ReconciliationReport struct {
+	CheckedAt time.Time `json:"checked_at"`
+	// Balanced is true when none of the invariants is violated
+	Balanced bool `json:"balanced"`
+	// AccountDrifts lists accounts whose balance differs from the sum of their entries
+	AccountDrifts []ListAccountBalanceDriftsRow `json:"account_drifts"`
+	// UnbalancedTransfers lists transfers that do not have exactly two matching entries of opposite sign,
+	// or four for cross-currency transfers that also book the fx system accounts
+	UnbalancedTransfers []ListUnbalancedTransfersRow `json:"unbalanced_transfers"`
+	// HeldAmountDrifts lists accounts whose held amount differs from the sum of their authorized holds
+	HeldAmountDrifts []ListHeldAmountDriftsRow `json:"held_amount_drifts"`
+	// Currencies lists the sum of all entries per currency, each of them must be zero
+	Currencies []ListCurrencyTotalsRow `json:"currencies"`
+}
+
+// Reconcile verifies the ledger invariants.
+// All checks run in a single read-only snapshot,
+// so transactions committed in the meantime do not show up as drift.
+func (store *SQLStore) Reconcile(ctx context.Context) (ReconciliationReport, error) {
+	report := ReconciliationReport{
+		CheckedAt: time.Now(),
+	}
+
+	opts := &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	}
+
+	err := store.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		var err error
+
+		report.AccountDrifts, err = q.ListAccountBalanceDrifts(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot check account balances: %w", err)
+		}
+
+		report.UnbalancedTransfers, err = q.ListUnbalancedTransfers(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot check transfers: %w", err)
+		}
+
+		report.HeldAmountDrifts, err = q.ListHeldAmountDrifts(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot check held amounts: %w", err)
+		}
+
+		report.Currencies, err = q.ListCurrencyTotals(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot check currency totals: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	report.Balanced = len(report.AccountDrifts) == 0 && len(report.UnbalancedTransfers) == 0 &&
+		len(report.HeldAmountDrifts) == 0
+	for _, currency := range report.Currencies {
+		if currency.EntriesTotal != 0 {
+			report.Balanced = false
+		}
+	}
+
+	return report, nil
+}
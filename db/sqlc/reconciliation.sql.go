@@ -0,0 +1,201 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.18.0
+// source: reconciliation.sql
+
+package db
+
+import (
+	"context"
+)
+
+const listAccountBalanceDrifts = `-- name: ListAccountBalanceDrifts :many
+SELECT a.id,
+       a.owner,
+       a.currency,
+       a.balance,
+       COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts AS a
+         LEFT JOIN entries AS e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id
+`
+
+type ListAccountBalanceDriftsRow struct {
+	ID           int64  `json:"id"`
+	Owner        string `json:"owner"`
+	Currency     string `json:"currency"`
+	Balance      int64  `json:"balance"`
+	EntriesTotal int64  `json:"entries_total"`
+}
+
+func (q *Queries) ListAccountBalanceDrifts(ctx context.Context) ([]ListAccountBalanceDriftsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountBalanceDrifts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountBalanceDriftsRow{}
+	for rows.Next() {
+		var i ListAccountBalanceDriftsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Currency,
+			&i.Balance,
+			&i.EntriesTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCurrencyTotals = `-- name: ListCurrencyTotals :many
+SELECT a.currency,
+       COUNT(e.id)                        AS entries_count,
+       COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts AS a
+         LEFT JOIN entries AS e ON e.account_id = a.id
+GROUP BY a.currency
+ORDER BY a.currency
+`
+
+type ListCurrencyTotalsRow struct {
+	Currency     string `json:"currency"`
+	EntriesCount int64  `json:"entries_count"`
+	EntriesTotal int64  `json:"entries_total"`
+}
+
+func (q *Queries) ListCurrencyTotals(ctx context.Context) ([]ListCurrencyTotalsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCurrencyTotals)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListCurrencyTotalsRow{}
+	for rows.Next() {
+		var i ListCurrencyTotalsRow
+		if err := rows.Scan(&i.Currency, &i.EntriesCount, &i.EntriesTotal); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHeldAmountDrifts = `-- name: ListHeldAmountDrifts :many
+SELECT a.id,
+       a.owner,
+       a.currency,
+       a.held_amount,
+       COALESCE(SUM(h.amount), 0)::bigint AS holds_total
+FROM accounts AS a
+         LEFT JOIN holds AS h ON h.account_id = a.id AND h.status = 'authorized'
+GROUP BY a.id
+HAVING a.held_amount <> COALESCE(SUM(h.amount), 0)
+ORDER BY a.id
+`
+
+type ListHeldAmountDriftsRow struct {
+	ID         int64  `json:"id"`
+	Owner      string `json:"owner"`
+	Currency   string `json:"currency"`
+	HeldAmount int64  `json:"held_amount"`
+	HoldsTotal int64  `json:"holds_total"`
+}
+
+func (q *Queries) ListHeldAmountDrifts(ctx context.Context) ([]ListHeldAmountDriftsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listHeldAmountDrifts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListHeldAmountDriftsRow{}
+	for rows.Next() {
+		var i ListHeldAmountDriftsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Currency,
+			&i.HeldAmount,
+			&i.HoldsTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnbalancedTransfers = `-- name: ListUnbalancedTransfers :many
+SELECT t.id,
+       t.from_account_id,
+       t.to_account_id,
+       t.amount,
+       COUNT(e.id) AS entries_count
+FROM transfers AS t
+         LEFT JOIN entries AS e ON e.transfer_id = t.id
+GROUP BY t.id
+HAVING COUNT(e.id) <> CASE WHEN t.to_amount IS NULL THEN 2 ELSE 4 END
+    OR COUNT(e.id) FILTER (WHERE e.account_id = t.from_account_id AND e.amount = -t.amount) <> 1
+    OR COUNT(e.id) FILTER (WHERE e.account_id = t.to_account_id AND e.amount = COALESCE(t.to_amount, t.amount)) <> 1
+ORDER BY t.id
+`
+
+type ListUnbalancedTransfersRow struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+	EntriesCount  int64 `json:"entries_count"`
+}
+
+func (q *Queries) ListUnbalancedTransfers(ctx context.Context) ([]ListUnbalancedTransfersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUnbalancedTransfers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUnbalancedTransfersRow{}
+	for rows.Next() {
+		var i ListUnbalancedTransfersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.EntriesCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestReconcile(t *testing.T) {
+	store := NewStore(testDB)
+
+	// random accounts start with a balance that is not backed by entries
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// a transfer without entries
+	brokenTransfer := createRandomTransfer(t, account1, account2)
+
+	res, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance,
+	})
+	require.NoError(t, err)
+
+	report, err := store.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.False(t, report.Balanced)
+	require.NotZero(t, report.CheckedAt)
+	require.NotEmpty(t, report.Currencies)
+
+	driftedAccounts := make(map[int64]ListAccountBalanceDriftsRow)
+	for _, drift := range report.AccountDrifts {
+		driftedAccounts[drift.ID] = drift
+	}
+	require.Contains(t, driftedAccounts, account1.ID)
+	require.Contains(t, driftedAccounts, account2.ID)
+	require.Equal(t, res.FromAccount.Balance, driftedAccounts[account1.ID].Balance)
+	require.Equal(t, res.FromEntry.Amount, driftedAccounts[account1.ID].EntriesTotal)
+
+	unbalancedTransfers := make(map[int64]ListUnbalancedTransfersRow)
+	for _, transfer := range report.UnbalancedTransfers {
+		unbalancedTransfers[transfer.ID] = transfer
+	}
+	require.Contains(t, unbalancedTransfers, brokenTransfer.ID)
+	require.Zero(t, unbalancedTransfers[brokenTransfer.ID].EntriesCount)
+	require.NotContains(t, unbalancedTransfers, res.Transfer.ID)
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"strconv"
+)
+
+// ReverseTransferTxParams contains the parameters of the reverse transfer transaction.
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount can be lower than the remaining amount of the transfer for a partial refund,
+	// zero reverses the whole remaining amount
+	Amount int64 `json:"amount"`
+}
+
+type ReverseTransferTxResult struct {
+	// OriginalTransfer is the reversed transfer with its updated refunded amount
+	OriginalTransfer Transfer `json:"original_transfer"`
+	TransferTxResult
+}
+
+// ReverseTransferTx moves money back from the recipient of a transfer to its sender.
+// The reversal is a transfer of its own, with entries of the reversal kind,
+// that references the original transfer. Several partial reversals are allowed
+// as long as together they do not exceed the amount of the original transfer.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// concurrent reversals of the same transfer wait here
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if original.ReversedTransferID != nil {
+			return ErrTransferIsReversal
+		}
+		if original.ToAmount != nil {
+			return ErrFXTransferNotReversible
+		}
+
+		// deposits and other money sent by the bank are not refunded to it by the recipient
+		sender, err := q.GetAccount(ctx, original.FromAccountID)
+		if err != nil {
+			return err
+		}
+		if IsSystemAccountOwner(sender.Owner) {
+			return ErrSystemAccount
+		}
+
+		remaining := original.Amount - original.RefundedAmount
+		if remaining == 0 {
+			return ErrTransferAlreadyReversed
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount > remaining {
+			return ErrReversalExceedsTransfer
+		}
+
+		result.Transfer, err = q.CreateReversalTransfer(ctx, CreateReversalTransferParams{
+			FromAccountID:      original.ToAccountID,
+			ToAccountID:        original.FromAccountID,
+			Amount:             amount,
+			ReversedTransferID: &original.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromEntry, result.ToEntry, err = createTransferEntries(ctx, q, result.Transfer, EntryKindReversal)
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, result.ToAccount, err = moveMoney(ctx, q, TransferTxParams{
+			FromAccountID: result.Transfer.FromAccountID,
+			ToAccountID:   result.Transfer.ToAccountID,
+			Amount:        amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.OriginalTransfer, err = q.AddTransferRefundedAmount(ctx, AddTransferRefundedAmountParams{
+			ID:     original.ID,
+			Amount: amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionReverseTransfer,
+			TargetType: AuditTargetTransfer,
+			TargetID:   strconv.FormatInt(original.ID, 10),
+			Before:     original,
+			After:      result.OriginalTransfer,
+		})
+	})
+
+	observeTransfer(EntryKindReversal, result.TransferTxResult, err)
+	return result, err
+}
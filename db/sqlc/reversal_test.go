@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// createTestTransfer transfers 100 between two new accounts
+func createTestTransfer(t *testing.T, store Store) TransferTxResult {
+	account1 := createAccountInCurrency(t, utils.EUR, 100)
+	account2 := createAccountInCurrency(t, utils.EUR, 0)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	original := createTestTransfer(t, store)
+
+	// partial refund
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     30,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(30), result.OriginalTransfer.RefundedAmount)
+	require.NotNil(t, result.Transfer.ReversedTransferID)
+	require.Equal(t, original.Transfer.ID, *result.Transfer.ReversedTransferID)
+	require.Equal(t, original.Transfer.ToAccountID, result.Transfer.FromAccountID)
+	require.Equal(t, original.Transfer.FromAccountID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(30), result.Transfer.Amount)
+
+	require.Equal(t, EntryKindReversal, result.FromEntry.Kind)
+	require.Equal(t, int64(-30), result.FromEntry.Amount)
+	require.Equal(t, int64(30), result.ToEntry.Amount)
+	require.Equal(t, result.Transfer.ID, *result.FromEntry.TransferID)
+
+	require.Equal(t, int64(70), result.FromAccount.Balance)
+	require.Equal(t, int64(30), result.ToAccount.Balance)
+
+	// more than the remaining amount
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     71,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	// the rest of the transfer
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(70), result.Transfer.Amount)
+	require.Equal(t, original.Transfer.Amount, result.OriginalTransfer.RefundedAmount)
+	require.Zero(t, result.FromAccount.Balance)
+	require.Equal(t, int64(100), result.ToAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+
+	// a reversal cannot be reversed
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: result.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferIsReversal)
+}
+
+func TestReverseTransferTxSystemAccount(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	deposit, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    utils.RandomAmount(),
+		Channel:   CashChannel,
+	})
+	require.NoError(t, err)
+
+	// the recipient of a deposit cannot send it back to the cash account
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: deposit.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrSystemAccount)
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, deposit.ToAccount.Balance, updatedAccount.Balance)
+}
+
+func TestReverseTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+	original := createTestTransfer(t, store)
+
+	n := 5
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+				TransferID: original.Transfer.ID,
+			})
+			errs <- err
+		}()
+	}
+
+	// only one of the full reversals succeeds
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+	}
+	require.Equal(t, 1, succeeded)
+
+	transfer, err := store.GetTransfer(context.Background(), original.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, transfer.Amount, transfer.RefundedAmount)
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"github.com/aalug/bank-go/token"
+	"time"
+)
+
+// revocationStore loads a token.RevocationList from the revoked tokens tables
+type revocationStore struct {
+	querier Querier
+}
+
+// NewRevocationStore returns the token.RevocationStore of the querier
+func NewRevocationStore(querier Querier) token.RevocationStore {
+	return &revocationStore{querier: querier}
+}
+
+func (store *revocationStore) ListRevokedTokens(ctx context.Context) ([]token.RevokedToken, error) {
+	rows, err := store.querier.ListRevokedTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	revokedTokens := make([]token.RevokedToken, len(rows))
+	for i, row := range rows {
+		revokedTokens[i] = token.RevokedToken{
+			ID:        row.ID,
+			ExpiresAt: row.ExpiresAt,
+		}
+	}
+
+	return revokedTokens, nil
+}
+
+func (store *revocationStore) ListUserTokenRevocations(ctx context.Context, issuedAfter time.Time) ([]token.UserTokenRevocation, error) {
+	rows, err := store.querier.ListUserTokenRevocations(ctx, issuedAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	revocations := make([]token.UserTokenRevocation, len(rows))
+	for i, row := range rows {
+		revocations[i] = token.UserTokenRevocation{
+			Username:     row.Username,
+			IssuedBefore: row.IssuedBefore,
+		}
+	}
+
+	return revocations, nil
+}
+
+func (store *revocationStore) DeleteExpiredRevokedTokens(ctx context.Context) (int64, error) {
+	return store.querier.DeleteExpiredRevokedTokens(ctx)
+}
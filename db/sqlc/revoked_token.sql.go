@@ -0,0 +1,127 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.18.0
+// source: revoked_token.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const deleteExpiredRevokedTokens = `-- name: DeleteExpiredRevokedTokens :execrows
+DELETE
+FROM revoked_tokens
+WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredRevokedTokens(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredRevokedTokens)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listRevokedTokens = `-- name: ListRevokedTokens :many
+SELECT id, username, expires_at, created_at
+FROM revoked_tokens
+WHERE expires_at > now()
+`
+
+func (q *Queries) ListRevokedTokens(ctx context.Context) ([]RevokedToken, error) {
+	rows, err := q.db.QueryContext(ctx, listRevokedTokens)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RevokedToken{}
+	for rows.Next() {
+		var i RevokedToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTokenRevocations = `-- name: ListUserTokenRevocations :many
+SELECT username, issued_before, created_at
+FROM user_token_revocations
+WHERE issued_before > $1
+`
+
+func (q *Queries) ListUserTokenRevocations(ctx context.Context, issuedAfter time.Time) ([]UserTokenRevocation, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTokenRevocations, issuedAfter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UserTokenRevocation{}
+	for rows.Next() {
+		var i UserTokenRevocation
+		if err := rows.Scan(&i.Username, &i.IssuedBefore, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeToken = `-- name: RevokeToken :exec
+INSERT INTO revoked_tokens (id, username, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (id) DO NOTHING
+`
+
+type RevokeTokenParams struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) RevokeToken(ctx context.Context, arg RevokeTokenParams) error {
+	_, err := q.db.ExecContext(ctx, revokeToken, arg.ID, arg.Username, arg.ExpiresAt)
+	return err
+}
+
+const revokeUserTokens = `-- name: RevokeUserTokens :one
+INSERT INTO user_token_revocations (username, issued_before)
+VALUES ($1, $2)
+ON CONFLICT (username) DO UPDATE
+    SET issued_before = GREATEST(user_token_revocations.issued_before, EXCLUDED.issued_before)
+RETURNING username, issued_before, created_at
+`
+
+type RevokeUserTokensParams struct {
+	Username     string    `json:"username"`
+	IssuedBefore time.Time `json:"issued_before"`
+}
+
+func (q *Queries) RevokeUserTokens(ctx context.Context, arg RevokeUserTokensParams) (UserTokenRevocation, error) {
+	row := q.db.QueryRowContext(ctx, revokeUserTokens, arg.Username, arg.IssuedBefore)
+	var i UserTokenRevocation
+	err := row.Scan(&i.Username, &i.IssuedBefore, &i.CreatedAt)
+	return i, err
+}
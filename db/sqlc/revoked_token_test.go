@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRevokeToken(t *testing.T) {
+	user := createRandomUser(t)
+
+	activeID := uuid.New()
+	err := testQueries.RevokeToken(context.Background(), RevokeTokenParams{
+		ID:        activeID,
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	// revoking the same token again is not an error
+	err = testQueries.RevokeToken(context.Background(), RevokeTokenParams{
+		ID:        activeID,
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	expiredID := uuid.New()
+	err = testQueries.RevokeToken(context.Background(), RevokeTokenParams{
+		ID:        expiredID,
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	deleted, err := testQueries.DeleteExpiredRevokedTokens(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	revokedTokens, err := testQueries.ListRevokedTokens(context.Background())
+	require.NoError(t, err)
+
+	ids := map[uuid.UUID]bool{}
+	for _, revokedToken := range revokedTokens {
+		ids[revokedToken.ID] = true
+	}
+	require.True(t, ids[activeID])
+	require.False(t, ids[expiredID])
+}
+
+func TestRevokeUserTokens(t *testing.T) {
+	user := createRandomUser(t)
+	issuedBefore := time.Now().Truncate(time.Microsecond)
+
+	revocation, err := testQueries.RevokeUserTokens(context.Background(), RevokeUserTokensParams{
+		Username:     user.Username,
+		IssuedBefore: issuedBefore,
+	})
+	require.NoError(t, err)
+	require.True(t, issuedBefore.Equal(revocation.IssuedBefore))
+
+	// an earlier revocation never moves the time back
+	revocation, err = testQueries.RevokeUserTokens(context.Background(), RevokeUserTokensParams{
+		Username:     user.Username,
+		IssuedBefore: issuedBefore.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.True(t, issuedBefore.Equal(revocation.IssuedBefore))
+
+	revocation, err = testQueries.RevokeUserTokens(context.Background(), RevokeUserTokensParams{
+		Username:     user.Username,
+		IssuedBefore: issuedBefore.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.True(t, issuedBefore.Add(time.Hour).Equal(revocation.IssuedBefore))
+}
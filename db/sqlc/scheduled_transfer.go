@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// how often a scheduled transfer is executed
+const (
+	RecurrenceOnce    = "once"
+	RecurrenceDaily   = "daily"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+)
+
+// IsSupportedRecurrence checks if the recurrence of a scheduled transfer is supported
+func IsSupportedRecurrence(recurrence string) bool {
+	switch recurrence {
+	case RecurrenceOnce, RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly:
+		return true
+	}
+	return false
+}
+
+// statuses of a scheduled transfer, only active ones are executed
+const (
+	ScheduledTransferActive    = "active"
+	ScheduledTransferSuspended = "suspended"
+	ScheduledTransferCompleted = "completed"
+)
+
+// RecordScheduledTransferRunTxParams contains the outcome of a scheduled transfer run
+// and the state the scheduled transfer moves to.
+type RecordScheduledTransferRunTxParams struct {
+	ScheduledTransferID int64     `json:"scheduled_transfer_id"`
+	ScheduledFor        time.Time `json:"scheduled_for"`
+	// TransferID is set when the run succeeded
+	TransferID   *int64    `json:"transfer_id"`
+	ErrorMessage string    `json:"error_message"`
+	NextRunAt    time.Time `json:"next_run_at"`
+	Status       string    `json:"status"`
+	FailureCount int32     `json:"failure_count"`
+}
+
+type RecordScheduledTransferRunTxResult struct {
+	Run               ScheduledTransferRun `json:"run"`
+	ScheduledTransfer ScheduledTransfer    `json:"scheduled_transfer"`
+}
+
+// RecordScheduledTransferRunTx records the outcome of a run, moves the scheduled transfer
+// to its next state and releases the worker's claim on it.
+func (store *SQLStore) RecordScheduledTransferRunTx(ctx context.Context, arg RecordScheduledTransferRunTxParams) (RecordScheduledTransferRunTxResult, error) {
+	var result RecordScheduledTransferRunTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Run, err = q.CreateScheduledTransferRun(ctx, CreateScheduledTransferRunParams{
+			ScheduledTransferID: arg.ScheduledTransferID,
+			ScheduledFor:        arg.ScheduledFor,
+			TransferID:          arg.TransferID,
+			Succeeded:           arg.TransferID != nil,
+			ErrorMessage:        arg.ErrorMessage,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ScheduledTransfer, err = q.FinishScheduledTransferRun(ctx, FinishScheduledTransferRunParams{
+			ID:           arg.ScheduledTransferID,
+			NextRunAt:    arg.NextRunAt,
+			Status:       arg.Status,
+			FailureCount: arg.FailureCount,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// CreateScheduledTransferTx creates a scheduled transfer and records it in the audit log
+func (store *SQLStore) CreateScheduledTransferTx(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	var result ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result, err = q.CreateScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionCreateScheduledTransfer,
+			TargetType: AuditTargetScheduledTransfer,
+			TargetID:   strconv.FormatInt(result.ID, 10),
+			After:      result,
+		})
+	})
+
+	return result, err
+}
+
+// UpdateScheduledTransferTx updates a scheduled transfer and records
+// its state before and after the change in the audit log
+func (store *SQLStore) UpdateScheduledTransferTx(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error) {
+	var result ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetScheduledTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.UpdateScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionUpdateScheduledTransfer,
+			TargetType: AuditTargetScheduledTransfer,
+			TargetID:   strconv.FormatInt(result.ID, 10),
+			Before:     before,
+			After:      result,
+		})
+	})
+
+	return result, err
+}
+
+// DeleteScheduledTransferTx deletes a scheduled transfer together with the history of its runs
+// and records its last state in the audit log.
+// It returns sql.ErrNoRows if the scheduled transfer does not exist.
+func (store *SQLStore) DeleteScheduledTransferTx(ctx context.Context, id int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		scheduledTransfer, err := q.GetScheduledTransferForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteScheduledTransfer(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionDeleteScheduledTransfer,
+			TargetType: AuditTargetScheduledTransfer,
+			TargetID:   strconv.FormatInt(id, 10),
+			Before:     scheduledTransfer,
+		})
+	})
+}
@@ -0,0 +1,402 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.18.0
+// source: scheduled_transfer.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const claimDueScheduledTransfers = `-- name: ClaimDueScheduledTransfers :many
+UPDATE scheduled_transfers
+SET claimed_until = $1::timestamptz
+WHERE id IN (SELECT id
+             FROM scheduled_transfers
+             WHERE status = 'active'
+               AND next_run_at <= now()
+               AND (claimed_until IS NULL OR claimed_until <= now())
+             ORDER BY next_run_at
+             LIMIT $2 FOR UPDATE SKIP LOCKED)
+RETURNING id, owner, from_account_id, to_account_id, amount, recurrence, start_at, next_run_at, status, failure_count, claimed_until, created_at, api_key_id
+`
+
+type ClaimDueScheduledTransfersParams struct {
+	ClaimedUntil time.Time `json:"claimed_until"`
+	MaxCount     int32     `json:"max_count"`
+}
+
+func (q *Queries) ClaimDueScheduledTransfers(ctx context.Context, arg ClaimDueScheduledTransfersParams) ([]ScheduledTransfer, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueScheduledTransfers, arg.ClaimedUntil, arg.MaxCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ScheduledTransfer{}
+	for rows.Next() {
+		var i ScheduledTransfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Recurrence,
+			&i.StartAt,
+			&i.NextRunAt,
+			&i.Status,
+			&i.FailureCount,
+			&i.ClaimedUntil,
+			&i.CreatedAt,
+			&i.APIKeyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createScheduledTransfer = `-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers
+    (owner, from_account_id, to_account_id, amount, recurrence, start_at, next_run_at, api_key_id)
+VALUES ($1, $2, $3, $4,
+        $5, $6, $6, $7)
+RETURNING id, owner, from_account_id, to_account_id, amount, recurrence, start_at, next_run_at, status, failure_count, claimed_until, created_at, api_key_id
+`
+
+type CreateScheduledTransferParams struct {
+	Owner         string    `json:"owner"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Recurrence    string    `json:"recurrence"`
+	StartAt       time.Time `json:"start_at"`
+	APIKeyID      *int64    `json:"api_key_id"`
+}
+
+func (q *Queries) CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	row := q.db.QueryRowContext(ctx, createScheduledTransfer,
+		arg.Owner,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Recurrence,
+		arg.StartAt,
+		arg.APIKeyID,
+	)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Recurrence,
+		&i.StartAt,
+		&i.NextRunAt,
+		&i.Status,
+		&i.FailureCount,
+		&i.ClaimedUntil,
+		&i.CreatedAt,
+		&i.APIKeyID,
+	)
+	return i, err
+}
+
+const createScheduledTransferRun = `-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs
+    (scheduled_transfer_id, scheduled_for, transfer_id, succeeded, error_message)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, scheduled_transfer_id, scheduled_for, transfer_id, succeeded, error_message, created_at
+`
+
+type CreateScheduledTransferRunParams struct {
+	ScheduledTransferID int64     `json:"scheduled_transfer_id"`
+	ScheduledFor        time.Time `json:"scheduled_for"`
+	TransferID          *int64    `json:"transfer_id"`
+	Succeeded           bool      `json:"succeeded"`
+	ErrorMessage        string    `json:"error_message"`
+}
+
+func (q *Queries) CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error) {
+	row := q.db.QueryRowContext(ctx, createScheduledTransferRun,
+		arg.ScheduledTransferID,
+		arg.ScheduledFor,
+		arg.TransferID,
+		arg.Succeeded,
+		arg.ErrorMessage,
+	)
+	var i ScheduledTransferRun
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduledTransferID,
+		&i.ScheduledFor,
+		&i.TransferID,
+		&i.Succeeded,
+		&i.ErrorMessage,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteScheduledTransfer = `-- name: DeleteScheduledTransfer :exec
+DELETE
+FROM scheduled_transfers
+WHERE id = $1
+`
+
+func (q *Queries) DeleteScheduledTransfer(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteScheduledTransfer, id)
+	return err
+}
+
+const finishScheduledTransferRun = `-- name: FinishScheduledTransferRun :one
+UPDATE scheduled_transfers
+SET next_run_at   = $1,
+    status        = $2,
+    failure_count = $3,
+    claimed_until = NULL
+WHERE id = $4
+RETURNING id, owner, from_account_id, to_account_id, amount, recurrence, start_at, next_run_at, status, failure_count, claimed_until, created_at, api_key_id
+`
+
+type FinishScheduledTransferRunParams struct {
+	NextRunAt    time.Time `json:"next_run_at"`
+	Status       string    `json:"status"`
+	FailureCount int32     `json:"failure_count"`
+	ID           int64     `json:"id"`
+}
+
+func (q *Queries) FinishScheduledTransferRun(ctx context.Context, arg FinishScheduledTransferRunParams) (ScheduledTransfer, error) {
+	row := q.db.QueryRowContext(ctx, finishScheduledTransferRun,
+		arg.NextRunAt,
+		arg.Status,
+		arg.FailureCount,
+		arg.ID,
+	)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Recurrence,
+		&i.StartAt,
+		&i.NextRunAt,
+		&i.Status,
+		&i.FailureCount,
+		&i.ClaimedUntil,
+		&i.CreatedAt,
+		&i.APIKeyID,
+	)
+	return i, err
+}
+
+const getScheduledTransfer = `-- name: GetScheduledTransfer :one
+SELECT id, owner, from_account_id, to_account_id, amount, recurrence, start_at, next_run_at, status, failure_count, claimed_until, created_at, api_key_id
+FROM scheduled_transfers
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error) {
+	row := q.db.QueryRowContext(ctx, getScheduledTransfer, id)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Recurrence,
+		&i.StartAt,
+		&i.NextRunAt,
+		&i.Status,
+		&i.FailureCount,
+		&i.ClaimedUntil,
+		&i.CreatedAt,
+		&i.APIKeyID,
+	)
+	return i, err
+}
+
+const getScheduledTransferForUpdate = `-- name: GetScheduledTransferForUpdate :one
+SELECT id, owner, from_account_id, to_account_id, amount, recurrence, start_at, next_run_at, status, failure_count, claimed_until, created_at, api_key_id
+FROM scheduled_transfers
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error) {
+	row := q.db.QueryRowContext(ctx, getScheduledTransferForUpdate, id)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Recurrence,
+		&i.StartAt,
+		&i.NextRunAt,
+		&i.Status,
+		&i.FailureCount,
+		&i.ClaimedUntil,
+		&i.CreatedAt,
+		&i.APIKeyID,
+	)
+	return i, err
+}
+
+const listScheduledTransferRuns = `-- name: ListScheduledTransferRuns :many
+SELECT id, scheduled_transfer_id, scheduled_for, transfer_id, succeeded, error_message, created_at
+FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListScheduledTransferRunsParams struct {
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+	Limit               int32 `json:"limit"`
+	Offset              int32 `json:"offset"`
+}
+
+func (q *Queries) ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error) {
+	rows, err := q.db.QueryContext(ctx, listScheduledTransferRuns, arg.ScheduledTransferID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ScheduledTransferRun{}
+	for rows.Next() {
+		var i ScheduledTransferRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduledTransferID,
+			&i.ScheduledFor,
+			&i.TransferID,
+			&i.Succeeded,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScheduledTransfers = `-- name: ListScheduledTransfers :many
+SELECT id, owner, from_account_id, to_account_id, amount, recurrence, start_at, next_run_at, status, failure_count, claimed_until, created_at, api_key_id
+FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2 OFFSET $3
+`
+
+type ListScheduledTransfersParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error) {
+	rows, err := q.db.QueryContext(ctx, listScheduledTransfers, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ScheduledTransfer{}
+	for rows.Next() {
+		var i ScheduledTransfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Recurrence,
+			&i.StartAt,
+			&i.NextRunAt,
+			&i.Status,
+			&i.FailureCount,
+			&i.ClaimedUntil,
+			&i.CreatedAt,
+			&i.APIKeyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateScheduledTransfer = `-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET amount        = COALESCE($1, amount),
+    recurrence    = COALESCE($2, recurrence),
+    next_run_at   = COALESCE($3, next_run_at),
+    status        = COALESCE($4, status),
+    failure_count = CASE WHEN $4::varchar = 'active' THEN 0 ELSE failure_count END
+WHERE id = $5
+RETURNING id, owner, from_account_id, to_account_id, amount, recurrence, start_at, next_run_at, status, failure_count, claimed_until, created_at, api_key_id
+`
+
+type UpdateScheduledTransferParams struct {
+	Amount     sql.NullInt64  `json:"amount"`
+	Recurrence sql.NullString `json:"recurrence"`
+	NextRunAt  sql.NullTime   `json:"next_run_at"`
+	Status     sql.NullString `json:"status"`
+	ID         int64          `json:"id"`
+}
+
+func (q *Queries) UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error) {
+	row := q.db.QueryRowContext(ctx, updateScheduledTransfer,
+		arg.Amount,
+		arg.Recurrence,
+		arg.NextRunAt,
+		arg.Status,
+		arg.ID,
+	)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Recurrence,
+		&i.StartAt,
+		&i.NextRunAt,
+		&i.Status,
+		&i.FailureCount,
+		&i.ClaimedUntil,
+		&i.CreatedAt,
+		&i.APIKeyID,
+	)
+	return i, err
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// createRandomScheduledTransfer creates a scheduled transfer between two new accounts
+func createRandomScheduledTransfer(t *testing.T, startAt time.Time) ScheduledTransfer {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	params := CreateScheduledTransferParams{
+		Owner:         account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Recurrence:    RecurrenceDaily,
+		StartAt:       startAt,
+	}
+
+	scheduledTransfer, err := testQueries.CreateScheduledTransfer(context.Background(), params)
+	require.NoError(t, err)
+
+	require.NotZero(t, scheduledTransfer.ID)
+	require.Equal(t, params.Owner, scheduledTransfer.Owner)
+	require.Equal(t, params.FromAccountID, scheduledTransfer.FromAccountID)
+	require.Equal(t, params.ToAccountID, scheduledTransfer.ToAccountID)
+	require.Equal(t, params.Amount, scheduledTransfer.Amount)
+	require.Equal(t, params.Recurrence, scheduledTransfer.Recurrence)
+	require.WithinDuration(t, startAt, scheduledTransfer.StartAt, time.Second)
+	require.WithinDuration(t, startAt, scheduledTransfer.NextRunAt, time.Second)
+	require.Equal(t, ScheduledTransferActive, scheduledTransfer.Status)
+	require.Zero(t, scheduledTransfer.FailureCount)
+	require.Nil(t, scheduledTransfer.ClaimedUntil)
+
+	return scheduledTransfer
+}
+
+// TestCreateScheduledTransfer tests the create scheduled transfer function
+func TestCreateScheduledTransfer(t *testing.T) {
+	createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+}
+
+// TestClaimDueScheduledTransfers tests that a due scheduled transfer is claimed only once
+func TestClaimDueScheduledTransfers(t *testing.T) {
+	due := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+	notDue := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	params := ClaimDueScheduledTransfersParams{
+		ClaimedUntil: time.Now().Add(time.Minute),
+		MaxCount:     1000,
+	}
+
+	claimed, err := testQueries.ClaimDueScheduledTransfers(context.Background(), params)
+	require.NoError(t, err)
+
+	ids := make(map[int64]bool)
+	for _, scheduledTransfer := range claimed {
+		ids[scheduledTransfer.ID] = true
+	}
+	require.True(t, ids[due.ID])
+	require.False(t, ids[notDue.ID])
+
+	// the lease has not expired yet
+	claimed, err = testQueries.ClaimDueScheduledTransfers(context.Background(), params)
+	require.NoError(t, err)
+	for _, scheduledTransfer := range claimed {
+		require.NotEqual(t, due.ID, scheduledTransfer.ID)
+	}
+}
+
+// TestRecordScheduledTransferRunTx tests recording a failed run of a scheduled transfer
+func TestRecordScheduledTransferRunTx(t *testing.T) {
+	store := NewStore(testDB)
+	scheduledTransfer := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+	nextRunAt := time.Now().Add(15 * time.Minute)
+
+	result, err := store.RecordScheduledTransferRunTx(context.Background(), RecordScheduledTransferRunTxParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		ScheduledFor:        scheduledTransfer.NextRunAt,
+		ErrorMessage:        ErrInsufficientFunds.Error(),
+		NextRunAt:           nextRunAt,
+		Status:              ScheduledTransferActive,
+		FailureCount:        1,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, scheduledTransfer.ID, result.Run.ScheduledTransferID)
+	require.False(t, result.Run.Succeeded)
+	require.Nil(t, result.Run.TransferID)
+	require.Equal(t, ErrInsufficientFunds.Error(), result.Run.ErrorMessage)
+
+	require.Equal(t, int32(1), result.ScheduledTransfer.FailureCount)
+	require.WithinDuration(t, nextRunAt, result.ScheduledTransfer.NextRunAt, time.Second)
+	require.Nil(t, result.ScheduledTransfer.ClaimedUntil)
+
+	runs, err := store.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               5,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, result.Run.ID, runs[0].ID)
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+// RotateSessionTxParams contains the parameters of the rotate session transaction.
+type RotateSessionTxParams struct {
+	// SessionID is the session of the refresh token that is exchanged
+	SessionID uuid.UUID `json:"session_id"`
+	// NewSessionID is the ID of the payload of the new refresh token
+	NewSessionID uuid.UUID `json:"new_session_id"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// RotateSessionTx exchanges the refresh token of a session for a new one.
+// The session is marked as rotated and a child session with the new refresh token
+// is created in the same family. It expires with the session created at login at the latest,
+// so rotating the refresh token can not extend the login. If the session was already rotated, its refresh token
+// was used twice, so either the client or an attacker holds a stolen copy. In that case
+// the whole family is blocked and ErrRefreshTokenReused is returned.
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (Session, error) {
+	var result Session
+	var reused bool
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		reused = false
+
+		// concurrent rotations of the same session wait here
+		session, err := q.GetSessionForUpdate(ctx, arg.SessionID)
+		if err != nil {
+			return err
+		}
+
+		if session.RotatedAt != nil {
+			// the family has to be blocked even though an error is returned,
+			// so the transaction is committed and the error reported after it
+			reused = true
+			_, err = q.BlockSessionFamily(ctx, session.FamilyID)
+			return err
+		}
+
+		if session.IsBlocked {
+			return ErrSessionBlocked
+		}
+
+		_, err = q.MarkSessionRotated(ctx, session.ID)
+		if err != nil {
+			return err
+		}
+
+		// the ID of the family is the ID of the session created at login
+		root := session
+		if session.FamilyID != session.ID {
+			root, err = q.GetSession(ctx, session.FamilyID)
+			if err != nil {
+				return err
+			}
+		}
+
+		expiresAt := arg.ExpiresAt
+		if expiresAt.After(root.ExpiresAt) {
+			expiresAt = root.ExpiresAt
+		}
+
+		result, err = q.CreateRotatedSession(ctx, CreateRotatedSessionParams{
+			ID:           arg.NewSessionID,
+			Username:     session.Username,
+			RefreshToken: arg.RefreshToken,
+			UserAgent:    arg.UserAgent,
+			ClientIp:     arg.ClientIp,
+			ExpiresAt:    expiresAt,
+			FamilyID:     session.FamilyID,
+			ParentID:     &session.ID,
+		})
+
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if reused {
+		return Session{}, ErrRefreshTokenReused
+	}
+
+	return result, nil
+}
+
+// BlockSessionTx blocks a session, so its refresh token can no longer be used,
+// and records it in the audit log
+func (store *SQLStore) BlockSessionTx(ctx context.Context, id uuid.UUID) (Session, error) {
+	var result Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result, err = auditedBlockSession(ctx, q, id)
+		return err
+	})
+
+	return result, err
+}
+
+// auditedBlockSession blocks a session using the given queries and records it in the audit log
+func auditedBlockSession(ctx context.Context, q *Queries, id uuid.UUID) (Session, error) {
+	before, err := q.GetSessionForUpdate(ctx, id)
+	if err != nil {
+		return before, err
+	}
+
+	result, err := q.BlockSession(ctx, id)
+	if err != nil {
+		return result, err
+	}
+
+	err = recordAuditEvent(ctx, q, auditChange{
+		Action:     AuditActionBlockSession,
+		TargetType: AuditTargetSession,
+		TargetID:   id.String(),
+		Before:     before,
+		After:      result,
+	})
+	return result, err
+}
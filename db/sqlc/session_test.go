@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"github.com/aalug/bank-go/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomSession(t *testing.T, username string, expiresAt time.Time) Session {
+	params := CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     username,
+		RefreshToken: utils.RandomString(32),
+		UserAgent:    utils.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		IsBlocked:    false,
+		ExpiresAt:    expiresAt,
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), params)
+	require.NoError(t, err)
+	require.NotEmpty(t, session)
+
+	require.Equal(t, params.ID, session.ID)
+	require.Equal(t, params.Username, session.Username)
+	require.Equal(t, params.RefreshToken, session.RefreshToken)
+	require.False(t, session.IsBlocked)
+	require.NotZero(t, session.CreatedAt)
+
+	return session
+}
+
+func TestCreateSession(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomSession(t, user.Username, time.Now().Add(time.Hour))
+}
+
+func TestBlockSession(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username, time.Now().Add(time.Hour))
+
+	blockedSession, err := testQueries.BlockSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blockedSession.IsBlocked)
+	require.Equal(t, session.RefreshToken, blockedSession.RefreshToken)
+}
+
+func TestListActiveSessions(t *testing.T) {
+	user := createRandomUser(t)
+
+	var active []Session
+	for i := 0; i < 3; i++ {
+		active = append(active, createRandomSession(t, user.Username, time.Now().Add(time.Hour)))
+	}
+	createRandomSession(t, user.Username, time.Now().Add(-time.Minute))
+	blocked := createRandomSession(t, user.Username, time.Now().Add(time.Hour))
+	_, err := testQueries.BlockSession(context.Background(), blocked.ID)
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListActiveSessions(context.Background(), ListActiveSessionsParams{
+		Username: user.Username,
+		Limit:    10,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, sessions, len(active))
+
+	for _, session := range sessions {
+		require.Equal(t, user.Username, session.Username)
+		require.False(t, session.IsBlocked)
+		require.True(t, session.ExpiresAt.After(time.Now()))
+	}
+}
+
+func TestRotateSessionTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username, time.Now().Add(time.Hour))
+	require.Equal(t, session.ID, session.FamilyID)
+	require.Nil(t, session.ParentID)
+
+	arg := RotateSessionTxParams{
+		SessionID:    session.ID,
+		NewSessionID: uuid.New(),
+		RefreshToken: utils.RandomString(32),
+		UserAgent:    utils.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+
+	child, err := store.RotateSessionTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.NewSessionID, child.ID)
+	require.Equal(t, user.Username, child.Username)
+	require.Equal(t, arg.RefreshToken, child.RefreshToken)
+	require.Equal(t, session.FamilyID, child.FamilyID)
+	require.NotNil(t, child.ParentID)
+	require.Equal(t, session.ID, *child.ParentID)
+	require.Nil(t, child.RotatedAt)
+
+	parent, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.NotNil(t, parent.RotatedAt)
+	require.False(t, parent.IsBlocked)
+}
+
+func TestRotateSessionTxExpiry(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username, time.Now().Add(time.Hour))
+
+	rotate := func(sessionID uuid.UUID) Session {
+		rotated, err := store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+			SessionID:    sessionID,
+			NewSessionID: uuid.New(),
+			RefreshToken: utils.RandomString(32),
+			ExpiresAt:    time.Now().Add(24 * time.Hour),
+		})
+		require.NoError(t, err)
+		return rotated
+	}
+
+	// every rotation asks for a later expiry than the login session has
+	child := rotate(session.ID)
+	require.WithinDuration(t, session.ExpiresAt, child.ExpiresAt, time.Second)
+
+	grandchild := rotate(child.ID)
+	require.WithinDuration(t, session.ExpiresAt, grandchild.ExpiresAt, time.Second)
+}
+
+func TestRotateSessionTxReuse(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username, time.Now().Add(time.Hour))
+
+	rotate := func(sessionID uuid.UUID) (Session, error) {
+		return store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+			SessionID:    sessionID,
+			NewSessionID: uuid.New(),
+			RefreshToken: utils.RandomString(32),
+			ExpiresAt:    time.Now().Add(time.Hour),
+		})
+	}
+
+	child, err := rotate(session.ID)
+	require.NoError(t, err)
+
+	grandchild, err := rotate(child.ID)
+	require.NoError(t, err)
+
+	// the refresh token of the first session is presented again
+	_, err = rotate(session.ID)
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	for _, id := range []uuid.UUID{session.ID, child.ID, grandchild.ID} {
+		s, err := testQueries.GetSession(context.Background(), id)
+		require.NoError(t, err)
+		require.True(t, s.IsBlocked)
+	}
+
+	_, err = rotate(grandchild.ID)
+	require.ErrorIs(t, err, ErrSessionBlocked)
+}
+
+func TestRotateSessionTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username, time.Now().Add(time.Hour))
+
+	n := 2
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+				SessionID:    session.ID,
+				NewSessionID: uuid.New(),
+				RefreshToken: utils.RandomString(32),
+				ExpiresAt:    time.Now().Add(time.Hour),
+			})
+			errs <- err
+		}()
+	}
+
+	var reused int
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err != nil {
+			require.ErrorIs(t, err, ErrRefreshTokenReused)
+			reused++
+		}
+	}
+	require.Equal(t, 1, reused)
+}
@@ -2,19 +2,75 @@ package db
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"github.com/aalug/bank-go/fx"
+	"github.com/aalug/bank-go/metrics"
+	"github.com/google/uuid"
+	"log/slog"
+	"math/rand"
+	"sync/atomic"
+	"time"
 )
 
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	IdempotentTransferTx(ctx context.Context, arg IdempotentTransferTxParams) (TransferTxResult, error)
+	DepositTx(ctx context.Context, arg DepositTxParams) (TransferTxResult, error)
+	WithdrawTx(ctx context.Context, arg WithdrawTxParams) (TransferTxResult, error)
+	Reconcile(ctx context.Context) (ReconciliationReport, error)
+	CreateQuoteTx(ctx context.Context, arg CreateQuoteTxParams) (FXQuote, error)
+	FXTransferTx(ctx context.Context, arg FXTransferTxParams) (TransferTxResult, error)
+	LoadFXRatesTx(ctx context.Context, rates fx.Rates) ([]FXRate, error)
+	RecordScheduledTransferRunTx(ctx context.Context, arg RecordScheduledTransferRunTxParams) (RecordScheduledTransferRunTxResult, error)
+	AuthorizeTx(ctx context.Context, arg AuthorizeTxParams) (HoldTxResult, error)
+	CaptureTx(ctx context.Context, arg CaptureTxParams) (CaptureTxResult, error)
+	VoidTx(ctx context.Context, holdID int64) (HoldTxResult, error)
+	ExpireHoldsTx(ctx context.Context, maxCount int32) ([]Hold, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (Session, error)
+	SetAccountFrozenTx(ctx context.Context, arg SetAccountFrozenTxParams) (Account, error)
+	AdminBlockSessionTx(ctx context.Context, arg AdminBlockSessionTxParams) (Session, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserParams) (User, error)
+	RecordLoginFailureTx(ctx context.Context, arg RecordLoginFailureTxParams) ([]LoginFailure, error)
+	AdminUnlockUserTx(ctx context.Context, arg AdminUnlockUserTxParams) error
+	ConfirmTOTPTx(ctx context.Context, arg ConfirmTOTPTxParams) (UserTOTP, error)
+	CreateEmailTokenTx(ctx context.Context, arg CreateEmailTokenParams) (EmailToken, error)
+	VerifyEmailTx(ctx context.Context, hashedToken string) (User, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error)
+	AdminCreateAPIKeyTx(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error)
+	AdminRevokeAPIKeyTx(ctx context.Context, arg AdminRevokeAPIKeyTxParams) (APIKey, error)
+	AuthorizeOAuthClientTx(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) (OAuthAuthorizationCode, error)
+	ExchangeOAuthCodeTx(ctx context.Context, arg ExchangeOAuthCodeTxParams) (OAuthRefreshToken, error)
+	RotateOAuthRefreshTokenTx(ctx context.Context, arg RotateOAuthRefreshTokenTxParams) (OAuthRefreshToken, error)
+	RevokeOAuthConsentTx(ctx context.Context, arg DeleteOAuthConsentParams) error
+	CreateOAuthClientTx(ctx context.Context, arg CreateOAuthClientParams) (OAuthClient, error)
+	CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error)
+	DeleteAccountTx(ctx context.Context, id int64) error
+	CreateScheduledTransferTx(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateScheduledTransferTx(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	DeleteScheduledTransferTx(ctx context.Context, id int64) error
+	CreateAPIKeyTx(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error)
+	RevokeAPIKeyTx(ctx context.Context, id int64) (APIKey, error)
+	BlockSessionTx(ctx context.Context, id uuid.UUID) (Session, error)
+	VerifyAuditChain(ctx context.Context) (AuditChainReport, error)
+	TxStats() TxStats
 }
 
 // SQLStore provides all functions to execute db queries and transactions
 type SQLStore struct {
 	*Queries
 	db *sql.DB
+
+	// retried counts transaction attempts repeated after a retryable error,
+	// exhausted counts transactions that failed on their last attempt
+	retried   atomic.Int64
+	exhausted atomic.Int64
 }
 
 // NewStore creates a new Store
@@ -25,8 +81,81 @@ func NewStore(db *sql.DB) Store {
 	}
 }
 
+const (
+	// maxTxAttempts is how many times a transaction runs before a retryable error is returned
+	maxTxAttempts = 5
+	// txRetryBaseDelay is the upper bound of the delay before the first retry,
+	// it doubles with every attempt and the actual delay is picked at random below it
+	txRetryBaseDelay = 10 * time.Millisecond
+)
+
+// TxStats reports how often transactions had to be retried
+type TxStats struct {
+	// Retried is the number of attempts repeated after a deadlock or a serialization failure
+	Retried int64 `json:"retried"`
+	// Exhausted is the number of transactions that still failed after the last attempt
+	Exhausted int64 `json:"exhausted"`
+}
+
+// TxStats returns the retry counters of the store since it was created
+func (store *SQLStore) TxStats() TxStats {
+	return TxStats{
+		Retried:   store.retried.Load(),
+		Exhausted: store.exhausted.Load(),
+	}
+}
+
+// execTx runs fn in a transaction with the default isolation level
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+	return store.execTxWithOptions(ctx, nil, fn)
+}
+
+// execTxWithOptions runs fn in a transaction with the given options.
+// When Postgres aborts the transaction because of a deadlock or a serialization failure,
+// the whole transaction, fn included, runs again after a short random delay,
+// so fn must not have side effects outside the transaction.
+func (store *SQLStore) execTxWithOptions(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) (err error) {
+	startTime := time.Now()
+	attempt := 1
+	defer func() {
+		metrics.ObserveTx(time.Since(startTime), attempt-1, err)
+	}()
+
+	for ; ; attempt++ {
+		err = store.runTx(ctx, opts, fn)
+		if err == nil || !IsRetryableTxError(err) {
+			return err
+		}
+
+		if attempt == maxTxAttempts {
+			store.exhausted.Add(1)
+			slog.ErrorContext(ctx, "transaction failed after the last attempt",
+				slog.Int("attempt", attempt),
+				slog.String("error", err.Error()),
+			)
+			return err
+		}
+
+		delay := time.Duration(rand.Int63n(int64(txRetryBaseDelay << (attempt - 1))))
+		store.retried.Add(1)
+		slog.WarnContext(ctx, "retrying transaction",
+			slog.Duration("delay", delay),
+			slog.Int("attempt", attempt+1),
+			slog.Int("max_attempts", maxTxAttempts),
+			slog.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// runTx runs fn in a single transaction, which is rolled back when fn returns an error
+func (store *SQLStore) runTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -35,7 +164,7 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx error %v, rb error %v", err, rbErr)
+			return fmt.Errorf("tx error %w, rb error %v", err, rbErr)
 		}
 		return err
 	}
@@ -60,53 +189,198 @@ type TransferTxResult struct {
 
 // TransferTx performs a money transfer between two accounts.
 // it creates a transfer record, account entries, and updates accounts'  balance.
+// It returns ErrInsufficientFunds if the transfer would take the source account
+// below its overdraft limit; in that case nothing is written.
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
+		result, err = transfer(ctx, q, arg, EntryKindTransfer)
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(ctx, q, AuditActionCreateTransfer, result.Transfer)
+	})
+
+	observeTransfer(EntryKindTransfer, result, err)
+	return result, err
+}
+
+// kinds of operations that produce entries
+const (
+	EntryKindTransfer   = "transfer"
+	EntryKindDeposit    = "deposit"
+	EntryKindWithdrawal = "withdrawal"
+	EntryKindCapture    = "capture"
+	EntryKindReversal   = "reversal"
+)
+
+// transfer moves money between two accounts using the given queries,
+// so it can be run as a part of a bigger transaction.
+// Both entries are linked to the created transfer and marked with the given kind.
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams, kind string) (TransferTxResult, error) {
+	result, err := createTransferRecords(ctx, q, arg, kind)
+	if err != nil {
+		return result, err
+	}
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
+	result.FromAccount, result.ToAccount, err = moveMoney(ctx, q, arg)
+	return result, err
+}
+
+// moveMoney updates the balances of both accounts of a transfer in the order of their IDs,
+// so that concurrent transfers do not deadlock
+func moveMoney(ctx context.Context, q *Queries, arg TransferTxParams) (fromAccount Account, toAccount Account, err error) {
+	if arg.FromAccountID < arg.ToAccountID {
+		fromAccount, toAccount, err = addMoney(ctx, q,
+			arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount,
+		)
+	} else {
+		toAccount, fromAccount, err = addMoney(ctx, q,
+			arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount,
+		)
+	}
+	if IsInsufficientFunds(err) {
+		err = ErrInsufficientFunds
+	}
+
+	return
+}
+
+// createTransferRecords creates the transfer and its two entries without updating the balances
+func createTransferRecords(ctx context.Context, q *Queries, arg TransferTxParams, kind string) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.FromEntry, result.ToEntry, err = createTransferEntries(ctx, q, result.Transfer, kind)
+	return result, err
+}
+
+// createTransferEntries creates the two entries of the transfer marked with the given kind
+func createTransferEntries(ctx context.Context, q *Queries, transfer Transfer, kind string) (fromEntry Entry, toEntry Entry, err error) {
+	fromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  transfer.FromAccountID,
+		Amount:     -transfer.Amount,
+		TransferID: &transfer.ID,
+		Kind:       kind,
+	})
+	if err != nil {
+		return
+	}
+
+	toEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  transfer.ToAccountID,
+		Amount:     transfer.Amount,
+		TransferID: &transfer.ID,
+		Kind:       kind,
+	})
+	return
+}
+
+// IdempotentTransferTxParams contains the parameters of the idempotent transfer transaction.
+type IdempotentTransferTxParams struct {
+	TransferTxParams
+	Username       string        `json:"username"`
+	IdempotencyKey string        `json:"idempotency_key"`
+	KeyDuration    time.Duration `json:"key_duration"`
+}
+
+// IdempotentTransferTx performs a money transfer at most once per idempotency key.
+// The first call with a key runs TransferTx and stores its result together with
+// a hash of the parameters; repeated calls return the stored result without
+// moving money again. Reusing a key with different parameters
+// returns ErrIdempotencyKeyReused.
+func (store *SQLStore) IdempotentTransferTx(ctx context.Context, arg IdempotentTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	requestHash, err := hashRequest(arg.TransferTxParams)
+	if err != nil {
+		return result, err
+	}
+
+	// a replayed result is not counted as a new transfer
+	var replayed bool
+	err = store.execTx(ctx, func(q *Queries) error {
+		replayed = false
+
+		// concurrent calls with the same key wait here for the first one to finish
+		_, err := q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+			Username:    arg.Username,
+			Key:         arg.IdempotencyKey,
+			RequestHash: requestHash,
+			ExpiresAt:   time.Now().Add(arg.KeyDuration),
 		})
+		if err == sql.ErrNoRows {
+			// the key is already in use, replay the stored result
+			key, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+				Username: arg.Username,
+				Key:      arg.IdempotencyKey,
+			})
+			if err != nil {
+				return err
+			}
+
+			if key.RequestHash != requestHash {
+				return ErrIdempotencyKeyReused
+			}
+
+			replayed = true
+			return json.Unmarshal(key.Response, &result)
+		}
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+		result, err = transfer(ctx, q, arg.TransferTxParams, EntryKindTransfer)
 		if err != nil {
 			return err
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+		response, err := json.Marshal(result)
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q,
-				arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount,
-			)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q,
-				arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount,
-			)
+		_, err = q.UpdateIdempotencyKeyResponse(ctx, UpdateIdempotencyKeyResponseParams{
+			Username: arg.Username,
+			Key:      arg.IdempotencyKey,
+			Response: response,
+		})
+		if err != nil {
+			return err
 		}
 
-		return nil
+		return recordTransferEvent(ctx, q, AuditActionCreateTransfer, result.Transfer)
 	})
 
+	if !replayed {
+		observeTransfer(EntryKindTransfer, result, err)
+	}
 	return result, err
 }
 
+// hashRequest returns a hex encoded sha256 hash of the JSON encoded request
+func hashRequest(request any) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 func addMoney(
 	ctx context.Context,
 	q *Queries,
@@ -122,10 +396,27 @@ func addMoney(
 	if err != nil {
 		return
 	}
+	if err = checkFrozen(account1, amount1); err != nil {
+		return
+	}
 
 	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
 		ID:     account2ID,
 		Amount: amount2,
 	})
+	if err != nil {
+		return
+	}
+	err = checkFrozen(account2, amount2)
 	return
 }
+
+// checkFrozen returns ErrAccountFrozen when money is taken from a frozen account.
+// It is checked after the update, which rolls back with the transaction.
+func checkFrozen(account Account, amount int64) error {
+	if amount < 0 && account.IsFrozen {
+		return ErrAccountFrozen
+	}
+
+	return nil
+}
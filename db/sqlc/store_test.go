@@ -2,23 +2,40 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"github.com/aalug/bank-go/utils"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
-func TestTransferTx(t *testing.T) {
-	store := NewStore(testDB)
+// createFundedAccount creates a random account with at least the given balance
+func createFundedAccount(t *testing.T, amount int64) Account {
+	account := createRandomAccount(t)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: amount,
+	})
+	require.NoError(t, err)
 
-	fmt.Println(">> balance before: ", account1.Balance, account2.Balance)
+	return account
+}
+
+func TestTransferTx(t *testing.T) {
+	store := NewStore(testDB)
 
 	// run n concurrent transfer transactions
 	n := 5
 	amount := int64(10)
 
+	account1 := createFundedAccount(t, int64(n)*amount)
+	account2 := createRandomAccount(t)
+
+	fmt.Println(">> balance before: ", account1.Balance, account2.Balance)
+
 	errs := make(chan error)
 	results := make(chan TransferTxResult)
 
@@ -59,6 +76,8 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, fromEntry)
 		require.Equal(t, account1.ID, fromEntry.AccountID)
 		require.Equal(t, -amount, fromEntry.Amount)
+		require.Equal(t, transfer.ID, *fromEntry.TransferID)
+		require.Equal(t, EntryKindTransfer, fromEntry.Kind)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
 
@@ -69,6 +88,8 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, toEntry)
 		require.Equal(t, account2.ID, toEntry.AccountID)
 		require.Equal(t, amount, toEntry.Amount)
+		require.Equal(t, transfer.ID, *toEntry.TransferID)
+		require.Equal(t, EntryKindTransfer, toEntry.Kind)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt)
 
@@ -114,15 +135,15 @@ func TestTransferTx(t *testing.T) {
 func TestTransferTxDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
-
-	fmt.Println(">> balance before: ", account1.Balance, account2.Balance)
-
 	// run n concurrent transfer transactions
 	n := 10
 	amount := int64(10)
 
+	account1 := createFundedAccount(t, int64(n)*amount)
+	account2 := createFundedAccount(t, int64(n)*amount)
+
+	fmt.Println(">> balance before: ", account1.Balance, account2.Balance)
+
 	errs := make(chan error)
 
 	for i := 0; i < n; i++ {
@@ -161,3 +182,239 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+// TestTransferTxSerializableRetry makes another transaction commit an update of the source account
+// after a serializable transfer took its snapshot, so Postgres aborts the transfer with a serialization
+// failure, and checks that the transfer is retried once and then succeeds
+func TestTransferTxSerializableRetry(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+
+	amount := int64(10)
+	account1 := createFundedAccount(t, amount)
+	account2 := createFundedAccount(t, amount)
+
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	statsBefore := store.TxStats()
+
+	snapshotTaken := make(chan struct{})
+	updateCommitted := make(chan struct{})
+
+	attempts := 0
+	errs := make(chan error)
+	go func() {
+		errs <- store.execTxWithOptions(context.Background(), opts, func(q *Queries) error {
+			attempts++
+
+			if attempts == 1 {
+				// the first query takes the snapshot of the transaction
+				_, err := q.GetAccount(context.Background(), account1.ID)
+				if err != nil {
+					return err
+				}
+
+				close(snapshotTaken)
+				<-updateCommitted
+			}
+
+			_, err := transfer(context.Background(), q, TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+			}, EntryKindTransfer)
+			return err
+		})
+	}()
+
+	<-snapshotTaken
+	_, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account1.ID,
+		Amount: amount,
+	})
+	require.NoError(t, err)
+	close(updateCommitted)
+
+	require.NoError(t, <-errs)
+	require.Equal(t, 2, attempts)
+
+	stats := store.TxStats()
+	require.Equal(t, int64(1), stats.Retried-statsBefore.Retried)
+	require.Equal(t, statsBefore.Exhausted, stats.Exhausted)
+
+	// the retried transfer is applied once, on top of the concurrent update
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+	require.Equal(t, account2.Balance+amount, updatedAccount2.Balance)
+}
+
+// TestTransferTxSerializableConcurrent runs opposite-direction transfers between the same accounts
+// with serializable isolation, where concurrent updates of a row abort all but one transaction,
+// and checks that the aborted transactions are retried until all of them succeed
+func TestTransferTxSerializableConcurrent(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+
+	// with n transactions each of them can be aborted by at most n-1 others
+	n := maxTxAttempts
+	amount := int64(10)
+
+	account1 := createFundedAccount(t, int64(n)*amount)
+	account2 := createFundedAccount(t, int64(n)*amount)
+
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	statsBefore := store.TxStats()
+
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		arg := TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		}
+
+		if i%2 == 1 {
+			arg.FromAccountID, arg.ToAccountID = arg.ToAccountID, arg.FromAccountID
+		}
+
+		go func() {
+			errs <- store.execTxWithOptions(context.Background(), opts, func(q *Queries) error {
+				_, err := transfer(context.Background(), q, arg, EntryKindTransfer)
+				return err
+			})
+		}()
+	}
+
+	// no serialization failure reaches the caller
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, statsBefore.Exhausted, store.TxStats().Exhausted)
+
+	// an odd number of transfers moves one amount from account1 to account2
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+
+	diff := int64(n%2) * amount
+	require.Equal(t, account1.Balance-diff, updatedAccount1.Balance)
+	require.Equal(t, account2.Balance+diff, updatedAccount2.Balance)
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	require.True(t, IsRetryableTxError(&pq.Error{Code: serializationFailure}))
+	require.True(t, IsRetryableTxError(fmt.Errorf("tx error %w, rb error %v", &pq.Error{Code: deadlockDetected}, sql.ErrTxDone)))
+	require.False(t, IsRetryableTxError(&pq.Error{Code: "23514"}))
+	require.False(t, IsRetryableTxError(ErrInsufficientFunds))
+	require.False(t, IsRetryableTxError(nil))
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// without an overdraft the whole balance can be moved, but not a cent more
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// nothing was written
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+
+	// an overdraft allows going below zero up to the limit
+	overdraftLimit := utils.RandomAmount()
+	account1, err = testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: overdraftLimit,
+	})
+	require.NoError(t, err)
+
+	res, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + overdraftLimit,
+	})
+	require.NoError(t, err)
+	require.Equal(t, -overdraftLimit, res.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestIdempotentTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	// run n concurrent transfer transactions with the same key
+	n := 5
+	amount := int64(10)
+
+	account1 := createFundedAccount(t, amount)
+	account2 := createRandomAccount(t)
+
+	arg := IdempotentTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		},
+		Username:       account1.Owner,
+		IdempotencyKey: utils.RandomString(32),
+		KeyDuration:    time.Minute,
+	}
+
+	errs := make(chan error)
+	results := make(chan TransferTxResult)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			res, err := store.IdempotentTransferTx(context.Background(), arg)
+			errs <- err
+			results <- res
+		}()
+	}
+
+	// every call returns the same transfer
+	var transferID int64
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+
+		res := <-results
+		require.NotZero(t, res.Transfer.ID)
+		if transferID == 0 {
+			transferID = res.Transfer.ID
+		}
+		require.Equal(t, transferID, res.Transfer.ID)
+		require.Equal(t, account1.Balance-amount, res.FromAccount.Balance)
+	}
+
+	// money was moved only once
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-amount, updatedAccount1.Balance)
+
+	// the same key with different parameters is rejected
+	arg.Amount = amount + 1
+	_, err = store.IdempotentTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
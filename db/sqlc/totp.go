@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ConfirmTOTPTxParams contains the parameters of the confirm TOTP transaction.
+type ConfirmTOTPTxParams struct {
+	Username string `json:"username"`
+	// Step is the time step of the code the enrollment was confirmed with
+	Step int64 `json:"step"`
+	// HashedRecoveryCodes replace the recovery codes the user had before
+	HashedRecoveryCodes []string `json:"hashed_recovery_codes"`
+}
+
+// ConfirmTOTPTx enables two-factor authentication of the user after the code
+// was checked against the pending secret, and stores a new set of recovery codes
+func (store *SQLStore) ConfirmTOTPTx(ctx context.Context, arg ConfirmTOTPTxParams) (UserTOTP, error) {
+	var result UserTOTP
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.UseTOTPStep(ctx, UseTOTPStepParams{
+			Username: arg.Username,
+			Step:     arg.Step,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrTOTPCodeUsed
+			}
+			return err
+		}
+
+		result, err = q.ConfirmUserTOTP(ctx, arg.Username)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrTOTPAlreadyEnabled
+			}
+			return err
+		}
+
+		err = q.DeleteRecoveryCodes(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		for _, hashedCode := range arg.HashedRecoveryCodes {
+			_, err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				Username:   arg.Username,
+				HashedCode: hashedCode,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// the secret is left out of the recorded state
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionEnableTOTP,
+			TargetType: AuditTargetUser,
+			TargetID:   arg.Username,
+			After:      result,
+		})
+	})
+
+	return result, err
+}
@@ -0,0 +1,297 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.18.0
+// source: totp.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const confirmUserTOTP = `-- name: ConfirmUserTOTP :one
+UPDATE user_totp
+SET confirmed_at = now()
+WHERE username = $1
+  AND confirmed_at IS NULL
+RETURNING username, secret, confirmed_at, last_used_step, created_at
+`
+
+func (q *Queries) ConfirmUserTOTP(ctx context.Context, username string) (UserTOTP, error) {
+	row := q.db.QueryRowContext(ctx, confirmUserTOTP, username)
+	var i UserTOTP
+	err := row.Scan(
+		&i.Username,
+		&i.Secret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const countUnusedRecoveryCodes = `-- name: CountUnusedRecoveryCodes :one
+SELECT count(*)
+FROM totp_recovery_codes
+WHERE username = $1
+  AND used_at IS NULL
+`
+
+func (q *Queries) CountUnusedRecoveryCodes(ctx context.Context, username string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUnusedRecoveryCodes, username)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createMFAChallenge = `-- name: CreateMFAChallenge :one
+INSERT INTO mfa_challenges (id, hashed_token, username, client_ip, user_agent, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, hashed_token, username, attempts, client_ip, user_agent, expires_at, used_at, created_at
+`
+
+type CreateMFAChallengeParams struct {
+	ID          uuid.UUID `json:"id"`
+	HashedToken string    `json:"hashed_token"`
+	Username    string    `json:"username"`
+	ClientIp    string    `json:"client_ip"`
+	UserAgent   string    `json:"user_agent"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateMFAChallenge(ctx context.Context, arg CreateMFAChallengeParams) (MFAChallenge, error) {
+	row := q.db.QueryRowContext(ctx, createMFAChallenge,
+		arg.ID,
+		arg.HashedToken,
+		arg.Username,
+		arg.ClientIp,
+		arg.UserAgent,
+		arg.ExpiresAt,
+	)
+	var i MFAChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.HashedToken,
+		&i.Username,
+		&i.Attempts,
+		&i.ClientIp,
+		&i.UserAgent,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createRecoveryCode = `-- name: CreateRecoveryCode :one
+INSERT INTO totp_recovery_codes (username, hashed_code)
+VALUES ($1, $2)
+RETURNING id, username, hashed_code, used_at, created_at
+`
+
+type CreateRecoveryCodeParams struct {
+	Username   string `json:"username"`
+	HashedCode string `json:"hashed_code"`
+}
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (TotpRecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, createRecoveryCode, arg.Username, arg.HashedCode)
+	var i TotpRecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedCode,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createUserTOTP = `-- name: CreateUserTOTP :one
+INSERT INTO user_totp (username, secret)
+VALUES ($1, $2)
+ON CONFLICT (username) DO UPDATE
+    SET secret         = EXCLUDED.secret,
+        last_used_step = 0,
+        created_at     = now()
+WHERE user_totp.confirmed_at IS NULL
+RETURNING username, secret, confirmed_at, last_used_step, created_at
+`
+
+type CreateUserTOTPParams struct {
+	Username string `json:"username"`
+	Secret   string `json:"secret"`
+}
+
+func (q *Queries) CreateUserTOTP(ctx context.Context, arg CreateUserTOTPParams) (UserTOTP, error) {
+	row := q.db.QueryRowContext(ctx, createUserTOTP, arg.Username, arg.Secret)
+	var i UserTOTP
+	err := row.Scan(
+		&i.Username,
+		&i.Secret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE
+FROM totp_recovery_codes
+WHERE username = $1
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, username string) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodes, username)
+	return err
+}
+
+const getMFAChallengeByToken = `-- name: GetMFAChallengeByToken :one
+SELECT id, hashed_token, username, attempts, client_ip, user_agent, expires_at, used_at, created_at
+FROM mfa_challenges
+WHERE hashed_token = $1
+LIMIT 1
+`
+
+func (q *Queries) GetMFAChallengeByToken(ctx context.Context, hashedToken string) (MFAChallenge, error) {
+	row := q.db.QueryRowContext(ctx, getMFAChallengeByToken, hashedToken)
+	var i MFAChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.HashedToken,
+		&i.Username,
+		&i.Attempts,
+		&i.ClientIp,
+		&i.UserAgent,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserTOTP = `-- name: GetUserTOTP :one
+SELECT username, secret, confirmed_at, last_used_step, created_at
+FROM user_totp
+WHERE username = $1
+LIMIT 1
+`
+
+func (q *Queries) GetUserTOTP(ctx context.Context, username string) (UserTOTP, error) {
+	row := q.db.QueryRowContext(ctx, getUserTOTP, username)
+	var i UserTOTP
+	err := row.Scan(
+		&i.Username,
+		&i.Secret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const incrementMFAChallengeAttempts = `-- name: IncrementMFAChallengeAttempts :one
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING id, hashed_token, username, attempts, client_ip, user_agent, expires_at, used_at, created_at
+`
+
+func (q *Queries) IncrementMFAChallengeAttempts(ctx context.Context, id uuid.UUID) (MFAChallenge, error) {
+	row := q.db.QueryRowContext(ctx, incrementMFAChallengeAttempts, id)
+	var i MFAChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.HashedToken,
+		&i.Username,
+		&i.Attempts,
+		&i.ClientIp,
+		&i.UserAgent,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useMFAChallenge = `-- name: UseMFAChallenge :one
+UPDATE mfa_challenges
+SET used_at = now()
+WHERE id = $1
+  AND used_at IS NULL
+RETURNING id, hashed_token, username, attempts, client_ip, user_agent, expires_at, used_at, created_at
+`
+
+func (q *Queries) UseMFAChallenge(ctx context.Context, id uuid.UUID) (MFAChallenge, error) {
+	row := q.db.QueryRowContext(ctx, useMFAChallenge, id)
+	var i MFAChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.HashedToken,
+		&i.Username,
+		&i.Attempts,
+		&i.ClientIp,
+		&i.UserAgent,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :one
+UPDATE totp_recovery_codes
+SET used_at = now()
+WHERE username = $1
+  AND hashed_code = $2
+  AND used_at IS NULL
+RETURNING id, username, hashed_code, used_at, created_at
+`
+
+type UseRecoveryCodeParams struct {
+	Username   string `json:"username"`
+	HashedCode string `json:"hashed_code"`
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (TotpRecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, useRecoveryCode, arg.Username, arg.HashedCode)
+	var i TotpRecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedCode,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useTOTPStep = `-- name: UseTOTPStep :one
+UPDATE user_totp
+SET last_used_step = $1
+WHERE username = $2
+  AND last_used_step < $1
+RETURNING username, secret, confirmed_at, last_used_step, created_at
+`
+
+type UseTOTPStepParams struct {
+	Step     int64  `json:"step"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (UserTOTP, error) {
+	row := q.db.QueryRowContext(ctx, useTOTPStep, arg.Step, arg.Username)
+	var i UserTOTP
+	err := row.Scan(
+		&i.Username,
+		&i.Secret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
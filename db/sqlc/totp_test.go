@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"github.com/aalug/bank-go/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCreateUserTOTP(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := CreateUserTOTPParams{
+		Username: user.Username,
+		Secret:   utils.RandomString(32),
+	}
+
+	userTOTP, err := testQueries.CreateUserTOTP(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, userTOTP.Username)
+	require.Equal(t, arg.Secret, userTOTP.Secret)
+	require.Nil(t, userTOTP.ConfirmedAt)
+	require.Zero(t, userTOTP.LastUsedStep)
+
+	// a pending enrollment can be started over
+	arg.Secret = utils.RandomString(32)
+	userTOTP, err = testQueries.CreateUserTOTP(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Secret, userTOTP.Secret)
+}
+
+func TestConfirmTOTPTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	_, err := testQueries.CreateUserTOTP(context.Background(), CreateUserTOTPParams{
+		Username: user.Username,
+		Secret:   utils.RandomString(32),
+	})
+	require.NoError(t, err)
+
+	codes, err := utils.GenerateRecoveryCodes(utils.RecoveryCodeCount)
+	require.NoError(t, err)
+
+	hashedCodes := make([]string, len(codes))
+	for i, code := range codes {
+		hashedCodes[i] = utils.HashRecoveryCode(code)
+	}
+
+	step := time.Now().Unix() / 30
+	arg := ConfirmTOTPTxParams{
+		Username:            user.Username,
+		Step:                step,
+		HashedRecoveryCodes: hashedCodes,
+	}
+
+	userTOTP, err := store.ConfirmTOTPTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotNil(t, userTOTP.ConfirmedAt)
+	require.Equal(t, step, userTOTP.LastUsedStep)
+
+	count, err := testQueries.CountUnusedRecoveryCodes(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(codes)), count)
+
+	// the same code cannot be used twice
+	_, err = store.ConfirmTOTPTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTOTPCodeUsed)
+
+	// and an enabled secret cannot be replaced or confirmed again
+	_, err = testQueries.CreateUserTOTP(context.Background(), CreateUserTOTPParams{
+		Username: user.Username,
+		Secret:   utils.RandomString(32),
+	})
+	require.Error(t, err)
+
+	arg.Step++
+	_, err = store.ConfirmTOTPTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTOTPAlreadyEnabled)
+
+	// recovery codes can be used once
+	recoveryCode, err := testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: hashedCodes[0],
+	})
+	require.NoError(t, err)
+	require.NotNil(t, recoveryCode.UsedAt)
+
+	_, err = testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: hashedCodes[0],
+	})
+	require.Error(t, err)
+}
+
+func TestMFAChallenge(t *testing.T) {
+	user := createRandomUser(t)
+
+	token, err := utils.GenerateSecureToken(32)
+	require.NoError(t, err)
+
+	arg := CreateMFAChallengeParams{
+		ID:          uuid.New(),
+		HashedToken: utils.HashToken(token),
+		Username:    user.Username,
+		ClientIp:    "198.51.100.1",
+		UserAgent:   "test",
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+
+	challenge, err := testQueries.CreateMFAChallenge(context.Background(), arg)
+	require.NoError(t, err)
+
+	challenge2, err := testQueries.GetMFAChallengeByToken(context.Background(), utils.HashToken(token))
+	require.NoError(t, err)
+	require.Equal(t, challenge.ID, challenge2.ID)
+	require.Equal(t, user.Username, challenge2.Username)
+	require.Zero(t, challenge2.Attempts)
+	require.Nil(t, challenge2.UsedAt)
+
+	challenge2, err = testQueries.IncrementMFAChallengeAttempts(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), challenge2.Attempts)
+
+	challenge2, err = testQueries.UseMFAChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.NotNil(t, challenge2.UsedAt)
+
+	// a challenge can be used once
+	_, err = testQueries.UseMFAChallenge(context.Background(), challenge.ID)
+	require.Error(t, err)
+}
@@ -7,13 +7,128 @@ package db
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 )
 
+const addTransferRefundedAmount = `-- name: AddTransferRefundedAmount :one
+UPDATE transfers
+SET refunded_amount = refunded_amount + $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, fx_quote_id, fx_rate, fx_spread, reversed_transfer_id, refunded_amount
+`
+
+type AddTransferRefundedAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddTransferRefundedAmount(ctx context.Context, arg AddTransferRefundedAmountParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, addTransferRefundedAmount, arg.Amount, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FXQuoteID,
+		&i.FXRate,
+		&i.FXSpread,
+		&i.ReversedTransferID,
+		&i.RefundedAmount,
+	)
+	return i, err
+}
+
+const createFXTransfer = `-- name: CreateFXTransfer :one
+INSERT INTO transfers
+    (from_account_id, to_account_id, amount, to_amount, fx_quote_id, fx_rate, fx_spread)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, fx_quote_id, fx_rate, fx_spread, reversed_transfer_id, refunded_amount
+`
+
+type CreateFXTransferParams struct {
+	FromAccountID int64      `json:"from_account_id"`
+	ToAccountID   int64      `json:"to_account_id"`
+	Amount        int64      `json:"amount"`
+	ToAmount      *int64     `json:"to_amount"`
+	FXQuoteID     *uuid.UUID `json:"fx_quote_id"`
+	FXRate        *string    `json:"fx_rate"`
+	FXSpread      *string    `json:"fx_spread"`
+}
+
+func (q *Queries) CreateFXTransfer(ctx context.Context, arg CreateFXTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, createFXTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.FXQuoteID,
+		arg.FXRate,
+		arg.FXSpread,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FXQuoteID,
+		&i.FXRate,
+		&i.FXSpread,
+		&i.ReversedTransferID,
+		&i.RefundedAmount,
+	)
+	return i, err
+}
+
+const createReversalTransfer = `-- name: CreateReversalTransfer :one
+INSERT INTO transfers
+    (from_account_id, to_account_id, amount, reversed_transfer_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, fx_quote_id, fx_rate, fx_spread, reversed_transfer_id, refunded_amount
+`
+
+type CreateReversalTransferParams struct {
+	FromAccountID      int64  `json:"from_account_id"`
+	ToAccountID        int64  `json:"to_account_id"`
+	Amount             int64  `json:"amount"`
+	ReversedTransferID *int64 `json:"reversed_transfer_id"`
+}
+
+func (q *Queries) CreateReversalTransfer(ctx context.Context, arg CreateReversalTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, createReversalTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ReversedTransferID,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FXQuoteID,
+		&i.FXRate,
+		&i.FXSpread,
+		&i.ReversedTransferID,
+		&i.RefundedAmount,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers
     (from_account_id, to_account_id, amount)
 VALUES ($1, $2, $3)
-RETURNING id, from_account_id, to_account_id, amount, created_at
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, fx_quote_id, fx_rate, fx_spread, reversed_transfer_id, refunded_amount
 `
 
 type CreateTransferParams struct {
@@ -31,12 +146,18 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FXQuoteID,
+		&i.FXRate,
+		&i.FXSpread,
+		&i.ReversedTransferID,
+		&i.RefundedAmount,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, fx_quote_id, fx_rate, fx_spread, reversed_transfer_id, refunded_amount
 FROM transfers
 WHERE id = $1
 LIMIT 1
@@ -51,12 +172,44 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FXQuoteID,
+		&i.FXRate,
+		&i.FXSpread,
+		&i.ReversedTransferID,
+		&i.RefundedAmount,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, fx_quote_id, fx_rate, fx_spread, reversed_transfer_id, refunded_amount
+FROM transfers
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FXQuoteID,
+		&i.FXRate,
+		&i.FXSpread,
+		&i.ReversedTransferID,
+		&i.RefundedAmount,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, fx_quote_id, fx_rate, fx_spread, reversed_transfer_id, refunded_amount
 FROM transfers
 WHERE from_account_id = $1
    OR to_account_id = $2
@@ -91,6 +244,12 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.FXQuoteID,
+			&i.FXRate,
+			&i.FXSpread,
+			&i.ReversedTransferID,
+			&i.RefundedAmount,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+)
+
+// CreateUserTx creates a user and records it in the audit log
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error) {
+	var result User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionCreateUser,
+			TargetType: AuditTargetUser,
+			TargetID:   result.Username,
+			After:      result,
+		})
+	})
+
+	return result, err
+}
+
+// UpdateUserTx updates a user. When the password changes, it also revokes
+// all tokens of the user issued before the password_changed_at of the update
+func (store *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserParams) (User, error) {
+	var result User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.UpdateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		if arg.PasswordChangedAt.Valid {
+			_, err = q.RevokeUserTokens(ctx, RevokeUserTokensParams{
+				Username:     result.Username,
+				IssuedBefore: result.PasswordChangedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return recordAuditEvent(ctx, q, auditChange{
+			Action:     AuditActionUpdateUser,
+			TargetType: AuditTargetUser,
+			TargetID:   result.Username,
+			Before:     before,
+			After:      result,
+		})
+	})
+
+	return result, err
+}
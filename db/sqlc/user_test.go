@@ -83,3 +83,52 @@ func TestUpdateUser(t *testing.T) {
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 }
+
+func TestUpdateUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	user1 := createRandomUser(t)
+
+	// without a password change no tokens are revoked
+	user2, err := store.UpdateUserTx(context.Background(), UpdateUserParams{
+		Username: user1.Username,
+		FullName: sql.NullString{
+			String: utils.RandomOwner(),
+			Valid:  true,
+		},
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, user1.FullName, user2.FullName)
+
+	revocations, err := testQueries.ListUserTokenRevocations(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	for _, revocation := range revocations {
+		require.NotEqual(t, user1.Username, revocation.Username)
+	}
+
+	passwordChangedAt := time.Now()
+	user3, err := store.UpdateUserTx(context.Background(), UpdateUserParams{
+		Username: user1.Username,
+		HashedPassword: sql.NullString{
+			String: utils.RandomString(6),
+			Valid:  true,
+		},
+		PasswordChangedAt: sql.NullTime{
+			Time:  passwordChangedAt,
+			Valid: true,
+		},
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, passwordChangedAt, user3.PasswordChangedAt, time.Second)
+
+	revocations, err = testQueries.ListUserTokenRevocations(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	found := false
+	for _, revocation := range revocations {
+		if revocation.Username == user1.Username {
+			found = true
+			require.True(t, revocation.IssuedBefore.Equal(user3.PasswordChangedAt))
+		}
+	}
+	require.True(t, found)
+}
@@ -9,7 +9,10 @@ CREATE TABLE "users"
     "full_name"           varchar        NOT NULL,
     "email"               varchar UNIQUE NOT NULL,
     "password_changed_at" timestamptz    NOT NULL DEFAULT '0001-01-01',
-    "created_at"          timestamptz    NOT NULL DEFAULT (now())
+    "created_at"          timestamptz    NOT NULL DEFAULT (now()),
+    "role"                varchar        NOT NULL DEFAULT 'customer',
+    "is_email_verified"   boolean        NOT NULL DEFAULT false,
+    CONSTRAINT "supported_role" CHECK ("role" IN ('customer', 'admin'))
 );
 
 CREATE TABLE "accounts"
@@ -18,7 +21,14 @@ CREATE TABLE "accounts"
     "owner"      varchar     NOT NULL,
     "balance"    bigint      NOT NULL,
     "currency"   varchar     NOT NULL,
-    "created_at" timestamptz NOT NULL DEFAULT (now())
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "overdraft_limit" bigint NOT NULL DEFAULT 0,
+    "held_amount" bigint NOT NULL DEFAULT 0,
+    "available_balance" bigint NOT NULL GENERATED ALWAYS AS ("balance" - "held_amount") STORED,
+    "is_frozen" boolean NOT NULL DEFAULT false,
+    CONSTRAINT "overdraft_limit_non_negative" CHECK ("overdraft_limit" >= 0),
+    CONSTRAINT "held_amount_non_negative" CHECK ("held_amount" >= 0),
+    CONSTRAINT "balance_within_overdraft_limit" CHECK ("balance" - "held_amount" >= -"overdraft_limit")
 );
 
 CREATE TABLE "entries"
@@ -26,7 +36,9 @@ CREATE TABLE "entries"
     "id"         bigserial PRIMARY KEY,
     "account_id" bigint      NOT NULL,
     "amount"     bigint      NOT NULL,
-    "created_at" timestamptz NOT NULL DEFAULT (now())
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "transfer_id" bigint,
+    "kind"       varchar     NOT NULL
 );
 
 CREATE TABLE "transfers"
@@ -35,7 +47,14 @@ CREATE TABLE "transfers"
     "from_account_id" bigint      NOT NULL,
     "to_account_id"   bigint      NOT NULL,
     "amount"          bigint      NOT NULL,
-    "created_at"      timestamptz NOT NULL DEFAULT (now())
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "to_amount"       bigint,
+    "fx_quote_id"     uuid UNIQUE,
+    "fx_rate"         numeric(20, 10),
+    "fx_spread"       numeric(10, 6),
+    "reversed_transfer_id" bigint,
+    "refunded_amount" bigint      NOT NULL DEFAULT 0,
+    CONSTRAINT "refunded_amount_within_amount" CHECK ("refunded_amount" >= 0 AND "refunded_amount" <= "amount")
 );
 
 CREATE TABLE "sessions"
@@ -47,31 +66,452 @@ CREATE TABLE "sessions"
     "client_ip"     varchar     NOT NULL,
     "is_blocked"    boolean     NOT NULL DEFAULT false,
     "expires_at"    timestamptz NOT NULL,
+    "created_at"    timestamptz NOT NULL DEFAULT (now()),
+    "family_id"     uuid        NOT NULL,
+    "parent_id"     uuid,
+    "rotated_at"    timestamptz
+);
+
+CREATE TABLE "idempotency_keys"
+(
+    "username"     varchar     NOT NULL,
+    "key"          varchar     NOT NULL,
+    "request_hash" varchar     NOT NULL,
+    "response"     jsonb       NOT NULL DEFAULT '{}',
+    "expires_at"   timestamptz NOT NULL,
+    "created_at"   timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("username", "key")
+);
+
+CREATE TABLE "fx_rates"
+(
+    "currency"     varchar PRIMARY KEY,
+    "rate"         numeric(20, 10) NOT NULL,
+    "published_on" date            NOT NULL,
+    "updated_at"   timestamptz     NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "fx_quotes"
+(
+    "id"            uuid PRIMARY KEY,
+    "username"      varchar         NOT NULL,
+    "from_currency" varchar         NOT NULL,
+    "to_currency"   varchar         NOT NULL,
+    "rate"          numeric(20, 10) NOT NULL,
+    "spread"        numeric(10, 6)  NOT NULL,
+    "is_used"       boolean         NOT NULL DEFAULT false,
+    "expires_at"    timestamptz     NOT NULL,
+    "created_at"    timestamptz     NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "holds"
+(
+    "id"              bigserial PRIMARY KEY,
+    "account_id"      bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'authorized',
+    "captured_amount" bigint      NOT NULL DEFAULT 0,
+    "transfer_id"     bigint,
+    "expires_at"      timestamptz NOT NULL,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    CONSTRAINT "hold_amount_positive" CHECK ("amount" > 0),
+    CONSTRAINT "hold_captured_amount" CHECK ("captured_amount" >= 0 AND "captured_amount" <= "amount"),
+    CONSTRAINT "hold_status" CHECK ("status" IN ('authorized', 'captured', 'voided', 'expired'))
+);
+
+CREATE TABLE "scheduled_transfers"
+(
+    "id"              bigserial PRIMARY KEY,
+    "owner"           varchar     NOT NULL,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "recurrence"      varchar     NOT NULL,
+    "start_at"        timestamptz NOT NULL,
+    "next_run_at"     timestamptz NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'active',
+    "failure_count"   int         NOT NULL DEFAULT 0,
+    "claimed_until"   timestamptz,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    "api_key_id"      bigint
+);
+
+CREATE TABLE "scheduled_transfer_runs"
+(
+    "id"                    bigserial PRIMARY KEY,
+    "scheduled_transfer_id" bigint      NOT NULL,
+    "scheduled_for"         timestamptz NOT NULL,
+    "transfer_id"           bigint,
+    "succeeded"             boolean     NOT NULL,
+    "error_message"         varchar     NOT NULL DEFAULT '',
+    "created_at"            timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "revoked_tokens"
+(
+    "id"         uuid PRIMARY KEY,
+    "username"   varchar     NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "user_token_revocations"
+(
+    "username"      varchar PRIMARY KEY,
+    "issued_before" timestamptz NOT NULL,
+    "created_at"    timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "login_failures"
+(
+    "key_type"       varchar     NOT NULL,
+    "key"            varchar     NOT NULL,
+    "failures"       integer     NOT NULL,
+    "last_failed_at" timestamptz NOT NULL,
+    "locked_until"   timestamptz NOT NULL,
+    PRIMARY KEY ("key_type", "key")
+);
+
+CREATE TABLE "user_totp"
+(
+    "username"       varchar PRIMARY KEY,
+    "secret"         varchar     NOT NULL,
+    "confirmed_at"   timestamptz,
+    "last_used_step" bigint      NOT NULL DEFAULT 0,
+    "created_at"     timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "totp_recovery_codes"
+(
+    "id"          bigserial PRIMARY KEY,
+    "username"    varchar     NOT NULL,
+    "hashed_code" varchar     NOT NULL,
+    "used_at"     timestamptz,
+    "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "mfa_challenges"
+(
+    "id"           uuid PRIMARY KEY,
+    "hashed_token" varchar     NOT NULL,
+    "username"     varchar     NOT NULL,
+    "attempts"     integer     NOT NULL DEFAULT 0,
+    "client_ip"    varchar     NOT NULL,
+    "user_agent"   varchar     NOT NULL,
+    "expires_at"   timestamptz NOT NULL,
+    "used_at"      timestamptz,
+    "created_at"   timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "email_tokens"
+(
+    "id"           bigserial PRIMARY KEY,
+    "hashed_token" varchar     NOT NULL,
+    "username"     varchar     NOT NULL,
+    "purpose"      varchar     NOT NULL,
+    "email"        varchar     NOT NULL,
+    "expires_at"   timestamptz NOT NULL,
+    "used_at"      timestamptz,
+    "created_at"   timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "api_keys"
+(
+    "id"            bigserial PRIMARY KEY,
+    "prefix"        varchar     NOT NULL,
+    "hashed_secret" varchar     NOT NULL,
+    "owner"         varchar     NOT NULL,
+    "name"          varchar     NOT NULL,
+    "scopes"        varchar[]   NOT NULL,
+    "account_ids"   bigint[]    NOT NULL DEFAULT '{}',
+    "created_by"    varchar     NOT NULL,
+    "expires_at"    timestamptz,
+    "last_used_at"  timestamptz,
+    "last_used_ip"  varchar     NOT NULL DEFAULT '',
+    "revoked_at"    timestamptz,
     "created_at"    timestamptz NOT NULL DEFAULT (now())
 );
 
+CREATE TABLE "oauth_clients"
+(
+    "id"            varchar PRIMARY KEY,
+    "hashed_secret" varchar     NOT NULL DEFAULT '',
+    "name"          varchar     NOT NULL,
+    "owner"         varchar     NOT NULL,
+    "redirect_uris" varchar[]   NOT NULL,
+    "scopes"        varchar[]   NOT NULL,
+    "created_at"    timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "oauth_consents"
+(
+    "username"   varchar     NOT NULL,
+    "client_id"  varchar     NOT NULL,
+    "scopes"     varchar[]   NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "updated_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("username", "client_id")
+);
+
+CREATE TABLE "oauth_authorization_codes"
+(
+    "id"             bigserial PRIMARY KEY,
+    "hashed_code"    varchar     NOT NULL,
+    "client_id"      varchar     NOT NULL,
+    "username"       varchar     NOT NULL,
+    "redirect_uri"   varchar     NOT NULL,
+    "scopes"         varchar[]   NOT NULL,
+    "code_challenge" varchar     NOT NULL,
+    "expires_at"     timestamptz NOT NULL,
+    "used_at"        timestamptz,
+    "created_at"     timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "oauth_refresh_tokens"
+(
+    "id"           bigserial PRIMARY KEY,
+    "hashed_token" varchar     NOT NULL,
+    "client_id"    varchar     NOT NULL,
+    "username"     varchar     NOT NULL,
+    "scopes"       varchar[]   NOT NULL,
+    "expires_at"   timestamptz NOT NULL,
+    "revoked_at"   timestamptz,
+    "created_at"   timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "audit_events"
+(
+    "id"          bigserial PRIMARY KEY,
+    "actor"       varchar     NOT NULL,
+    "action"      varchar     NOT NULL,
+    "target_type" varchar     NOT NULL,
+    "target_id"   varchar     NOT NULL,
+    "before"      json,
+    "after"       json,
+    "request_id"  varchar     NOT NULL DEFAULT '',
+    "client_ip"   varchar     NOT NULL DEFAULT '',
+    "user_agent"  varchar     NOT NULL DEFAULT '',
+    "prev_hash"   varchar     NOT NULL,
+    "hash"        varchar     NOT NULL,
+    "created_at"  timestamptz NOT NULL
+);
+
+CREATE TABLE "admin_actions"
+(
+    "id"             bigserial PRIMARY KEY,
+    "admin_username" varchar     NOT NULL,
+    "action"         varchar     NOT NULL,
+    "target_type"    varchar     NOT NULL,
+    "target_id"      varchar     NOT NULL,
+    "created_at"     timestamptz NOT NULL DEFAULT (now())
+);
+
 CREATE INDEX ON "accounts" ("owner");
 
 CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
 
 CREATE INDEX ON "entries" ("account_id");
 
+CREATE INDEX ON "entries" ("transfer_id");
+
 CREATE INDEX ON "transfers" ("from_account_id");
 
 CREATE INDEX ON "transfers" ("to_account_id");
 
 CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
 
+CREATE INDEX ON "transfers" ("reversed_transfer_id");
+
+CREATE INDEX ON "sessions" ("family_id");
+
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'how far below zero the balance may go';
+
+COMMENT ON COLUMN "accounts"."held_amount" IS 'sum of authorized holds on the account';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'balance less the held amount';
+
+COMMENT ON COLUMN "accounts"."is_frozen" IS 'no money can leave a frozen account';
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
+
+CREATE INDEX ON "fx_quotes" ("username");
+
+CREATE INDEX ON "holds" ("account_id");
+
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "next_run_at");
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+
+CREATE INDEX ON "admin_actions" ("admin_username");
+
+CREATE INDEX ON "admin_actions" ("target_type", "target_id");
+
+CREATE INDEX ON "revoked_tokens" ("expires_at");
+
+COMMENT ON COLUMN "revoked_tokens"."id" IS 'id of the token payload';
+
+COMMENT ON COLUMN "revoked_tokens"."expires_at" IS 'expiry of the token, the row can be deleted afterwards';
+
+COMMENT ON COLUMN "user_token_revocations"."issued_before" IS 'tokens of the user issued before this time are revoked';
+
+COMMENT ON COLUMN "login_failures"."key_type" IS 'username or ip';
+
+COMMENT ON COLUMN "login_failures"."key" IS 'username or client ip, unknown usernames are tracked too';
+
+COMMENT ON COLUMN "login_failures"."failures" IS 'failed logins in a row, reset once the lockout duration passes without a failure';
+
+COMMENT ON COLUMN "login_failures"."locked_until" IS 'no login attempts are checked before this time';
+
+CREATE UNIQUE INDEX ON "totp_recovery_codes" ("username", "hashed_code");
+
+CREATE UNIQUE INDEX ON "mfa_challenges" ("hashed_token");
+
+COMMENT ON COLUMN "user_totp"."confirmed_at" IS 'two-factor authentication is enabled once the enrollment is confirmed with a code';
+
+COMMENT ON COLUMN "user_totp"."last_used_step" IS 'time step of the last accepted code, codes of this or earlier steps are refused';
+
+COMMENT ON COLUMN "totp_recovery_codes"."hashed_code" IS 'sha256 of the normalized one-time recovery code';
+
+COMMENT ON COLUMN "mfa_challenges"."hashed_token" IS 'sha256 of the challenge token returned by the first login step';
+
+COMMENT ON COLUMN "mfa_challenges"."attempts" IS 'failed attempts at the second login step';
+
+CREATE UNIQUE INDEX ON "email_tokens" ("hashed_token");
+
+CREATE INDEX ON "email_tokens" ("username", "purpose");
+
+COMMENT ON COLUMN "users"."is_email_verified" IS 'users can transfer money only after verifying their email address';
+
+COMMENT ON COLUMN "email_tokens"."hashed_token" IS 'sha256 of the token sent in the email';
+
+COMMENT ON COLUMN "email_tokens"."purpose" IS 'verify_email or reset_password';
+
+COMMENT ON COLUMN "email_tokens"."email" IS 'address the token was sent to';
+
+CREATE UNIQUE INDEX ON "api_keys" ("prefix");
+
+CREATE INDEX ON "api_keys" ("owner");
+
+COMMENT ON COLUMN "api_keys"."prefix" IS 'public part of the key that it is looked up by';
+
+COMMENT ON COLUMN "api_keys"."hashed_secret" IS 'sha256 of the secret part of the key';
+
+COMMENT ON COLUMN "api_keys"."scopes" IS 'what the key can be used for, for example accounts:read or transfers:write';
+
+COMMENT ON COLUMN "api_keys"."account_ids" IS 'accounts of the owner the key can act on, empty for all of them';
+
+COMMENT ON COLUMN "api_keys"."created_by" IS 'the owner or an admin';
+
+CREATE INDEX ON "oauth_clients" ("owner");
+
+CREATE UNIQUE INDEX ON "oauth_authorization_codes" ("hashed_code");
+
+CREATE UNIQUE INDEX ON "oauth_refresh_tokens" ("hashed_token");
+
+CREATE INDEX ON "oauth_refresh_tokens" ("username", "client_id");
+
+COMMENT ON COLUMN "oauth_clients"."id" IS 'public client_id of the app';
+
+COMMENT ON COLUMN "oauth_clients"."hashed_secret" IS 'sha256 of the client secret, empty for public clients';
+
+COMMENT ON COLUMN "oauth_clients"."owner" IS 'user that registered the app';
+
+COMMENT ON COLUMN "oauth_clients"."scopes" IS 'the most the app can ask users for';
+
+COMMENT ON COLUMN "oauth_consents"."scopes" IS 'scopes the user has granted to the app';
+
+COMMENT ON COLUMN "oauth_authorization_codes"."hashed_code" IS 'sha256 of the code sent to the redirect URI';
+
+COMMENT ON COLUMN "oauth_authorization_codes"."code_challenge" IS 'S256 PKCE challenge the code verifier is checked against';
+
+COMMENT ON COLUMN "oauth_refresh_tokens"."hashed_token" IS 'sha256 of the refresh token';
+
+CREATE INDEX ON "audit_events" ("actor");
+
+CREATE INDEX ON "audit_events" ("target_type", "target_id");
+
+CREATE INDEX ON "audit_events" ("created_at");
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'user that made the change, system for background jobs';
+
+COMMENT ON COLUMN "audit_events"."before" IS 'state of the target before the change, null if it was created';
+
+COMMENT ON COLUMN "audit_events"."after" IS 'state of the target after the change, null if it was deleted';
+
+COMMENT ON COLUMN "audit_events"."prev_hash" IS 'hash of the previous event, empty for the first one';
+
+COMMENT ON COLUMN "audit_events"."hash" IS 'sha256 of the event and prev_hash, any change to a past event breaks the chain';
+
 COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
 
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer that produced the entry, if any';
+
+COMMENT ON COLUMN "entries"."kind" IS 'operation that produced the entry, e.g. transfer, deposit or withdrawal';
+
 COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
 
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited in the currency of to_account, set only for cross-currency transfers';
+
+COMMENT ON COLUMN "transfers"."fx_rate" IS 'rate of the quote used for a cross-currency transfer';
+
+COMMENT ON COLUMN "transfers"."fx_spread" IS 'spread of the quote used for a cross-currency transfer';
+
+COMMENT ON COLUMN "transfers"."reversed_transfer_id" IS 'transfer this one reverses, set only for reversals and refunds';
+
+COMMENT ON COLUMN "transfers"."refunded_amount" IS 'sum of the reversals of this transfer';
+
+COMMENT ON COLUMN "sessions"."family_id" IS 'id of the session created at login, shared by all sessions rotated from it';
+
+COMMENT ON COLUMN "sessions"."parent_id" IS 'session whose refresh token was exchanged for this one';
+
+COMMENT ON COLUMN "sessions"."rotated_at" IS 'set once the refresh token has been exchanged, presenting it again means it was reused';
+
+COMMENT ON COLUMN "fx_rates"."rate" IS 'units of the currency for one euro, as published by the ECB';
+
+COMMENT ON COLUMN "fx_quotes"."rate" IS 'mid-market units of to_currency for one unit of from_currency';
+
+COMMENT ON COLUMN "fx_quotes"."spread" IS 'fraction of the converted amount kept by the bank';
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'sha256 of the request the key was first used with';
+
+COMMENT ON COLUMN "idempotency_keys"."response" IS 'serialized result returned on replay';
+
+COMMENT ON COLUMN "holds"."account_id" IS 'account whose funds are reserved';
+
+COMMENT ON COLUMN "holds"."to_account_id" IS 'account that receives the money when the hold is captured';
+
+COMMENT ON COLUMN "holds"."status" IS 'authorized, captured, voided or expired';
+
+COMMENT ON COLUMN "holds"."transfer_id" IS 'transfer created by the capture';
+
+COMMENT ON COLUMN "scheduled_transfers"."recurrence" IS 'once, daily, weekly or monthly';
+
+COMMENT ON COLUMN "scheduled_transfers"."start_at" IS 'first run, later runs keep its time and day of the month';
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active, suspended after repeated failures, or completed';
+
+COMMENT ON COLUMN "scheduled_transfers"."failure_count" IS 'failed runs in a row';
+
+COMMENT ON COLUMN "scheduled_transfers"."claimed_until" IS 'set while a worker executes the transfer';
+
+COMMENT ON COLUMN "scheduled_transfers"."api_key_id" IS 'API key the transfer was scheduled with, its limits are checked before every run';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."scheduled_for" IS 'next_run_at of the scheduled transfer at the time of the run';
+
 ALTER TABLE "accounts"
     ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
 
 ALTER TABLE "entries"
     ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
 
+ALTER TABLE "entries"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
 ALTER TABLE "transfers"
     ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
 
@@ -80,3 +520,93 @@ ALTER TABLE "transfers"
 
 ALTER TABLE "sessions"
     ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "sessions"
+    ADD FOREIGN KEY ("parent_id") REFERENCES "sessions" ("id");
+
+ALTER TABLE "idempotency_keys"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "fx_quotes"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfers"
+    ADD FOREIGN KEY ("fx_quote_id") REFERENCES "fx_quotes" ("id");
+
+ALTER TABLE "transfers"
+    ADD FOREIGN KEY ("reversed_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "holds"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfer_runs"
+    ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "scheduled_transfer_runs"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "admin_actions"
+    ADD FOREIGN KEY ("admin_username") REFERENCES "users" ("username");
+
+ALTER TABLE "revoked_tokens"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "user_token_revocations"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "user_totp"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "totp_recovery_codes"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "mfa_challenges"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "email_tokens"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "api_keys"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "api_keys"
+    ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("api_key_id") REFERENCES "api_keys" ("id");
+
+ALTER TABLE "oauth_clients"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "oauth_consents"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "oauth_consents"
+    ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("id");
+
+ALTER TABLE "oauth_authorization_codes"
+    ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("id");
+
+ALTER TABLE "oauth_authorization_codes"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "oauth_refresh_tokens"
+    ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("id");
+
+ALTER TABLE "oauth_refresh_tokens"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,88 @@
+package fx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const (
+	// RatePrecision is the number of decimal places rates are stored with
+	RatePrecision = 10
+	// SpreadPrecision is the number of decimal places spreads are stored with
+	SpreadPrecision = 6
+)
+
+// ErrAmountTooSmall is returned when an amount converts to zero units of the target currency
+var ErrAmountTooSmall = errors.New("amount is too small to be converted")
+
+// ErrAmountTooLarge is returned when a converted amount does not fit in int64
+var ErrAmountTooLarge = errors.New("converted amount is too large")
+
+// ParseRate parses a positive decimal number, e.g. a rate or a spread
+func ParseRate(value string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a decimal number", value)
+	}
+
+	if rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%q is not positive", value)
+	}
+
+	return rate, nil
+}
+
+// ParseSpread parses a spread, a fraction in the range [0, 1)
+func ParseSpread(value string) (*big.Rat, error) {
+	spread, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a decimal number", value)
+	}
+
+	if spread.Sign() < 0 || spread.Cmp(big.NewRat(1, 1)) >= 0 {
+		return nil, fmt.Errorf("spread %q must be in the range [0, 1)", value)
+	}
+
+	return spread, nil
+}
+
+// FormatRate formats a rate the way it is stored in the database
+func FormatRate(rate *big.Rat) string {
+	return rate.FloatString(RatePrecision)
+}
+
+// FormatSpread formats a spread the way it is stored in the database
+func FormatSpread(spread *big.Rat) string {
+	return spread.FloatString(SpreadPrecision)
+}
+
+// CrossRate returns the number of units of the target currency for one unit
+// of the source currency, given both currencies' rates against the euro.
+// The result is rounded to RatePrecision, so it can be stored without a loss.
+func CrossRate(fromPerEuro, toPerEuro *big.Rat) (*big.Rat, error) {
+	rate := new(big.Rat).Quo(toPerEuro, fromPerEuro)
+	return ParseRate(FormatRate(rate))
+}
+
+// Convert converts an amount with the mid-market rate, keeping the spread.
+// The result is rounded down, so the customer never gets more than the rate allows.
+func Convert(amount int64, rate, spread *big.Rat) (int64, error) {
+	customerShare := new(big.Rat).Sub(big.NewRat(1, 1), spread)
+
+	converted := new(big.Rat).SetInt64(amount)
+	converted.Mul(converted, rate)
+	converted.Mul(converted, customerShare)
+
+	// Quo truncates towards zero, amounts are positive
+	result := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !result.IsInt64() {
+		return 0, ErrAmountTooLarge
+	}
+
+	if result.Sign() <= 0 {
+		return 0, ErrAmountTooSmall
+	}
+
+	return result.Int64(), nil
+}
@@ -0,0 +1,60 @@
+package fx
+
+import (
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestCrossRate(t *testing.T) {
+	usd, err := ParseRate("1.0866")
+	require.NoError(t, err)
+	pln, err := ParseRate("4.4388")
+	require.NoError(t, err)
+
+	rate, err := CrossRate(usd, pln)
+	require.NoError(t, err)
+	require.Equal(t, "4.0850358918", FormatRate(rate))
+
+	rate, err = CrossRate(big.NewRat(1, 1), usd)
+	require.NoError(t, err)
+	require.Equal(t, "1.0866000000", FormatRate(rate))
+}
+
+func TestConvert(t *testing.T) {
+	rate, err := ParseRate("1.0866")
+	require.NoError(t, err)
+
+	noSpread, err := ParseSpread("0")
+	require.NoError(t, err)
+	converted, err := Convert(10000, rate, noSpread)
+	require.NoError(t, err)
+	require.Equal(t, int64(10866), converted)
+
+	// the spread is kept by the bank and the result is rounded down
+	spread, err := ParseSpread("0.005")
+	require.NoError(t, err)
+	converted, err = Convert(10000, rate, spread)
+	require.NoError(t, err)
+	require.Equal(t, int64(10811), converted)
+
+	smallRate, err := ParseRate("0.001")
+	require.NoError(t, err)
+	_, err = Convert(10, smallRate, spread)
+	require.ErrorIs(t, err, ErrAmountTooSmall)
+
+	_, err = Convert(math.MaxInt64, rate, noSpread)
+	require.ErrorIs(t, err, ErrAmountTooLarge)
+}
+
+func TestParseSpread(t *testing.T) {
+	for _, value := range []string{"-0.1", "1", "1.5", "abc"} {
+		_, err := ParseSpread(value)
+		require.Error(t, err, value)
+	}
+
+	spread, err := ParseSpread("0.0025")
+	require.NoError(t, err)
+	require.Equal(t, "0.002500", FormatSpread(spread))
+}
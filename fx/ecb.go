@@ -0,0 +1,168 @@
+package fx
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BaseCurrency is the currency all ECB reference rates are quoted against
+const BaseCurrency = "EUR"
+
+// Rates contains the euro reference rates published on one day
+type Rates struct {
+	PublishedOn time.Time
+	// Rates maps currency codes to units of the currency for one euro
+	Rates map[string]*big.Rat
+}
+
+// ErrNoRates is returned when a rates file does not contain any rates
+var ErrNoRates = errors.New("no rates found")
+
+// LoadFile reads rates from an ECB XML (eurofxref-daily.xml) or CSV (eurofxref.csv) file.
+// Files with the history of rates are accepted as well, only the most recent day is returned.
+func LoadFile(path string) (Rates, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Rates{}, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		return ParseECBXML(file)
+	case ".csv":
+		return ParseECBCSV(file)
+	}
+
+	return Rates{}, fmt.Errorf("unsupported rates file %s, expected .xml or .csv", path)
+}
+
+type ecbEnvelope struct {
+	Cube struct {
+		Days []struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string `xml:"currency,attr"`
+				Rate     string `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+// ParseECBXML parses rates in the format of the ECB eurofxref XML files
+func ParseECBXML(r io.Reader) (Rates, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return Rates{}, fmt.Errorf("cannot decode rates: %w", err)
+	}
+
+	if len(envelope.Cube.Days) == 0 {
+		return Rates{}, ErrNoRates
+	}
+
+	// days are listed from the most recent one
+	day := envelope.Cube.Days[0]
+	rates, err := newRates(day.Time)
+	if err != nil {
+		return Rates{}, err
+	}
+
+	for _, rate := range day.Rates {
+		if err := rates.add(rate.Currency, rate.Rate); err != nil {
+			return Rates{}, err
+		}
+	}
+
+	return rates.validate()
+}
+
+// ParseECBCSV parses rates in the format of the ECB eurofxref CSV files,
+// a header row with currency codes followed by one row per day
+func ParseECBCSV(r io.Reader) (Rates, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return Rates{}, ErrNoRates
+	}
+	if err != nil {
+		return Rates{}, fmt.Errorf("cannot read rates header: %w", err)
+	}
+
+	// rows are listed from the most recent day
+	row, err := reader.Read()
+	if err == io.EOF {
+		return Rates{}, ErrNoRates
+	}
+	if err != nil {
+		return Rates{}, fmt.Errorf("cannot read rates: %w", err)
+	}
+
+	rates, err := newRates(row[0])
+	if err != nil {
+		return Rates{}, err
+	}
+
+	for i := 1; i < len(header) && i < len(row); i++ {
+		currency := strings.TrimSpace(header[i])
+		value := strings.TrimSpace(row[i])
+		// the files end every row with a comma and use N/A for missing rates
+		if currency == "" || value == "" || value == "N/A" {
+			continue
+		}
+
+		if err := rates.add(currency, value); err != nil {
+			return Rates{}, err
+		}
+	}
+
+	return rates.validate()
+}
+
+// ecbDateLayouts are the date formats used by the daily and the historical ECB files
+var ecbDateLayouts = []string{"2006-01-02", "2 January 2006"}
+
+func newRates(date string) (Rates, error) {
+	date = strings.TrimSpace(date)
+	for _, layout := range ecbDateLayouts {
+		publishedOn, err := time.Parse(layout, date)
+		if err == nil {
+			return Rates{
+				PublishedOn: publishedOn,
+				Rates: map[string]*big.Rat{
+					BaseCurrency: big.NewRat(1, 1),
+				},
+			}, nil
+		}
+	}
+
+	return Rates{}, fmt.Errorf("invalid rates date %q", date)
+}
+
+func (rates Rates) add(currency, value string) error {
+	rate, err := ParseRate(value)
+	if err != nil {
+		return fmt.Errorf("invalid rate of %s: %w", currency, err)
+	}
+
+	rates.Rates[strings.ToUpper(strings.TrimSpace(currency))] = rate
+	return nil
+}
+
+func (rates Rates) validate() (Rates, error) {
+	// the base currency is always present
+	if len(rates.Rates) < 2 {
+		return Rates{}, ErrNoRates
+	}
+	return rates, nil
+}
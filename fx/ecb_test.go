@@ -0,0 +1,110 @@
+package fx
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const dailyXML = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time='2023-06-30'>
+			<Cube currency='USD' rate='1.0866'/>
+			<Cube currency='PLN' rate='4.4388'/>
+			<Cube currency='CAD' rate='1.4415'/>
+		</Cube>
+		<Cube time='2023-06-29'>
+			<Cube currency='USD' rate='1.0895'/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+const dailyCSV = `Date, USD, JPY, PLN, CAD, 
+30 June 2023, 1.0866, 157.16, 4.4388, 1.4415, 
+`
+
+const historicalCSV = `Date,USD,CYP,PLN,
+2023-06-30,1.0866,N/A,4.4388,
+2023-06-29,1.0895,N/A,4.4353,
+`
+
+func requireRate(t *testing.T, expected string, rate *big.Rat) {
+	require.NotNil(t, rate)
+	require.Equal(t, expected, rate.FloatString(4))
+}
+
+func TestParseECBXML(t *testing.T) {
+	rates, err := ParseECBXML(strings.NewReader(dailyXML))
+	require.NoError(t, err)
+
+	require.Equal(t, time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC), rates.PublishedOn)
+	require.Len(t, rates.Rates, 4)
+	requireRate(t, "1.0000", rates.Rates[BaseCurrency])
+	requireRate(t, "1.0866", rates.Rates["USD"])
+	requireRate(t, "4.4388", rates.Rates["PLN"])
+	requireRate(t, "1.4415", rates.Rates["CAD"])
+}
+
+func TestParseECBCSV(t *testing.T) {
+	rates, err := ParseECBCSV(strings.NewReader(dailyCSV))
+	require.NoError(t, err)
+
+	require.Equal(t, time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC), rates.PublishedOn)
+	require.Len(t, rates.Rates, 5)
+	requireRate(t, "1.0866", rates.Rates["USD"])
+	requireRate(t, "157.1600", rates.Rates["JPY"])
+
+	// historical files skip missing rates and return only the most recent day
+	rates, err = ParseECBCSV(strings.NewReader(historicalCSV))
+	require.NoError(t, err)
+
+	require.Equal(t, time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC), rates.PublishedOn)
+	require.Len(t, rates.Rates, 3)
+	require.NotContains(t, rates.Rates, "CYP")
+	requireRate(t, "4.4388", rates.Rates["PLN"])
+}
+
+func TestParseECBInvalid(t *testing.T) {
+	_, err := ParseECBXML(strings.NewReader("<gesmes:Envelope></gesmes:Envelope>"))
+	require.ErrorIs(t, err, ErrNoRates)
+
+	_, err = ParseECBXML(strings.NewReader("not xml"))
+	require.Error(t, err)
+
+	_, err = ParseECBCSV(strings.NewReader("Date, USD\n"))
+	require.ErrorIs(t, err, ErrNoRates)
+
+	_, err = ParseECBCSV(strings.NewReader("Date, USD\nyesterday, 1.0866\n"))
+	require.Error(t, err)
+
+	_, err = ParseECBCSV(strings.NewReader("Date, USD\n2023-06-30, -1\n"))
+	require.Error(t, err)
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	xmlPath := filepath.Join(dir, "eurofxref-daily.xml")
+	require.NoError(t, os.WriteFile(xmlPath, []byte(dailyXML), 0o600))
+	rates, err := LoadFile(xmlPath)
+	require.NoError(t, err)
+	requireRate(t, "1.0866", rates.Rates["USD"])
+
+	csvPath := filepath.Join(dir, "eurofxref.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte(dailyCSV), 0o600))
+	rates, err = LoadFile(csvPath)
+	require.NoError(t, err)
+	requireRate(t, "1.0866", rates.Rates["USD"])
+
+	_, err = LoadFile(filepath.Join(dir, "rates.json"))
+	require.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// getOwnAccount returns the account if it exists, has the given currency
+// and belongs to the authenticated user
+func (server *Server) getOwnAccount(ctx context.Context, authPayload *token.Payload, accountID int64, currency string) (db.Account, error) {
+	account, err := server.getAccount(ctx, accountID, currency)
+	if err != nil {
+		return account, err
+	}
+
+	if account.Owner != authPayload.Username || !authPayload.AllowsAccount(account.ID) {
+		return account, status.Errorf(codes.PermissionDenied, "account does not belong to the authenticated user")
+	}
+
+	return account, nil
+}
+
+// getOwnAccountByID returns the account if it exists and belongs to the authenticated user
+func (server *Server) getOwnAccountByID(ctx context.Context, authPayload *token.Payload, accountID int64) (db.Account, error) {
+	account, err := server.findAccount(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+
+	if account.Owner != authPayload.Username || !authPayload.AllowsAccount(account.ID) {
+		return account, status.Errorf(codes.PermissionDenied, "account does not belong to the authenticated user")
+	}
+
+	return account, nil
+}
+
+// getAccount returns the account if it exists, has the given currency
+// and is not a system account
+func (server *Server) getAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := server.findAccount(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+
+	if db.IsSystemAccountOwner(account.Owner) {
+		return account, status.Errorf(codes.PermissionDenied, "%s", db.ErrSystemAccount)
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
+	}
+
+	return account, nil
+}
+
+// findAccount returns the account with the given ID
+func (server *Server) findAccount(ctx context.Context, accountID int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return account, status.Errorf(codes.NotFound, "account not found")
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	return account, nil
+}
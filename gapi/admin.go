@@ -0,0 +1,47 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordAdminAction writes an action that only reads data to the audit trail of the admins
+func (server *Server) recordAdminAction(ctx context.Context, authPayload *token.Payload, action, targetType, targetID string) error {
+	_, err := server.store.CreateAdminAction(ctx, db.CreateAdminActionParams{
+		AdminUsername: authPayload.Username,
+		Action:        action,
+		TargetType:    targetType,
+		TargetID:      targetID,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to record admin action: %s", err)
+	}
+
+	return nil
+}
+
+// setAccountFrozen freezes or unfreezes an account that is not a system account
+func (server *Server) setAccountFrozen(ctx context.Context, authPayload *token.Payload, accountID int64, frozen bool) (db.Account, error) {
+	account, err := server.findAccount(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+
+	if db.IsSystemAccountOwner(account.Owner) {
+		return account, status.Errorf(codes.PermissionDenied, "%s", db.ErrSystemAccount)
+	}
+
+	account, err = server.store.SetAccountFrozenTx(ctx, db.SetAccountFrozenTxParams{
+		AdminUsername: authPayload.Username,
+		AccountID:     account.ID,
+		Frozen:        frozen,
+	})
+	if err != nil {
+		return account, status.Errorf(codes.Internal, "failed to update account: %s", err)
+	}
+
+	return account, nil
+}
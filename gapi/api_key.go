@@ -0,0 +1,176 @@
+package gapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"time"
+)
+
+// verifyAPIKey returns the payload of a valid API key and records its use
+func (server *Server) verifyAPIKey(ctx context.Context, key string) (*token.Payload, error) {
+	prefix, secret, err := utils.ParseAPIKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	apiKey, err := server.store.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid API key")
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(secret)), []byte(apiKey.HashedSecret)) != 1 {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	if apiKey.RevokedAt != nil {
+		return nil, fmt.Errorf("API key has been revoked")
+	}
+
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		return nil, fmt.Errorf("API key has expired")
+	}
+
+	// the last use is only informative, a failure to record it does not fail the call
+	err = server.store.TouchAPIKey(ctx, db.TouchAPIKeyParams{
+		ID:       apiKey.ID,
+		ClientIp: server.extractMetadata(ctx).ClientIP,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot record the use of API key",
+			slog.Int64("api_key_id", apiKey.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	payload := &token.Payload{
+		Username:   apiKey.Owner,
+		Role:       apiKey.OwnerRole,
+		IssuedAt:   apiKey.CreatedAt,
+		APIKeyID:   apiKey.ID,
+		Scopes:     apiKey.Scopes,
+		AccountIDs: apiKey.AccountIds,
+	}
+	if apiKey.ExpiresAt != nil {
+		payload.ExpiredAt = *apiKey.ExpiresAt
+	}
+
+	return payload, nil
+}
+
+// issueAPIKey creates an API key of the owner and returns it with the key that is shown only once.
+// The admin scope is granted only to admins and the allowed accounts must belong to the owner.
+func (server *Server) issueAPIKey(ctx context.Context, authPayload *token.Payload, owner string, request *pb.CreateAPIKeyRequest, byAdmin bool) (string, db.APIKey, error) {
+	var apiKey db.APIKey
+
+	user, err := server.store.GetUser(ctx, owner)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", apiKey, status.Errorf(codes.NotFound, "user not found")
+		}
+		return "", apiKey, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	for _, scope := range request.GetScopes() {
+		if scope == token.ScopeAdmin && user.Role != utils.AdminRole {
+			return "", apiKey, status.Errorf(codes.PermissionDenied, "admin scope can be granted only to admins")
+		}
+	}
+
+	var expiresAt *time.Time
+	if request.ExpiresAt != nil {
+		t := request.GetExpiresAt().AsTime()
+		if !t.After(time.Now()) {
+			return "", apiKey, status.Errorf(codes.InvalidArgument, "expires_at must be in the future")
+		}
+		expiresAt = &t
+	}
+
+	for _, accountID := range request.GetAccountIds() {
+		account, err := server.findAccount(ctx, accountID)
+		if err != nil {
+			return "", apiKey, err
+		}
+
+		if account.Owner != owner {
+			return "", apiKey, status.Errorf(codes.PermissionDenied, "account [%d] does not belong to the owner of the key", accountID)
+		}
+	}
+
+	key, prefix, secret, err := utils.GenerateAPIKey()
+	if err != nil {
+		return "", apiKey, status.Errorf(codes.Internal, "failed to generate API key: %s", err)
+	}
+
+	params := db.CreateAPIKeyParams{
+		Prefix:       prefix,
+		HashedSecret: utils.HashToken(secret),
+		Owner:        owner,
+		Name:         request.GetName(),
+		Scopes:       request.GetScopes(),
+		AccountIds:   request.GetAccountIds(),
+		CreatedBy:    authPayload.Username,
+		ExpiresAt:    expiresAt,
+	}
+	if params.AccountIds == nil {
+		params.AccountIds = []int64{}
+	}
+
+	if byAdmin {
+		apiKey, err = server.store.AdminCreateAPIKeyTx(ctx, params)
+	} else {
+		apiKey, err = server.store.CreateAPIKeyTx(ctx, params)
+	}
+	if err != nil {
+		return "", apiKey, status.Errorf(codes.Internal, "failed to create API key: %s", err)
+	}
+
+	return key, apiKey, nil
+}
+
+// validateCreateAPIKeyFields validates the fields of a request that creates an API key.
+// The names of the violated fields are prefixed with the prefix.
+func validateCreateAPIKeyFields(request *pb.CreateAPIKeyRequest, prefix string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateStringLength(request.GetName(), 1, 100); err != nil {
+		violations = append(violations, fieldViolation(prefix+"name", err))
+	}
+
+	if len(request.GetScopes()) == 0 {
+		violations = append(violations, fieldViolation(prefix+"scopes", fmt.Errorf("must contain at least one scope")))
+	}
+
+	seenScopes := make(map[string]bool)
+	for _, scope := range request.GetScopes() {
+		if err := validation.ValidateScope(scope); err != nil {
+			violations = append(violations, fieldViolation(prefix+"scopes", err))
+		} else if seenScopes[scope] {
+			violations = append(violations, fieldViolation(prefix+"scopes", fmt.Errorf("duplicate scope %s", scope)))
+		}
+		seenScopes[scope] = true
+	}
+
+	seenAccounts := make(map[int64]bool)
+	for _, accountID := range request.GetAccountIds() {
+		if err := validation.ValidateID(accountID); err != nil {
+			violations = append(violations, fieldViolation(prefix+"account_ids", err))
+		} else if seenAccounts[accountID] {
+			violations = append(violations, fieldViolation(prefix+"account_ids", fmt.Errorf("duplicate account ID %d", accountID)))
+		}
+		seenAccounts[accountID] = true
+	}
+
+	return violations
+}
@@ -0,0 +1,29 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/logger"
+)
+
+// withAuditInfo returns a copy of the context with the audit info of the call.
+// An empty actor is recorded as anonymous.
+func (server *Server) withAuditInfo(ctx context.Context, actor string) context.Context {
+	mtdt := server.extractMetadata(ctx)
+
+	return db.WithAuditInfo(ctx, db.AuditInfo{
+		Actor:     actor,
+		RequestID: logger.RequestID(ctx),
+		ClientIP:  mtdt.ClientIP,
+		UserAgent: mtdt.UserAgent,
+	})
+}
+
+// withAuditActor returns a copy of the context with a different actor in its audit info,
+// for public calls that still identify the user, e.g. with a refresh token
+func withAuditActor(ctx context.Context, actor string) context.Context {
+	info := db.AuditInfoFromContext(ctx)
+	info.Actor = actor
+
+	return db.WithAuditInfo(ctx, info)
+}
@@ -9,11 +9,24 @@ import (
 )
 
 const (
-	authorizationHeader = "authorization"
-	authorizationType   = "bearer"
+	authorizationHeader     = "authorization"
+	authorizationType       = "bearer"
+	authorizationTypeAPIKey = "apikey"
 )
 
+// authorizeUser returns the payload of the access token of the authenticated user.
+// The token is verified by the auth interceptor according to the method's access policy.
 func (server *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
+	authPayload, ok := authPayloadFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("missing access token payload")
+	}
+
+	return authPayload, nil
+}
+
+// verifyAccessToken verifies the bearer token or the API key of the authorization header
+func (server *Server) verifyAccessToken(ctx context.Context) (*token.Payload, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return nil, fmt.Errorf("missing metadata")
@@ -31,6 +44,10 @@ func (server *Server) authorizeUser(ctx context.Context) (*token.Payload, error)
 	}
 
 	authType := strings.ToLower(fields[0])
+	if authType == authorizationTypeAPIKey {
+		return server.verifyAPIKey(ctx, fields[1])
+	}
+
 	if authType != authorizationType {
 		return nil, fmt.Errorf("unsupported authorization type %s", authType)
 	}
@@ -41,5 +58,10 @@ func (server *Server) authorizeUser(ctx context.Context) (*token.Payload, error)
 		return nil, fmt.Errorf("invalid access token: %w", err)
 	}
 
+	err = server.revocations.Check(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %w", err)
+	}
+
 	return payload, nil
 }
@@ -4,8 +4,135 @@ import (
 	db "github.com/aalug/bank-go/db/sqlc"
 	"github.com/aalug/bank-go/pb"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
 )
 
+// convertAccount converts a db.Account object to an Account object
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:               account.ID,
+		Owner:            account.Owner,
+		Balance:          account.Balance,
+		Currency:         account.Currency,
+		CreatedAt:        timestamppb.New(account.CreatedAt),
+		OverdraftLimit:   account.OverdraftLimit,
+		HeldAmount:       account.HeldAmount,
+		AvailableBalance: account.AvailableBalance,
+		IsFrozen:         account.IsFrozen,
+	}
+}
+
+// convertTransfer converts a db.Transfer object to a Transfer object
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	res := &pb.Transfer{
+		Id:                 transfer.ID,
+		FromAccountId:      transfer.FromAccountID,
+		ToAccountId:        transfer.ToAccountID,
+		Amount:             transfer.Amount,
+		CreatedAt:          timestamppb.New(transfer.CreatedAt),
+		ToAmount:           transfer.ToAmount,
+		FxRate:             transfer.FXRate,
+		FxSpread:           transfer.FXSpread,
+		ReversedTransferId: transfer.ReversedTransferID,
+		RefundedAmount:     transfer.RefundedAmount,
+	}
+
+	if transfer.FXQuoteID != nil {
+		quoteID := transfer.FXQuoteID.String()
+		res.FxQuoteId = &quoteID
+	}
+
+	return res
+}
+
+// convertStatementLine converts a db.ListAccountStatementRow object to an Entry object
+// with the counterparty of the transfer that produced it
+func convertStatementLine(row db.ListAccountStatementRow) *pb.Entry {
+	res := convertEntry(db.Entry{
+		ID:         row.ID,
+		AccountID:  row.AccountID,
+		Amount:     row.Amount,
+		CreatedAt:  row.CreatedAt,
+		TransferID: row.TransferID,
+		Kind:       row.Kind,
+	})
+
+	if row.CounterpartyAccountID.Valid {
+		res.CounterpartyAccountId = &row.CounterpartyAccountID.Int64
+	}
+
+	if row.CounterpartyOwner.Valid {
+		res.CounterpartyOwner = &row.CounterpartyOwner.String
+	}
+
+	return res
+}
+
+// convertQuote converts a db.FXQuote object to a Quote object
+func convertQuote(quote db.FXQuote) *pb.Quote {
+	return &pb.Quote{
+		Id:           quote.ID.String(),
+		FromCurrency: quote.FromCurrency,
+		ToCurrency:   quote.ToCurrency,
+		Rate:         quote.Rate,
+		Spread:       quote.Spread,
+		ExpiresAt:    timestamppb.New(quote.ExpiresAt),
+		CreatedAt:    timestamppb.New(quote.CreatedAt),
+	}
+}
+
+// convertEntry converts a db.Entry object to an Entry object
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:         entry.ID,
+		AccountId:  entry.AccountID,
+		Amount:     entry.Amount,
+		CreatedAt:  timestamppb.New(entry.CreatedAt),
+		TransferId: entry.TransferID,
+		Kind:       entry.Kind,
+	}
+}
+
+// convertScheduledTransfer converts a db.ScheduledTransfer object to a ScheduledTransfer object
+func convertScheduledTransfer(scheduledTransfer db.ScheduledTransfer) *pb.ScheduledTransfer {
+	return &pb.ScheduledTransfer{
+		Id:            scheduledTransfer.ID,
+		Owner:         scheduledTransfer.Owner,
+		FromAccountId: scheduledTransfer.FromAccountID,
+		ToAccountId:   scheduledTransfer.ToAccountID,
+		Amount:        scheduledTransfer.Amount,
+		Recurrence:    scheduledTransfer.Recurrence,
+		StartAt:       timestamppb.New(scheduledTransfer.StartAt),
+		NextRunAt:     timestamppb.New(scheduledTransfer.NextRunAt),
+		Status:        scheduledTransfer.Status,
+		FailureCount:  scheduledTransfer.FailureCount,
+		CreatedAt:     timestamppb.New(scheduledTransfer.CreatedAt),
+	}
+}
+
+// convertSession converts a db.Session object to a Session object.
+// The refresh token of the session is never returned.
+func convertSession(session db.Session) *pb.Session {
+	return &pb.Session{
+		Id:        session.ID.String(),
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+		CreatedAt: timestamppb.New(session.CreatedAt),
+	}
+}
+
+// convertUserLockout converts the db.LoginFailure of a username to a UserLockout object
+func convertUserLockout(username string, failure db.LoginFailure) *pb.UserLockout {
+	return &pb.UserLockout{
+		Username:     username,
+		Failures:     failure.Failures,
+		LastFailedAt: timestamppb.New(failure.LastFailedAt),
+		LockedUntil:  timestamppb.New(failure.LockedUntil),
+		IsLocked:     time.Now().Before(failure.LockedUntil),
+	}
+}
+
 // convertUser converts a db.User object to a UserResponse object
 func convertUser(user db.User) *pb.User {
 	return &pb.User{
@@ -14,5 +141,79 @@ func convertUser(user db.User) *pb.User {
 		Email:             user.Email,
 		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
 		CreatedAt:         timestamppb.New(user.CreatedAt),
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
+	}
+}
+
+// convertAPIKey converts a db.APIKey object to an APIKey object.
+// The hash of the secret is never returned.
+func convertAPIKey(apiKey db.APIKey) *pb.APIKey {
+	res := &pb.APIKey{
+		Id:         apiKey.ID,
+		Prefix:     apiKey.Prefix,
+		Owner:      apiKey.Owner,
+		Name:       apiKey.Name,
+		Scopes:     apiKey.Scopes,
+		AccountIds: apiKey.AccountIds,
+		CreatedBy:  apiKey.CreatedBy,
+		LastUsedIp: apiKey.LastUsedIp,
+		CreatedAt:  timestamppb.New(apiKey.CreatedAt),
+	}
+
+	if apiKey.ExpiresAt != nil {
+		res.ExpiresAt = timestamppb.New(*apiKey.ExpiresAt)
+	}
+
+	if apiKey.LastUsedAt != nil {
+		res.LastUsedAt = timestamppb.New(*apiKey.LastUsedAt)
+	}
+
+	if apiKey.RevokedAt != nil {
+		res.RevokedAt = timestamppb.New(*apiKey.RevokedAt)
+	}
+
+	return res
+}
+
+// convertOAuthClient converts a db.OAuthClient object to an OAuthClient object
+func convertOAuthClient(client db.OAuthClient) *pb.OAuthClient {
+	return &pb.OAuthClient{
+		Id:             client.ID,
+		Name:           client.Name,
+		Owner:          client.Owner,
+		RedirectUris:   client.RedirectUris,
+		Scopes:         client.Scopes,
+		IsConfidential: client.HashedSecret != "",
+		CreatedAt:      timestamppb.New(client.CreatedAt),
+	}
+}
+
+// convertOAuthConsent converts a db.OAuthConsent object to an OAuthConsent object
+func convertOAuthConsent(consent db.OAuthConsent) *pb.OAuthConsent {
+	return &pb.OAuthConsent{
+		ClientId:  consent.ClientID,
+		Scopes:    consent.Scopes,
+		CreatedAt: timestamppb.New(consent.CreatedAt),
+		UpdatedAt: timestamppb.New(consent.UpdatedAt),
+	}
+}
+
+// convertAuditEvent converts a db.AuditEvent object to an AuditEvent object
+func convertAuditEvent(event db.AuditEvent) *pb.AuditEvent {
+	return &pb.AuditEvent{
+		Id:         event.ID,
+		Actor:      event.Actor,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetId:   event.TargetID,
+		Before:     string(event.Before),
+		After:      string(event.After),
+		RequestId:  event.RequestID,
+		ClientIp:   event.ClientIp,
+		UserAgent:  event.UserAgent,
+		PrevHash:   event.PrevHash,
+		Hash:       event.Hash,
+		CreatedAt:  timestamppb.New(event.CreatedAt),
 	}
 }
@@ -0,0 +1,51 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/mail"
+	"github.com/aalug/bank-go/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// sendVerificationEmail sends the user a link that verifies their current email address
+func (server *Server) sendVerificationEmail(ctx context.Context, user db.User) error {
+	emailToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+
+	_, err = server.store.CreateEmailTokenTx(ctx, db.CreateEmailTokenParams{
+		HashedToken: utils.HashToken(emailToken),
+		Username:    user.Username,
+		Purpose:     db.EmailTokenVerifyEmail,
+		Email:       user.Email,
+		ExpiresAt:   time.Now().Add(server.config.EmailVerificationDuration),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := strings.TrimSuffix(server.config.AppURL, "/") + "/v1/verify_email?token=" + url.QueryEscape(emailToken)
+	msg := mail.NewVerifyEmailMessage(user.Email, user.FullName, link, server.config.EmailVerificationDuration)
+
+	return server.mailer.Send(ctx, msg)
+}
+
+// checkEmailVerified returns a permission denied error if the user did not verify their email address
+func (server *Server) checkEmailVerified(ctx context.Context, username string) error {
+	user, err := server.store.GetUser(ctx, username)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	if !user.IsEmailVerified {
+		return status.Errorf(codes.PermissionDenied, "email address must be verified before transferring money")
+	}
+
+	return nil
+}
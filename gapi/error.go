@@ -4,6 +4,8 @@ import (
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"time"
 )
 
 // fieldViolation is a helper function to create a FieldViolation.
@@ -29,4 +31,18 @@ func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) er
 
 func unauthenticatedError(err error) error {
 	return status.Errorf(codes.Unauthenticated, "unauthorized: %s", err)
+}
+
+// resourceExhaustedError is a helper function to create a ResourceExhausted error
+// that tells the client how long to wait before retrying.
+func resourceExhaustedError(message string, retryDelay time.Duration) error {
+	retryInfo := &errdetails.RetryInfo{RetryDelay: durationpb.New(retryDelay)}
+	statusExhausted := status.New(codes.ResourceExhausted, message)
+
+	statusDetails, err := statusExhausted.WithDetails(retryInfo)
+	if err != nil {
+		return statusExhausted.Err()
+	}
+
+	return statusDetails.Err()
 }
\ No newline at end of file
@@ -0,0 +1,207 @@
+package gapi
+
+import (
+	"context"
+	"github.com/aalug/bank-go/logger"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+// accessLevel is who is allowed to call a method
+type accessLevel int
+
+const (
+	// accessPublic methods can be called without an access token
+	accessPublic accessLevel = iota + 1
+	// accessAuthenticated methods require a valid access token
+	accessAuthenticated
+	// accessAdmin methods require a valid access token of an admin
+	accessAdmin
+)
+
+// methodAccess is the access policy of every method served by the gRPC server.
+// Methods that are not listed here are denied, so a new RPC must be added explicitly.
+var methodAccess = map[string]accessLevel{
+	pb.GoBank_CreateUser_FullMethodName:                accessPublic,
+	pb.GoBank_LoginUser_FullMethodName:                 accessPublic,
+	pb.GoBank_VerifyLoginMFA_FullMethodName:            accessPublic,
+	pb.GoBank_VerifyEmail_FullMethodName:               accessPublic,
+	pb.GoBank_RequestPasswordReset_FullMethodName:      accessPublic,
+	pb.GoBank_ResetPassword_FullMethodName:             accessPublic,
+	pb.GoBank_RenewAccessToken_FullMethodName:          accessPublic,
+	pb.GoBank_LogoutUser_FullMethodName:                accessPublic,
+	pb.GoBank_OAuthToken_FullMethodName:                accessPublic,
+	pb.GoBank_RevokeOAuthToken_FullMethodName:          accessPublic,
+	pb.GoBank_UpdateUser_FullMethodName:                accessAuthenticated,
+	pb.GoBank_EnrollTOTP_FullMethodName:                accessAuthenticated,
+	pb.GoBank_ConfirmTOTP_FullMethodName:               accessAuthenticated,
+	pb.GoBank_SendVerificationEmail_FullMethodName:     accessAuthenticated,
+	pb.GoBank_ListSessions_FullMethodName:              accessAuthenticated,
+	pb.GoBank_RevokeSession_FullMethodName:             accessAuthenticated,
+	pb.GoBank_CreateAPIKey_FullMethodName:              accessAuthenticated,
+	pb.GoBank_ListAPIKeys_FullMethodName:               accessAuthenticated,
+	pb.GoBank_RevokeAPIKey_FullMethodName:              accessAuthenticated,
+	pb.GoBank_CreateOAuthClient_FullMethodName:         accessAuthenticated,
+	pb.GoBank_ListOAuthClients_FullMethodName:          accessAuthenticated,
+	pb.GoBank_GetOAuthAuthorization_FullMethodName:     accessAuthenticated,
+	pb.GoBank_AuthorizeOAuthClient_FullMethodName:      accessAuthenticated,
+	pb.GoBank_ListOAuthConsents_FullMethodName:         accessAuthenticated,
+	pb.GoBank_RevokeOAuthConsent_FullMethodName:        accessAuthenticated,
+	pb.GoBank_CreateAccount_FullMethodName:             accessAuthenticated,
+	pb.GoBank_GetAccount_FullMethodName:                accessAuthenticated,
+	pb.GoBank_ListAccounts_FullMethodName:              accessAuthenticated,
+	pb.GoBank_DeleteAccount_FullMethodName:             accessAuthenticated,
+	pb.GoBank_CreateTransfer_FullMethodName:            accessAuthenticated,
+	pb.GoBank_ListTransfers_FullMethodName:             accessAuthenticated,
+	pb.GoBank_ListEntries_FullMethodName:               accessAuthenticated,
+	pb.GoBank_ReverseTransfer_FullMethodName:           accessAuthenticated,
+	pb.GoBank_CreateQuote_FullMethodName:               accessAuthenticated,
+	pb.GoBank_CreateFXTransfer_FullMethodName:          accessAuthenticated,
+	pb.GoBank_CreateScheduledTransfer_FullMethodName:   accessAuthenticated,
+	pb.GoBank_GetScheduledTransfer_FullMethodName:      accessAuthenticated,
+	pb.GoBank_ListScheduledTransfers_FullMethodName:    accessAuthenticated,
+	pb.GoBank_UpdateScheduledTransfer_FullMethodName:   accessAuthenticated,
+	pb.GoBank_DeleteScheduledTransfer_FullMethodName:   accessAuthenticated,
+	pb.GoBank_AdminSearchUsers_FullMethodName:          accessAdmin,
+	pb.GoBank_AdminFreezeAccount_FullMethodName:        accessAdmin,
+	pb.GoBank_AdminUnfreezeAccount_FullMethodName:      accessAdmin,
+	pb.GoBank_AdminBlockSession_FullMethodName:         accessAdmin,
+	pb.GoBank_AdminListAccountTransfers_FullMethodName: accessAdmin,
+	pb.GoBank_AdminGetUserLockout_FullMethodName:       accessAdmin,
+	pb.GoBank_AdminUnlockUser_FullMethodName:           accessAdmin,
+	pb.GoBank_AdminCreateAPIKey_FullMethodName:         accessAdmin,
+	pb.GoBank_AdminRevokeAPIKey_FullMethodName:         accessAdmin,
+	pb.GoBank_AdminListAuditEvents_FullMethodName:      accessAdmin,
+	pb.GoBank_AdminVerifyAuditChain_FullMethodName:     accessAdmin,
+	pb.GoBank_Deposit_FullMethodName:                   accessAdmin,
+	pb.GoBank_Withdraw_FullMethodName:                  accessAdmin,
+
+	grpc_reflection_v1alpha.ServerReflection_ServerReflectionInfo_FullMethodName: accessPublic,
+}
+
+// methodScopes is the scope an API key or an OAuth client needs for every method it can call.
+// Methods that are not listed here, like managing users, sessions, API keys and OAuth clients,
+// take only access tokens of users.
+var methodScopes = map[string]string{
+	pb.GoBank_GetAccount_FullMethodName:                token.ScopeAccountsRead,
+	pb.GoBank_ListAccounts_FullMethodName:              token.ScopeAccountsRead,
+	pb.GoBank_ListEntries_FullMethodName:               token.ScopeAccountsRead,
+	pb.GoBank_CreateAccount_FullMethodName:             token.ScopeAccountsWrite,
+	pb.GoBank_DeleteAccount_FullMethodName:             token.ScopeAccountsWrite,
+	pb.GoBank_ListTransfers_FullMethodName:             token.ScopeTransfersRead,
+	pb.GoBank_GetScheduledTransfer_FullMethodName:      token.ScopeTransfersRead,
+	pb.GoBank_ListScheduledTransfers_FullMethodName:    token.ScopeTransfersRead,
+	pb.GoBank_CreateTransfer_FullMethodName:            token.ScopeTransfersWrite,
+	pb.GoBank_ReverseTransfer_FullMethodName:           token.ScopeTransfersWrite,
+	pb.GoBank_CreateQuote_FullMethodName:               token.ScopeTransfersWrite,
+	pb.GoBank_CreateFXTransfer_FullMethodName:          token.ScopeTransfersWrite,
+	pb.GoBank_CreateScheduledTransfer_FullMethodName:   token.ScopeTransfersWrite,
+	pb.GoBank_UpdateScheduledTransfer_FullMethodName:   token.ScopeTransfersWrite,
+	pb.GoBank_DeleteScheduledTransfer_FullMethodName:   token.ScopeTransfersWrite,
+	pb.GoBank_AdminSearchUsers_FullMethodName:          token.ScopeAdmin,
+	pb.GoBank_AdminFreezeAccount_FullMethodName:        token.ScopeAdmin,
+	pb.GoBank_AdminUnfreezeAccount_FullMethodName:      token.ScopeAdmin,
+	pb.GoBank_AdminBlockSession_FullMethodName:         token.ScopeAdmin,
+	pb.GoBank_AdminListAccountTransfers_FullMethodName: token.ScopeAdmin,
+	pb.GoBank_AdminGetUserLockout_FullMethodName:       token.ScopeAdmin,
+	pb.GoBank_AdminUnlockUser_FullMethodName:           token.ScopeAdmin,
+	pb.GoBank_AdminListAuditEvents_FullMethodName:      token.ScopeAdmin,
+	pb.GoBank_AdminVerifyAuditChain_FullMethodName:     token.ScopeAdmin,
+	pb.GoBank_Deposit_FullMethodName:                   token.ScopeAdmin,
+	pb.GoBank_Withdraw_FullMethodName:                  token.ScopeAdmin,
+}
+
+// authPayloadKey is the context key of the payload of a verified access token
+type authPayloadKey struct{}
+
+// UnaryAuthInterceptor authenticates and authorizes unary calls
+// according to the access policy of the called method
+func (server *Server) UnaryAuthInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	ctx, err := server.authorizeMethod(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// StreamAuthInterceptor authenticates and authorizes streaming calls
+// according to the access policy of the called method
+func (server *Server) StreamAuthInterceptor(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := server.authorizeMethod(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+}
+
+// contextStream is a grpc.ServerStream with the context returned by an interceptor
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *contextStream) Context() context.Context {
+	return stream.ctx
+}
+
+// authorizeMethod checks the access policy of the method. For methods that are not public
+// it verifies the access token or the API key and returns a context that carries its payload.
+// The returned context also carries the audit info of the call, with the user of the token as the actor.
+func (server *Server) authorizeMethod(ctx context.Context, method string) (context.Context, error) {
+	access, ok := methodAccess[method]
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "method %s is not allowed", method)
+	}
+
+	if access == accessPublic {
+		return server.withAuditInfo(ctx, ""), nil
+	}
+
+	authPayload, err := server.verifyAccessToken(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if access == accessAdmin && authPayload.Role != utils.AdminRole {
+		return nil, status.Errorf(codes.PermissionDenied, "method %s requires the admin role", method)
+	}
+
+	if authPayload.IsScoped() {
+		scope, ok := methodScopes[method]
+		if !ok {
+			return nil, status.Errorf(codes.PermissionDenied, "method %s cannot be called with an API key or an OAuth access token", method)
+		}
+
+		if !authPayload.HasScope(scope) {
+			return nil, status.Errorf(codes.PermissionDenied, "the %s scope was not granted", scope)
+		}
+	}
+
+	logger.SetUsername(ctx, authPayload.Username)
+
+	ctx = context.WithValue(ctx, authPayloadKey{}, authPayload)
+	return server.withAuditInfo(ctx, authPayload.Username), nil
+}
+
+// authPayloadFromContext returns the payload of the access token verified by the interceptor
+func authPayloadFromContext(ctx context.Context) (*token.Payload, bool) {
+	authPayload, ok := ctx.Value(authPayloadKey{}).(*token.Payload)
+	return authPayload, ok
+}
@@ -0,0 +1,73 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestUnaryAuthInterceptorAdminMethods(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		role   string
+		code   codes.Code
+	}{
+		{
+			name:   "Customer Deposit",
+			method: pb.GoBank_Deposit_FullMethodName,
+			role:   utils.CustomerRole,
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "Customer Withdraw",
+			method: pb.GoBank_Withdraw_FullMethodName,
+			role:   utils.CustomerRole,
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "Admin Deposit",
+			method: pb.GoBank_Deposit_FullMethodName,
+			role:   utils.AdminRole,
+			code:   codes.OK,
+		},
+		{
+			name:   "Admin Withdraw",
+			method: pb.GoBank_Withdraw_FullMethodName,
+			role:   utils.AdminRole,
+			code:   codes.OK,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil) // nil because the interceptor does not use the db for access tokens
+
+			accessToken, _, err := server.tokenMaker.CreateToken(utils.RandomOwner(), tc.role, time.Minute)
+			require.NoError(t, err)
+
+			md := metadata.Pairs(authorizationHeader, fmt.Sprintf("%s %s", authorizationType, accessToken))
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			var called bool
+			handler := func(ctx context.Context, req any) (any, error) {
+				called = true
+				return nil, nil
+			}
+
+			_, err = server.UnaryAuthInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+			require.Equal(t, tc.code, status.Code(err))
+			require.Equal(t, tc.code == codes.OK, called)
+		})
+	}
+}
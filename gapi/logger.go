@@ -0,0 +1,85 @@
+package gapi
+
+import (
+	"context"
+	"github.com/aalug/bank-go/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"time"
+)
+
+// UnaryLoggerInterceptor logs every unary call. It must run before the auth interceptor,
+// so calls that fail authentication are logged too.
+func (server *Server) UnaryLoggerInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	startTime := time.Now()
+	ctx = server.withRequest(ctx)
+
+	result, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, startTime, err)
+
+	return result, err
+}
+
+// StreamLoggerInterceptor logs every streaming call once it ends
+func (server *Server) StreamLoggerInterceptor(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	startTime := time.Now()
+	ctx := server.withRequest(stream.Context())
+
+	err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	logCall(ctx, info.FullMethod, startTime, err)
+
+	return err
+}
+
+// withRequest returns a copy of the context that carries the request the call belongs to.
+// The request ID is accepted from the x-request-id metadata, which the gateway fills
+// from the X-Request-ID header, or generated, and it is sent back in the response headers.
+func (server *Server) withRequest(ctx context.Context) context.Context {
+	requestID := logger.AcceptRequestID(server.extractMetadata(ctx).RequestID)
+	ctx, _ = logger.WithRequest(ctx, requestID)
+
+	// a header that cannot be sent does not fail the call
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, requestID))
+
+	return ctx
+}
+
+// logCall logs the outcome of a call, server errors are errors and client errors are warnings
+func logCall(ctx context.Context, method string, startTime time.Time, err error) {
+	code := status.Code(err)
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK:
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("protocol", "grpc"),
+		slog.String("method", method),
+		slog.Int("status_code", int(code)),
+		slog.String("status_text", code.String()),
+		slog.Duration("latency", time.Since(startTime)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+
+	slog.LogAttrs(ctx, level, "received a gRPC request", attrs...)
+}
@@ -0,0 +1,26 @@
+package gapi
+
+import (
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/mail"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := utils.Config{
+		TokenSymmetricKey:       utils.RandomString(32),
+		AccessTokenDuration:     time.Minute,
+		IdempotencyKeyDuration:  time.Hour,
+		LoginFailureThreshold:   5,
+		LoginIPFailureThreshold: 50,
+		LoginLockoutDuration:    time.Minute,
+	}
+
+	server, err := NewServer(config, store, mail.NewMemorySender())
+	require.NoError(t, err)
+
+	return server
+}
@@ -4,43 +4,88 @@ import (
 	"context"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"net"
+	"strings"
 )
 
 const (
 	grpcGatewayUserAgentHeader = "grpcgateway-user-agent"
 	userAgentHeader            = "user-agent"
 	xForwardedForHeader        = "x-forwarded-for"
+	idempotencyKeyHeader       = "idempotency-key"
+	requestIDHeader            = "x-request-id"
 )
 
 type Metadata struct {
-	UserAgent string
-	ClientIP  string
+	UserAgent      string
+	ClientIP       string
+	IdempotencyKey string
+	RequestID      string
 }
 
 func (server *Server) extractMetadata(ctx context.Context) *Metadata {
 	data := &Metadata{}
 
+	// for gRPC
+	fromGateway := false
+	if p, ok := peer.FromContext(ctx); ok {
+		data.ClientIP = hostOf(p.Addr)
+		fromGateway = isLoopback(p.Addr)
+	}
+
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		// for gRPC
+		if userAgent := md.Get(userAgentHeader); len(userAgent) > 0 {
+			data.UserAgent = userAgent[0]
+		}
+
 		// for HTTP
 		if userAgent := md.Get(grpcGatewayUserAgentHeader); len(userAgent) > 0 {
 			data.UserAgent = userAgent[0]
 		}
 
-		// for gRPC
-		if userAgent := md.Get(userAgentHeader); len(userAgent) > 0 {
-			data.UserAgent = userAgent[0]
+		// for HTTP, only trusted when the call comes from the gateway,
+		// any other gRPC client could set it to an arbitrary address
+		if forwardedFor := md.Get(xForwardedForHeader); len(forwardedFor) > 0 && fromGateway {
+			data.ClientIP = lastForwardedHop(forwardedFor)
 		}
 
-		// for HTTP
-		if clientIP := md.Get(xForwardedForHeader); len(clientIP) > 0 {
-			data.ClientIP = clientIP[0]
+		// for both, the gateway forwards the Idempotency-Key HTTP header
+		if idempotencyKey := md.Get(idempotencyKeyHeader); len(idempotencyKey) > 0 {
+			data.IdempotencyKey = idempotencyKey[0]
 		}
-	}
 
-	// for gRPC
-	if p, ok := peer.FromContext(ctx); ok {
-		data.ClientIP = p.Addr.String()
+		// for both, the gateway forwards the X-Request-ID HTTP header, which is set
+		// by the gateway itself when the client did not send one
+		if requestID := md.Get(requestIDHeader); len(requestID) > 0 {
+			data.RequestID = requestID[0]
+		}
 	}
 
 	return data
 }
+
+// hostOf returns the IP of the address without its port,
+// so that every connection of a client has the same IP
+func hostOf(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// lastForwardedHop returns the address the gateway appended to the X-Forwarded-For values.
+// The gateway adds the remote address of the connection after whatever the client sent,
+// so only the last hop cannot be chosen by the client.
+func lastForwardedHop(values []string) string {
+	hops := strings.Split(values[len(values)-1], ",")
+	return strings.TrimSpace(hops[len(hops)-1])
+}
+
+// isLoopback checks if the address is a loopback address,
+// the gateway runs in the same process and calls the gRPC server through it
+func isLoopback(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	return ok && tcpAddr.IP.IsLoopback()
+}
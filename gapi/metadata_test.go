@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"testing"
+)
+
+func TestExtractMetadataClientIP(t *testing.T) {
+	testCases := []struct {
+		name         string
+		peerAddr     net.Addr
+		forwardedFor []string
+		clientIP     string
+	}{
+		{
+			name:     "gRPC Client",
+			peerAddr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 54321},
+			clientIP: "203.0.113.7",
+		},
+		{
+			name:         "gRPC Client With X-Forwarded-For",
+			peerAddr:     &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 54321},
+			forwardedFor: []string{"198.51.100.1"},
+			clientIP:     "203.0.113.7",
+		},
+		{
+			name:         "Gateway",
+			peerAddr:     &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 54321},
+			forwardedFor: []string{"203.0.113.7"},
+			clientIP:     "203.0.113.7",
+		},
+		{
+			name:         "Gateway With X-Forwarded-For Of The Client",
+			peerAddr:     &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 54321},
+			forwardedFor: []string{"198.51.100.1, 198.51.100.2, 203.0.113.7"},
+			clientIP:     "203.0.113.7",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil) // nil because metadata is not read from the db
+
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: tc.peerAddr})
+			md := metadata.MD{}
+			if tc.forwardedFor != nil {
+				md.Set(xForwardedForHeader, tc.forwardedFor...)
+			}
+			ctx = metadata.NewIncomingContext(ctx, md)
+
+			require.Equal(t, tc.clientIP, server.extractMetadata(ctx).ClientIP)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package gapi
+
+import (
+	"context"
+	"github.com/aalug/bank-go/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// UnaryMetricsInterceptor records the rate, the errors and the duration of every unary call.
+// It must run before the auth interceptor, so rejected calls are counted too.
+func (server *Server) UnaryMetricsInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	startTime := time.Now()
+
+	result, err := handler(ctx, req)
+	metrics.ObserveGRPC(info.FullMethod, status.Code(err).String(), time.Since(startTime))
+
+	return result, err
+}
+
+// StreamMetricsInterceptor records every streaming call once it ends
+func (server *Server) StreamMetricsInterceptor(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	startTime := time.Now()
+
+	err := handler(srv, stream)
+	metrics.ObserveGRPC(info.FullMethod, status.Code(err).String(), time.Since(startTime))
+
+	return err
+}
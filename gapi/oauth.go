@@ -0,0 +1,284 @@
+package gapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// error codes of the OAuth token and revocation endpoints, RFC 6749 section 5.2.
+// They prefix the messages of the returned errors, so clients can tell them apart.
+const (
+	oauthErrorInvalidRequest = "invalid_request"
+	oauthErrorInvalidClient  = "invalid_client"
+	oauthErrorInvalidGrant   = "invalid_grant"
+)
+
+// oauthError returns an error with the code of the OAuth token and revocation endpoints
+func oauthError(code codes.Code, oauthCode string, msg string) error {
+	return status.Errorf(code, "%s: %s", oauthCode, msg)
+}
+
+// oauthAuthorizeRequest - the parameters of an authorization request, RFC 6749 section 4.1.1.
+// Both GetOAuthAuthorizationRequest and AuthorizeOAuthClientRequest carry them.
+type oauthAuthorizeRequest interface {
+	GetResponseType() string
+	GetClientId() string
+	GetRedirectUri() string
+	GetScope() string
+	GetState() string
+	GetCodeChallenge() string
+	GetCodeChallengeMethod() string
+}
+
+// validateOAuthAuthorizeFields validates the parameters of an authorization request.
+// Only the S256 PKCE method is supported, and PKCE is required from every client.
+func validateOAuthAuthorizeFields(request oauthAuthorizeRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if request.GetResponseType() != "code" {
+		violations = append(violations, fieldViolation("response_type", fmt.Errorf("must be code")))
+	}
+
+	if request.GetClientId() == "" {
+		violations = append(violations, fieldViolation("client_id", fmt.Errorf("must not be empty")))
+	}
+
+	if request.GetRedirectUri() == "" {
+		violations = append(violations, fieldViolation("redirect_uri", fmt.Errorf("must not be empty")))
+	}
+
+	if request.GetScope() == "" {
+		violations = append(violations, fieldViolation("scope", fmt.Errorf("must not be empty")))
+	}
+
+	if err := validation.ValidateStringLength(request.GetState(), 0, 500); err != nil {
+		violations = append(violations, fieldViolation("state", err))
+	}
+
+	if err := validation.ValidateStringLength(request.GetCodeChallenge(), 43, 43); err != nil {
+		violations = append(violations, fieldViolation("code_challenge", err))
+	}
+
+	if request.GetCodeChallengeMethod() != "S256" {
+		violations = append(violations, fieldViolation("code_challenge_method", fmt.Errorf("must be S256")))
+	}
+
+	return violations
+}
+
+// checkOAuthAuthorizeRequest checks the client, the redirect URI and the scopes of an authorization request
+// and returns the client and the requested scopes.
+// Errors are not sent to the redirect URI, which may not belong to the client.
+func (server *Server) checkOAuthAuthorizeRequest(ctx context.Context, request oauthAuthorizeRequest) (db.OAuthClient, []string, error) {
+	client, err := server.store.GetOAuthClient(ctx, request.GetClientId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return client, nil, status.Errorf(codes.NotFound, "unknown client")
+		}
+		return client, nil, status.Errorf(codes.Internal, "failed to get OAuth client: %s", err)
+	}
+
+	// redirect URIs are compared exactly, so codes are never sent anywhere else
+	registered := false
+	for _, redirectURI := range client.RedirectUris {
+		if redirectURI == request.GetRedirectUri() {
+			registered = true
+			break
+		}
+	}
+	if !registered {
+		return client, nil, status.Errorf(codes.InvalidArgument, "redirect URI is not registered for the client")
+	}
+
+	scopes := token.ParseScope(request.GetScope())
+	if len(scopes) == 0 || !token.HasScopes(client.Scopes, scopes) {
+		return client, nil, status.Errorf(codes.InvalidArgument, "the client cannot ask for the requested scopes")
+	}
+
+	return client, scopes, nil
+}
+
+// oauthRedirectURI adds the non-empty parameters to the query of the redirect URI
+func oauthRedirectURI(redirectURI string, params map[string]string) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := u.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// authenticateOAuthClient returns the client of the request. Credentials sent with HTTP Basic
+// authentication take precedence over the ones in the request, and confidential clients must send their secret.
+func (server *Server) authenticateOAuthClient(ctx context.Context, clientID string, clientSecret string) (db.OAuthClient, error) {
+	if id, secret, ok := basicAuthFromContext(ctx); ok {
+		clientID, clientSecret = id, secret
+	}
+
+	errInvalidClient := oauthError(codes.Unauthenticated, oauthErrorInvalidClient, "client authentication failed")
+	if clientID == "" {
+		return db.OAuthClient{}, errInvalidClient
+	}
+
+	client, err := server.store.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return client, errInvalidClient
+		}
+		return client, status.Errorf(codes.Internal, "failed to get OAuth client: %s", err)
+	}
+
+	if client.HashedSecret != "" &&
+		subtle.ConstantTimeCompare([]byte(utils.HashToken(clientSecret)), []byte(client.HashedSecret)) != 1 {
+		return client, errInvalidClient
+	}
+
+	return client, nil
+}
+
+// basicAuthFromContext returns the credentials of the HTTP Basic authorization header, if there is one
+func basicAuthFromContext(ctx context.Context) (string, string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", "", false
+	}
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return "", "", false
+	}
+
+	// net/http already knows how to parse the header
+	request := http.Request{Header: http.Header{"Authorization": {values[0]}}}
+	return request.BasicAuth()
+}
+
+// exchangeOAuthCode checks an authorization code and its PKCE verifier
+// and exchanges the code for a refresh token
+func (server *Server) exchangeOAuthCode(ctx context.Context, client db.OAuthClient, rawCode, redirectURI, codeVerifier string) (db.OAuthRefreshToken, string, error) {
+	var refreshToken db.OAuthRefreshToken
+
+	if rawCode == "" || redirectURI == "" || codeVerifier == "" {
+		return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidRequest, "code, redirect_uri and code_verifier are required")
+	}
+
+	code, err := server.store.GetOAuthAuthorizationCode(ctx, utils.HashToken(rawCode))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidGrant, "invalid authorization code")
+		}
+		return refreshToken, "", status.Errorf(codes.Internal, "failed to get authorization code: %s", err)
+	}
+
+	if code.ClientID != client.ID {
+		return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidGrant, "invalid authorization code")
+	}
+
+	// a code used twice may have been stolen, so the tokens issued for it are revoked, RFC 6749 section 4.1.2
+	if code.UsedAt != nil {
+		_, err = server.store.RevokeOAuthRefreshTokens(ctx, db.RevokeOAuthRefreshTokensParams{
+			Username: code.Username,
+			ClientID: code.ClientID,
+		})
+		if err != nil {
+			return refreshToken, "", status.Errorf(codes.Internal, "failed to revoke refresh tokens: %s", err)
+		}
+
+		return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidGrant, db.ErrOAuthCodeUsed.Error())
+	}
+
+	if time.Now().After(code.ExpiresAt) {
+		return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidGrant, "authorization code has expired")
+	}
+
+	if code.RedirectUri != redirectURI {
+		return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidGrant, "redirect URI does not match the authorization request")
+	}
+
+	if !utils.CheckPKCE(codeVerifier, code.CodeChallenge) {
+		return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidGrant, "invalid code verifier")
+	}
+
+	rawRefreshToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return refreshToken, "", status.Errorf(codes.Internal, "failed to generate refresh token: %s", err)
+	}
+
+	refreshToken, err = server.store.ExchangeOAuthCodeTx(ctx, db.ExchangeOAuthCodeTxParams{
+		CodeID:             code.ID,
+		HashedRefreshToken: utils.HashToken(rawRefreshToken),
+		ExpiresAt:          time.Now().Add(server.config.OAuthRefreshTokenDuration),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrOAuthCodeUsed) {
+			return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidGrant, err.Error())
+		}
+		return refreshToken, "", status.Errorf(codes.Internal, "failed to exchange authorization code: %s", err)
+	}
+
+	return refreshToken, rawRefreshToken, nil
+}
+
+// rotateOAuthRefreshToken exchanges a refresh token of the client for a new one
+func (server *Server) rotateOAuthRefreshToken(ctx context.Context, client db.OAuthClient, rawRefreshToken string) (db.OAuthRefreshToken, string, error) {
+	var refreshToken db.OAuthRefreshToken
+
+	if rawRefreshToken == "" {
+		return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidRequest, "refresh_token is required")
+	}
+
+	refreshToken, err := server.store.GetOAuthRefreshToken(ctx, utils.HashToken(rawRefreshToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidGrant, "invalid refresh token")
+		}
+		return refreshToken, "", status.Errorf(codes.Internal, "failed to get refresh token: %s", err)
+	}
+
+	if refreshToken.ClientID != client.ID {
+		return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidGrant, "invalid refresh token")
+	}
+
+	if time.Now().After(refreshToken.ExpiresAt) {
+		return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidGrant, "refresh token has expired")
+	}
+
+	newRawRefreshToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return refreshToken, "", status.Errorf(codes.Internal, "failed to generate refresh token: %s", err)
+	}
+
+	refreshToken, err = server.store.RotateOAuthRefreshTokenTx(ctx, db.RotateOAuthRefreshTokenTxParams{
+		ID:             refreshToken.ID,
+		NewHashedToken: utils.HashToken(newRawRefreshToken),
+		ExpiresAt:      time.Now().Add(server.config.OAuthRefreshTokenDuration),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrOAuthRefreshTokenReused) {
+			return refreshToken, "", oauthError(codes.InvalidArgument, oauthErrorInvalidGrant, err.Error())
+		}
+		return refreshToken, "", status.Errorf(codes.Internal, "failed to rotate refresh token: %s", err)
+	}
+
+	return refreshToken, newRawRefreshToken, nil
+}
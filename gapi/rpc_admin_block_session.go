@@ -0,0 +1,53 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminBlockSession blocks a session of any user
+func (server *Server) AdminBlockSession(ctx context.Context, request *pb.AdminBlockSessionRequest) (*pb.AdminBlockSessionResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAdminBlockSessionRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	session, err := server.store.GetSession(ctx, uuid.MustParse(request.GetId()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+
+	_, err = server.store.AdminBlockSessionTx(ctx, db.AdminBlockSessionTxParams{
+		AdminUsername: authPayload.Username,
+		SessionID:     session.ID,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block session: %s", err)
+	}
+
+	return &pb.AdminBlockSessionResponse{}, nil
+}
+
+// validateAdminBlockSessionRequest validates all the fields of the request.
+func validateAdminBlockSessionRequest(request *pb.AdminBlockSessionRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateUUID(request.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
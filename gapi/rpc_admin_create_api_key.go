@@ -0,0 +1,42 @@
+package gapi
+
+import (
+	"context"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// AdminCreateAPIKey creates an API key of any user
+func (server *Server) AdminCreateAPIKey(ctx context.Context, request *pb.AdminCreateAPIKeyRequest) (*pb.AdminCreateAPIKeyResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAdminCreateAPIKeyRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	key, apiKey, err := server.issueAPIKey(ctx, authPayload, request.GetUsername(), request.GetApiKey(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.AdminCreateAPIKeyResponse{
+		Key:    key,
+		ApiKey: convertAPIKey(apiKey),
+	}, nil
+}
+
+// validateAdminCreateAPIKeyRequest validates all the fields of the request.
+func validateAdminCreateAPIKeyRequest(request *pb.AdminCreateAPIKeyRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateUsername(request.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+
+	violations = append(violations, validateCreateAPIKeyFields(request.GetApiKey(), "api_key.")...)
+
+	return violations
+}
@@ -0,0 +1,41 @@
+package gapi
+
+import (
+	"context"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// AdminFreezeAccount freezes an account, so no money can leave it
+func (server *Server) AdminFreezeAccount(ctx context.Context, request *pb.AdminFreezeAccountRequest) (*pb.AdminFreezeAccountResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAdminFreezeAccountRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.setAccountFrozen(ctx, authPayload, request.GetId(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.AdminFreezeAccountResponse{
+		Account: convertAccount(account),
+	}
+
+	return res, nil
+}
+
+// validateAdminFreezeAccountRequest validates all the fields of the request.
+func validateAdminFreezeAccountRequest(request *pb.AdminFreezeAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
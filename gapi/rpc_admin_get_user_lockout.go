@@ -0,0 +1,54 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminGetUserLockout returns the failed logins of a username and whether its logins are locked
+func (server *Server) AdminGetUserLockout(ctx context.Context, request *pb.AdminGetUserLockoutRequest) (*pb.AdminGetUserLockoutResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAdminGetUserLockoutRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	err = server.recordAdminAction(ctx, authPayload, db.AdminActionGetUserLockout, db.AdminTargetUser, request.GetUsername())
+	if err != nil {
+		return nil, err
+	}
+
+	// usernames without failed logins have no record
+	failure, err := server.store.GetLoginFailure(ctx, db.GetLoginFailureParams{
+		KeyType: db.LoginKeyUsername,
+		Key:     request.GetUsername(),
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, status.Errorf(codes.Internal, "failed to get failed logins: %s", err)
+	}
+
+	res := &pb.AdminGetUserLockoutResponse{
+		Lockout: convertUserLockout(request.GetUsername(), failure),
+	}
+
+	return res, nil
+}
+
+// validateAdminGetUserLockoutRequest validates all the fields of the request.
+func validateAdminGetUserLockoutRequest(request *pb.AdminGetUserLockoutRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateUsername(request.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+
+	return violations
+}
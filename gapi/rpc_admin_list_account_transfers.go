@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+)
+
+// AdminListAccountTransfers returns transfers sent from or received by any account
+func (server *Server) AdminListAccountTransfers(ctx context.Context, request *pb.AdminListAccountTransfersRequest) (*pb.AdminListAccountTransfersResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAdminListAccountTransfersRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.findAccount(ctx, request.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	targetID := strconv.FormatInt(account.ID, 10)
+	err = server.recordAdminAction(ctx, authPayload, db.AdminActionListAccountTransfers, db.AdminTargetAccount, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers, err := server.store.ListTransfers(ctx, db.ListTransfersParams{
+		FromAccountID: account.ID,
+		ToAccountID:   account.ID,
+		Limit:         request.GetPageSize(),
+		Offset:        (request.GetPageId() - 1) * request.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list transfers: %s", err)
+	}
+
+	res := &pb.AdminListAccountTransfersResponse{
+		Transfers: make([]*pb.Transfer, 0, len(transfers)),
+	}
+	for _, transfer := range transfers {
+		res.Transfers = append(res.Transfers, convertTransfer(transfer))
+	}
+
+	return res, nil
+}
+
+// validateAdminListAccountTransfersRequest validates all the fields of the request.
+func validateAdminListAccountTransfersRequest(request *pb.AdminListAccountTransfersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := validation.ValidatePage(request.GetPageId(), request.GetPageSize(), 5, 50); err != nil {
+		violations = append(violations, fieldViolation("page", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,88 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminListAuditEvents returns the events of the audit log that match the filters of the request,
+// the most recent first
+func (server *Server) AdminListAuditEvents(ctx context.Context, request *pb.AdminListAuditEventsRequest) (*pb.AdminListAuditEventsResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAdminListAuditEventsRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	err = server.recordAdminAction(ctx, authPayload, db.AdminActionListAuditEvents, db.AdminTargetAuditLog, request.GetTargetId())
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.ListAuditEventsParams{
+		Actor:      sql.NullString{String: request.GetActor(), Valid: request.Actor != nil},
+		Action:     sql.NullString{String: request.GetAction(), Valid: request.Action != nil},
+		TargetType: sql.NullString{String: request.GetTargetType(), Valid: request.TargetType != nil},
+		TargetID:   sql.NullString{String: request.GetTargetId(), Valid: request.TargetId != nil},
+		RequestID:  sql.NullString{String: request.GetRequestId(), Valid: request.RequestId != nil},
+		Limit:      request.GetPageSize(),
+		Offset:     (request.GetPageId() - 1) * request.GetPageSize(),
+	}
+	if request.FromTime != nil {
+		arg.FromTime = sql.NullTime{Time: request.GetFromTime().AsTime(), Valid: true}
+	}
+	if request.ToTime != nil {
+		arg.ToTime = sql.NullTime{Time: request.GetToTime().AsTime(), Valid: true}
+	}
+
+	events, err := server.store.ListAuditEvents(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list audit events: %s", err)
+	}
+
+	res := &pb.AdminListAuditEventsResponse{
+		Events: make([]*pb.AuditEvent, 0, len(events)),
+	}
+	for _, event := range events {
+		res.Events = append(res.Events, convertAuditEvent(event))
+	}
+
+	return res, nil
+}
+
+// validateAdminListAuditEventsRequest validates all the fields of the request.
+func validateAdminListAuditEventsRequest(request *pb.AdminListAuditEventsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidatePage(request.GetPageId(), request.GetPageSize(), 5, 50); err != nil {
+		violations = append(violations, fieldViolation("page", err))
+	}
+
+	if request.FromTime != nil {
+		if err := request.GetFromTime().CheckValid(); err != nil {
+			violations = append(violations, fieldViolation("from_time", err))
+		}
+	}
+
+	if request.ToTime != nil {
+		if err := request.GetToTime().CheckValid(); err != nil {
+			violations = append(violations, fieldViolation("to_time", err))
+		}
+	}
+
+	if request.FromTime != nil && request.ToTime != nil &&
+		!request.GetFromTime().AsTime().Before(request.GetToTime().AsTime()) {
+		violations = append(violations, fieldViolation("to_time", fmt.Errorf("must be after from_time")))
+	}
+
+	return violations
+}
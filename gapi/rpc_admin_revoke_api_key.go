@@ -0,0 +1,56 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminRevokeAPIKey revokes an API key of any user
+func (server *Server) AdminRevokeAPIKey(ctx context.Context, request *pb.AdminRevokeAPIKeyRequest) (*pb.AdminRevokeAPIKeyResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAdminRevokeAPIKeyRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	apiKey, err := server.store.GetAPIKey(ctx, request.GetId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "API key not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get API key: %s", err)
+	}
+
+	if apiKey.RevokedAt != nil {
+		return &pb.AdminRevokeAPIKeyResponse{}, nil
+	}
+
+	_, err = server.store.AdminRevokeAPIKeyTx(ctx, db.AdminRevokeAPIKeyTxParams{
+		AdminUsername: authPayload.Username,
+		APIKeyID:      apiKey.ID,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, status.Errorf(codes.Internal, "failed to revoke API key: %s", err)
+	}
+
+	return &pb.AdminRevokeAPIKeyResponse{}, nil
+}
+
+// validateAdminRevokeAPIKeyRequest validates all the fields of the request.
+func validateAdminRevokeAPIKeyRequest(request *pb.AdminRevokeAPIKeyRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
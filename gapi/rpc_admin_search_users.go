@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminSearchUsers returns the users whose username, email or full name contains the query
+func (server *Server) AdminSearchUsers(ctx context.Context, request *pb.AdminSearchUsersRequest) (*pb.AdminSearchUsersResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAdminSearchUsersRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	err = server.recordAdminAction(ctx, authPayload, db.AdminActionSearchUsers, db.AdminTargetUser, request.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := server.store.SearchUsers(ctx, db.SearchUsersParams{
+		Query:  request.GetQuery(),
+		Limit:  request.GetPageSize(),
+		Offset: (request.GetPageId() - 1) * request.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to search users: %s", err)
+	}
+
+	res := &pb.AdminSearchUsersResponse{
+		Users: make([]*pb.User, 0, len(users)),
+	}
+	for _, user := range users {
+		res.Users = append(res.Users, convertUser(user))
+	}
+
+	return res, nil
+}
+
+// validateAdminSearchUsersRequest validates all the fields of the request.
+func validateAdminSearchUsersRequest(request *pb.AdminSearchUsersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateStringLength(request.GetQuery(), 1, 100); err != nil {
+		violations = append(violations, fieldViolation("query", err))
+	}
+
+	if err := validation.ValidatePage(request.GetPageId(), request.GetPageSize(), 5, 50); err != nil {
+		violations = append(violations, fieldViolation("page", err))
+	}
+
+	return violations
+}
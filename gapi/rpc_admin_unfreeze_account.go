@@ -0,0 +1,41 @@
+package gapi
+
+import (
+	"context"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// AdminUnfreezeAccount unfreezes an account
+func (server *Server) AdminUnfreezeAccount(ctx context.Context, request *pb.AdminUnfreezeAccountRequest) (*pb.AdminUnfreezeAccountResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAdminUnfreezeAccountRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.setAccountFrozen(ctx, authPayload, request.GetId(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.AdminUnfreezeAccountResponse{
+		Account: convertAccount(account),
+	}
+
+	return res, nil
+}
+
+// validateAdminUnfreezeAccountRequest validates all the fields of the request.
+func validateAdminUnfreezeAccountRequest(request *pb.AdminUnfreezeAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,43 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminUnlockUser clears the failed logins of a username, so it can log in again right away
+func (server *Server) AdminUnlockUser(ctx context.Context, request *pb.AdminUnlockUserRequest) (*pb.AdminUnlockUserResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAdminUnlockUserRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	err = server.store.AdminUnlockUserTx(ctx, db.AdminUnlockUserTxParams{
+		AdminUsername: authPayload.Username,
+		Username:      request.GetUsername(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to unlock user: %s", err)
+	}
+
+	return &pb.AdminUnlockUserResponse{}, nil
+}
+
+// validateAdminUnlockUserRequest validates all the fields of the request.
+func validateAdminUnlockUserRequest(request *pb.AdminUnlockUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateUsername(request.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+
+	return violations
+}
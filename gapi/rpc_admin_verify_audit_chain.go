@@ -0,0 +1,40 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AdminVerifyAuditChain recomputes the hash chain of the audit log
+// and reports the first event that was changed or whose predecessor was removed
+func (server *Server) AdminVerifyAuditChain(ctx context.Context, request *pb.AdminVerifyAuditChainRequest) (*pb.AdminVerifyAuditChainResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	err = server.recordAdminAction(ctx, authPayload, db.AdminActionVerifyAuditChain, db.AdminTargetAuditLog, "")
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := server.store.VerifyAuditChain(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to verify audit chain: %s", err)
+	}
+
+	res := &pb.AdminVerifyAuditChainResponse{
+		CheckedAt: timestamppb.New(report.CheckedAt),
+		Checked:   report.Checked,
+		Valid:     report.Valid,
+	}
+	if !report.Valid {
+		res.BrokenAtId = &report.BrokenAtID
+	}
+
+	return res, nil
+}
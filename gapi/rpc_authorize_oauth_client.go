@@ -0,0 +1,63 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// AuthorizeOAuthClient records the decision of the authenticated user on an authorization request.
+// If the user approves, the consent is stored and an authorization code is issued.
+func (server *Server) AuthorizeOAuthClient(ctx context.Context, request *pb.AuthorizeOAuthClientRequest) (*pb.AuthorizeOAuthClientResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateOAuthAuthorizeFields(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	client, scopes, err := server.checkOAuthAuthorizeRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{"state": request.GetState()}
+	if !request.GetApprove() {
+		params["error"] = "access_denied"
+		return &pb.AuthorizeOAuthClientResponse{
+			RedirectTo: oauthRedirectURI(request.GetRedirectUri(), params),
+		}, nil
+	}
+
+	code, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate authorization code: %s", err)
+	}
+
+	_, err = server.store.AuthorizeOAuthClientTx(ctx, db.CreateOAuthAuthorizationCodeParams{
+		HashedCode:    utils.HashToken(code),
+		ClientID:      client.ID,
+		Username:      authPayload.Username,
+		RedirectUri:   request.GetRedirectUri(),
+		Scopes:        scopes,
+		CodeChallenge: request.GetCodeChallenge(),
+		ExpiresAt:     time.Now().Add(server.config.OAuthCodeDuration),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to authorize OAuth client: %s", err)
+	}
+
+	params["code"] = code
+	res := &pb.AuthorizeOAuthClientResponse{
+		RedirectTo: oauthRedirectURI(request.GetRedirectUri(), params),
+	}
+
+	return res, nil
+}
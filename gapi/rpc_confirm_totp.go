@@ -0,0 +1,82 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/utils"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// ConfirmTOTP enables two-factor authentication after the user proved they set up the secret,
+// and returns the recovery codes. They are shown only once.
+func (server *Server) ConfirmTOTP(ctx context.Context, request *pb.ConfirmTOTPRequest) (*pb.ConfirmTOTPResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateConfirmTOTPRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	userTOTP, err := server.store.GetUserTOTP(ctx, authPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "two-factor authentication is not enrolled")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get two-factor authentication: %s", err)
+	}
+
+	if userTOTP.ConfirmedAt != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "%s", db.ErrTOTPAlreadyEnabled)
+	}
+
+	step, ok := utils.ValidateTOTP(userTOTP.Secret, request.GetCode(), time.Now())
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid two-factor authentication code")
+	}
+
+	recoveryCodes, err := utils.GenerateRecoveryCodes(utils.RecoveryCodeCount)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate recovery codes: %s", err)
+	}
+
+	hashedCodes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashedCodes[i] = utils.HashRecoveryCode(code)
+	}
+
+	_, err = server.store.ConfirmTOTPTx(ctx, db.ConfirmTOTPTxParams{
+		Username:            authPayload.Username,
+		Step:                step,
+		HashedRecoveryCodes: hashedCodes,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrTOTPCodeUsed):
+			return nil, status.Errorf(codes.Unauthenticated, "invalid two-factor authentication code")
+		case errors.Is(err, db.ErrTOTPAlreadyEnabled):
+			return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to confirm two-factor authentication: %s", err)
+	}
+
+	return &pb.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// validateConfirmTOTPRequest validates all the fields of the request.
+func validateConfirmTOTPRequest(request *pb.ConfirmTOTPRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateTOTPCode(request.GetCode()); err != nil {
+		violations = append(violations, fieldViolation("code", err))
+	}
+
+	return violations
+}
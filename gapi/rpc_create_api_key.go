@@ -0,0 +1,35 @@
+package gapi
+
+import (
+	"context"
+	"github.com/aalug/bank-go/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// CreateAPIKey creates an API key of the authenticated user
+func (server *Server) CreateAPIKey(ctx context.Context, request *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateAPIKeyRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	key, apiKey, err := server.issueAPIKey(ctx, authPayload, authPayload.Username, request, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateAPIKeyResponse{
+		Key:    key,
+		ApiKey: convertAPIKey(apiKey),
+	}, nil
+}
+
+// validateCreateAPIKeyRequest validates all the fields of the request.
+func validateCreateAPIKeyRequest(request *pb.CreateAPIKeyRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	return validateCreateAPIKeyFields(request, "")
+}
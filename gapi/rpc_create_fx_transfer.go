@@ -0,0 +1,122 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/fx"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateFXTransfer transfers money from an account of the authenticated user to an account
+// of a different currency using the rate locked by a quote of the user
+func (server *Server) CreateFXTransfer(ctx context.Context, request *pb.CreateFXTransferRequest) (*pb.CreateFXTransferResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateFXTransferRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	quoteID := uuid.MustParse(request.GetQuoteId())
+	quote, err := server.store.GetFXQuote(ctx, quoteID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "quote not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get quote: %s", err)
+	}
+
+	if quote.Username != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "quote does not belong to the authenticated user")
+	}
+
+	_, err = server.getOwnAccount(ctx, authPayload, request.GetFromAccountId(), quote.FromCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = server.getAccount(ctx, request.GetToAccountId(), quote.ToCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.checkEmailVerified(ctx, authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.checkStepUp(ctx, authPayload.Username, request.GetAmount(), request.GetTotpCode())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.FXTransferTx(ctx, db.FXTransferTxParams{
+		FromAccountID: request.GetFromAccountId(),
+		ToAccountID:   request.GetToAccountId(),
+		Amount:        request.GetAmount(),
+		QuoteID:       quoteID,
+		Username:      authPayload.Username,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds),
+			errors.Is(err, db.ErrAccountFrozen),
+			errors.Is(err, db.ErrFXQuoteExpired),
+			errors.Is(err, db.ErrFXQuoteUsed),
+			errors.Is(err, fx.ErrAmountTooSmall),
+			errors.Is(err, fx.ErrAmountTooLarge):
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to transfer money: %s", err)
+	}
+
+	// the balance of the recipient's account is not shown to the sender
+	res := &pb.CreateFXTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+	}
+
+	return res, nil
+}
+
+// validateCreateFXTransferRequest validates all the fields of the request.
+func validateCreateFXTransferRequest(request *pb.CreateFXTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	if err := validation.ValidateID(request.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+
+	if request.GetFromAccountId() == request.GetToAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", errors.New("must be different from from_account_id")))
+	}
+
+	if err := validation.ValidateAmount(request.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := validation.ValidateUUID(request.GetQuoteId()); err != nil {
+		violations = append(violations, fieldViolation("quote_id", err))
+	}
+
+	if request.TotpCode != nil {
+		if err := validation.ValidateTOTPCode(request.GetTotpCode()); err != nil {
+			violations = append(violations, fieldViolation("totp_code", err))
+		}
+	}
+
+	return violations
+}
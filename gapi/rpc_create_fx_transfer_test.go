@@ -0,0 +1,183 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	mockdb "github.com/aalug/bank-go/db/mock"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestCreateFXTransferAPI(t *testing.T) {
+	username := utils.RandomOwner()
+	user := db.User{Username: username, Role: utils.CustomerRole, IsEmailVerified: true}
+
+	fromAccount := db.Account{ID: 1, Owner: username, Balance: 1000, Currency: utils.EUR}
+	toAccount := db.Account{ID: 2, Owner: utils.RandomOwner(), Balance: 1000, Currency: utils.USD}
+
+	quote := db.FXQuote{
+		ID:           uuid.New(),
+		Username:     username,
+		FromCurrency: utils.EUR,
+		ToCurrency:   utils.USD,
+		Rate:         "1.0866000000",
+		Spread:       "0.005000",
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+	otherQuote := quote
+	otherQuote.Username = utils.RandomOwner()
+
+	var amount int64 = 100
+
+	testCases := []struct {
+		name       string
+		request    *pb.CreateFXTransferRequest
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			request: &pb.CreateFXTransferRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        amount,
+				QuoteId:       quote.ID.String(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFXQuote(gomock.Any(), gomock.Eq(quote.ID)).
+					Times(1).
+					Return(quote, nil)
+				store.EXPECT().
+					FXTransferTx(gomock.Any(), gomock.Eq(db.FXTransferTxParams{
+						FromAccountID: fromAccount.ID,
+						ToAccountID:   toAccount.ID,
+						Amount:        amount,
+						QuoteID:       quote.ID,
+						Username:      username,
+					})).
+					Times(1).
+					Return(db.TransferTxResult{FromAccount: fromAccount}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "Quote Of Another User",
+			request: &pb.CreateFXTransferRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        amount,
+				QuoteId:       quote.ID.String(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFXQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(otherQuote, nil)
+				store.EXPECT().
+					FXTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "Quote Not Found",
+			request: &pb.CreateFXTransferRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        amount,
+				QuoteId:       quote.ID.String(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFXQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FXQuote{}, sql.ErrNoRows)
+				store.EXPECT().
+					FXTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "Quote Expired",
+			request: &pb.CreateFXTransferRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        amount,
+				QuoteId:       quote.ID.String(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFXQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(quote, nil)
+				store.EXPECT().
+					FXTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrFXQuoteExpired)
+			},
+			code: codes.FailedPrecondition,
+		},
+		{
+			name: "Invalid Quote ID",
+			request: &pb.CreateFXTransferRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        amount,
+				QuoteId:       "invalid",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFXQuote(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				AnyTimes().
+				Return(fromAccount, nil)
+			store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				AnyTimes().
+				Return(toAccount, nil)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(username)).
+				AnyTimes().
+				Return(user, nil)
+
+			server := newTestServer(t, store)
+
+			authPayload, err := token.NewPayload(username, utils.CustomerRole, time.Minute)
+			require.NoError(t, err)
+			ctx := context.WithValue(context.Background(), authPayloadKey{}, authPayload)
+
+			res, err := server.CreateFXTransfer(ctx, tc.request)
+			require.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				require.Equal(t, fromAccount.ID, res.GetFromAccount().GetId())
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateOAuthClient registers an OAuth client (a third-party app) owned by the authenticated user
+func (server *Server) CreateOAuthClient(ctx context.Context, request *pb.CreateOAuthClientRequest) (*pb.CreateOAuthClientResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateOAuthClientRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	clientID, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate client ID: %s", err)
+	}
+
+	// public clients like mobile apps cannot keep a secret
+	var clientSecret, hashedSecret string
+	if request.GetConfidential() {
+		clientSecret, err = utils.GenerateSecureToken(32)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate client secret: %s", err)
+		}
+		hashedSecret = utils.HashToken(clientSecret)
+	}
+
+	client, err := server.store.CreateOAuthClientTx(ctx, db.CreateOAuthClientParams{
+		ID:           clientID,
+		HashedSecret: hashedSecret,
+		Name:         request.GetName(),
+		Owner:        authPayload.Username,
+		RedirectUris: request.GetRedirectUris(),
+		Scopes:       request.GetScopes(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create OAuth client: %s", err)
+	}
+
+	res := &pb.CreateOAuthClientResponse{
+		ClientSecret: clientSecret,
+		Client:       convertOAuthClient(client),
+	}
+
+	return res, nil
+}
+
+// validateCreateOAuthClientRequest validates all the fields of the request.
+func validateCreateOAuthClientRequest(request *pb.CreateOAuthClientRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateStringLength(request.GetName(), 1, 100); err != nil {
+		violations = append(violations, fieldViolation("name", err))
+	}
+
+	if n := len(request.GetRedirectUris()); n < 1 || n > 10 {
+		violations = append(violations, fieldViolation("redirect_uris", fmt.Errorf("must contain between 1 and 10 redirect URIs")))
+	}
+
+	seenRedirectURIs := make(map[string]bool)
+	for _, redirectURI := range request.GetRedirectUris() {
+		if err := validation.ValidateRedirectURI(redirectURI); err != nil {
+			violations = append(violations, fieldViolation("redirect_uris", err))
+		} else if seenRedirectURIs[redirectURI] {
+			violations = append(violations, fieldViolation("redirect_uris", fmt.Errorf("duplicate redirect URI %s", redirectURI)))
+		}
+		seenRedirectURIs[redirectURI] = true
+	}
+
+	if len(request.GetScopes()) == 0 {
+		violations = append(violations, fieldViolation("scopes", fmt.Errorf("must contain at least one scope")))
+	}
+
+	seenScopes := make(map[string]bool)
+	for _, scope := range request.GetScopes() {
+		if err := validation.ValidateScope(scope); err != nil {
+			violations = append(violations, fieldViolation("scopes", err))
+		} else if scope == token.ScopeAdmin {
+			violations = append(violations, fieldViolation("scopes", fmt.Errorf("admin scope cannot be granted to OAuth clients")))
+		} else if seenScopes[scope] {
+			violations = append(violations, fieldViolation("scopes", fmt.Errorf("duplicate scope %s", scope)))
+		}
+		seenScopes[scope] = true
+	}
+
+	return violations
+}
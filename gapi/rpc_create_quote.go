@@ -0,0 +1,75 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateQuote locks the exchange rate between two currencies for the authenticated user
+func (server *Server) CreateQuote(ctx context.Context, request *pb.CreateQuoteRequest) (*pb.CreateQuoteResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateQuoteRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	quote, err := server.store.CreateQuoteTx(ctx, db.CreateQuoteTxParams{
+		Username:     authPayload.Username,
+		FromCurrency: request.GetFromCurrency(),
+		ToCurrency:   request.GetToCurrency(),
+		Spread:       server.config.FXSpread,
+		Duration:     server.config.FXQuoteDuration,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrFXRateNotFound) {
+			return nil, status.Errorf(codes.NotFound, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create quote: %s", err)
+	}
+
+	res := &pb.CreateQuoteResponse{
+		Quote: convertQuote(quote),
+	}
+
+	if request.Amount != nil {
+		toAmount, err := quote.Convert(request.GetAmount())
+		if err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		res.ToAmount = &toAmount
+	}
+
+	return res, nil
+}
+
+// validateCreateQuoteRequest validates all the fields of the request.
+func validateCreateQuoteRequest(request *pb.CreateQuoteRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateCurrency(request.GetFromCurrency()); err != nil {
+		violations = append(violations, fieldViolation("from_currency", err))
+	}
+
+	if err := validation.ValidateCurrency(request.GetToCurrency()); err != nil {
+		violations = append(violations, fieldViolation("to_currency", err))
+	} else if request.GetToCurrency() == request.GetFromCurrency() {
+		violations = append(violations, fieldViolation("to_currency", fmt.Errorf("must be different from from_currency")))
+	}
+
+	if request.Amount != nil {
+		if err := validation.ValidateAmount(request.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+
+	return violations
+}
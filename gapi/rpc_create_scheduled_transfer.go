@@ -0,0 +1,105 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// CreateScheduledTransfer schedules a transfer from an account of the authenticated user
+func (server *Server) CreateScheduledTransfer(ctx context.Context, request *pb.CreateScheduledTransferRequest) (*pb.CreateScheduledTransferResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateScheduledTransferRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	_, err = server.getOwnAccount(ctx, authPayload, request.GetFromAccountId(), request.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = server.getAccount(ctx, request.GetToAccountId(), request.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.checkEmailVerified(ctx, authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.checkStepUp(ctx, authPayload.Username, request.GetAmount(), request.GetTotpCode())
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: request.GetFromAccountId(),
+		ToAccountID:   request.GetToAccountId(),
+		Amount:        request.GetAmount(),
+		Recurrence:    request.GetRecurrence(),
+		StartAt:       request.GetStartAt().AsTime(),
+	}
+	// the worker checks before every run that the key still allows the transfer
+	if authPayload.IsAPIKey() {
+		arg.APIKeyID = &authPayload.APIKeyID
+	}
+
+	scheduledTransfer, err := server.store.CreateScheduledTransferTx(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create scheduled transfer: %s", err)
+	}
+
+	res := &pb.CreateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduledTransfer),
+	}
+
+	return res, nil
+}
+
+// validateCreateScheduledTransferRequest validates all the fields of the request.
+func validateCreateScheduledTransferRequest(request *pb.CreateScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	if err := validation.ValidateID(request.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+
+	if err := validation.ValidateAmount(request.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := validation.ValidateCurrency(request.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if !db.IsSupportedRecurrence(request.GetRecurrence()) {
+		violations = append(violations, fieldViolation("recurrence", errUnsupportedRecurrence))
+	}
+
+	if request.StartAt == nil || !request.GetStartAt().AsTime().After(time.Now()) {
+		violations = append(violations, fieldViolation("start_at", fmt.Errorf("start_at must be in the future")))
+	}
+
+	if request.TotpCode != nil {
+		if err := validation.ValidateTOTPCode(request.GetTotpCode()); err != nil {
+			violations = append(violations, fieldViolation("totp_code", err))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,154 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// maxIdempotencyKeyLength is the maximum length of the idempotency-key metadata
+const maxIdempotencyKeyLength = 255
+
+// CreateTransfer transfers money from an account of the authenticated user to another account.
+// A transfer sent with the idempotency-key metadata is performed at most once.
+func (server *Server) CreateTransfer(ctx context.Context, request *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateTransferRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	idempotencyKey := server.extractMetadata(ctx).IdempotencyKey
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		err := fmt.Errorf("must be at most %d characters long", maxIdempotencyKeyLength)
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation(idempotencyKeyHeader, err)})
+	}
+
+	_, err = server.getOwnAccount(ctx, authPayload, request.GetFromAccountId(), request.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = server.getAccount(ctx, request.GetToAccountId(), request.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.checkEmailVerified(ctx, authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	replay, err := server.isIdempotencyReplay(ctx, authPayload.Username, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// a retry of a committed transfer is replayed without a step-up,
+	// the code sent with it was already used by the first request
+	if !replay {
+		err = server.checkStepUp(ctx, authPayload.Username, request.GetAmount(), request.GetTotpCode())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: request.GetFromAccountId(),
+		ToAccountID:   request.GetToAccountId(),
+		Amount:        request.GetAmount(),
+	}
+
+	var result db.TransferTxResult
+	if idempotencyKey != "" {
+		result, err = server.store.IdempotentTransferTx(ctx, db.IdempotentTransferTxParams{
+			TransferTxParams: arg,
+			Username:         authPayload.Username,
+			IdempotencyKey:   idempotencyKey,
+			KeyDuration:      server.config.IdempotencyKeyDuration,
+		})
+	} else {
+		result, err = server.store.TransferTx(ctx, arg)
+	}
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) ||
+			errors.Is(err, db.ErrAccountFrozen) ||
+			errors.Is(err, db.ErrIdempotencyKeyReused) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to transfer money: %s", err)
+	}
+
+	// the balance of the recipient's account is not shown to the sender
+	res := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+	}
+
+	return res, nil
+}
+
+// isIdempotencyReplay tells if the idempotency key already holds the result
+// of a committed transfer of the user, so the request will be replayed
+func (server *Server) isIdempotencyReplay(ctx context.Context, username string, idempotencyKey string) (bool, error) {
+	if idempotencyKey == "" {
+		return false, nil
+	}
+
+	key, err := server.store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: username,
+		Key:      idempotencyKey,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, status.Errorf(codes.Internal, "failed to get idempotency key: %s", err)
+	}
+
+	return key.ExpiresAt.After(time.Now()), nil
+}
+
+// validateCreateTransferRequest validates all the fields of the request.
+func validateCreateTransferRequest(request *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	if err := validation.ValidateID(request.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+
+	if request.GetFromAccountId() == request.GetToAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", errors.New("must be different from from_account_id")))
+	}
+
+	if err := validation.ValidateAmount(request.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := validation.ValidateCurrency(request.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if request.TotpCode != nil {
+		if err := validation.ValidateTOTPCode(request.GetTotpCode()); err != nil {
+			violations = append(violations, fieldViolation("totp_code", err))
+		}
+	}
+
+	return violations
+}
@@ -10,6 +10,7 @@ import (
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"log/slog"
 )
 
 // CreateUser creates a new user
@@ -30,7 +31,8 @@ func (server *Server) CreateUser(ctx context.Context, request *pb.CreateUserRequ
 		Email:          request.GetEmail(),
 	}
 
-	user, err := server.store.CreateUser(ctx, params)
+	// a new user creates the account on their own behalf
+	user, err := server.store.CreateUserTx(withAuditActor(ctx, params.Username), params)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
@@ -41,6 +43,15 @@ func (server *Server) CreateUser(ctx context.Context, request *pb.CreateUserRequ
 		return nil, status.Errorf(codes.Internal, "failed to create user: %s", err)
 	}
 
+	// the user can ask for another email if this one is not sent
+	err = server.sendVerificationEmail(ctx, user)
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot send verification email",
+			slog.String("email_user", user.Username),
+			slog.String("error", err.Error()),
+		)
+	}
+
 	res := &pb.CreateUserResponse{
 		User: convertUser(user),
 	}
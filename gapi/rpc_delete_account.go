@@ -0,0 +1,49 @@
+package gapi
+
+import (
+	"context"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"github.com/lib/pq"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeleteAccount deletes an account of the authenticated user.
+// Accounts with entries, transfers or holds cannot be deleted.
+func (server *Server) DeleteAccount(ctx context.Context, request *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateDeleteAccountRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	_, err = server.getOwnAccountByID(ctx, authPayload, request.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.store.DeleteAccountTx(ctx, request.GetId())
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			return nil, status.Errorf(codes.FailedPrecondition, "account has a history and cannot be deleted")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to delete account: %s", err)
+	}
+
+	return &pb.DeleteAccountResponse{}, nil
+}
+
+// validateDeleteAccountRequest validates all the fields of the request.
+func validateDeleteAccountRequest(request *pb.DeleteAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
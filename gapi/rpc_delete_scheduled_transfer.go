@@ -0,0 +1,45 @@
+package gapi
+
+import (
+	"context"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeleteScheduledTransfer deletes a scheduled transfer of the authenticated user
+// together with the history of its runs
+func (server *Server) DeleteScheduledTransfer(ctx context.Context, request *pb.DeleteScheduledTransferRequest) (*pb.DeleteScheduledTransferResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateDeleteScheduledTransferRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	_, err = server.getOwnScheduledTransfer(ctx, authPayload, request.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.store.DeleteScheduledTransferTx(ctx, request.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete scheduled transfer: %s", err)
+	}
+
+	return &pb.DeleteScheduledTransferResponse{}, nil
+}
+
+// validateDeleteScheduledTransferRequest validates all the fields of the request.
+func validateDeleteScheduledTransferRequest(request *pb.DeleteScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
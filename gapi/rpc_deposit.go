@@ -0,0 +1,69 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Deposit puts money into a customer account. Only admins can call it,
+// because the money comes from the bank's own cash or settlement account.
+func (server *Server) Deposit(ctx context.Context, request *pb.DepositRequest) (*pb.DepositResponse, error) {
+	_, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateDepositRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	_, err = server.getAccount(ctx, request.GetAccountId(), request.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.DepositTx(ctx, db.DepositTxParams{
+		AccountID: request.GetAccountId(),
+		Amount:    request.GetAmount(),
+		Channel:   request.GetChannel(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to deposit money: %s", err)
+	}
+
+	res := &pb.DepositResponse{
+		Transfer: convertTransfer(result.Transfer),
+		Account:  convertAccount(result.ToAccount),
+		Entry:    convertEntry(result.ToEntry),
+	}
+
+	return res, nil
+}
+
+// validateDepositRequest validates all the fields of the request.
+func validateDepositRequest(request *pb.DepositRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := validation.ValidateAmount(request.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := validation.ValidateCurrency(request.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if !db.IsSupportedChannel(request.GetChannel()) {
+		violations = append(violations, fieldViolation("channel", fmt.Errorf("channel must be %s or %s", db.CashChannel, db.SettlementChannel)))
+	}
+
+	return violations
+}
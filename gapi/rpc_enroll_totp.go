@@ -0,0 +1,44 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EnrollTOTP generates a new TOTP secret for the authenticated user.
+// Two-factor authentication is enabled once the secret is confirmed with a code.
+func (server *Server) EnrollTOTP(ctx context.Context, request *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	secret, otpauthURL, err := utils.GenerateTOTPKey(authPayload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate TOTP secret: %s", err)
+	}
+
+	// a pending enrollment is replaced, an enabled one is not
+	_, err = server.store.CreateUserTOTP(ctx, db.CreateUserTOTPParams{
+		Username: authPayload.Username,
+		Secret:   secret,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.AlreadyExists, "%s", db.ErrTOTPAlreadyEnabled)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to enroll TOTP: %s", err)
+	}
+
+	res := &pb.EnrollTOTPResponse{
+		Secret:     secret,
+		OtpauthUrl: otpauthURL,
+	}
+
+	return res, nil
+}
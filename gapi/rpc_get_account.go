@@ -0,0 +1,41 @@
+package gapi
+
+import (
+	"context"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// GetAccount returns an account of the authenticated user
+func (server *Server) GetAccount(ctx context.Context, request *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateGetAccountRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.getOwnAccountByID(ctx, authPayload, request.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.GetAccountResponse{
+		Account: convertAccount(account),
+	}
+
+	return res, nil
+}
+
+// validateGetAccountRequest validates all the fields of the request.
+func validateGetAccountRequest(request *pb.GetAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
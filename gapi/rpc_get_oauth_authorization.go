@@ -0,0 +1,50 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetOAuthAuthorization checks an authorization request of an OAuth client
+// and returns what the authenticated user is asked to consent to
+func (server *Server) GetOAuthAuthorization(ctx context.Context, request *pb.GetOAuthAuthorizationRequest) (*pb.GetOAuthAuthorizationResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateOAuthAuthorizeFields(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	client, scopes, err := server.checkOAuthAuthorizeRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	// the consent screen can be skipped if the user has already granted all the scopes
+	consented := false
+	consent, err := server.store.GetOAuthConsent(ctx, db.GetOAuthConsentParams{
+		Username: authPayload.Username,
+		ClientID: client.ID,
+	})
+	if err == nil {
+		consented = token.HasScopes(consent.Scopes, scopes)
+	} else if err != sql.ErrNoRows {
+		return nil, status.Errorf(codes.Internal, "failed to get OAuth consent: %s", err)
+	}
+
+	res := &pb.GetOAuthAuthorizationResponse{
+		Client:    convertOAuthClient(client),
+		Scopes:    scopes,
+		Consented: consented,
+	}
+
+	return res, nil
+}
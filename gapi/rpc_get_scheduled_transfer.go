@@ -0,0 +1,41 @@
+package gapi
+
+import (
+	"context"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// GetScheduledTransfer returns a scheduled transfer of the authenticated user
+func (server *Server) GetScheduledTransfer(ctx context.Context, request *pb.GetScheduledTransferRequest) (*pb.GetScheduledTransferResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateGetScheduledTransferRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	scheduledTransfer, err := server.getOwnScheduledTransfer(ctx, authPayload, request.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.GetScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduledTransfer),
+	}
+
+	return res, nil
+}
+
+// validateGetScheduledTransferRequest validates all the fields of the request.
+func validateGetScheduledTransferRequest(request *pb.GetScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
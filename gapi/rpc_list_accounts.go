@@ -0,0 +1,54 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListAccounts returns accounts of the authenticated user
+func (server *Server) ListAccounts(ctx context.Context, request *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateListAccountsRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	accounts, err := server.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:  authPayload.Username,
+		Limit:  request.GetPageSize(),
+		Offset: (request.GetPageId() - 1) * request.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	res := &pb.ListAccountsResponse{
+		Accounts: make([]*pb.Account, 0, len(accounts)),
+	}
+	for _, account := range accounts {
+		// an API key sees only the accounts it is allowed to act on
+		if authPayload.AllowsAccount(account.ID) {
+			res.Accounts = append(res.Accounts, convertAccount(account))
+		}
+	}
+
+	return res, nil
+}
+
+// validateListAccountsRequest validates all the fields of the request.
+func validateListAccountsRequest(request *pb.ListAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidatePage(request.GetPageId(), request.GetPageSize(), 5, 10); err != nil {
+		violations = append(violations, fieldViolation("page", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,51 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListAPIKeys returns the API keys of the authenticated user
+func (server *Server) ListAPIKeys(ctx context.Context, request *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateListAPIKeysRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	apiKeys, err := server.store.ListAPIKeys(ctx, db.ListAPIKeysParams{
+		Owner:  authPayload.Username,
+		Limit:  request.GetPageSize(),
+		Offset: (request.GetPageId() - 1) * request.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list API keys: %s", err)
+	}
+
+	res := &pb.ListAPIKeysResponse{
+		ApiKeys: make([]*pb.APIKey, 0, len(apiKeys)),
+	}
+	for _, apiKey := range apiKeys {
+		res.ApiKeys = append(res.ApiKeys, convertAPIKey(apiKey))
+	}
+
+	return res, nil
+}
+
+// validateListAPIKeysRequest validates all the fields of the request.
+func validateListAPIKeysRequest(request *pb.ListAPIKeysRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidatePage(request.GetPageId(), request.GetPageSize(), 5, 10); err != nil {
+		violations = append(violations, fieldViolation("page", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,61 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListEntries returns the statement of an account of the authenticated user:
+// its entries with the transfer and the counterparty that produced them
+func (server *Server) ListEntries(ctx context.Context, request *pb.ListEntriesRequest) (*pb.ListEntriesResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateListEntriesRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.getOwnAccountByID(ctx, authPayload, request.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := server.store.ListAccountStatement(ctx, db.ListAccountStatementParams{
+		AccountID: account.ID,
+		Limit:     request.GetPageSize(),
+		Offset:    (request.GetPageId() - 1) * request.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list entries: %s", err)
+	}
+
+	res := &pb.ListEntriesResponse{
+		Entries: make([]*pb.Entry, 0, len(rows)),
+	}
+	for _, row := range rows {
+		res.Entries = append(res.Entries, convertStatementLine(row))
+	}
+
+	return res, nil
+}
+
+// validateListEntriesRequest validates all the fields of the request.
+func validateListEntriesRequest(request *pb.ListEntriesRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := validation.ValidatePage(request.GetPageId(), request.GetPageSize(), 5, 50); err != nil {
+		violations = append(violations, fieldViolation("page", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,51 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListOAuthClients returns the OAuth clients registered by the authenticated user
+func (server *Server) ListOAuthClients(ctx context.Context, request *pb.ListOAuthClientsRequest) (*pb.ListOAuthClientsResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateListOAuthClientsRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	clients, err := server.store.ListOAuthClients(ctx, db.ListOAuthClientsParams{
+		Owner:  authPayload.Username,
+		Limit:  request.GetPageSize(),
+		Offset: (request.GetPageId() - 1) * request.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list OAuth clients: %s", err)
+	}
+
+	res := &pb.ListOAuthClientsResponse{
+		Clients: make([]*pb.OAuthClient, 0, len(clients)),
+	}
+	for _, client := range clients {
+		res.Clients = append(res.Clients, convertOAuthClient(client))
+	}
+
+	return res, nil
+}
+
+// validateListOAuthClientsRequest validates all the fields of the request.
+func validateListOAuthClientsRequest(request *pb.ListOAuthClientsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidatePage(request.GetPageId(), request.GetPageSize(), 5, 10); err != nil {
+		violations = append(violations, fieldViolation("page", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,51 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListOAuthConsents returns the OAuth clients the authenticated user has granted access to, with the granted scopes
+func (server *Server) ListOAuthConsents(ctx context.Context, request *pb.ListOAuthConsentsRequest) (*pb.ListOAuthConsentsResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateListOAuthConsentsRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	consents, err := server.store.ListOAuthConsents(ctx, db.ListOAuthConsentsParams{
+		Username: authPayload.Username,
+		Limit:    request.GetPageSize(),
+		Offset:   (request.GetPageId() - 1) * request.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list OAuth consents: %s", err)
+	}
+
+	res := &pb.ListOAuthConsentsResponse{
+		Consents: make([]*pb.OAuthConsent, 0, len(consents)),
+	}
+	for _, consent := range consents {
+		res.Consents = append(res.Consents, convertOAuthConsent(consent))
+	}
+
+	return res, nil
+}
+
+// validateListOAuthConsentsRequest validates all the fields of the request.
+func validateListOAuthConsentsRequest(request *pb.ListOAuthConsentsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidatePage(request.GetPageId(), request.GetPageSize(), 5, 10); err != nil {
+		violations = append(violations, fieldViolation("page", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,54 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListScheduledTransfers returns scheduled transfers of the authenticated user
+func (server *Server) ListScheduledTransfers(ctx context.Context, request *pb.ListScheduledTransfersRequest) (*pb.ListScheduledTransfersResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateListScheduledTransfersRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  request.GetPageSize(),
+		Offset: (request.GetPageId() - 1) * request.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list scheduled transfers: %s", err)
+	}
+
+	res := &pb.ListScheduledTransfersResponse{
+		ScheduledTransfers: make([]*pb.ScheduledTransfer, 0, len(scheduledTransfers)),
+	}
+	for _, scheduledTransfer := range scheduledTransfers {
+		// an API key sees only the scheduled transfers of the accounts it is allowed to act on
+		if authPayload.AllowsAccount(scheduledTransfer.FromAccountID) {
+			res.ScheduledTransfers = append(res.ScheduledTransfers, convertScheduledTransfer(scheduledTransfer))
+		}
+	}
+
+	return res, nil
+}
+
+// validateListScheduledTransfersRequest validates all the fields of the request.
+func validateListScheduledTransfersRequest(request *pb.ListScheduledTransfersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidatePage(request.GetPageId(), request.GetPageSize(), 5, 10); err != nil {
+		violations = append(violations, fieldViolation("page", err))
+	}
+
+	return violations
+}
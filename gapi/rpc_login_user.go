@@ -4,13 +4,14 @@ import (
 	"context"
 	"database/sql"
 	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/metrics"
 	"github.com/aalug/bank-go/pb"
 	"github.com/aalug/bank-go/utils"
 	"github.com/aalug/bank-go/validation"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
 )
 
 // LoginUser handles user login
@@ -20,56 +21,60 @@ func (server *Server) LoginUser(ctx context.Context, request *pb.LoginUserReques
 		return nil, invalidArgumentError(violations)
 	}
 
-	user, err := server.store.GetUser(ctx, request.GetUsername())
+	metaData := server.extractMetadata(ctx)
+
+	// refuse the login without checking the password while the username or the IP is locked
+	locks, err := server.store.ListLoginLocks(ctx, db.ListLoginLocksParams{
+		Username: request.GetUsername(),
+		ClientIp: metaData.ClientIP,
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, status.Errorf(codes.NotFound, "user not found: %s", err)
-		}
-		return nil, status.Errorf(codes.Internal, "error getting user: %s", err)
+		return nil, status.Errorf(codes.Internal, "error checking login locks: %s", err)
 	}
 
-	err = utils.CheckPassword(request.GetPassword(), user.HashedPassword)
-	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "invalid password: %s", err)
+	if len(locks) > 0 {
+		metrics.LoginFailed(metrics.LoginFailureLocked)
+		return nil, resourceExhaustedError("too many failed login attempts, try again later", time.Until(locks[0].LockedUntil))
 	}
 
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "error creating access token: %s", err)
+	user, err := server.store.GetUser(ctx, request.GetUsername())
+	if err != nil && err != sql.ErrNoRows {
+		return nil, status.Errorf(codes.Internal, "error getting user: %s", err)
 	}
 
-	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
-		user.Username,
-		server.config.RefreshTokenDuration,
-	)
+	// unknown usernames and wrong passwords get the same response
+	if err == sql.ErrNoRows {
+		err = utils.CheckPasswordOfUnknownUser(request.GetPassword())
+	} else {
+		err = utils.CheckPassword(request.GetPassword(), user.HashedPassword)
+	}
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "error creating refresh token: %s", err)
+		_, err = server.store.RecordLoginFailureTx(ctx, db.RecordLoginFailureTxParams{
+			Username:          request.GetUsername(),
+			ClientIP:          metaData.ClientIP,
+			UsernameThreshold: server.config.LoginFailureThreshold,
+			IPThreshold:       server.config.LoginIPFailureThreshold,
+			LockoutDuration:   server.config.LoginLockoutDuration,
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error recording failed login: %s", err)
+		}
+
+		metrics.LoginFailed(metrics.LoginFailureInvalidCredentials)
+		return nil, status.Errorf(codes.Unauthenticated, "invalid username or password")
 	}
 
-	metaData := server.extractMetadata(ctx)
-	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
-		ID:           refreshPayload.ID,
-		Username:     user.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    metaData.UserAgent,
-		ClientIp:     metaData.ClientIP,
-		IsBlocked:    false,
-		ExpiresAt:    refreshPayload.ExpiredAt,
-	})
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "error creating session: %s", err)
+	// users with two-factor authentication get the tokens after the second step
+	userTOTP, err := server.store.GetUserTOTP(ctx, user.Username)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, status.Errorf(codes.Internal, "error getting two-factor authentication: %s", err)
 	}
 
-	res := &pb.LoginUserResponse{
-		User:                  convertUser(user),
-		SessionId:             session.ID.String(),
-		AccessToken:           accessToken,
-		RefreshToken:          refreshToken,
-		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
-		RefreshTokenExpiresAt: timestamppb.New(refreshPayload.ExpiredAt),
+	if err == nil && userTOTP.ConfirmedAt != nil {
+		return server.createMFAChallenge(ctx, user, metaData)
 	}
 
-	return res, nil
+	return server.completeLogin(ctx, user, metaData)
 }
 
 // validateLoginUserRequest validates all the fields of the request.
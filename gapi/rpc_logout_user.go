@@ -0,0 +1,58 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LogoutUser blocks the session of the refresh token,
+// so it can no longer be used to renew access tokens.
+// The access token sent in the authorization header, if any, is revoked.
+func (server *Server) LogoutUser(ctx context.Context, request *pb.LogoutUserRequest) (*pb.LogoutUserResponse, error) {
+	violations := validateLogoutUserRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	_, session, err := server.getActiveSession(ctx, request.GetRefreshToken())
+	if err != nil {
+		return nil, err
+	}
+
+	// the refresh token proves who the user is, the call may come without an access token
+	_, err = server.store.BlockSessionTx(withAuditActor(ctx, session.Username), session.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block session: %s", err)
+	}
+
+	// the access token is revoked too if it is sent along and belongs to the same user
+	accessPayload, err := server.verifyAccessToken(ctx)
+	if err == nil && accessPayload.Username == session.Username {
+		err = server.store.RevokeToken(ctx, db.RevokeTokenParams{
+			ID:        accessPayload.ID,
+			Username:  accessPayload.Username,
+			ExpiresAt: accessPayload.ExpiredAt,
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to revoke access token: %s", err)
+		}
+
+		server.revocations.RevokeToken(accessPayload)
+	}
+
+	return &pb.LogoutUserResponse{}, nil
+}
+
+// validateLogoutUserRequest validates all the fields of the request.
+func validateLogoutUserRequest(request *pb.LogoutUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateStringLength(request.GetRefreshToken(), 1, 1024); err != nil {
+		violations = append(violations, fieldViolation("refresh_token", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,59 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OAuthToken exchanges an authorization code or a refresh token of an OAuth client
+// for an access token limited to the scopes the user granted
+func (server *Server) OAuthToken(ctx context.Context, request *pb.OAuthTokenRequest) (*pb.OAuthTokenResponse, error) {
+	client, err := server.authenticateOAuthClient(ctx, request.GetClientId(), request.GetClientSecret())
+	if err != nil {
+		return nil, err
+	}
+
+	var refreshToken db.OAuthRefreshToken
+	var rawRefreshToken string
+	switch request.GetGrantType() {
+	case "authorization_code":
+		refreshToken, rawRefreshToken, err = server.exchangeOAuthCode(ctx, client, request.GetCode(), request.GetRedirectUri(), request.GetCodeVerifier())
+	case "refresh_token":
+		refreshToken, rawRefreshToken, err = server.rotateOAuthRefreshToken(ctx, client, request.GetRefreshToken())
+	default:
+		err = oauthError(codes.InvalidArgument, oauthErrorInvalidRequest, "grant_type must be authorization_code or refresh_token")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUser(ctx, refreshToken.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	accessToken, _, err := server.tokenMaker.CreateClientToken(
+		user.Username,
+		user.Role,
+		client.ID,
+		refreshToken.Scopes,
+		server.config.AccessTokenDuration,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token: %s", err)
+	}
+
+	res := &pb.OAuthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(server.config.AccessTokenDuration.Seconds()),
+		RefreshToken: rawRefreshToken,
+		Scope:        token.FormatScope(refreshToken.Scopes),
+	}
+
+	return res, nil
+}
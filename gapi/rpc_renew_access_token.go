@@ -0,0 +1,98 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log/slog"
+)
+
+// RenewAccessToken creates a new access token for the session of the refresh token.
+// The refresh token is rotated, the returned one has to be used for the next renewal.
+func (server *Server) RenewAccessToken(ctx context.Context, request *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	violations := validateRenewAccessTokenRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	refreshPayload, session, err := server.getSession(ctx, request.GetRefreshToken())
+	if err != nil {
+		return nil, err
+	}
+
+	// the role may have changed since the login, so it is not taken from the refresh token
+	user, err := server.store.GetUser(ctx, refreshPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	refreshToken, newRefreshPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		server.config.RefreshTokenDuration,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error creating refresh token: %s", err)
+	}
+
+	metaData := server.extractMetadata(ctx)
+	newSession, err := server.store.RotateSessionTx(ctx, db.RotateSessionTxParams{
+		SessionID:    session.ID,
+		NewSessionID: newRefreshPayload.ID,
+		RefreshToken: refreshToken,
+		UserAgent:    metaData.UserAgent,
+		ClientIp:     metaData.ClientIP,
+		ExpiresAt:    newRefreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrRefreshTokenReused):
+			slog.WarnContext(ctx, "refresh token reuse detected, blocked the session family",
+				slog.String("family_id", session.FamilyID.String()),
+				slog.String("session_user", session.Username),
+			)
+			return nil, unauthenticatedError(err)
+		case errors.Is(err, db.ErrSessionBlocked):
+			return nil, unauthenticatedError(err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to rotate session: %s", err)
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		server.config.AccessTokenDuration,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error creating access token: %s", err)
+	}
+
+	res := &pb.RenewAccessTokenResponse{
+		SessionId:             newRefreshPayload.ID.String(),
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshTokenExpiresAt: timestamppb.New(newSession.ExpiresAt),
+	}
+
+	return res, nil
+}
+
+// validateRenewAccessTokenRequest validates all the fields of the request.
+func validateRenewAccessTokenRequest(request *pb.RenewAccessTokenRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateStringLength(request.GetRefreshToken(), 1, 1024); err != nil {
+		violations = append(violations, fieldViolation("refresh_token", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,66 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/mail"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/utils"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// RequestPasswordReset sends a password reset token to the email address.
+// The response is the same whether a user has the address or not,
+// so it cannot be used to find out who has an account.
+func (server *Server) RequestPasswordReset(ctx context.Context, request *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	violations := validateRequestPasswordResetRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := server.store.GetUserByEmail(ctx, request.GetEmail())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &pb.RequestPasswordResetResponse{}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	resetToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate password reset token: %s", err)
+	}
+
+	_, err = server.store.CreateEmailTokenTx(ctx, db.CreateEmailTokenParams{
+		HashedToken: utils.HashToken(resetToken),
+		Username:    user.Username,
+		Purpose:     db.EmailTokenResetPassword,
+		Email:       user.Email,
+		ExpiresAt:   time.Now().Add(server.config.PasswordResetDuration),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create password reset token: %s", err)
+	}
+
+	msg := mail.NewPasswordResetMessage(user.Email, user.FullName, resetToken, server.config.PasswordResetDuration)
+	err = server.mailer.Send(ctx, msg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to send password reset email: %s", err)
+	}
+
+	return &pb.RequestPasswordResetResponse{}, nil
+}
+
+// validateRequestPasswordResetRequest validates all the fields of the request.
+func validateRequestPasswordResetRequest(request *pb.RequestPasswordResetRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateEmail(request.GetEmail()); err != nil {
+		violations = append(violations, fieldViolation("email", err))
+	}
+
+	return violations
+}
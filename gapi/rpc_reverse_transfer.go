@@ -0,0 +1,91 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReverseTransfer moves the money of a transfer received by the authenticated user back to the sender
+func (server *Server) ReverseTransfer(ctx context.Context, request *pb.ReverseTransferRequest) (*pb.ReverseTransferResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateReverseTransferRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, request.GetTransferId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "transfer not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get transfer: %s", err)
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if toAccount.Owner != authPayload.Username || !authPayload.AllowsAccount(toAccount.ID) {
+		return nil, status.Errorf(codes.PermissionDenied, "only the recipient of the transfer can reverse it")
+	}
+
+	err = server.checkEmailVerified(ctx, authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: request.GetTransferId(),
+		Amount:     request.GetAmount(),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrTransferIsReversal) ||
+			errors.Is(err, db.ErrTransferAlreadyReversed) ||
+			errors.Is(err, db.ErrReversalExceedsTransfer) ||
+			errors.Is(err, db.ErrFXTransferNotReversible) ||
+			errors.Is(err, db.ErrInsufficientFunds) ||
+			errors.Is(err, db.ErrAccountFrozen) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		if errors.Is(err, db.ErrSystemAccount) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reverse transfer: %s", err)
+	}
+
+	res := &pb.ReverseTransferResponse{
+		OriginalTransfer: convertTransfer(result.OriginalTransfer),
+		Transfer:         convertTransfer(result.Transfer),
+		Account:          convertAccount(result.FromAccount),
+		Entry:            convertEntry(result.FromEntry),
+	}
+
+	return res, nil
+}
+
+// validateReverseTransferRequest validates all the fields of the request.
+func validateReverseTransferRequest(request *pb.ReverseTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetTransferId()); err != nil {
+		violations = append(violations, fieldViolation("transfer_id", err))
+	}
+
+	if request.Amount != nil {
+		if err := validation.ValidateAmount(request.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+
+	return violations
+}
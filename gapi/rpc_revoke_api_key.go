@@ -0,0 +1,53 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RevokeAPIKey revokes an API key of the authenticated user
+func (server *Server) RevokeAPIKey(ctx context.Context, request *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateRevokeAPIKeyRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	apiKey, err := server.store.GetAPIKey(ctx, request.GetId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "API key not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get API key: %s", err)
+	}
+
+	if apiKey.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "API key does not belong to the authenticated user")
+	}
+
+	// a key that is already revoked is left as it is
+	_, err = server.store.RevokeAPIKeyTx(ctx, apiKey.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, status.Errorf(codes.Internal, "failed to revoke API key: %s", err)
+	}
+
+	return &pb.RevokeAPIKeyResponse{}, nil
+}
+
+// validateRevokeAPIKeyRequest validates all the fields of the request.
+func validateRevokeAPIKeyRequest(request *pb.RevokeAPIKeyRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,48 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RevokeOAuthConsent withdraws the consent of the authenticated user to an OAuth client
+// and revokes the refresh tokens of the client, so it can no longer get access tokens
+func (server *Server) RevokeOAuthConsent(ctx context.Context, request *pb.RevokeOAuthConsentRequest) (*pb.RevokeOAuthConsentResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateRevokeOAuthConsentRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	err = server.store.RevokeOAuthConsentTx(ctx, db.DeleteOAuthConsentParams{
+		Username: authPayload.Username,
+		ClientID: request.GetClientId(),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "OAuth consent not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to revoke OAuth consent: %s", err)
+	}
+
+	return &pb.RevokeOAuthConsentResponse{}, nil
+}
+
+// validateRevokeOAuthConsentRequest validates all the fields of the request.
+func validateRevokeOAuthConsentRequest(request *pb.RevokeOAuthConsentRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if request.GetClientId() == "" {
+		violations = append(violations, fieldViolation("client_id", fmt.Errorf("must not be empty")))
+	}
+
+	return violations
+}
@@ -0,0 +1,55 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RevokeOAuthToken revokes an access or refresh token issued to the OAuth client.
+// Unknown tokens and tokens of other clients are ignored, so the response is the same for all of them.
+func (server *Server) RevokeOAuthToken(ctx context.Context, request *pb.RevokeOAuthTokenRequest) (*pb.RevokeOAuthTokenResponse, error) {
+	if request.GetToken() == "" {
+		return nil, oauthError(codes.InvalidArgument, oauthErrorInvalidRequest, "token is required")
+	}
+
+	client, err := server.authenticateOAuthClient(ctx, request.GetClientId(), request.GetClientSecret())
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := server.store.GetOAuthRefreshToken(ctx, utils.HashToken(request.GetToken()))
+	if err == nil {
+		if refreshToken.ClientID == client.ID {
+			_, err = server.store.RevokeOAuthRefreshToken(ctx, refreshToken.ID)
+			if err != nil && err != sql.ErrNoRows {
+				return nil, status.Errorf(codes.Internal, "failed to revoke refresh token: %s", err)
+			}
+		}
+
+		return &pb.RevokeOAuthTokenResponse{}, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, status.Errorf(codes.Internal, "failed to get refresh token: %s", err)
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(request.GetToken())
+	if err == nil && payload.ClientID == client.ID {
+		err = server.store.RevokeToken(ctx, db.RevokeTokenParams{
+			ID:        payload.ID,
+			Username:  payload.Username,
+			ExpiresAt: payload.ExpiredAt,
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to revoke access token: %s", err)
+		}
+
+		server.revocations.RevokeToken(payload)
+	}
+
+	return &pb.RevokeOAuthTokenResponse{}, nil
+}
@@ -0,0 +1,37 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/aalug/bank-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SendVerificationEmail sends the authenticated user a new email verification link.
+// The links sent before stop working.
+func (server *Server) SendVerificationEmail(ctx context.Context, request *pb.SendVerificationEmailRequest) (*pb.SendVerificationEmailResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	if user.IsEmailVerified {
+		return nil, status.Errorf(codes.FailedPrecondition, "email address is already verified")
+	}
+
+	err = server.sendVerificationEmail(ctx, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to send verification email: %s", err)
+	}
+
+	return &pb.SendVerificationEmailResponse{}, nil
+}
@@ -0,0 +1,118 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// UpdateScheduledTransfer changes a scheduled transfer of the authenticated user
+func (server *Server) UpdateScheduledTransfer(ctx context.Context, request *pb.UpdateScheduledTransferRequest) (*pb.UpdateScheduledTransferResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateUpdateScheduledTransferRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	scheduledTransfer, err := server.getOwnScheduledTransfer(ctx, authPayload, request.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if scheduledTransfer.Status == db.ScheduledTransferCompleted {
+		return nil, status.Errorf(codes.FailedPrecondition, "scheduled transfer is completed")
+	}
+
+	// only suspending the transfer needs no step-up
+	if request.Amount != nil || request.Recurrence != nil || request.NextRunAt != nil ||
+		request.GetStatus() == db.ScheduledTransferActive {
+		amount := scheduledTransfer.Amount
+		if request.Amount != nil {
+			amount = request.GetAmount()
+		}
+
+		err = server.checkStepUp(ctx, scheduledTransfer.Owner, amount, request.GetTotpCode())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	params := db.UpdateScheduledTransferParams{
+		ID: request.GetId(),
+		Amount: sql.NullInt64{
+			Int64: request.GetAmount(),
+			Valid: request.Amount != nil,
+		},
+		Recurrence: sql.NullString{
+			String: request.GetRecurrence(),
+			Valid:  request.Recurrence != nil,
+		},
+		NextRunAt: sql.NullTime{
+			Time:  request.GetNextRunAt().AsTime(),
+			Valid: request.NextRunAt != nil,
+		},
+		Status: sql.NullString{
+			String: request.GetStatus(),
+			Valid:  request.Status != nil,
+		},
+	}
+
+	scheduledTransfer, err = server.store.UpdateScheduledTransferTx(ctx, params)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update scheduled transfer: %s", err)
+	}
+
+	res := &pb.UpdateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduledTransfer),
+	}
+
+	return res, nil
+}
+
+// validateUpdateScheduledTransferRequest validates all the fields of the request.
+func validateUpdateScheduledTransferRequest(request *pb.UpdateScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	if request.Amount != nil {
+		if err := validation.ValidateAmount(request.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+
+	if request.Recurrence != nil && !db.IsSupportedRecurrence(request.GetRecurrence()) {
+		violations = append(violations, fieldViolation("recurrence", errUnsupportedRecurrence))
+	}
+
+	if request.NextRunAt != nil && !request.GetNextRunAt().AsTime().After(time.Now()) {
+		violations = append(violations, fieldViolation("next_run_at", fmt.Errorf("next_run_at must be in the future")))
+	}
+
+	if request.Status != nil {
+		switch request.GetStatus() {
+		case db.ScheduledTransferActive, db.ScheduledTransferSuspended:
+		default:
+			violations = append(violations, fieldViolation("status", fmt.Errorf("status must be %s or %s", db.ScheduledTransferActive, db.ScheduledTransferSuspended)))
+		}
+	}
+
+	if request.TotpCode != nil {
+		if err := validation.ValidateTOTPCode(request.GetTotpCode()); err != nil {
+			violations = append(violations, fieldViolation("totp_code", err))
+		}
+	}
+
+	return violations
+}
@@ -10,6 +10,7 @@ import (
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"log/slog"
 	"time"
 )
 
@@ -61,7 +62,8 @@ func (server *Server) UpdateUser(ctx context.Context, request *pb.UpdateUserRequ
 		}
 	}
 
-	user, err := server.store.UpdateUser(ctx, params)
+	// a password change revokes all tokens issued before it
+	user, err := server.store.UpdateUserTx(ctx, params)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, status.Errorf(codes.NotFound, "user not found")
@@ -69,6 +71,21 @@ func (server *Server) UpdateUser(ctx context.Context, request *pb.UpdateUserRequ
 		return nil, status.Errorf(codes.Internal, "failed to update user: %s", err)
 	}
 
+	if params.PasswordChangedAt.Valid {
+		server.revocations.RevokeUserTokens(user.Username, user.PasswordChangedAt)
+	}
+
+	// a new email address has to be verified again
+	if params.Email.Valid && !user.IsEmailVerified {
+		err = server.sendVerificationEmail(ctx, user)
+		if err != nil {
+			slog.ErrorContext(ctx, "cannot send verification email",
+				slog.String("email_user", user.Username),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
 	res := &pb.UpdateUserResponse{
 		User: convertUser(user),
 	}
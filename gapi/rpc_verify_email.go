@@ -0,0 +1,44 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/utils"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VerifyEmail marks the email address the verification link was sent to as verified
+func (server *Server) VerifyEmail(ctx context.Context, request *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	violations := validateVerifyEmailRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := server.store.VerifyEmailTx(ctx, utils.HashToken(request.GetToken()))
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidEmailToken) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to verify email: %s", err)
+	}
+
+	res := &pb.VerifyEmailResponse{
+		User: convertUser(user),
+	}
+
+	return res, nil
+}
+
+// validateVerifyEmailRequest validates all the fields of the request.
+func validateVerifyEmailRequest(request *pb.VerifyEmailRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateStringLength(request.GetToken(), 1, 100); err != nil {
+		violations = append(violations, fieldViolation("token", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,134 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/metrics"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/utils"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// VerifyLoginMFA completes the login of a user with two-factor authentication
+// with a TOTP code or a recovery code and the challenge token of the first step
+func (server *Server) VerifyLoginMFA(ctx context.Context, request *pb.VerifyLoginMFARequest) (*pb.VerifyLoginMFAResponse, error) {
+	violations := validateVerifyLoginMFARequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	challenge, err := server.store.GetMFAChallengeByToken(ctx, utils.HashToken(request.GetMfaChallengeToken()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid or expired MFA challenge")
+		}
+		return nil, status.Errorf(codes.Internal, "error getting MFA challenge: %s", err)
+	}
+
+	if challenge.UsedAt != nil || time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= utils.MaxMFAAttempts {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid or expired MFA challenge")
+	}
+
+	metaData := server.extractMetadata(ctx)
+
+	// the codes are guessed no faster than passwords
+	locks, err := server.store.ListLoginLocks(ctx, db.ListLoginLocksParams{
+		Username: challenge.Username,
+		ClientIp: metaData.ClientIP,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error checking login locks: %s", err)
+	}
+
+	if len(locks) > 0 {
+		metrics.LoginFailed(metrics.LoginFailureLocked)
+		return nil, resourceExhaustedError("too many failed login attempts, try again later", time.Until(locks[0].LockedUntil))
+	}
+
+	var valid bool
+	if request.TotpCode != nil {
+		var userTOTP db.UserTOTP
+		userTOTP, err = server.store.GetUserTOTP(ctx, challenge.Username)
+		if err == nil {
+			valid, err = server.useTOTPCode(ctx, userTOTP, request.GetTotpCode())
+		}
+	} else {
+		valid, err = server.useRecoveryCode(ctx, challenge.Username, request.GetRecoveryCode())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error verifying code: %s", err)
+	}
+
+	if !valid {
+		_, err = server.store.IncrementMFAChallengeAttempts(ctx, challenge.ID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error recording failed attempt: %s", err)
+		}
+
+		_, err = server.store.RecordLoginFailureTx(ctx, db.RecordLoginFailureTxParams{
+			Username:          challenge.Username,
+			ClientIP:          metaData.ClientIP,
+			UsernameThreshold: server.config.LoginFailureThreshold,
+			IPThreshold:       server.config.LoginIPFailureThreshold,
+			LockoutDuration:   server.config.LoginLockoutDuration,
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error recording failed login: %s", err)
+		}
+
+		metrics.LoginFailed(metrics.LoginFailureInvalidCode)
+		return nil, status.Errorf(codes.Unauthenticated, "invalid two-factor authentication code")
+	}
+
+	// a challenge completes one login, even when the same token is sent twice at once
+	_, err = server.store.UseMFAChallenge(ctx, challenge.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid or expired MFA challenge")
+		}
+		return nil, status.Errorf(codes.Internal, "error using MFA challenge: %s", err)
+	}
+
+	user, err := server.store.GetUser(ctx, challenge.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error getting user: %s", err)
+	}
+
+	login, err := server.completeLogin(ctx, user, metaData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.VerifyLoginMFAResponse{Login: login}, nil
+}
+
+// validateVerifyLoginMFARequest validates all the fields of the request.
+func validateVerifyLoginMFARequest(request *pb.VerifyLoginMFARequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if request.GetMfaChallengeToken() == "" {
+		violations = append(violations, fieldViolation("mfa_challenge_token", errors.New("must not be empty")))
+	}
+
+	if (request.TotpCode == nil) == (request.RecoveryCode == nil) {
+		violations = append(violations, fieldViolation("totp_code", errors.New("exactly one of totp_code and recovery_code must be given")))
+	}
+
+	if request.TotpCode != nil {
+		if err := validation.ValidateTOTPCode(request.GetTotpCode()); err != nil {
+			violations = append(violations, fieldViolation("totp_code", err))
+		}
+	}
+
+	if request.RecoveryCode != nil {
+		if err := validation.ValidateStringLength(request.GetRecoveryCode(), 1, 32); err != nil {
+			violations = append(violations, fieldViolation("recovery_code", err))
+		}
+	}
+
+	return violations
+}
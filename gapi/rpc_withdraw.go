@@ -0,0 +1,79 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Withdraw takes money out of a customer account. Only admins can call it,
+// because the money leaves the bank through its cash or settlement account.
+func (server *Server) Withdraw(ctx context.Context, request *pb.WithdrawRequest) (*pb.WithdrawResponse, error) {
+	_, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateWithdrawRequest(request)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.getAccount(ctx, request.GetAccountId(), request.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	// money leaves the bank only for customers who verified their email address
+	err = server.checkEmailVerified(ctx, account.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.WithdrawTx(ctx, db.WithdrawTxParams{
+		AccountID: request.GetAccountId(),
+		Amount:    request.GetAmount(),
+		Channel:   request.GetChannel(),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountFrozen) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to withdraw money: %s", err)
+	}
+
+	res := &pb.WithdrawResponse{
+		Transfer: convertTransfer(result.Transfer),
+		Account:  convertAccount(result.FromAccount),
+		Entry:    convertEntry(result.FromEntry),
+	}
+
+	return res, nil
+}
+
+// validateWithdrawRequest validates all the fields of the request.
+func validateWithdrawRequest(request *pb.WithdrawRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validation.ValidateID(request.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := validation.ValidateAmount(request.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := validation.ValidateCurrency(request.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if !db.IsSupportedChannel(request.GetChannel()) {
+		violations = append(violations, fieldViolation("channel", fmt.Errorf("channel must be %s or %s", db.CashChannel, db.SettlementChannel)))
+	}
+
+	return violations
+}
@@ -0,0 +1,33 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errUnsupportedRecurrence is the field violation of an unsupported recurrence
+var errUnsupportedRecurrence = fmt.Errorf("recurrence must be %s, %s, %s or %s",
+	db.RecurrenceOnce, db.RecurrenceDaily, db.RecurrenceWeekly, db.RecurrenceMonthly)
+
+// getOwnScheduledTransfer returns the scheduled transfer if it exists
+// and belongs to the authenticated user
+func (server *Server) getOwnScheduledTransfer(ctx context.Context, authPayload *token.Payload, id int64) (db.ScheduledTransfer, error) {
+	scheduledTransfer, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return scheduledTransfer, status.Errorf(codes.NotFound, "scheduled transfer not found")
+		}
+		return scheduledTransfer, status.Errorf(codes.Internal, "failed to get scheduled transfer: %s", err)
+	}
+
+	if scheduledTransfer.Owner != authPayload.Username || !authPayload.AllowsAccount(scheduledTransfer.FromAccountID) {
+		return scheduledTransfer, status.Errorf(codes.PermissionDenied, "scheduled transfer does not belong to the authenticated user")
+	}
+
+	return scheduledTransfer, nil
+}
@@ -1,8 +1,10 @@
 package gapi
 
 import (
+	"context"
 	"fmt"
 	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/mail"
 	"github.com/aalug/bank-go/pb"
 	"github.com/aalug/bank-go/token"
 	"github.com/aalug/bank-go/utils"
@@ -11,14 +13,21 @@ import (
 // Server serves gRPC requests for the service
 type Server struct {
 	pb.UnimplementedGoBankServer
-	config     utils.Config
-	store      db.Store
-	tokenMaker token.Maker
+	config      utils.Config
+	store       db.Store
+	tokenMaker  token.Maker
+	revocations *token.RevocationList
+	mailer      mail.Sender
 }
 
 // NewServer creates a new gRPC server
-func NewServer(config utils.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+func NewServer(config utils.Config, store db.Store, mailer mail.Sender) (*Server, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	tokenMaker, err := token.NewMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker:  %w", err)
 	}
@@ -26,7 +35,15 @@ func NewServer(config utils.Config, store db.Store) (*Server, error) {
 		config:     config,
 		store:      store,
 		tokenMaker: tokenMaker,
+		// refresh tokens outlive access tokens, so their revocations are kept the longest
+		revocations: token.NewRevocationList(db.NewRevocationStore(store), config.RefreshTokenDuration),
+		mailer:      mailer,
 	}
 
 	return server, nil
 }
+
+// RefreshRevokedTokens keeps the list of revoked tokens up to date until the context is cancelled
+func (server *Server) RefreshRevokedTokens(ctx context.Context) {
+	server.revocations.Start(ctx, server.config.RevocationRefreshInterval)
+}
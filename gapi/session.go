@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// getActiveSession verifies the refresh token and returns its payload together
+// with the session it was issued for. The session must not be blocked, expired or rotated.
+func (server *Server) getActiveSession(ctx context.Context, refreshToken string) (*token.Payload, db.Session, error) {
+	refreshPayload, session, err := server.getSession(ctx, refreshToken)
+	if err != nil {
+		return nil, session, err
+	}
+
+	if session.RotatedAt != nil {
+		return nil, session, unauthenticatedError(fmt.Errorf("rotated session"))
+	}
+
+	return refreshPayload, session, nil
+}
+
+// getSession verifies the refresh token and returns its payload together
+// with the session it was issued for. The session must not be blocked or expired.
+func (server *Server) getSession(ctx context.Context, refreshToken string) (*token.Payload, db.Session, error) {
+	refreshPayload, err := server.tokenMaker.VerifyToken(refreshToken)
+	if err != nil {
+		return nil, db.Session{}, unauthenticatedError(err)
+	}
+
+	// a password change revokes the refresh tokens issued before it as well
+	err = server.revocations.Check(refreshPayload)
+	if err != nil {
+		return nil, db.Session{}, unauthenticatedError(err)
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, session, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, session, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+
+	if session.IsBlocked {
+		return nil, session, unauthenticatedError(fmt.Errorf("blocked session"))
+	}
+
+	if session.Username != refreshPayload.Username {
+		return nil, session, unauthenticatedError(fmt.Errorf("incorrect session user"))
+	}
+
+	if session.RefreshToken != refreshToken {
+		return nil, session, unauthenticatedError(fmt.Errorf("mismatched session token"))
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return nil, session, unauthenticatedError(fmt.Errorf("expired session"))
+	}
+
+	return refreshPayload, session, nil
+}
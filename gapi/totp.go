@@ -0,0 +1,198 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/metrics"
+	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// createMFAChallenge starts the second login step of a user with two-factor authentication.
+// Only the hash of the challenge token is stored.
+func (server *Server) createMFAChallenge(ctx context.Context, user db.User, metaData *Metadata) (*pb.LoginUserResponse, error) {
+	challengeToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error creating MFA challenge token: %s", err)
+	}
+
+	challenge, err := server.store.CreateMFAChallenge(ctx, db.CreateMFAChallengeParams{
+		ID:          uuid.New(),
+		HashedToken: utils.HashToken(challengeToken),
+		Username:    user.Username,
+		ClientIp:    metaData.ClientIP,
+		UserAgent:   metaData.UserAgent,
+		ExpiresAt:   time.Now().Add(server.config.MFAChallengeDuration),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error creating MFA challenge: %s", err)
+	}
+
+	res := &pb.LoginUserResponse{
+		MfaRequired:           true,
+		MfaChallengeToken:     challengeToken,
+		MfaChallengeExpiresAt: timestamppb.New(challenge.ExpiresAt),
+	}
+
+	return res, nil
+}
+
+// completeLogin clears the failed logins of the user and creates the tokens and the session
+func (server *Server) completeLogin(ctx context.Context, user db.User, metaData *Metadata) (*pb.LoginUserResponse, error) {
+	err := server.store.DeleteLoginFailure(ctx, db.DeleteLoginFailureParams{
+		KeyType: db.LoginKeyUsername,
+		Key:     user.Username,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error clearing failed logins: %s", err)
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, server.config.AccessTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error creating access token: %s", err)
+	}
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		server.config.RefreshTokenDuration,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error creating refresh token: %s", err)
+	}
+
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    metaData.UserAgent,
+		ClientIp:     metaData.ClientIP,
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error creating session: %s", err)
+	}
+
+	metrics.LoginSucceeded()
+
+	res := &pb.LoginUserResponse{
+		User:                  convertUser(user),
+		SessionId:             session.ID.String(),
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshTokenExpiresAt: timestamppb.New(refreshPayload.ExpiredAt),
+	}
+
+	return res, nil
+}
+
+// useTOTPCode checks the code against the enabled TOTP secret of the user.
+// A code is accepted once, so a code seen by someone else cannot be used after the user.
+func (server *Server) useTOTPCode(ctx context.Context, userTOTP db.UserTOTP, code string) (bool, error) {
+	if userTOTP.ConfirmedAt == nil {
+		return false, nil
+	}
+
+	step, ok := utils.ValidateTOTP(userTOTP.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	_, err := server.store.UseTOTPStep(ctx, db.UseTOTPStepParams{
+		Username: userTOTP.Username,
+		Step:     step,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// useRecoveryCode marks an unused recovery code of the user as used
+func (server *Server) useRecoveryCode(ctx context.Context, username string, code string) (bool, error) {
+	_, err := server.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		Username:   username,
+		HashedCode: utils.HashRecoveryCode(code),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// checkStepUp requires a fresh TOTP code for a transfer of the given amount
+// when it reaches the step-up amount and the user has two-factor authentication enabled
+func (server *Server) checkStepUp(ctx context.Context, username string, amount int64, totpCode string) error {
+	if server.config.StepUpTransferAmount <= 0 || amount < server.config.StepUpTransferAmount {
+		return nil
+	}
+
+	userTOTP, err := server.store.GetUserTOTP(ctx, username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return status.Errorf(codes.Internal, "failed to get two-factor authentication: %s", err)
+	}
+
+	if userTOTP.ConfirmedAt == nil {
+		return nil
+	}
+
+	if totpCode == "" {
+		return status.Errorf(codes.PermissionDenied, "transfers of %d or more require totp_code", server.config.StepUpTransferAmount)
+	}
+
+	metaData := server.extractMetadata(ctx)
+
+	// step-up codes count against the same lockout as the codes of a login
+	locks, err := server.store.ListLoginLocks(ctx, db.ListLoginLocksParams{
+		Username: username,
+		ClientIp: metaData.ClientIP,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "error checking login locks: %s", err)
+	}
+
+	if len(locks) > 0 {
+		return resourceExhaustedError("too many failed login attempts, try again later", time.Until(locks[0].LockedUntil))
+	}
+
+	valid, err := server.useTOTPCode(ctx, userTOTP, totpCode)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to verify code: %s", err)
+	}
+
+	if !valid {
+		_, err = server.store.RecordLoginFailureTx(ctx, db.RecordLoginFailureTxParams{
+			Username:          username,
+			ClientIP:          metaData.ClientIP,
+			UsernameThreshold: server.config.LoginFailureThreshold,
+			IPThreshold:       server.config.LoginIPFailureThreshold,
+			LockoutDuration:   server.config.LoginLockoutDuration,
+		})
+		if err != nil {
+			return status.Errorf(codes.Internal, "error recording failed login: %s", err)
+		}
+
+		return status.Errorf(codes.PermissionDenied, "invalid two-factor authentication code")
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// statusRecorder remembers the status code written to the response
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (recorder *statusRecorder) WriteHeader(statusCode int) {
+	recorder.statusCode = statusCode
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+// HTTPHandler logs every request served by the handler. The request ID is accepted
+// from or generated into the X-Request-ID header of the request, so handlers that pass
+// the header on, like the gRPC gateway, keep it, and it is returned in the response.
+func HTTPHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		startTime := time.Now()
+
+		requestID := AcceptRequestID(req.Header.Get(RequestIDHeader))
+		req.Header.Set(RequestIDHeader, requestID)
+		res.Header().Set(RequestIDHeader, requestID)
+
+		ctx, _ := WithRequest(req.Context(), requestID)
+		recorder := &statusRecorder{ResponseWriter: res, statusCode: http.StatusOK}
+		handler.ServeHTTP(recorder, req.WithContext(ctx))
+
+		slog.Log(ctx, HTTPStatusLevel(recorder.statusCode), "received an HTTP request",
+			slog.String("protocol", "http"),
+			slog.String("method", req.Method),
+			slog.String("path", req.URL.Path),
+			slog.Int("status_code", recorder.statusCode),
+			slog.String("status_text", http.StatusText(recorder.statusCode)),
+			slog.Duration("latency", time.Since(startTime)),
+		)
+	})
+}
+
+// HTTPStatusLevel returns the level requests with the status code are logged at,
+// server errors are errors and client errors are warnings
+func HTTPStatusLevel(statusCode int) slog.Level {
+	switch {
+	case statusCode >= http.StatusInternalServerError:
+		return slog.LevelError
+	case statusCode >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// New creates a logger that writes JSON lines to w at the given level,
+// debug, info (the default), warn or error. Records logged with the context of a request
+// carry its request ID and, once the request is authenticated, its user.
+func New(w io.Writer, level string) (*slog.Logger, error) {
+	var minLevel slog.Level
+	if level != "" {
+		err := minLevel.UnmarshalText([]byte(level))
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+		}
+	}
+
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: minLevel})
+	return slog.New(contextHandler{Handler: handler}), nil
+}
+
+// contextHandler adds the fields of the request in the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if request, ok := RequestFromContext(ctx); ok {
+		record.AddAttrs(slog.String("request_id", request.ID))
+		if username := request.Username(); username != "" {
+			record.AddAttrs(slog.String("username", username))
+		}
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
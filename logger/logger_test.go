@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoggerAddsRequestFields(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(&buf, "info")
+	require.NoError(t, err)
+
+	ctx, request := WithRequest(context.Background(), "request-id")
+	log.InfoContext(ctx, "before authentication")
+
+	request.SetUsername("user")
+	log.With(slog.String("component", "test")).InfoContext(ctx, "after authentication")
+
+	log.InfoContext(context.Background(), "outside of a request")
+	log.DebugContext(ctx, "below the level")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var records []map[string]any
+	for _, line := range lines {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+
+	require.Equal(t, "request-id", records[0]["request_id"])
+	require.NotContains(t, records[0], "username")
+
+	require.Equal(t, "request-id", records[1]["request_id"])
+	require.Equal(t, "user", records[1]["username"])
+	require.Equal(t, "test", records[1]["component"])
+
+	require.NotContains(t, records[2], "request_id")
+}
+
+func TestNewInvalidLevel(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "verbose")
+	require.Error(t, err)
+}
+
+func TestAcceptRequestID(t *testing.T) {
+	require.Equal(t, "abc-123", AcceptRequestID("abc-123"))
+
+	for _, requestID := range []string{"", strings.Repeat("a", maxRequestIDLength+1), "bad\nid", "żółw"} {
+		accepted := AcceptRequestID(requestID)
+		require.NotEqual(t, requestID, accepted)
+		require.Len(t, accepted, 36)
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	var gotRequestID, gotHeader string
+	handler := HTTPHandler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		gotRequestID = RequestID(req.Context())
+		gotHeader = req.Header.Get(RequestIDHeader)
+		res.WriteHeader(http.StatusTeapot)
+	}))
+
+	// accepted from the client
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "request-id")
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusTeapot, recorder.Code)
+	require.Equal(t, "request-id", gotRequestID)
+	require.Equal(t, "request-id", gotHeader)
+	require.Equal(t, "request-id", recorder.Header().Get(RequestIDHeader))
+
+	// generated and passed on to the handler
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.NotEmpty(t, gotRequestID)
+	require.Equal(t, gotRequestID, gotHeader)
+	require.Equal(t, gotRequestID, recorder.Header().Get(RequestIDHeader))
+}
+
+func TestHTTPStatusLevel(t *testing.T) {
+	require.Equal(t, slog.LevelInfo, HTTPStatusLevel(http.StatusOK))
+	require.Equal(t, slog.LevelWarn, HTTPStatusLevel(http.StatusNotFound))
+	require.Equal(t, slog.LevelError, HTTPStatusLevel(http.StatusInternalServerError))
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"sync"
+	"unicode"
+)
+
+// RequestIDHeader is the header the request ID is accepted from and returned in
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the longest request ID accepted from clients
+const maxRequestIDLength = 128
+
+// Request holds the fields of a request that are added to its log records
+type Request struct {
+	ID string
+
+	mu       sync.Mutex
+	username string
+}
+
+// Username returns the user of the request, empty until it is authenticated
+func (request *Request) Username() string {
+	request.mu.Lock()
+	defer request.mu.Unlock()
+
+	return request.username
+}
+
+// SetUsername sets the user of the request once it is authenticated
+func (request *Request) SetUsername(username string) {
+	request.mu.Lock()
+	defer request.mu.Unlock()
+
+	request.username = username
+}
+
+type requestKey struct{}
+
+// WithRequest returns a copy of the context that carries a request with the given ID
+func WithRequest(ctx context.Context, requestID string) (context.Context, *Request) {
+	request := &Request{ID: requestID}
+	return context.WithValue(ctx, requestKey{}, request), request
+}
+
+// RequestFromContext returns the request the context belongs to
+func RequestFromContext(ctx context.Context) (*Request, bool) {
+	request, ok := ctx.Value(requestKey{}).(*Request)
+	return request, ok
+}
+
+// RequestID returns the ID of the request the context belongs to, empty outside of requests
+func RequestID(ctx context.Context) string {
+	if request, ok := RequestFromContext(ctx); ok {
+		return request.ID
+	}
+	return ""
+}
+
+// SetUsername sets the user of the request the context belongs to, if there is one
+func SetUsername(ctx context.Context, username string) {
+	if request, ok := RequestFromContext(ctx); ok {
+		request.SetUsername(username)
+	}
+}
+
+// AcceptRequestID returns the request ID sent by the client, or a new one
+// if the client did not send one or it is too long or not printable
+func AcceptRequestID(requestID string) string {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return uuid.NewString()
+	}
+
+	for _, r := range requestID {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return uuid.NewString()
+		}
+	}
+
+	return requestID
+}
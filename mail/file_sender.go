@@ -0,0 +1,52 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileSender writes emails to a directory instead of sending them, one .eml file per email.
+// It is meant for development, where the links in the emails can be opened from the files
+type FileSender struct {
+	dir  string
+	from string
+}
+
+// NewFileSender creates a new FileSender, the directory is created if it does not exist
+func NewFileSender(dir string, from string) (Sender, error) {
+	if dir == "" {
+		return nil, errors.New("mail directory is required")
+	}
+
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create mail directory: %w", err)
+	}
+
+	return &FileSender{dir: dir, from: from}, nil
+}
+
+// Send writes the message to a new file
+func (sender *FileSender) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+
+	data, err := formatMessage(sender.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	recipient := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, msg.To)
+
+	name := fmt.Sprintf("%d-%s.eml", now.UnixNano(), recipient)
+	return os.WriteFile(filepath.Join(sender.dir, name), data, 0o600)
+}
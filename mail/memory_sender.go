@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"context"
+	"sync"
+)
+
+// MemorySender keeps the emails in memory instead of sending them, so tests can read them
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemorySender creates a new MemorySender
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+// Send stores the message
+func (sender *MemorySender) Send(ctx context.Context, msg Message) error {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	sender.messages = append(sender.messages, msg)
+	return nil
+}
+
+// Messages returns all the messages sent so far
+func (sender *MemorySender) Messages() []Message {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	return append([]Message(nil), sender.messages...)
+}
+
+// LastMessage returns the last message sent to the address
+func (sender *MemorySender) LastMessage(to string) (Message, bool) {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	for i := len(sender.messages) - 1; i >= 0; i-- {
+		if sender.messages[i].To == to {
+			return sender.messages[i], true
+		}
+	}
+
+	return Message{}, false
+}
@@ -0,0 +1,41 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"github.com/aalug/bank-go/utils"
+)
+
+// Senders that emails can be sent with, set with MAIL_SENDER
+const (
+	SenderSMTP   = "smtp"
+	SenderFile   = "file"
+	SenderMemory = "memory"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender - interface for sending emails
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender creates the sender selected in the config. SMTP sends from MAIL_FROM through SMTP_HOST,
+// the file sender, the default, writes the emails to MAIL_DIR instead of sending them
+func NewSender(config utils.Config) (Sender, error) {
+	switch config.MailSender {
+	case SenderSMTP:
+		return NewSMTPSender(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.MailFrom)
+	case "", SenderFile:
+		return NewFileSender(config.MailDir, config.MailFrom)
+	case SenderMemory:
+		return NewMemorySender(), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail sender %q", config.MailSender)
+	}
+}
@@ -0,0 +1,118 @@
+package mail
+
+import (
+	"context"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatMessage(t *testing.T) {
+	msg := Message{
+		To:      utils.RandomEmail(),
+		Subject: "Verify your email address",
+		Body:    "first line\nsecond line",
+	}
+	date := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	data, err := formatMessage("Go Bank <no-reply@example.com>", msg, date)
+	require.NoError(t, err)
+
+	email := string(data)
+	require.Contains(t, email, "From: Go Bank <no-reply@example.com>\r\n")
+	require.Contains(t, email, "To: "+msg.To+"\r\n")
+	require.Contains(t, email, "Subject: Verify your email address\r\n")
+	require.Contains(t, email, "Date: Sat, 01 Jul 2023 12:00:00 +0000\r\n")
+	require.True(t, strings.HasSuffix(email, "\r\n\r\nfirst line\r\nsecond line"))
+}
+
+func TestFormatMessageHeaderInjection(t *testing.T) {
+	msg := Message{
+		To:      utils.RandomEmail() + "\r\nBcc: victim@example.com",
+		Subject: "subject",
+	}
+
+	_, err := formatMessage("no-reply@example.com", msg, time.Now())
+	require.Error(t, err)
+
+	msg.To = utils.RandomEmail()
+	msg.Subject = "subject\nBcc: victim@example.com"
+	_, err = formatMessage("no-reply@example.com", msg, time.Now())
+	require.Error(t, err)
+}
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+
+	sender, err := NewFileSender(dir, "no-reply@example.com")
+	require.NoError(t, err)
+
+	msg := Message{
+		To:      utils.RandomEmail(),
+		Subject: "Reset your password",
+		Body:    utils.RandomString(32),
+	}
+
+	err = sender.Send(context.Background(), msg)
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.True(t, strings.HasSuffix(files[0].Name(), "-"+msg.To+".eml"))
+
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	require.Contains(t, string(data), msg.Body)
+}
+
+func TestMemorySender(t *testing.T) {
+	sender := NewMemorySender()
+
+	to := utils.RandomEmail()
+	_, ok := sender.LastMessage(to)
+	require.False(t, ok)
+
+	for i := 0; i < 3; i++ {
+		err := sender.Send(context.Background(), Message{To: to, Body: utils.RandomString(8)})
+		require.NoError(t, err)
+	}
+	err := sender.Send(context.Background(), Message{To: utils.RandomEmail()})
+	require.NoError(t, err)
+
+	messages := sender.Messages()
+	require.Len(t, messages, 4)
+
+	last, ok := sender.LastMessage(to)
+	require.True(t, ok)
+	require.Equal(t, messages[2], last)
+}
+
+func TestNewSender(t *testing.T) {
+	sender, err := NewSender(utils.Config{MailSender: SenderMemory})
+	require.NoError(t, err)
+	require.IsType(t, &MemorySender{}, sender)
+
+	sender, err = NewSender(utils.Config{MailDir: t.TempDir()})
+	require.NoError(t, err)
+	require.IsType(t, &FileSender{}, sender)
+
+	sender, err = NewSender(utils.Config{
+		MailSender: SenderSMTP,
+		MailFrom:   "Go Bank <no-reply@example.com>",
+		SMTPHost:   "localhost",
+		SMTPPort:   25,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "no-reply@example.com", sender.(*SMTPSender).envelopeFrom)
+
+	_, err = NewSender(utils.Config{MailSender: SenderSMTP, MailFrom: "no-reply@example.com"})
+	require.Error(t, err)
+
+	_, err = NewSender(utils.Config{MailSender: "carrier pigeon"})
+	require.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPSender sends emails through an SMTP server
+type SMTPSender struct {
+	addr string
+	from string
+	// envelopeFrom is the bare address of from, e.g. without the display name
+	envelopeFrom string
+	auth         smtp.Auth
+}
+
+// NewSMTPSender creates a new SMTPSender. The connection is upgraded with STARTTLS
+// when the server supports it, and the credentials are only sent over TLS or to localhost
+func NewSMTPSender(host string, port int, username, password, from string) (Sender, error) {
+	if host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+
+	address, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	sender := &SMTPSender{
+		addr:         net.JoinHostPort(host, strconv.Itoa(port)),
+		from:         from,
+		envelopeFrom: address.Address,
+	}
+
+	if username != "" {
+		sender.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return sender, nil
+}
+
+// Send sends the message
+func (sender *SMTPSender) Send(ctx context.Context, msg Message) error {
+	data, err := formatMessage(sender.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	err = smtp.SendMail(sender.addr, sender.auth, sender.envelopeFrom, []string{msg.To}, data)
+	if err != nil {
+		return fmt.Errorf("cannot send email: %w", err)
+	}
+
+	return nil
+}
+
+// formatMessage builds the headers and the body of an email
+func formatMessage(from string, msg Message, date time.Time) ([]byte, error) {
+	// a line break in a header would let the rest of the value add headers of its own
+	for _, value := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, errors.New("email headers must not contain line breaks")
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return buf.Bytes(), nil
+}
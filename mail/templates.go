@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewVerifyEmailMessage creates the email with the link that verifies the email address of a user
+func NewVerifyEmailMessage(to string, fullName string, link string, expiresIn time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(`Hello %s,
+
+please verify your email address by opening this link:
+
+%s
+
+The link expires in %s. Until the address is verified you cannot transfer money.
+
+Go Bank
+`, fullName, link, expiresIn),
+	}
+}
+
+// NewPasswordResetMessage creates the email with the token that lets a user set a new password
+func NewPasswordResetMessage(to string, fullName string, token string, expiresIn time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(`Hello %s,
+
+a password reset was requested for your account. Use this token to set a new password:
+
+%s
+
+The token expires in %s. If you did not request the reset, you can ignore this email.
+
+Go Bank
+`, fullName, token, expiresIn),
+	}
+}
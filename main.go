@@ -7,17 +7,27 @@ import (
 	db "github.com/aalug/bank-go/db/sqlc"
 	_ "github.com/aalug/bank-go/docs/statik"
 	"github.com/aalug/bank-go/gapi"
+	"github.com/aalug/bank-go/logger"
+	"github.com/aalug/bank-go/mail"
+	"github.com/aalug/bank-go/metrics"
 	"github.com/aalug/bank-go/pb"
+	"github.com/aalug/bank-go/token"
 	"github.com/aalug/bank-go/utils"
+	"github.com/aalug/bank-go/worker"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	_ "github.com/lib/pq"
 	"github.com/rakyll/statik/fs"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/protobuf/encoding/protojson"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	"os"
+	"strings"
 )
 
 func main() {
@@ -26,42 +36,103 @@ func main() {
 		log.Fatal("cannot load env file: ", err)
 	}
 
+	appLogger, err := logger.New(os.Stdout, config.LogLevel)
+	if err != nil {
+		log.Fatal("cannot create logger: ", err)
+	}
+	// the log package writes through the default logger too
+	slog.SetDefault(appLogger)
+
 	conn, err := sql.Open(config.DBDriver, config.DBSource)
 	if err != nil {
 		log.Fatal("cannot connect to the db: ", err)
 	}
 
+	// expose the stats of the connection pool
+	err = metrics.RegisterDB(conn)
+	if err != nil {
+		log.Fatal("cannot register db metrics: ", err)
+	}
+
 	store := db.NewStore(conn)
 
+	mailer, err := mail.NewSender(config)
+	if err != nil {
+		log.Fatal("cannot create mail sender: ", err)
+	}
+
 	//serverType := os.Getenv("SERVER_TYPE")
 	//if serverType == "gin" {
-	//	runGinServer(config, store)
+	//	runGinServer(config, store, mailer)
 	//} else {
-	//	runGrpcServer(config, store)
+	//	runGrpcServer(config, store, mailer)
 	//}
-	go runGatewayServer(config, store)
-	runGrpcServer(config, store)
+	if config.SchedulerInterval > 0 {
+		go runScheduledTransferWorker(config, store)
+		go runHoldExpiryWorker(config, store)
+		go runIdempotencyKeyCleanupWorker(config, store)
+	}
+
+	go runGatewayServer(config)
+	runGrpcServer(config, store, mailer)
+}
+
+func runScheduledTransferWorker(config utils.Config, store db.Store) {
+	scheduledTransferWorker := worker.NewScheduledTransferWorker(store, config.SchedulerInterval)
+
+	log.Printf("scheduled transfer worker running every %s", config.SchedulerInterval)
+	scheduledTransferWorker.Start(context.Background())
+}
+
+func runHoldExpiryWorker(config utils.Config, store db.Store) {
+	holdExpiryWorker := worker.NewHoldExpiryWorker(store, config.SchedulerInterval)
+
+	log.Printf("hold expiry worker running every %s", config.SchedulerInterval)
+	holdExpiryWorker.Start(context.Background())
 }
 
-func runGinServer(config utils.Config, store db.Store) {
-	server, err := api.NewServer(config, store)
+func runIdempotencyKeyCleanupWorker(config utils.Config, store db.Store) {
+	idempotencyKeyCleanupWorker := worker.NewIdempotencyKeyCleanupWorker(store, config.SchedulerInterval)
+
+	log.Printf("idempotency key cleanup worker running every %s", config.SchedulerInterval)
+	idempotencyKeyCleanupWorker.Start(context.Background())
+}
+
+func runGinServer(config utils.Config, store db.Store, mailer mail.Sender) {
+	server, err := api.NewServer(config, store, mailer)
 	if err != nil {
 		log.Fatal("cannot create server: ", err)
 	}
 
+	go server.RefreshRevokedTokens(context.Background())
+
 	err = server.Start(config.HTTPServerAddress)
 	if err != nil {
 		log.Fatal("cannot start the server:", err)
 	}
 }
 
-func runGrpcServer(config utils.Config, store db.Store) {
-	server, err := gapi.NewServer(config, store)
+func runGrpcServer(config utils.Config, store db.Store, mailer mail.Sender) {
+	server, err := gapi.NewServer(config, store, mailer)
 	if err != nil {
 		log.Fatal("cannot create server: ", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	go server.RefreshRevokedTokens(context.Background())
+
+	grpcServer := grpc.NewServer(
+		// the logger and the metrics run first, so calls rejected by the auth interceptor are recorded too
+		grpc.ChainUnaryInterceptor(
+			server.UnaryLoggerInterceptor,
+			server.UnaryMetricsInterceptor,
+			server.UnaryAuthInterceptor,
+		),
+		grpc.ChainStreamInterceptor(
+			server.StreamLoggerInterceptor,
+			server.StreamMetricsInterceptor,
+			server.StreamAuthInterceptor,
+		),
+	)
 	pb.RegisterGoBankServer(grpcServer, server)
 
 	reflection.Register(grpcServer)
@@ -78,12 +149,7 @@ func runGrpcServer(config utils.Config, store db.Store) {
 	}
 }
 
-func runGatewayServer(config utils.Config, store db.Store) {
-	server, err := gapi.NewServer(config, store)
-	if err != nil {
-		log.Fatal("cannot create server: ", err)
-	}
-
+func runGatewayServer(config utils.Config) {
 	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
 		MarshalOptions: protojson.MarshalOptions{
 			UseProtoNames: true,
@@ -93,12 +159,31 @@ func runGatewayServer(config utils.Config, store db.Store) {
 		},
 	})
 
-	grpcMux := runtime.NewServeMux(jsonOption)
+	headerMatcher := runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+		// pass the Idempotency-Key and X-Request-ID headers to the gRPC handlers as is
+		if strings.EqualFold(key, "Idempotency-Key") || strings.EqualFold(key, "X-Request-ID") {
+			return strings.ToLower(key), true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	})
+
+	// label the HTTP metrics of gateway requests with the path template of their RPC
+	routeAnnotator := runtime.WithMetadata(func(ctx context.Context, req *http.Request) metadata.MD {
+		if pattern, ok := runtime.HTTPPathPattern(ctx); ok {
+			metrics.SetRoute(ctx, pattern)
+		}
+		return nil
+	})
+
+	grpcMux := runtime.NewServeMux(jsonOption, headerMatcher, routeAnnotator)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	err = pb.RegisterGoBankHandlerServer(ctx, grpcMux, server)
+	// the gateway calls the gRPC server instead of the handlers directly,
+	// so HTTP requests go through the same interceptors as gRPC requests
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	err := pb.RegisterGoBankHandlerFromEndpoint(ctx, grpcMux, config.GRPCServerAddress, dialOptions)
 	if err != nil {
 		log.Fatal("cannot register handler server: ", err)
 	}
@@ -106,6 +191,13 @@ func runGatewayServer(config utils.Config, store db.Store) {
 	mux := http.NewServeMux()
 	mux.Handle("/", grpcMux)
 
+	// public keys that other services can verify access tokens with
+	tokenMaker, err := token.NewMaker(config)
+	if err != nil {
+		log.Fatal("cannot create token maker: ", err)
+	}
+	mux.Handle("/.well-known/jwks.json", token.JWKSHandler(tokenMaker))
+
 	// swagger docs server
 	statikFileSystem, err := fs.New()
 	if err != nil {
@@ -115,13 +207,16 @@ func runGatewayServer(config utils.Config, store db.Store) {
 	docsHandler := http.StripPrefix("/docs/", http.FileServer(statikFileSystem))
 	mux.Handle("/docs/", docsHandler)
 
+	// Prometheus metrics
+	mux.Handle("/metrics", metrics.Handler())
+
 	listener, err := net.Listen("tcp", config.HTTPServerAddress)
 	if err != nil {
 		log.Fatal("cannot create a listener:", err)
 	}
 
 	log.Printf("HTTP gateway server starting at %s", listener.Addr().String())
-	err = http.Serve(listener, mux)
+	err = http.Serve(listener, logger.HTTPHandler(metrics.HTTPHandler(mux)))
 	if err != nil {
 		log.Fatal("cannot start the HTTP gateway server:", err)
 	}
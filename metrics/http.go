@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type routeKey struct{}
+
+// route holds the route a request matched. The handler that matches it
+// runs deeper in the chain than HTTPHandler, so it is set through a pointer.
+type route struct {
+	mu      sync.Mutex
+	pattern string
+}
+
+// SetRoute sets the route label of the HTTP request the context belongs to.
+// It is a no-op for contexts that do not come from HTTPHandler.
+func SetRoute(ctx context.Context, pattern string) {
+	r, ok := ctx.Value(routeKey{}).(*route)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pattern = pattern
+}
+
+// statusRecorder remembers the status code written by the wrapped handler
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (rec *statusRecorder) WriteHeader(statusCode int) {
+	rec.statusCode = statusCode
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+// HTTPHandler records the RED metrics of every request served by the mux.
+// The route is the mux pattern the request matched, unless a handler
+// below narrows it down with SetRoute.
+func HTTPHandler(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		startTime := time.Now()
+
+		_, pattern := mux.Handler(req)
+		r := &route{pattern: pattern}
+		req = req.WithContext(context.WithValue(req.Context(), routeKey{}, r))
+
+		rec := &statusRecorder{ResponseWriter: res, statusCode: http.StatusOK}
+		mux.ServeHTTP(rec, req)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		ObserveHTTP(req.Method, r.pattern, rec.statusCode, time.Since(startTime))
+	})
+}
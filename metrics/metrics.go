@@ -0,0 +1,151 @@
+// Package metrics defines the Prometheus metrics of the bank
+// and the helpers that record them.
+package metrics
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// namespace prefixes the names of all the metrics of the bank
+const namespace = "bank"
+
+// reasons of failed logins
+const (
+	LoginFailureInvalidCredentials = "invalid_credentials"
+	LoginFailureInvalidCode        = "invalid_code"
+	LoginFailureLocked             = "locked"
+)
+
+var (
+	grpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "Number of gRPC calls handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	grpcRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Duration of gRPC calls, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests handled, by HTTP method, route and status code.",
+	}, []string{"method", "route", "code"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests, by HTTP method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	transfersCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_created_total",
+		Help:      "Number of transfers created, by kind.",
+	}, []string{"kind"})
+
+	transferVolume = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfer_volume_total",
+		Help:      "Amount of money moved by transfers in the smallest unit of the currency, by kind and currency of the source account.",
+	}, []string{"kind", "currency"})
+
+	transfersFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_failed_total",
+		Help:      "Number of transfers rejected or rolled back, by kind and reason.",
+	}, []string{"kind", "reason"})
+
+	logins = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Number of successful logins.",
+	})
+
+	failedLogins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "failed_logins_total",
+		Help:      "Number of failed logins, by reason.",
+	}, []string{"reason"})
+
+	txDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_tx_duration_seconds",
+		Help:      "Duration of database transactions including their retries, by result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	txRetries = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_tx_retries",
+		Help:      "Number of times a database transaction was retried after a deadlock or a serialization failure.",
+		Buckets:   prometheus.LinearBuckets(0, 1, 5),
+	})
+)
+
+// Handler returns the handler that serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterDB exposes the connection pool stats of the database as the go_sql_* metrics
+func RegisterDB(db *sql.DB) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// ObserveGRPC records a finished gRPC call
+func ObserveGRPC(method string, code string, duration time.Duration) {
+	grpcRequests.WithLabelValues(method, code).Inc()
+	grpcRequestDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+// ObserveHTTP records a finished HTTP request. The route is the pattern the request matched,
+// not its path, so that the number of series does not grow with every ID in a URL.
+func ObserveHTTP(method string, route string, code int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// TransferCreated records a committed transfer of the given kind
+func TransferCreated(kind string, currency string, amount int64) {
+	transfersCreated.WithLabelValues(kind).Inc()
+	transferVolume.WithLabelValues(kind, currency).Add(float64(amount))
+}
+
+// TransferFailed records a transfer that was not committed
+func TransferFailed(kind string, reason string) {
+	transfersFailed.WithLabelValues(kind, reason).Inc()
+}
+
+// LoginSucceeded records a login that issued tokens
+func LoginSucceeded() {
+	logins.Inc()
+}
+
+// LoginFailed records a refused login, reason is one of the LoginFailure constants
+func LoginFailed(reason string) {
+	failedLogins.WithLabelValues(reason).Inc()
+}
+
+// ObserveTx records a finished database transaction and how often it was retried
+func ObserveTx(duration time.Duration, retries int, err error) {
+	result := "committed"
+	if err != nil {
+		result = "failed"
+	}
+
+	txDuration.WithLabelValues(result).Observe(duration.Seconds())
+	txRetries.Observe(float64(retries))
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/docs/", func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/", func(res http.ResponseWriter, req *http.Request) {
+		SetRoute(req.Context(), "/v1/accounts/{id}")
+	})
+
+	testCases := []struct {
+		name  string
+		path  string
+		route string
+		code  string
+	}{
+		{
+			name:  "MuxPattern",
+			path:  "/docs/index.html",
+			route: "/docs/",
+			code:  "404",
+		},
+		{
+			name:  "RouteSetByHandler",
+			path:  "/v1/accounts/12",
+			route: "/v1/accounts/{id}",
+			code:  "200",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			counter := httpRequests.WithLabelValues(http.MethodGet, tc.route, tc.code)
+			before := testutil.ToFloat64(counter)
+
+			request := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			HTTPHandler(mux).ServeHTTP(httptest.NewRecorder(), request)
+
+			require.Equal(t, before+1, testutil.ToFloat64(counter))
+		})
+	}
+}
+
+func TestTransferCreated(t *testing.T) {
+	created := transfersCreated.WithLabelValues("transfer")
+	volume := transferVolume.WithLabelValues("transfer", "EUR")
+	createdBefore := testutil.ToFloat64(created)
+	volumeBefore := testutil.ToFloat64(volume)
+
+	TransferCreated("transfer", "EUR", 150)
+	TransferCreated("transfer", "EUR", 50)
+
+	require.Equal(t, createdBefore+2, testutil.ToFloat64(created))
+	require.Equal(t, volumeBefore+200, testutil.ToFloat64(volume))
+}
+
+func TestObserveTx(t *testing.T) {
+	ObserveTx(time.Millisecond, 0, nil)
+	ObserveTx(time.Millisecond, 4, errors.New("serialization failure"))
+
+	expected := `
+		# HELP bank_db_tx_retries Number of times a database transaction was retried after a deadlock or a serialization failure.
+		# TYPE bank_db_tx_retries histogram
+		bank_db_tx_retries_bucket{le="0"} 1
+		bank_db_tx_retries_bucket{le="1"} 1
+		bank_db_tx_retries_bucket{le="2"} 1
+		bank_db_tx_retries_bucket{le="3"} 1
+		bank_db_tx_retries_bucket{le="4"} 2
+		bank_db_tx_retries_bucket{le="+Inf"} 2
+		bank_db_tx_retries_sum 4
+		bank_db_tx_retries_count 2
+	`
+	require.NoError(t, testutil.CollectAndCompare(txRetries, strings.NewReader(expected)))
+
+	require.Equal(t, 2, testutil.CollectAndCount(txDuration))
+}
@@ -0,0 +1,239 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v4.23.3
+// source: rpc_admin_create_api_key.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AdminCreateAPIKeyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Username string               `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	ApiKey   *CreateAPIKeyRequest `protobuf:"bytes,2,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+}
+
+func (x *AdminCreateAPIKeyRequest) Reset() {
+	*x = AdminCreateAPIKeyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_admin_create_api_key_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AdminCreateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminCreateAPIKeyRequest) ProtoMessage() {}
+
+func (x *AdminCreateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_admin_create_api_key_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminCreateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*AdminCreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_admin_create_api_key_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AdminCreateAPIKeyRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *AdminCreateAPIKeyRequest) GetApiKey() *CreateAPIKeyRequest {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+type AdminCreateAPIKeyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the key is returned only once, only its hash is stored
+	Key    string  `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	ApiKey *APIKey `protobuf:"bytes,2,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+}
+
+func (x *AdminCreateAPIKeyResponse) Reset() {
+	*x = AdminCreateAPIKeyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_admin_create_api_key_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AdminCreateAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminCreateAPIKeyResponse) ProtoMessage() {}
+
+func (x *AdminCreateAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_admin_create_api_key_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminCreateAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*AdminCreateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_admin_create_api_key_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AdminCreateAPIKeyResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *AdminCreateAPIKeyResponse) GetApiKey() *APIKey {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+var File_rpc_admin_create_api_key_proto protoreflect.FileDescriptor
+
+var file_rpc_admin_create_api_key_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x72, 0x70, 0x63, 0x5f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x5f, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x5f, 0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x02, 0x70, 0x62, 0x1a, 0x0d, 0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65, 0x79, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x1a, 0x18, 0x72, 0x70, 0x63, 0x5f, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x5f,
+	0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x68, 0x0a,
+	0x18, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x50, 0x49, 0x4b,
+	0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65,
+	0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65,
+	0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x30, 0x0a, 0x07, 0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x41, 0x50, 0x49, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52,
+	0x06, 0x61, 0x70, 0x69, 0x4b, 0x65, 0x79, 0x22, 0x52, 0x0a, 0x19, 0x41, 0x64, 0x6d, 0x69, 0x6e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x50, 0x49, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x23, 0x0a, 0x07, 0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65,
+	0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x50, 0x49,
+	0x4b, 0x65, 0x79, 0x52, 0x06, 0x61, 0x70, 0x69, 0x4b, 0x65, 0x79, 0x42, 0x1d, 0x5a, 0x1b, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x61, 0x6c, 0x75, 0x67, 0x2f,
+	0x67, 0x6f, 0x2d, 0x62, 0x61, 0x6e, 0x6b, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_rpc_admin_create_api_key_proto_rawDescOnce sync.Once
+	file_rpc_admin_create_api_key_proto_rawDescData = file_rpc_admin_create_api_key_proto_rawDesc
+)
+
+func file_rpc_admin_create_api_key_proto_rawDescGZIP() []byte {
+	file_rpc_admin_create_api_key_proto_rawDescOnce.Do(func() {
+		file_rpc_admin_create_api_key_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_admin_create_api_key_proto_rawDescData)
+	})
+	return file_rpc_admin_create_api_key_proto_rawDescData
+}
+
+var file_rpc_admin_create_api_key_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_admin_create_api_key_proto_goTypes = []interface{}{
+	(*AdminCreateAPIKeyRequest)(nil),  // 0: pb.AdminCreateAPIKeyRequest
+	(*AdminCreateAPIKeyResponse)(nil), // 1: pb.AdminCreateAPIKeyResponse
+	(*CreateAPIKeyRequest)(nil),       // 2: pb.CreateAPIKeyRequest
+	(*APIKey)(nil),                    // 3: pb.APIKey
+}
+var file_rpc_admin_create_api_key_proto_depIdxs = []int32{
+	2, // 0: pb.AdminCreateAPIKeyRequest.api_key:type_name -> pb.CreateAPIKeyRequest
+	3, // 1: pb.AdminCreateAPIKeyResponse.api_key:type_name -> pb.APIKey
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_rpc_admin_create_api_key_proto_init() }
+func file_rpc_admin_create_api_key_proto_init() {
+	if File_rpc_admin_create_api_key_proto != nil {
+		return
+	}
+	file_api_key_proto_init()
+	file_rpc_create_api_key_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_admin_create_api_key_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AdminCreateAPIKeyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_admin_create_api_key_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AdminCreateAPIKeyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_admin_create_api_key_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_admin_create_api_key_proto_goTypes,
+		DependencyIndexes: file_rpc_admin_create_api_key_proto_depIdxs,
+		MessageInfos:      file_rpc_admin_create_api_key_proto_msgTypes,
+	}.Build()
+	File_rpc_admin_create_api_key_proto = out.File
+	file_rpc_admin_create_api_key_proto_rawDesc = nil
+	file_rpc_admin_create_api_key_proto_goTypes = nil
+	file_rpc_admin_create_api_key_proto_depIdxs = nil
+}
@@ -0,0 +1,212 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v4.23.3
+// source: rpc_admin_freeze_account.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AdminFreezeAccountRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *AdminFreezeAccountRequest) Reset() {
+	*x = AdminFreezeAccountRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_admin_freeze_account_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AdminFreezeAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminFreezeAccountRequest) ProtoMessage() {}
+
+func (x *AdminFreezeAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_admin_freeze_account_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminFreezeAccountRequest.ProtoReflect.Descriptor instead.
+func (*AdminFreezeAccountRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_admin_freeze_account_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AdminFreezeAccountRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type AdminFreezeAccountResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Account *Account `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+}
+
+func (x *AdminFreezeAccountResponse) Reset() {
+	*x = AdminFreezeAccountResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_admin_freeze_account_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AdminFreezeAccountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminFreezeAccountResponse) ProtoMessage() {}
+
+func (x *AdminFreezeAccountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_admin_freeze_account_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminFreezeAccountResponse.ProtoReflect.Descriptor instead.
+func (*AdminFreezeAccountResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_admin_freeze_account_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AdminFreezeAccountResponse) GetAccount() *Account {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+var File_rpc_admin_freeze_account_proto protoreflect.FileDescriptor
+
+var file_rpc_admin_freeze_account_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x72, 0x70, 0x63, 0x5f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x5f, 0x66, 0x72, 0x65, 0x65,
+	0x7a, 0x65, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x02, 0x70, 0x62, 0x1a, 0x0d, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0x2b, 0x0a, 0x19, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x46, 0x72, 0x65, 0x65,
+	0x7a, 0x65, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64,
+	0x22, 0x43, 0x0a, 0x1a, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x46, 0x72, 0x65, 0x65, 0x7a, 0x65, 0x41,
+	0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x25,
+	0x0a, 0x07, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x07, 0x61, 0x63,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x42, 0x1d, 0x5a, 0x1b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x61, 0x6c, 0x75, 0x67, 0x2f, 0x67, 0x6f, 0x2d, 0x62, 0x61, 0x6e,
+	0x6b, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_admin_freeze_account_proto_rawDescOnce sync.Once
+	file_rpc_admin_freeze_account_proto_rawDescData = file_rpc_admin_freeze_account_proto_rawDesc
+)
+
+func file_rpc_admin_freeze_account_proto_rawDescGZIP() []byte {
+	file_rpc_admin_freeze_account_proto_rawDescOnce.Do(func() {
+		file_rpc_admin_freeze_account_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_admin_freeze_account_proto_rawDescData)
+	})
+	return file_rpc_admin_freeze_account_proto_rawDescData
+}
+
+var file_rpc_admin_freeze_account_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_admin_freeze_account_proto_goTypes = []interface{}{
+	(*AdminFreezeAccountRequest)(nil),  // 0: pb.AdminFreezeAccountRequest
+	(*AdminFreezeAccountResponse)(nil), // 1: pb.AdminFreezeAccountResponse
+	(*Account)(nil),                    // 2: pb.Account
+}
+var file_rpc_admin_freeze_account_proto_depIdxs = []int32{
+	2, // 0: pb.AdminFreezeAccountResponse.account:type_name -> pb.Account
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_rpc_admin_freeze_account_proto_init() }
+func file_rpc_admin_freeze_account_proto_init() {
+	if File_rpc_admin_freeze_account_proto != nil {
+		return
+	}
+	file_account_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_admin_freeze_account_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AdminFreezeAccountRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_admin_freeze_account_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AdminFreezeAccountResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_admin_freeze_account_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_admin_freeze_account_proto_goTypes,
+		DependencyIndexes: file_rpc_admin_freeze_account_proto_depIdxs,
+		MessageInfos:      file_rpc_admin_freeze_account_proto_msgTypes,
+	}.Build()
+	File_rpc_admin_freeze_account_proto = out.File
+	file_rpc_admin_freeze_account_proto_rawDesc = nil
+	file_rpc_admin_freeze_account_proto_goTypes = nil
+	file_rpc_admin_freeze_account_proto_depIdxs = nil
+}
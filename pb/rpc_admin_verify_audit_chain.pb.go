@@ -0,0 +1,238 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v4.23.3
+// source: rpc_admin_verify_audit_chain.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AdminVerifyAuditChainRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *AdminVerifyAuditChainRequest) Reset() {
+	*x = AdminVerifyAuditChainRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_admin_verify_audit_chain_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AdminVerifyAuditChainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminVerifyAuditChainRequest) ProtoMessage() {}
+
+func (x *AdminVerifyAuditChainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_admin_verify_audit_chain_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminVerifyAuditChainRequest.ProtoReflect.Descriptor instead.
+func (*AdminVerifyAuditChainRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_admin_verify_audit_chain_proto_rawDescGZIP(), []int{0}
+}
+
+type AdminVerifyAuditChainResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CheckedAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	Checked   int64                  `protobuf:"varint,2,opt,name=checked,proto3" json:"checked,omitempty"`
+	Valid     bool                   `protobuf:"varint,3,opt,name=valid,proto3" json:"valid,omitempty"`
+	// the first event that was changed, or whose predecessor was removed
+	BrokenAtId *int64 `protobuf:"varint,4,opt,name=broken_at_id,json=brokenAtId,proto3,oneof" json:"broken_at_id,omitempty"`
+}
+
+func (x *AdminVerifyAuditChainResponse) Reset() {
+	*x = AdminVerifyAuditChainResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_admin_verify_audit_chain_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AdminVerifyAuditChainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminVerifyAuditChainResponse) ProtoMessage() {}
+
+func (x *AdminVerifyAuditChainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_admin_verify_audit_chain_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminVerifyAuditChainResponse.ProtoReflect.Descriptor instead.
+func (*AdminVerifyAuditChainResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_admin_verify_audit_chain_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AdminVerifyAuditChainResponse) GetCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return nil
+}
+
+func (x *AdminVerifyAuditChainResponse) GetChecked() int64 {
+	if x != nil {
+		return x.Checked
+	}
+	return 0
+}
+
+func (x *AdminVerifyAuditChainResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *AdminVerifyAuditChainResponse) GetBrokenAtId() int64 {
+	if x != nil && x.BrokenAtId != nil {
+		return *x.BrokenAtId
+	}
+	return 0
+}
+
+var File_rpc_admin_verify_audit_chain_proto protoreflect.FileDescriptor
+
+var file_rpc_admin_verify_audit_chain_proto_rawDesc = []byte{
+	0x0a, 0x22, 0x72, 0x70, 0x63, 0x5f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x5f, 0x76, 0x65, 0x72, 0x69,
+	0x66, 0x79, 0x5f, 0x61, 0x75, 0x64, 0x69, 0x74, 0x5f, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x1e, 0x0a, 0x1c, 0x41, 0x64, 0x6d,
+	0x69, 0x6e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x41, 0x75, 0x64, 0x69, 0x74, 0x43, 0x68, 0x61,
+	0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xc2, 0x01, 0x0a, 0x1d, 0x41, 0x64,
+	0x6d, 0x69, 0x6e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x41, 0x75, 0x64, 0x69, 0x74, 0x43, 0x68,
+	0x61, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x63,
+	0x68, 0x65, 0x63, 0x6b, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x68, 0x65,
+	0x63, 0x6b, 0x65, 0x64, 0x41, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x64,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x12, 0x25, 0x0a, 0x0c, 0x62, 0x72, 0x6f, 0x6b, 0x65, 0x6e,
+	0x5f, 0x61, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0a,
+	0x62, 0x72, 0x6f, 0x6b, 0x65, 0x6e, 0x41, 0x74, 0x49, 0x64, 0x88, 0x01, 0x01, 0x42, 0x0f, 0x0a,
+	0x0d, 0x5f, 0x62, 0x72, 0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x61, 0x74, 0x5f, 0x69, 0x64, 0x42, 0x1d,
+	0x5a, 0x1b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x61, 0x6c,
+	0x75, 0x67, 0x2f, 0x67, 0x6f, 0x2d, 0x62, 0x61, 0x6e, 0x6b, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_admin_verify_audit_chain_proto_rawDescOnce sync.Once
+	file_rpc_admin_verify_audit_chain_proto_rawDescData = file_rpc_admin_verify_audit_chain_proto_rawDesc
+)
+
+func file_rpc_admin_verify_audit_chain_proto_rawDescGZIP() []byte {
+	file_rpc_admin_verify_audit_chain_proto_rawDescOnce.Do(func() {
+		file_rpc_admin_verify_audit_chain_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_admin_verify_audit_chain_proto_rawDescData)
+	})
+	return file_rpc_admin_verify_audit_chain_proto_rawDescData
+}
+
+var file_rpc_admin_verify_audit_chain_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_admin_verify_audit_chain_proto_goTypes = []interface{}{
+	(*AdminVerifyAuditChainRequest)(nil),  // 0: pb.AdminVerifyAuditChainRequest
+	(*AdminVerifyAuditChainResponse)(nil), // 1: pb.AdminVerifyAuditChainResponse
+	(*timestamppb.Timestamp)(nil),         // 2: google.protobuf.Timestamp
+}
+var file_rpc_admin_verify_audit_chain_proto_depIdxs = []int32{
+	2, // 0: pb.AdminVerifyAuditChainResponse.checked_at:type_name -> google.protobuf.Timestamp
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_rpc_admin_verify_audit_chain_proto_init() }
+func file_rpc_admin_verify_audit_chain_proto_init() {
+	if File_rpc_admin_verify_audit_chain_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_admin_verify_audit_chain_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AdminVerifyAuditChainRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_admin_verify_audit_chain_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AdminVerifyAuditChainResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_rpc_admin_verify_audit_chain_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_admin_verify_audit_chain_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_admin_verify_audit_chain_proto_goTypes,
+		DependencyIndexes: file_rpc_admin_verify_audit_chain_proto_depIdxs,
+		MessageInfos:      file_rpc_admin_verify_audit_chain_proto_msgTypes,
+	}.Build()
+	File_rpc_admin_verify_audit_chain_proto = out.File
+	file_rpc_admin_verify_audit_chain_proto_rawDesc = nil
+	file_rpc_admin_verify_audit_chain_proto_goTypes = nil
+	file_rpc_admin_verify_audit_chain_proto_depIdxs = nil
+}
@@ -0,0 +1,98 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+)
+
+// JWK - a public key in the JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS - a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKeyMaker - a Maker whose tokens can be verified with published public keys
+type PublicKeyMaker interface {
+	Maker
+	JWKS() JWKS
+}
+
+// JWKS returns the verification keys of the maker
+func (maker *PasetoPublicMaker) JWKS() JWKS {
+	// JOSE has no algorithm name for PASETO, the key is identified by its type only
+	return maker.keys.jwks("")
+}
+
+// JWKS returns the verification keys of the maker
+func (maker *JWTAsymmetricMaker) JWKS() JWKS {
+	return maker.keys.jwks(maker.method.Alg())
+}
+
+// jwks returns all verification keys of the set
+func (keys *KeySet) jwks(algorithm string) JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, keyID := range keys.KeyIDs() {
+		jwks.Keys = append(jwks.Keys, newJWK(keyID, algorithm, keys.publicKeys[keyID]))
+	}
+
+	return jwks
+}
+
+func newJWK(keyID string, algorithm string, publicKey crypto.PublicKey) JWK {
+	jwk := JWK{
+		KeyID:     keyID,
+		Use:       "sig",
+		Algorithm: algorithm,
+	}
+
+	switch publicKey := publicKey.(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	}
+
+	return jwk
+}
+
+// JWKSHandler serves the public keys of the maker as a JSON Web Key Set.
+// A maker with a symmetric key has no keys that can be published, so the set is empty
+func JWKSHandler(maker Maker) http.Handler {
+	jwks := JWKS{Keys: []JWK{}}
+	if publicKeyMaker, ok := maker.(PublicKeyMaker); ok {
+		jwks = publicKeyMaker.JWKS()
+	}
+
+	body, err := json.Marshal(jwks)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_, _ = w.Write(body)
+	})
+}
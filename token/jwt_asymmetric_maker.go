@@ -0,0 +1,106 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"time"
+)
+
+const jwtKeyIDHeader = "kid"
+
+// JWTAsymmetricMaker - JSON Web Token maker that signs tokens with EdDSA or RS256
+type JWTAsymmetricMaker struct {
+	method jwt.SigningMethod
+	keys   *KeySet
+}
+
+// NewJWTAsymmetricMaker creates a new JWTAsymmetricMaker for EdDSA with Ed25519 keys
+// or for RS256 with RSA keys of at least 2048 bits
+func NewJWTAsymmetricMaker(algorithm string, keys *KeySet) (Maker, error) {
+	var method jwt.SigningMethod
+	var err error
+
+	switch algorithm {
+	case AlgorithmEdDSA:
+		method = jwt.SigningMethodEdDSA
+		err = keys.checkKeys(isEd25519Key)
+	case AlgorithmRS256:
+		method = jwt.SigningMethodRS256
+		err = keys.checkKeys(isRSAKey)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s key set: %w", algorithm, err)
+	}
+
+	return &JWTAsymmetricMaker{method: method, keys: keys}, nil
+}
+
+// CreateToken creates a new token for a username, role and duration
+func (maker *JWTAsymmetricMaker) CreateToken(username string, role string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	token, err := maker.sign(payload)
+	return token, payload, err
+}
+
+// CreateClientToken creates a new token of an OAuth client acting for a user, limited to the scopes
+func (maker *JWTAsymmetricMaker) CreateClientToken(username string, role string, clientID string, scopes []string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewClientPayload(username, role, clientID, scopes, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	token, err := maker.sign(payload)
+	return token, payload, err
+}
+
+// sign signs the payload into a token with the signing key
+func (maker *JWTAsymmetricMaker) sign(payload *Payload) (string, error) {
+	jwtToken := jwt.NewWithClaims(maker.method, payload)
+	jwtToken.Header[jwtKeyIDHeader] = maker.keys.signingKeyID
+
+	return jwtToken.SignedString(maker.keys.signingKey)
+}
+
+// VerifyToken checks if the token is valid
+func (maker *JWTAsymmetricMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != maker.method.Alg() {
+			return nil, ErrInvalidToken
+		}
+
+		keyID, ok := token.Header[jwtKeyIDHeader].(string)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		publicKey, ok := maker.keys.PublicKey(keyID)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		return publicKey, nil
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
+	if err != nil {
+		validationErr, ok := err.(*jwt.ValidationError)
+		if ok && errors.Is(validationErr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,114 @@
+package token
+
+import (
+	"github.com/aalug/bank-go/utils"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestJWTAsymmetricMaker(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmEdDSA, AlgorithmRS256} {
+		t.Run(algorithm, func(t *testing.T) {
+			maker, err := NewJWTAsymmetricMaker(algorithm, newTestKeySet(t, algorithm, "key-1"))
+			require.NoError(t, err)
+
+			username := utils.RandomOwner()
+			role := utils.CustomerRole
+			duration := time.Minute
+
+			issuedAt := time.Now()
+			expiredAt := issuedAt.Add(duration)
+
+			token, payload, err := maker.CreateToken(username, role, duration)
+			require.NoError(t, err)
+			require.NotEmpty(t, token)
+			require.NotEmpty(t, payload)
+
+			jwtToken, _, err := jwt.NewParser().ParseUnverified(token, &Payload{})
+			require.NoError(t, err)
+			require.Equal(t, algorithm, jwtToken.Header["alg"])
+			require.Equal(t, "key-1", jwtToken.Header["kid"])
+
+			payload, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.NotEmpty(t, payload)
+
+			require.NotZero(t, payload.ID)
+			require.Equal(t, username, payload.Username)
+			require.Equal(t, role, payload.Role)
+			require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+			require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+		})
+	}
+}
+
+func TestExpiredJWTAsymmetricToken(t *testing.T) {
+	maker, err := NewJWTAsymmetricMaker(AlgorithmEdDSA, newTestKeySet(t, AlgorithmEdDSA, "key-1"))
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(utils.RandomOwner(), utils.CustomerRole, -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestJWTAsymmetricTokenKeyRotation(t *testing.T) {
+	oldKeys := newTestKeySet(t, AlgorithmRS256, "old")
+	oldMaker, err := NewJWTAsymmetricMaker(AlgorithmRS256, oldKeys)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(utils.RandomOwner(), utils.CustomerRole, time.Minute)
+	require.NoError(t, err)
+
+	newKeys := newTestKeySet(t, AlgorithmRS256, "new")
+	oldPublicKey, _ := oldKeys.PublicKey("old")
+	require.NoError(t, newKeys.AddVerificationKey("old", oldPublicKey))
+
+	newMaker, err := NewJWTAsymmetricMaker(AlgorithmRS256, newKeys)
+	require.NoError(t, err)
+
+	_, err = newMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	retiredMaker, err := NewJWTAsymmetricMaker(AlgorithmRS256, newTestKeySet(t, AlgorithmRS256, "new"))
+	require.NoError(t, err)
+
+	payload, err := retiredMaker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestInvalidJWTAsymmetricTokenAlgorithm(t *testing.T) {
+	keys := newTestKeySet(t, AlgorithmEdDSA, "key-1")
+	maker, err := NewJWTAsymmetricMaker(AlgorithmEdDSA, keys)
+	require.NoError(t, err)
+
+	payload, err := NewPayload(utils.RandomOwner(), utils.AdminRole, time.Minute)
+	require.NoError(t, err)
+
+	// a token that is not signed at all
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
+	jwtToken.Header["kid"] = "key-1"
+	token, err := jwtToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestNewJWTAsymmetricMakerRejectsMismatchedKeys(t *testing.T) {
+	_, err := NewJWTAsymmetricMaker(AlgorithmRS256, newTestKeySet(t, AlgorithmEdDSA, "key-1"))
+	require.Error(t, err)
+
+	_, err = NewJWTAsymmetricMaker(AlgorithmHS256, newTestKeySet(t, AlgorithmEdDSA, "key-1"))
+	require.Error(t, err)
+}
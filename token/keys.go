@@ -0,0 +1,220 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	keyFileExtension = ".pem"
+	rsaKeySize       = 2048
+)
+
+// KeySet - keys of an asymmetric token maker. Tokens are signed with the signing key
+// and verified with the public key whose ID they carry, so keys can be rotated
+// without invalidating the tokens signed with the previous key
+type KeySet struct {
+	signingKeyID string
+	signingKey   crypto.Signer
+	publicKeys   map[string]crypto.PublicKey
+}
+
+// NewKeySet creates a new KeySet with a signing key, its public key is also a verification key
+func NewKeySet(signingKeyID string, signingKey crypto.Signer) (*KeySet, error) {
+	if signingKeyID == "" {
+		return nil, fmt.Errorf("signing key id must not be empty")
+	}
+
+	keys := &KeySet{
+		signingKeyID: signingKeyID,
+		signingKey:   signingKey,
+		publicKeys:   map[string]crypto.PublicKey{signingKeyID: signingKey.Public()},
+	}
+
+	return keys, nil
+}
+
+// AddVerificationKey adds a public key that tokens can be verified with, but not signed
+func (keys *KeySet) AddVerificationKey(keyID string, publicKey crypto.PublicKey) error {
+	if keyID == "" {
+		return fmt.Errorf("key id must not be empty")
+	}
+	if _, ok := keys.publicKeys[keyID]; ok {
+		return fmt.Errorf("duplicate key id %q", keyID)
+	}
+
+	keys.publicKeys[keyID] = publicKey
+	return nil
+}
+
+// KeyIDs returns the sorted IDs of all verification keys
+func (keys *KeySet) KeyIDs() []string {
+	keyIDs := make([]string, 0, len(keys.publicKeys))
+	for keyID := range keys.publicKeys {
+		keyIDs = append(keyIDs, keyID)
+	}
+	sort.Strings(keyIDs)
+
+	return keyIDs
+}
+
+// PublicKey returns the verification key with the given ID
+func (keys *KeySet) PublicKey(keyID string) (crypto.PublicKey, bool) {
+	publicKey, ok := keys.publicKeys[keyID]
+	return publicKey, ok
+}
+
+// checkKeys returns an error if any of the keys is not accepted by the maker
+func (keys *KeySet) checkKeys(accept func(crypto.PublicKey) bool) error {
+	for _, keyID := range keys.KeyIDs() {
+		if !accept(keys.publicKeys[keyID]) {
+			return fmt.Errorf("key %q has an unsupported type", keyID)
+		}
+	}
+
+	return nil
+}
+
+// LoadKeySet loads the keys from the PEM files in the directory, the ID of a key is
+// the name of its file without the extension. The file of the signing key must hold
+// a private key, every other file can hold either a private or a public key
+func LoadKeySet(dir string, signingKeyID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+keyFileExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys *KeySet
+	publicKeys := map[string]crypto.PublicKey{}
+
+	for _, path := range paths {
+		keyID := strings.TrimSuffix(filepath.Base(path), keyFileExtension)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		privateKey, publicKey, err := ParseKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse key %q: %w", keyID, err)
+		}
+
+		if keyID != signingKeyID {
+			publicKeys[keyID] = publicKey
+			continue
+		}
+
+		if privateKey == nil {
+			return nil, fmt.Errorf("signing key %q is not a private key", keyID)
+		}
+		keys, err = NewKeySet(keyID, privateKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if keys == nil {
+		return nil, fmt.Errorf("signing key %q not found in %s", signingKeyID, dir)
+	}
+
+	for keyID, publicKey := range publicKeys {
+		err = keys.AddVerificationKey(keyID, publicKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// ParseKey parses a PEM encoded Ed25519 or RSA key. A private key is returned
+// together with its public key, for a public key the private key is nil
+func ParseKey(data []byte) (crypto.Signer, crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("no PEM block found")
+	}
+
+	var key any
+	var err error
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		return key, key.Public(), nil
+	case *rsa.PrivateKey:
+		return key, key.Public(), nil
+	case ed25519.PublicKey, *rsa.PublicKey:
+		return nil, key, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// GenerateKey generates a new private key for the algorithm,
+// an Ed25519 key for v4.public and EdDSA and an RSA key for RS256
+func GenerateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case AlgorithmPasetoPublic, AlgorithmEdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	case AlgorithmRS256:
+		return rsa.GenerateKey(rand.Reader, rsaKeySize)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+}
+
+// EncodePrivateKey encodes a private key as a PKCS #8 PEM block
+func EncodePrivateKey(privateKey crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// EncodePublicKey encodes a public key as a PKIX PEM block
+func EncodePublicKey(publicKey crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+func isEd25519Key(publicKey crypto.PublicKey) bool {
+	_, ok := publicKey.(ed25519.PublicKey)
+	return ok
+}
+
+func isRSAKey(publicKey crypto.PublicKey) bool {
+	rsaKey, ok := publicKey.(*rsa.PublicKey)
+	return ok && rsaKey.N.BitLen() >= rsaKeySize
+}
@@ -0,0 +1,84 @@
+package token
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestKey(t *testing.T, dir string, keyID string, algorithm string, public bool) {
+	privateKey, err := GenerateKey(algorithm)
+	require.NoError(t, err)
+
+	data, err := EncodePrivateKey(privateKey)
+	if public {
+		data, err = EncodePublicKey(privateKey.Public())
+	}
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, keyID+".pem"), data, 0o600)
+	require.NoError(t, err)
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+	writeTestKey(t, dir, "2023-06", AlgorithmEdDSA, true)
+	writeTestKey(t, dir, "2023-07", AlgorithmEdDSA, false)
+	writeTestKey(t, dir, "2023-08", AlgorithmEdDSA, false)
+
+	keys, err := LoadKeySet(dir, "2023-07")
+	require.NoError(t, err)
+	require.Equal(t, "2023-07", keys.signingKeyID)
+	require.Equal(t, []string{"2023-06", "2023-07", "2023-08"}, keys.KeyIDs())
+
+	// the public key can only verify tokens
+	_, err = LoadKeySet(dir, "2023-06")
+	require.Error(t, err)
+
+	_, err = LoadKeySet(dir, "2023-09")
+	require.Error(t, err)
+}
+
+func TestJWKSHandler(t *testing.T) {
+	dir := t.TempDir()
+	writeTestKey(t, dir, "old", AlgorithmRS256, true)
+	writeTestKey(t, dir, "new", AlgorithmRS256, false)
+
+	keys, err := LoadKeySet(dir, "new")
+	require.NoError(t, err)
+
+	maker, err := NewJWTAsymmetricMaker(AlgorithmRS256, keys)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	JWKSHandler(maker).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var jwks JWKS
+	err = json.Unmarshal(recorder.Body.Bytes(), &jwks)
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 2)
+
+	for i, keyID := range []string{"new", "old"} {
+		require.Equal(t, keyID, jwks.Keys[i].KeyID)
+		require.Equal(t, "RSA", jwks.Keys[i].KeyType)
+		require.Equal(t, AlgorithmRS256, jwks.Keys[i].Algorithm)
+		require.Equal(t, "sig", jwks.Keys[i].Use)
+		require.Equal(t, "AQAB", jwks.Keys[i].E)
+		require.NotEmpty(t, jwks.Keys[i].N)
+	}
+}
+
+func TestJWKSHandlerSymmetricKey(t *testing.T) {
+	maker, err := NewPasetoMaker("12345678901234567890123456789012")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	JWKSHandler(maker).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"keys":[]}`, recorder.Body.String())
+}
@@ -1,9 +1,46 @@
 package token
 
-import "time"
+import (
+	"fmt"
+	"github.com/aalug/bank-go/utils"
+	"time"
+)
+
+// Algorithms that tokens can be signed with, set with TOKEN_ALGORITHM
+const (
+	AlgorithmPasetoLocal  = "v2.local"
+	AlgorithmHS256        = "HS256"
+	AlgorithmPasetoPublic = "v4.public"
+	AlgorithmEdDSA        = "EdDSA"
+	AlgorithmRS256        = "RS256"
+)
 
 // Maker - interface for managing tokens
 type Maker interface {
-	CreateToken(username string, duration time.Duration) (string, *Payload, error)
+	CreateToken(username string, role string, duration time.Duration) (string, *Payload, error)
+	CreateClientToken(username string, role string, clientID string, scopes []string, duration time.Duration) (string, *Payload, error)
 	VerifyToken(token string) (*Payload, error)
 }
+
+// NewMaker creates the token maker for the algorithm from the config, PASETO v2.local by default.
+// Symmetric makers use TOKEN_SYMMETRIC_KEY, asymmetric makers load their keys from TOKEN_KEYS_DIR
+func NewMaker(config utils.Config) (Maker, error) {
+	switch config.TokenAlgorithm {
+	case "", AlgorithmPasetoLocal:
+		return NewPasetoMaker(config.TokenSymmetricKey)
+	case AlgorithmHS256:
+		return NewJWTMaker(config.TokenSymmetricKey)
+	case AlgorithmPasetoPublic, AlgorithmEdDSA, AlgorithmRS256:
+		keys, err := LoadKeySet(config.TokenKeysDir, config.TokenSigningKeyID)
+		if err != nil {
+			return nil, err
+		}
+
+		if config.TokenAlgorithm == AlgorithmPasetoPublic {
+			return NewPasetoPublicMaker(keys)
+		}
+		return NewJWTAsymmetricMaker(config.TokenAlgorithm, keys)
+	default:
+		return nil, fmt.Errorf("unsupported token algorithm %q", config.TokenAlgorithm)
+	}
+}
@@ -0,0 +1,149 @@
+package token
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const pasetoPublicHeader = "v4.public."
+
+// pasetoFooter - footer of a v4.public token, it carries the ID of the signing key
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
+// PasetoPublicMaker - a PASETO v4.public token maker, tokens are signed with Ed25519
+type PasetoPublicMaker struct {
+	keys *KeySet
+}
+
+// NewPasetoPublicMaker creates a new PasetoPublicMaker, all keys must be Ed25519 keys
+func NewPasetoPublicMaker(keys *KeySet) (Maker, error) {
+	err := keys.checkKeys(isEd25519Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s key set: %w", AlgorithmPasetoPublic, err)
+	}
+
+	return &PasetoPublicMaker{keys}, nil
+}
+
+// CreateToken creates a new token for a specific username, role and duration
+func (maker *PasetoPublicMaker) CreateToken(username string, role string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	token, err := maker.sign(payload)
+	return token, payload, err
+}
+
+// CreateClientToken creates a new token of an OAuth client acting for a user, limited to the scopes
+func (maker *PasetoPublicMaker) CreateClientToken(username string, role string, clientID string, scopes []string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewClientPayload(username, role, clientID, scopes, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	token, err := maker.sign(payload)
+	return token, payload, err
+}
+
+// sign signs the payload into a token with the signing key
+func (maker *PasetoPublicMaker) sign(payload *Payload) (string, error) {
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	footer, err := json.Marshal(pasetoFooter{KeyID: maker.keys.signingKeyID})
+	if err != nil {
+		return "", err
+	}
+
+	privateKey := maker.keys.signingKey.(ed25519.PrivateKey)
+	signature := ed25519.Sign(privateKey, preAuthEncode([]byte(pasetoPublicHeader), message, footer, nil))
+
+	token := pasetoPublicHeader +
+		base64.RawURLEncoding.EncodeToString(append(message, signature...)) + "." +
+		base64.RawURLEncoding.EncodeToString(footer)
+
+	return token, nil
+}
+
+// VerifyToken checks if the token is valid
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	if !strings.HasPrefix(token, pasetoPublicHeader) {
+		return nil, ErrInvalidToken
+	}
+
+	parts := strings.Split(strings.TrimPrefix(token, pasetoPublicHeader), ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(body) < ed25519.SignatureSize {
+		return nil, ErrInvalidToken
+	}
+
+	footer, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var decodedFooter pasetoFooter
+	err = json.Unmarshal(footer, &decodedFooter)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	publicKey, ok := maker.keys.PublicKey(decodedFooter.KeyID)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	message := body[:len(body)-ed25519.SignatureSize]
+	signature := body[len(body)-ed25519.SignatureSize:]
+	if !ed25519.Verify(publicKey.(ed25519.PublicKey), preAuthEncode([]byte(pasetoPublicHeader), message, footer, nil), signature) {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{}
+	err = json.Unmarshal(message, payload)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	err = payload.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// preAuthEncode - PASETO pre-authentication encoding of the pieces that are signed
+func preAuthEncode(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+
+	writeLength := func(length int) {
+		var encoded [8]byte
+		binary.LittleEndian.PutUint64(encoded[:], uint64(length)&^(1<<63))
+		buf.Write(encoded[:])
+	}
+
+	writeLength(len(pieces))
+	for _, piece := range pieces {
+		writeLength(len(piece))
+		buf.Write(piece)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,150 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestKeySet(t *testing.T, algorithm string, keyID string) *KeySet {
+	privateKey, err := GenerateKey(algorithm)
+	require.NoError(t, err)
+
+	keys, err := NewKeySet(keyID, privateKey)
+	require.NoError(t, err)
+
+	return keys
+}
+
+func TestPasetoPublicMaker(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(newTestKeySet(t, AlgorithmPasetoPublic, "key-1"))
+	require.NoError(t, err)
+
+	username := utils.RandomOwner()
+	role := utils.CustomerRole
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, role, duration)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.public."))
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestExpiredPasetoPublicToken(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(newTestKeySet(t, AlgorithmPasetoPublic, "key-1"))
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(utils.RandomOwner(), utils.CustomerRole, -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoPublicTokenKeyRotation(t *testing.T) {
+	oldKeys := newTestKeySet(t, AlgorithmPasetoPublic, "old")
+	oldMaker, err := NewPasetoPublicMaker(oldKeys)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(utils.RandomOwner(), utils.CustomerRole, time.Minute)
+	require.NoError(t, err)
+
+	// the new signing key still accepts tokens signed with the old one
+	newKeys := newTestKeySet(t, AlgorithmPasetoPublic, "new")
+	oldPublicKey, _ := oldKeys.PublicKey("old")
+	require.NoError(t, newKeys.AddVerificationKey("old", oldPublicKey))
+
+	newMaker, err := NewPasetoPublicMaker(newKeys)
+	require.NoError(t, err)
+
+	_, err = newMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	newToken, _, err := newMaker.CreateToken(utils.RandomOwner(), utils.CustomerRole, time.Minute)
+	require.NoError(t, err)
+
+	// once the old key is retired, its tokens are no longer valid
+	retiredMaker, err := NewPasetoPublicMaker(newTestKeySet(t, AlgorithmPasetoPublic, "new"))
+	require.NoError(t, err)
+
+	_, err = retiredMaker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	// same key id, but a different key
+	_, err = retiredMaker.VerifyToken(newToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestInvalidPasetoPublicToken(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(newTestKeySet(t, AlgorithmPasetoPublic, "key-1"))
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(utils.RandomOwner(), utils.CustomerRole, time.Minute)
+	require.NoError(t, err)
+
+	localMaker, err := NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+	localToken, _, err := localMaker.CreateToken(utils.RandomOwner(), utils.CustomerRole, time.Minute)
+	require.NoError(t, err)
+
+	footerStart := strings.LastIndex(token, ".")
+
+	// change a character of the signed message
+	tampered := []byte(token)
+	tamperedIndex := len(pasetoPublicHeader) + 10
+	tampered[tamperedIndex] = 'A'
+	if token[tamperedIndex] == 'A' {
+		tampered[tamperedIndex] = 'B'
+	}
+
+	for _, invalidToken := range []string{
+		"",
+		localToken,
+		token[:footerStart],
+		string(tampered),
+		token[:footerStart+1] + "eyJraWQiOiJrZXktMiJ9",
+	} {
+		payload, err := maker.VerifyToken(invalidToken)
+		require.EqualError(t, err, ErrInvalidToken.Error())
+		require.Nil(t, payload)
+	}
+}
+
+func TestPasetoPublicSignatureTestVector(t *testing.T) {
+	// test vector 4-S-1 of the PASETO specification
+	publicKey, err := hex.DecodeString("1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+
+	message := []byte(`{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`)
+	signature, err := hex.DecodeString("6e0fd7041cddb3c953652855970c0a4a078aa4b4f7cae913c3a254cf75b887f1" +
+		"31b1057e3f4579e337a6643700c4569279e7f54255c416d92ac53d5c8a24040c")
+	require.NoError(t, err)
+
+	require.True(t, ed25519.Verify(publicKey, preAuthEncode([]byte(pasetoPublicHeader), message, nil, nil), signature))
+}
+
+func TestNewPasetoPublicMakerRejectsRSAKeys(t *testing.T) {
+	_, err := NewPasetoPublicMaker(newTestKeySet(t, AlgorithmRS256, "key-1"))
+	require.Error(t, err)
+}
@@ -8,17 +8,29 @@ import (
 
 var ErrExpiredToken = errors.New("token has expired")
 var ErrInvalidToken = errors.New("token is invalid")
+var ErrRevokedToken = errors.New("token has been revoked")
 
 // Payload - payload data of the token
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
+
+	// set only when the request is authenticated with an API key
+	APIKeyID   int64   `json:"api_key_id,omitempty"`
+	AccountIDs []int64 `json:"account_ids,omitempty"`
+
+	// set only in access tokens issued to OAuth clients
+	ClientID string `json:"client_id,omitempty"`
+
+	// what an API key or an OAuth client can do, unused in access tokens of users
+	Scopes []string `json:"scopes,omitempty"`
 }
 
-// NewPayload creates a new token payload with a specific username and duration
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+// NewPayload creates a new token payload with a specific username, role and duration
+func NewPayload(username string, role string, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -27,12 +39,25 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        tokenID,
 		Username:  username,
+		Role:      role,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
 	return payload, nil
 }
 
+// NewClientPayload creates a new token payload of an OAuth client acting for a user, limited to the scopes
+func NewClientPayload(username string, role string, clientID string, scopes []string, duration time.Duration) (*Payload, error) {
+	payload, err := NewPayload(username, role, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	payload.ClientID = clientID
+	payload.Scopes = scopes
+	return payload, nil
+}
+
 // Valid checks if the token payload is valid
 func (payload *Payload) Valid() error {
 	if time.Now().After(payload.ExpiredAt) {
@@ -40,3 +65,49 @@ func (payload *Payload) Valid() error {
 	}
 	return nil
 }
+
+// IsAPIKey returns true if the payload is of an API key rather than of an access token
+func (payload *Payload) IsAPIKey() bool {
+	return payload.APIKeyID != 0
+}
+
+// IsClient returns true if the payload is of an access token issued to an OAuth client
+func (payload *Payload) IsClient() bool {
+	return payload.ClientID != ""
+}
+
+// IsScoped returns true if the payload is limited by scopes,
+// that is it is of an API key or of an OAuth client
+func (payload *Payload) IsScoped() bool {
+	return payload.IsAPIKey() || payload.IsClient()
+}
+
+// HasScope returns true if the payload grants the scope.
+// Access tokens of users are not limited by scopes
+func (payload *Payload) HasScope(scope string) bool {
+	if !payload.IsScoped() {
+		return true
+	}
+
+	for _, s := range payload.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAccount returns true if the payload can act on the account.
+// An empty allow-list allows all accounts of the user
+func (payload *Payload) AllowsAccount(accountID int64) bool {
+	if len(payload.AccountIDs) == 0 {
+		return true
+	}
+
+	for _, id := range payload.AccountIDs {
+		if id == accountID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package token
+
+import (
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPayloadScopes(t *testing.T) {
+	payload, err := NewPayload(utils.RandomOwner(), utils.CustomerRole, time.Minute)
+	require.NoError(t, err)
+
+	// access tokens are not limited
+	require.False(t, payload.IsAPIKey())
+	require.True(t, payload.HasScope(ScopeTransfersWrite))
+	require.True(t, payload.AllowsAccount(utils.RandomInt(1, 1000)))
+
+	payload.APIKeyID = 1
+	payload.Scopes = []string{ScopeAccountsRead}
+	payload.AccountIDs = []int64{1, 2}
+
+	require.True(t, payload.IsAPIKey())
+	require.True(t, payload.HasScope(ScopeAccountsRead))
+	require.False(t, payload.HasScope(ScopeTransfersWrite))
+	require.True(t, payload.AllowsAccount(2))
+	require.False(t, payload.AllowsAccount(3))
+
+	// an empty allow-list allows all accounts
+	payload.AccountIDs = nil
+	require.True(t, payload.AllowsAccount(3))
+}
+
+func TestClientPayloadScopes(t *testing.T) {
+	payload, err := NewClientPayload(utils.RandomOwner(), utils.CustomerRole, utils.RandomString(16), []string{ScopeAccountsRead}, time.Minute)
+	require.NoError(t, err)
+
+	require.False(t, payload.IsAPIKey())
+	require.True(t, payload.IsClient())
+	require.True(t, payload.IsScoped())
+	require.True(t, payload.HasScope(ScopeAccountsRead))
+	require.False(t, payload.HasScope(ScopeTransfersWrite))
+	require.True(t, payload.AllowsAccount(utils.RandomInt(1, 1000)))
+}
+
+func TestCreateClientToken(t *testing.T) {
+	pasetoMaker, err := NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+	jwtMaker, err := NewJWTMaker(utils.RandomString(32))
+	require.NoError(t, err)
+	pasetoPublicMaker, err := NewPasetoPublicMaker(newTestKeySet(t, AlgorithmPasetoPublic, "key-1"))
+	require.NoError(t, err)
+	jwtAsymmetricMaker, err := NewJWTAsymmetricMaker(AlgorithmEdDSA, newTestKeySet(t, AlgorithmEdDSA, "key-1"))
+	require.NoError(t, err)
+
+	makers := map[string]Maker{
+		AlgorithmPasetoLocal:  pasetoMaker,
+		AlgorithmHS256:        jwtMaker,
+		AlgorithmPasetoPublic: pasetoPublicMaker,
+		AlgorithmEdDSA:        jwtAsymmetricMaker,
+	}
+
+	for algorithm, maker := range makers {
+		t.Run(algorithm, func(t *testing.T) {
+			username := utils.RandomOwner()
+			clientID := utils.RandomString(16)
+			scopes := []string{ScopeAccountsRead, ScopeTransfersWrite}
+
+			token, payload, err := maker.CreateClientToken(username, utils.CustomerRole, clientID, scopes, time.Minute)
+			require.NoError(t, err)
+			require.NotEmpty(t, token)
+			require.Equal(t, clientID, payload.ClientID)
+
+			// the client and its scopes are signed with the rest of the payload
+			payload, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, username, payload.Username)
+			require.Equal(t, clientID, payload.ClientID)
+			require.Equal(t, scopes, payload.Scopes)
+			require.True(t, payload.IsClient())
+		})
+	}
+}
+
+func TestIsSupportedScope(t *testing.T) {
+	for _, scope := range []string{ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersRead, ScopeTransfersWrite, ScopeAdmin} {
+		require.True(t, IsSupportedScope(scope))
+	}
+	require.False(t, IsSupportedScope("accounts:delete"))
+	require.False(t, IsSupportedScope(""))
+}
+
+func TestParseScope(t *testing.T) {
+	scopes := ParseScope(" accounts:read  transfers:write ")
+	require.Equal(t, []string{ScopeAccountsRead, ScopeTransfersWrite}, scopes)
+	require.Equal(t, "accounts:read transfers:write", FormatScope(scopes))
+	require.Empty(t, ParseScope(""))
+
+	require.True(t, HasScopes(scopes, []string{ScopeTransfersWrite}))
+	require.True(t, HasScopes(scopes, nil))
+	require.False(t, HasScopes(scopes, []string{ScopeAccountsRead, ScopeAccountsWrite}))
+}
@@ -0,0 +1,174 @@
+package token
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// RevokedToken - a token revoked by its ID, kept until it expires
+type RevokedToken struct {
+	ID        uuid.UUID
+	ExpiresAt time.Time
+}
+
+// UserTokenRevocation - a revocation of the tokens of a user issued before a time
+type UserTokenRevocation struct {
+	Username     string
+	IssuedBefore time.Time
+}
+
+// RevocationStore - the queries a RevocationList is loaded with
+type RevocationStore interface {
+	ListRevokedTokens(ctx context.Context) ([]RevokedToken, error)
+	ListUserTokenRevocations(ctx context.Context, issuedAfter time.Time) ([]UserTokenRevocation, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+}
+
+// RevocationList - an in-memory copy of the revoked tokens, refreshed from the store periodically.
+// A token is revoked when its ID was revoked or when it was issued before
+// the revocation time of its user, e.g. the last password change
+type RevocationList struct {
+	store RevocationStore
+	// maxTokenDuration - tokens older than that have expired,
+	// so there is no need to keep the revocations of their users
+	maxTokenDuration time.Duration
+
+	mutex        sync.RWMutex
+	tokenIDs     map[uuid.UUID]time.Time
+	issuedBefore map[string]time.Time
+}
+
+const (
+	// firstRefreshRetryDelay is the wait after the first failed load of the list,
+	// it doubles after every failure up to maxRefreshRetryDelay
+	firstRefreshRetryDelay = time.Second
+	maxRefreshRetryDelay   = time.Minute
+)
+
+// NewRevocationList creates a new, empty RevocationList
+func NewRevocationList(store RevocationStore, maxTokenDuration time.Duration) *RevocationList {
+	return &RevocationList{
+		store:            store,
+		maxTokenDuration: maxTokenDuration,
+		tokenIDs:         map[uuid.UUID]time.Time{},
+		issuedBefore:     map[string]time.Time{},
+	}
+}
+
+// Start loads the list, retrying with backoff until it succeeds, and then refreshes it
+// every interval until the context is cancelled. With an interval of 0 the list is loaded once
+func (list *RevocationList) Start(ctx context.Context, interval time.Duration) {
+	// until the first load no revoked token is rejected
+	for delay := firstRefreshRetryDelay; ; delay = min(2*delay, maxRefreshRetryDelay) {
+		err := list.Refresh(ctx)
+		if err == nil {
+			break
+		}
+
+		slog.ErrorContext(ctx, "cannot load revoked tokens",
+			slog.String("error", err.Error()),
+			slog.Duration("retry_in", delay),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := list.Refresh(ctx); err != nil {
+			slog.ErrorContext(ctx, "cannot refresh revoked tokens", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// Refresh deletes the expired revoked tokens and replaces the list with the revocations from the store.
+// If the store cannot be read, the list is left as it was
+func (list *RevocationList) Refresh(ctx context.Context) error {
+	_, err := list.store.DeleteExpiredRevokedTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	revokedTokens, err := list.store.ListRevokedTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	userRevocations, err := list.store.ListUserTokenRevocations(ctx, time.Now().Add(-list.maxTokenDuration))
+	if err != nil {
+		return err
+	}
+
+	tokenIDs := make(map[uuid.UUID]time.Time, len(revokedTokens))
+	for _, revokedToken := range revokedTokens {
+		tokenIDs[revokedToken.ID] = revokedToken.ExpiresAt
+	}
+
+	issuedBefore := make(map[string]time.Time, len(userRevocations))
+	for _, userRevocation := range userRevocations {
+		issuedBefore[userRevocation.Username] = userRevocation.IssuedBefore
+	}
+
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	list.tokenIDs = tokenIDs
+	list.issuedBefore = issuedBefore
+
+	return nil
+}
+
+// RevokeToken adds a token revoked in the store to the list,
+// so it is rejected before the next refresh
+func (list *RevocationList) RevokeToken(payload *Payload) {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	list.tokenIDs[payload.ID] = payload.ExpiredAt
+}
+
+// RevokeUserTokens adds a revocation of the tokens of a user issued before a time to the list,
+// so they are rejected before the next refresh
+func (list *RevocationList) RevokeUserTokens(username string, issuedBefore time.Time) {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	if issuedBefore.After(list.issuedBefore[username]) {
+		list.issuedBefore[username] = issuedBefore
+	}
+}
+
+// Check returns ErrRevokedToken if the token has been revoked
+func (list *RevocationList) Check(payload *Payload) error {
+	list.mutex.RLock()
+	defer list.mutex.RUnlock()
+
+	if _, ok := list.tokenIDs[payload.ID]; ok {
+		return ErrRevokedToken
+	}
+
+	if issuedBefore, ok := list.issuedBefore[payload.Username]; ok && payload.IssuedAt.Before(issuedBefore) {
+		return ErrRevokedToken
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"github.com/aalug/bank-go/utils"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// fakeRevocationStore returns the revocations it holds, or err
+type fakeRevocationStore struct {
+	revokedTokens      []RevokedToken
+	userRevocations    []UserTokenRevocation
+	err                error
+	deleteExpiredCalls int
+	// failures - the number of calls of ListRevokedTokens that fail before the store works
+	failures int
+}
+
+func (store *fakeRevocationStore) ListRevokedTokens(ctx context.Context) ([]RevokedToken, error) {
+	if store.failures > 0 {
+		store.failures--
+		return nil, errors.New("connection refused")
+	}
+	return store.revokedTokens, store.err
+}
+
+func (store *fakeRevocationStore) ListUserTokenRevocations(ctx context.Context, issuedAfter time.Time) ([]UserTokenRevocation, error) {
+	return store.userRevocations, store.err
+}
+
+func (store *fakeRevocationStore) DeleteExpiredRevokedTokens(ctx context.Context) (int64, error) {
+	store.deleteExpiredCalls++
+	return 0, nil
+}
+
+func TestRevocationListRefresh(t *testing.T) {
+	store := &fakeRevocationStore{}
+	list := NewRevocationList(store, time.Hour)
+
+	revokedPayload, err := NewPayload(utils.RandomOwner(), utils.CustomerRole, time.Minute)
+	require.NoError(t, err)
+	oldPayload, err := NewPayload(utils.RandomOwner(), utils.CustomerRole, time.Minute)
+	require.NoError(t, err)
+	validPayload, err := NewPayload(utils.RandomOwner(), utils.CustomerRole, time.Minute)
+	require.NoError(t, err)
+
+	store.revokedTokens = []RevokedToken{{ID: revokedPayload.ID, ExpiresAt: revokedPayload.ExpiredAt}}
+	store.userRevocations = []UserTokenRevocation{{Username: oldPayload.Username, IssuedBefore: time.Now()}}
+
+	err = list.Refresh(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, store.deleteExpiredCalls)
+
+	require.ErrorIs(t, list.Check(revokedPayload), ErrRevokedToken)
+	require.ErrorIs(t, list.Check(oldPayload), ErrRevokedToken)
+	require.NoError(t, list.Check(validPayload))
+
+	// a failed refresh keeps the revocations loaded before
+	store.revokedTokens = nil
+	store.userRevocations = nil
+	store.err = errors.New("connection refused")
+
+	err = list.Refresh(context.Background())
+	require.Error(t, err)
+	require.ErrorIs(t, list.Check(revokedPayload), ErrRevokedToken)
+	require.ErrorIs(t, list.Check(oldPayload), ErrRevokedToken)
+}
+
+func TestRevocationListStartRetriesFirstLoad(t *testing.T) {
+	payload, err := NewPayload(utils.RandomOwner(), utils.CustomerRole, time.Minute)
+	require.NoError(t, err)
+
+	store := &fakeRevocationStore{
+		revokedTokens: []RevokedToken{{ID: payload.ID, ExpiresAt: payload.ExpiredAt}},
+		failures:      1,
+	}
+	list := NewRevocationList(store, time.Hour)
+
+	// with an interval of 0 Start returns once the list is loaded
+	list.Start(context.Background(), 0)
+	require.Equal(t, 2, store.deleteExpiredCalls)
+	require.ErrorIs(t, list.Check(payload), ErrRevokedToken)
+}
+
+func TestRevocationListStartCancelled(t *testing.T) {
+	store := &fakeRevocationStore{failures: 1}
+	list := NewRevocationList(store, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	list.Start(ctx, 0)
+	require.Equal(t, 1, store.deleteExpiredCalls)
+}
+
+func TestRevocationListRevokeUserTokens(t *testing.T) {
+	list := NewRevocationList(nil, time.Hour)
+
+	payload, err := NewPayload(utils.RandomOwner(), utils.CustomerRole, time.Minute)
+	require.NoError(t, err)
+
+	list.RevokeUserTokens(payload.Username, payload.IssuedAt.Add(time.Second))
+	require.ErrorIs(t, list.Check(payload), ErrRevokedToken)
+
+	// an earlier revocation does not move the time back
+	list.RevokeUserTokens(payload.Username, payload.IssuedAt.Add(-time.Second))
+	require.ErrorIs(t, list.Check(payload), ErrRevokedToken)
+
+	newPayload, err := NewPayload(payload.Username, utils.CustomerRole, time.Minute)
+	require.NoError(t, err)
+	newPayload.IssuedAt = payload.IssuedAt.Add(2 * time.Second)
+	require.NoError(t, list.Check(newPayload))
+}
@@ -0,0 +1,49 @@
+package token
+
+import "strings"
+
+// scopes that API keys and OAuth clients are granted
+const (
+	ScopeAccountsRead   = "accounts:read"
+	ScopeAccountsWrite  = "accounts:write"
+	ScopeTransfersRead  = "transfers:read"
+	ScopeTransfersWrite = "transfers:write"
+	// ScopeAdmin can be granted only to keys of admins, never to OAuth clients
+	ScopeAdmin = "admin"
+)
+
+// IsSupportedScope returns true if the scope is supported
+func IsSupportedScope(scope string) bool {
+	switch scope {
+	case ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersRead, ScopeTransfersWrite, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// ParseScope splits the space-delimited scope parameter of OAuth requests
+func ParseScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// FormatScope joins scopes into the space-delimited scope parameter of OAuth responses
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// HasScopes returns true if all the scopes are in the granted ones
+func HasScopes(granted []string, scopes []string) bool {
+	for _, scope := range scopes {
+		found := false
+		for _, g := range granted {
+			if g == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
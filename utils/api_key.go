@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to recognize
+const APIKeyPrefix = "gbk"
+
+var ErrInvalidAPIKey = errors.New("invalid API key format")
+
+// GenerateAPIKey returns a new API key in the form gbk_<prefix>_<secret> and its two parts.
+// The prefix is stored as it is to look the key up, the secret only as a hash
+func GenerateAPIKey() (key string, prefix string, secret string, err error) {
+	b := make([]byte, 6)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	prefix = hex.EncodeToString(b)
+
+	secret, err = GenerateSecureToken(32)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return APIKeyPrefix + "_" + prefix + "_" + secret, prefix, secret, nil
+}
+
+// ParseAPIKey splits an API key into its prefix and secret
+func ParseAPIKey(key string) (prefix string, secret string, err error) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != APIKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", "", ErrInvalidAPIKey
+	}
+
+	return parts[1], parts[2], nil
+}
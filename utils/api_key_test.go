@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, secret, err := GenerateAPIKey()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, APIKeyPrefix+"_"+prefix+"_"))
+	require.Len(t, prefix, 12)
+	require.NotEmpty(t, secret)
+
+	parsedPrefix, parsedSecret, err := ParseAPIKey(key)
+	require.NoError(t, err)
+	require.Equal(t, prefix, parsedPrefix)
+	require.Equal(t, secret, parsedSecret)
+
+	otherKey, otherPrefix, _, err := GenerateAPIKey()
+	require.NoError(t, err)
+	require.NotEqual(t, key, otherKey)
+	require.NotEqual(t, prefix, otherPrefix)
+}
+
+func TestParseAPIKey(t *testing.T) {
+	// the secret can contain the separator
+	prefix, secret, err := ParseAPIKey("gbk_abc_se_cr-et")
+	require.NoError(t, err)
+	require.Equal(t, "abc", prefix)
+	require.Equal(t, "se_cr-et", secret)
+
+	for _, key := range []string{"", "gbk", "gbk_abc", "gbk__secret", "gbk_abc_", "xyz_abc_secret"} {
+		_, _, err = ParseAPIKey(key)
+		require.ErrorIs(t, err, ErrInvalidAPIKey, key)
+	}
+}
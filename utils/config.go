@@ -1,19 +1,48 @@
 package utils
 
 import (
+	"fmt"
 	"github.com/spf13/viper"
 	"time"
 )
 
 // Config stores configuration of the application
 type Config struct {
-	DBDriver             string        `mapstructure:"DB_DRIVER"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
-	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	DBDriver                  string        `mapstructure:"DB_DRIVER"`
+	DBSource                  string        `mapstructure:"DB_SOURCE"`
+	HTTPServerAddress         string        `mapstructure:"HTTP_SERVER_ADDRESS"`
+	TrustedProxies            []string      `mapstructure:"TRUSTED_PROXIES"`
+	GRPCServerAddress         string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	TokenAlgorithm            string        `mapstructure:"TOKEN_ALGORITHM"`
+	TokenSymmetricKey         string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenKeysDir              string        `mapstructure:"TOKEN_KEYS_DIR"`
+	TokenSigningKeyID         string        `mapstructure:"TOKEN_SIGNING_KEY_ID"`
+	AccessTokenDuration       time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration      time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	RevocationRefreshInterval time.Duration `mapstructure:"REVOCATION_REFRESH_INTERVAL"`
+	LoginFailureThreshold     int32         `mapstructure:"LOGIN_FAILURE_THRESHOLD"`
+	LoginIPFailureThreshold   int32         `mapstructure:"LOGIN_IP_FAILURE_THRESHOLD"`
+	LoginLockoutDuration      time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	MFAChallengeDuration      time.Duration `mapstructure:"MFA_CHALLENGE_DURATION"`
+	StepUpTransferAmount      int64         `mapstructure:"STEP_UP_TRANSFER_AMOUNT"`
+	AppURL                    string        `mapstructure:"APP_URL"`
+	MailSender                string        `mapstructure:"MAIL_SENDER"`
+	MailFrom                  string        `mapstructure:"MAIL_FROM"`
+	MailDir                   string        `mapstructure:"MAIL_DIR"`
+	SMTPHost                  string        `mapstructure:"SMTP_HOST"`
+	SMTPPort                  int           `mapstructure:"SMTP_PORT"`
+	SMTPUsername              string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword              string        `mapstructure:"SMTP_PASSWORD"`
+	EmailVerificationDuration time.Duration `mapstructure:"EMAIL_VERIFICATION_DURATION"`
+	PasswordResetDuration     time.Duration `mapstructure:"PASSWORD_RESET_DURATION"`
+	OAuthCodeDuration         time.Duration `mapstructure:"OAUTH_CODE_DURATION"`
+	OAuthRefreshTokenDuration time.Duration `mapstructure:"OAUTH_REFRESH_TOKEN_DURATION"`
+	IdempotencyKeyDuration    time.Duration `mapstructure:"IDEMPOTENCY_KEY_DURATION"`
+	FXSpread                  string        `mapstructure:"FX_SPREAD"`
+	FXQuoteDuration           time.Duration `mapstructure:"FX_QUOTE_DURATION"`
+	SchedulerInterval         time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	HoldDuration              time.Duration `mapstructure:"HOLD_DURATION"`
+	LogLevel                  string        `mapstructure:"LOG_LEVEL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -23,11 +52,43 @@ func LoadConfig(path string) (config Config, err error) {
 
 	viper.AutomaticEnv()
 
+	viper.SetDefault("REVOCATION_REFRESH_INTERVAL", 30*time.Second)
+	viper.SetDefault("LOGIN_FAILURE_THRESHOLD", 5)
+	viper.SetDefault("LOGIN_IP_FAILURE_THRESHOLD", 50)
+	viper.SetDefault("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+	viper.SetDefault("IDEMPOTENCY_KEY_DURATION", 24*time.Hour)
+
 	err = viper.ReadInConfig()
 	if err != nil {
 		return
 	}
 
 	err = viper.Unmarshal(&config)
+	if err != nil {
+		return
+	}
+
+	err = config.Validate()
 	return
 }
+
+// Validate checks the values that turn off a protection when they are not positive
+func (config Config) Validate() error {
+	// without a lockout failed logins and step-up codes could be guessed without limit
+	if config.LoginFailureThreshold <= 0 {
+		return fmt.Errorf("LOGIN_FAILURE_THRESHOLD must be positive, got %d", config.LoginFailureThreshold)
+	}
+	if config.LoginIPFailureThreshold <= 0 {
+		return fmt.Errorf("LOGIN_IP_FAILURE_THRESHOLD must be positive, got %d", config.LoginIPFailureThreshold)
+	}
+	if config.LoginLockoutDuration <= 0 {
+		return fmt.Errorf("LOGIN_LOCKOUT_DURATION must be positive, got %s", config.LoginLockoutDuration)
+	}
+
+	// idempotency keys that expire at once would let retries post a transfer twice
+	if config.IdempotencyKeyDuration <= 0 {
+		return fmt.Errorf("IDEMPOTENCY_KEY_DURATION must be positive, got %s", config.IdempotencyKeyDuration)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package utils
+
+import "time"
+
+// loginBackoffBase is the delay after the second failed login in a row,
+// every next failure doubles it
+const loginBackoffBase = time.Second
+
+// LoginBackoff returns how long logins are refused after the given number of failed logins in a row.
+// The first failure is not delayed, the next ones back off exponentially up to the lockout duration,
+// and from the threshold on logins are locked for the lockout duration. A threshold of 0 never locks.
+func LoginBackoff(failures int32, threshold int32, lockout time.Duration) time.Duration {
+	if threshold > 0 && failures >= threshold {
+		return lockout
+	}
+
+	if failures < 2 {
+		return 0
+	}
+
+	backoff := loginBackoffBase
+	for i := int32(2); i < failures && backoff < lockout; i++ {
+		backoff *= 2
+	}
+
+	if backoff > lockout {
+		return lockout
+	}
+	return backoff
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLoginBackoff(t *testing.T) {
+	testCases := []struct {
+		name      string
+		failures  int32
+		threshold int32
+		lockout   time.Duration
+		backoff   time.Duration
+	}{
+		{
+			name:      "First Failure",
+			failures:  1,
+			threshold: 5,
+			lockout:   15 * time.Minute,
+			backoff:   0,
+		},
+		{
+			name:      "Second Failure",
+			failures:  2,
+			threshold: 5,
+			lockout:   15 * time.Minute,
+			backoff:   time.Second,
+		},
+		{
+			name:      "Fourth Failure",
+			failures:  4,
+			threshold: 5,
+			lockout:   15 * time.Minute,
+			backoff:   4 * time.Second,
+		},
+		{
+			name:      "Threshold Reached",
+			failures:  5,
+			threshold: 5,
+			lockout:   15 * time.Minute,
+			backoff:   15 * time.Minute,
+		},
+		{
+			name:      "Backoff Capped At Lockout",
+			failures:  30,
+			threshold: 0,
+			lockout:   time.Minute,
+			backoff:   time.Minute,
+		},
+		{
+			name:      "No Lockout",
+			failures:  10,
+			threshold: 0,
+			lockout:   0,
+			backoff:   0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.backoff, LoginBackoff(tc.failures, tc.threshold, tc.lockout))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"golang.org/x/crypto/bcrypt"
+	"sync"
 )
 
 // HashPassword returns hashed password
@@ -18,4 +19,21 @@ func HashPassword(password string) (string, error) {
 // CheckPassword checks if password is correct
 func CheckPassword(password string, hashedPassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-}
\ No newline at end of file
+}
+
+var (
+	dummyHashedPassword     []byte
+	dummyHashedPasswordOnce sync.Once
+)
+
+// CheckPasswordOfUnknownUser checks the password against a dummy hash and always fails.
+// It makes a login with an unknown username take as long as one with a wrong password,
+// so the response time does not reveal which usernames exist
+func CheckPasswordOfUnknownUser(password string) error {
+	dummyHashedPasswordOnce.Do(func() {
+		dummyHashedPassword, _ = bcrypt.GenerateFromPassword([]byte(RandomString(16)), bcrypt.DefaultCost)
+	})
+
+	_ = bcrypt.CompareHashAndPassword(dummyHashedPassword, []byte(password))
+	return bcrypt.ErrMismatchedHashAndPassword
+}
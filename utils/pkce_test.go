@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCheckPKCE(t *testing.T) {
+	// the example of RFC 7636, appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	require.Equal(t, challenge, PKCEChallenge(verifier))
+	require.True(t, CheckPKCE(verifier, challenge))
+	require.False(t, CheckPKCE(verifier+"x", challenge))
+	require.False(t, CheckPKCE(verifier, ""))
+}
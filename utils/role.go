@@ -0,0 +1,9 @@
+package utils
+
+// all roles of the users
+const (
+	// CustomerRole is the role of every user that signs up
+	CustomerRole = "customer"
+	// AdminRole is the role of the bank staff that can use the admin API
+	AdminRole = "admin"
+)
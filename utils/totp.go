@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"strings"
+	"time"
+)
+
+const (
+	// TOTPIssuer is the name authenticator apps show next to the code
+	TOTPIssuer = "Go Bank"
+
+	totpPeriod = 30
+	// totpSkew is the number of periods before and after the current one whose codes are accepted,
+	// so a code still works while it is typed and with a clock that is a bit off
+	totpSkew = 1
+
+	recoveryCodeBytes = 10
+
+	// RecoveryCodeCount is the number of recovery codes generated when two-factor authentication is enabled
+	RecoveryCodeCount = 10
+	// MaxMFAAttempts is the number of wrong codes after which an MFA challenge can no longer be used
+	MaxMFAAttempts = 5
+)
+
+var totpOpts = totp.ValidateOpts{
+	Period:    totpPeriod,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// GenerateTOTPKey generates a new TOTP secret for the user and returns it
+// together with the otpauth:// URI that authenticator apps can be set up with
+func GenerateTOTPKey(username string) (secret string, url string, err error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      TOTPIssuer,
+		AccountName: username,
+		Period:      totpPeriod,
+		Digits:      totpOpts.Digits,
+		Algorithm:   totpOpts.Algorithm,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return key.Secret(), key.URL(), nil
+}
+
+// TOTPCode returns the TOTP code of the secret at the given time
+func TOTPCode(secret string, t time.Time) (string, error) {
+	return totp.GenerateCodeCustom(secret, t, totpOpts)
+}
+
+// ValidateTOTP checks the code against the codes of the periods around the given time.
+// It returns the number of the period the code belongs to, so that the caller can refuse
+// to accept a code of the same or an earlier period again
+func ValidateTOTP(secret string, code string, t time.Time) (int64, bool) {
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		periodTime := t.Add(time.Duration(skew*totpPeriod) * time.Second)
+
+		expected, err := TOTPCode(secret, periodTime)
+		if err != nil {
+			return 0, false
+		}
+
+		if expected == code {
+			return periodTime.Unix() / totpPeriod, true
+		}
+	}
+
+	return 0, false
+}
+
+// GenerateRecoveryCodes generates n random one-time recovery codes, e.g. "ABCD-EFGH-IJKL-MNOP"
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+
+	for i := 0; i < n; i++ {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+
+		code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+		codes = append(codes, code[0:4]+"-"+code[4:8]+"-"+code[8:12]+"-"+code[12:16])
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hash a recovery code is stored as.
+// The code is normalized first, so it can be typed in lower case and without dashes
+func HashRecoveryCode(code string) string {
+	return HashToken(strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", "")))
+}
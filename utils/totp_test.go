@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateTOTPKey(t *testing.T) {
+	username := RandomOwner()
+
+	secret, keyURL, err := GenerateTOTPKey(username)
+	require.NoError(t, err)
+	require.NotEmpty(t, secret)
+
+	u, err := url.Parse(keyURL)
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, secret, u.Query().Get("secret"))
+	require.Equal(t, TOTPIssuer, u.Query().Get("issuer"))
+	require.Contains(t, u.Path, username)
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, _, err := GenerateTOTPKey(RandomOwner())
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := TOTPCode(secret, now)
+	require.NoError(t, err)
+
+	step, ok := ValidateTOTP(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, now.Unix()/totpPeriod, step)
+
+	// the code of the previous period is still accepted
+	previousStep, ok := ValidateTOTP(secret, code, now.Add(totpPeriod*time.Second))
+	require.True(t, ok)
+	require.Equal(t, step, previousStep)
+
+	// but not an older one
+	_, ok = ValidateTOTP(secret, code, now.Add(3*totpPeriod*time.Second))
+	require.False(t, ok)
+
+	_, ok = ValidateTOTP(secret, "abcdef", now)
+	require.False(t, ok)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		require.Len(t, code, 19)
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+
+	code := codes[0]
+	hash := HashRecoveryCode(code)
+	require.Len(t, hash, 64)
+	require.NotEqual(t, code, hash)
+	require.Equal(t, hash, HashRecoveryCode(" "+code+" "))
+	require.Equal(t, hash, HashRecoveryCode(strings.ToLower(strings.ReplaceAll(code, "-", ""))))
+	require.NotEqual(t, hash, HashRecoveryCode(codes[1]))
+}
@@ -2,13 +2,18 @@ package validation
 
 import (
 	"fmt"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/google/uuid"
 	"net/mail"
+	"net/url"
 	"regexp"
 )
 
 var (
 	isValidUsername = regexp.MustCompile(`^[a-zA-Z0-9_]+$`).MatchString
 	isValidFullName = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+	isValidTOTPCode = regexp.MustCompile(`^[0-9]{6}$`).MatchString
 )
 
 // ValidateStringLength check if the string length is between minLength and maxLength
@@ -72,3 +77,89 @@ func ValidateFullName(value string) error {
 
 	return nil
 }
+
+// ValidateID check if the id is valid.
+// It must be a positive number.
+func ValidateID(value int64) error {
+	if value < 1 {
+		return fmt.Errorf("id is invalid, must be a positive number")
+	}
+
+	return nil
+}
+
+// ValidateAmount check if the amount of money is valid.
+// It must be greater than zero.
+func ValidateAmount(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("amount is invalid, must be greater than zero")
+	}
+
+	return nil
+}
+
+// ValidateCurrency check if the currency is supported.
+func ValidateCurrency(value string) error {
+	if !utils.IsSupportedCurrency(value) {
+		return fmt.Errorf("currency %s is not supported", value)
+	}
+
+	return nil
+}
+
+// ValidatePage check if the page id and size are valid.
+// The page id must be a positive number and the size must be between minSize and maxSize.
+func ValidatePage(pageID, pageSize, minSize, maxSize int32) error {
+	if pageID < 1 {
+		return fmt.Errorf("page id is invalid, must be a positive number")
+	}
+
+	if pageSize < minSize || pageSize > maxSize {
+		return fmt.Errorf("page size must be between %d and %d", minSize, maxSize)
+	}
+
+	return nil
+}
+
+// ValidateUUID check if the value is a valid UUID.
+func ValidateUUID(value string) error {
+	if _, err := uuid.Parse(value); err != nil {
+		return fmt.Errorf("id is invalid, must be a UUID")
+	}
+
+	return nil
+}
+
+// ValidateTOTPCode check if the code of an authenticator app is valid.
+// It must be made of 6 digits.
+func ValidateTOTPCode(value string) error {
+	if !isValidTOTPCode(value) {
+		return fmt.Errorf("code is invalid, must be 6 digits")
+	}
+
+	return nil
+}
+
+// ValidateScope check if the scope of an API key is supported
+func ValidateScope(value string) error {
+	if !token.IsSupportedScope(value) {
+		return fmt.Errorf("unsupported scope %s", value)
+	}
+
+	return nil
+}
+
+// ValidateRedirectURI check if the redirect URI of an OAuth client is valid.
+// It must be an absolute URI without a fragment.
+func ValidateRedirectURI(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("redirect URI %s must be an absolute URI", value)
+	}
+
+	if u.Fragment != "" {
+		return fmt.Errorf("redirect URI %s must not contain a fragment", value)
+	}
+
+	return nil
+}
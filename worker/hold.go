@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"log/slog"
+	"time"
+)
+
+// expiryBatchSize is the maximum number of holds expired in one transaction
+const expiryBatchSize = 100
+
+// HoldExpiryWorker releases the funds of holds that were neither captured nor voided in time
+type HoldExpiryWorker struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewHoldExpiryWorker creates a worker that checks for expired holds every interval
+func NewHoldExpiryWorker(store db.Store, interval time.Duration) *HoldExpiryWorker {
+	return &HoldExpiryWorker{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start expires holds every interval until the context is cancelled
+func (worker *HoldExpiryWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(worker.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := worker.ExpireDue(ctx); err != nil {
+			slog.ErrorContext(ctx, "cannot expire holds", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireDue expires holds in batches until none are left and returns the number of expired holds
+func (worker *HoldExpiryWorker) ExpireDue(ctx context.Context) (int, error) {
+	expired := 0
+
+	for {
+		holds, err := worker.store.ExpireHoldsTx(ctx, expiryBatchSize)
+		if err != nil {
+			return expired, err
+		}
+
+		expired += len(holds)
+		if len(holds) < expiryBatchSize {
+			return expired, nil
+		}
+	}
+}
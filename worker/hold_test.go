@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	mockdb "github.com/aalug/bank-go/db/mock"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExpireDue(t *testing.T) {
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, expired int, err error)
+	}{
+		{
+			name: "Expires In Batches",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						ExpireHoldsTx(gomock.Any(), gomock.Eq(int32(expiryBatchSize))).
+						Times(1).
+						Return(make([]db.Hold, expiryBatchSize), nil),
+					store.EXPECT().
+						ExpireHoldsTx(gomock.Any(), gomock.Eq(int32(expiryBatchSize))).
+						Times(1).
+						Return(make([]db.Hold, 3), nil),
+				)
+			},
+			checkResponse: func(t *testing.T, expired int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, expiryBatchSize+3, expired)
+			},
+		},
+		{
+			name: "Nothing To Expire",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ExpireHoldsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Hold{}, nil)
+			},
+			checkResponse: func(t *testing.T, expired int, err error) {
+				require.NoError(t, err)
+				require.Zero(t, expired)
+			},
+		},
+		{
+			name: "Internal Error",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ExpireHoldsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, errors.New("connection lost"))
+			},
+			checkResponse: func(t *testing.T, expired int, err error) {
+				require.Error(t, err)
+				require.Zero(t, expired)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			worker := NewHoldExpiryWorker(store, 0)
+			expired, err := worker.ExpireDue(context.Background())
+			tc.checkResponse(t, expired, err)
+		})
+	}
+}
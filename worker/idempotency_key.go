@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"log/slog"
+	"time"
+)
+
+// IdempotencyKeyCleanupWorker deletes idempotency keys that can no longer be replayed
+type IdempotencyKeyCleanupWorker struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewIdempotencyKeyCleanupWorker creates a worker that deletes expired idempotency keys every interval
+func NewIdempotencyKeyCleanupWorker(store db.Store, interval time.Duration) *IdempotencyKeyCleanupWorker {
+	return &IdempotencyKeyCleanupWorker{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start deletes expired idempotency keys every interval until the context is cancelled
+func (worker *IdempotencyKeyCleanupWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(worker.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := worker.DeleteExpired(ctx); err != nil {
+			slog.ErrorContext(ctx, "cannot delete expired idempotency keys", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeleteExpired deletes the idempotency keys past their expiration time
+func (worker *IdempotencyKeyCleanupWorker) DeleteExpired(ctx context.Context) error {
+	return worker.store.DeleteExpiredIdempotencyKeys(ctx)
+}
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	mockdb "github.com/aalug/bank-go/db/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDeleteExpired(t *testing.T) {
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteExpiredIdempotencyKeys(gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Internal Error",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteExpiredIdempotencyKeys(gomock.Any()).
+					Times(1).
+					Return(errors.New("connection lost"))
+			},
+			checkResponse: func(t *testing.T, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			worker := NewIdempotencyKeyCleanupWorker(store, 0)
+			err := worker.DeleteExpired(context.Background())
+			tc.checkResponse(t, err)
+		})
+	}
+}
+
+func TestIdempotencyKeyCleanupWorkerStart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the first run happens right away, the second one after the interval
+	deleted := make(chan struct{}, 2)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		DeleteExpiredIdempotencyKeys(gomock.Any()).
+		MinTimes(2).
+		DoAndReturn(func(_ context.Context) error {
+			select {
+			case deleted <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+
+	done := make(chan struct{})
+	go func() {
+		NewIdempotencyKeyCleanupWorker(store, time.Millisecond).Start(ctx)
+		close(done)
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-deleted:
+		case <-time.After(time.Second):
+			t.Fatal("expired idempotency keys were not deleted")
+		}
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,61 @@
+package worker
+
+import (
+	db "github.com/aalug/bank-go/db/sqlc"
+	"time"
+)
+
+// NextOccurrence returns the first occurrence of a schedule that starts at startAt
+// after the given time. One-time schedules have no occurrences after their start.
+// Monthly schedules keep the day of the month of startAt
+// and fall back to the last day of shorter months.
+func NextOccurrence(recurrence string, startAt, after time.Time) (time.Time, bool) {
+	if startAt.After(after) {
+		return startAt, true
+	}
+
+	var n int
+	switch recurrence {
+	case db.RecurrenceDaily:
+		n = int(after.Sub(startAt) / (24 * time.Hour))
+	case db.RecurrenceWeekly:
+		n = int(after.Sub(startAt) / (7 * 24 * time.Hour))
+	case db.RecurrenceMonthly:
+		n = (after.Year()-startAt.Year())*12 + int(after.Month()-startAt.Month())
+	default:
+		return time.Time{}, false
+	}
+
+	// the estimate may be off by one around daylight saving time changes
+	if n > 0 {
+		n--
+	}
+
+	for ; ; n++ {
+		occurrence := nthOccurrence(recurrence, startAt, n)
+		if occurrence.After(after) {
+			return occurrence, true
+		}
+	}
+}
+
+// nthOccurrence returns the n-th occurrence of a schedule, the start is the zeroth one
+func nthOccurrence(recurrence string, startAt time.Time, n int) time.Time {
+	switch recurrence {
+	case db.RecurrenceDaily:
+		return startAt.AddDate(0, 0, n)
+	case db.RecurrenceWeekly:
+		return startAt.AddDate(0, 0, 7*n)
+	}
+
+	year, month, day := startAt.Date()
+	hour, min, sec := startAt.Clock()
+
+	// time.Date normalizes the overflowing day into the next month, clamp it instead
+	lastDay := time.Date(year, month+time.Month(n)+1, 0, 0, 0, 0, 0, startAt.Location()).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(year, month+time.Month(n), day, hour, min, sec, startAt.Nanosecond(), startAt.Location())
+}
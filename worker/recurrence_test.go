@@ -0,0 +1,86 @@
+package worker
+
+import (
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	startAt := time.Date(2023, 1, 31, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		recurrence string
+		after      time.Time
+		expected   time.Time
+		ok         bool
+	}{
+		{
+			name:       "Before Start",
+			recurrence: db.RecurrenceMonthly,
+			after:      startAt.Add(-time.Hour),
+			expected:   startAt,
+			ok:         true,
+		},
+		{
+			name:       "Once",
+			recurrence: db.RecurrenceOnce,
+			after:      startAt,
+			ok:         false,
+		},
+		{
+			name:       "Daily",
+			recurrence: db.RecurrenceDaily,
+			after:      startAt,
+			expected:   time.Date(2023, 2, 1, 9, 30, 0, 0, time.UTC),
+			ok:         true,
+		},
+		{
+			name:       "Daily Skips Missed Runs",
+			recurrence: db.RecurrenceDaily,
+			after:      time.Date(2023, 2, 10, 12, 0, 0, 0, time.UTC),
+			expected:   time.Date(2023, 2, 11, 9, 30, 0, 0, time.UTC),
+			ok:         true,
+		},
+		{
+			name:       "Weekly",
+			recurrence: db.RecurrenceWeekly,
+			after:      startAt.Add(time.Minute),
+			expected:   time.Date(2023, 2, 7, 9, 30, 0, 0, time.UTC),
+			ok:         true,
+		},
+		{
+			name:       "Monthly Clamps To Last Day",
+			recurrence: db.RecurrenceMonthly,
+			after:      startAt,
+			expected:   time.Date(2023, 2, 28, 9, 30, 0, 0, time.UTC),
+			ok:         true,
+		},
+		{
+			name:       "Monthly Keeps Day Of Start",
+			recurrence: db.RecurrenceMonthly,
+			after:      time.Date(2023, 2, 28, 9, 30, 0, 0, time.UTC),
+			expected:   time.Date(2023, 3, 31, 9, 30, 0, 0, time.UTC),
+			ok:         true,
+		},
+		{
+			name:       "Monthly Across Years",
+			recurrence: db.RecurrenceMonthly,
+			after:      time.Date(2023, 12, 31, 10, 0, 0, 0, time.UTC),
+			expected:   time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC),
+			ok:         true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			next, ok := NextOccurrence(tc.recurrence, startAt, tc.after)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, next)
+		})
+	}
+}
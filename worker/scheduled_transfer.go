@@ -0,0 +1,217 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"log/slog"
+	"time"
+)
+
+const (
+	// MaxFailures is the number of failed runs in a row after which a scheduled transfer is suspended
+	MaxFailures = 3
+	// RetryDelay is the delay before the first retry of a failed run, it doubles with every failure
+	RetryDelay = 15 * time.Minute
+
+	// claimLease is how long a claimed transfer is hidden from other workers,
+	// after that it is picked up again, e.g. when the worker crashed
+	claimLease = 5 * time.Minute
+	// batchSize is the maximum number of transfers claimed at once
+	batchSize = 10
+	// idempotencyKeyDuration must be longer than claimLease,
+	// so that a run repeated after a crash does not move money twice
+	idempotencyKeyDuration = 24 * time.Hour
+)
+
+var (
+	// errAccountNotOwned fails a run when the owner no longer owns the account the transfer is sent from
+	errAccountNotOwned = errors.New("from account does not belong to the owner of the scheduled transfer")
+	// errEmailNotVerified fails a run when the email of the owner is not verified
+	errEmailNotVerified = errors.New("email address of the owner is not verified")
+	// errAPIKeyInactive fails a run of a transfer scheduled with an API key that was revoked or expired
+	errAPIKeyInactive = errors.New("API key of the scheduled transfer has been revoked or has expired")
+	// errAPIKeyNotAllowed fails a run of a transfer scheduled with an API key
+	// that no longer has the scope or the account
+	errAPIKeyNotAllowed = errors.New("API key of the scheduled transfer no longer allows the transfer")
+)
+
+// ScheduledTransferWorker executes scheduled transfers when they are due
+type ScheduledTransferWorker struct {
+	store    db.Store
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewScheduledTransferWorker creates a worker that checks for due transfers every interval
+func NewScheduledTransferWorker(store db.Store, interval time.Duration) *ScheduledTransferWorker {
+	return &ScheduledTransferWorker{
+		store:    store,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start processes due transfers every interval until the context is cancelled
+func (worker *ScheduledTransferWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(worker.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := worker.ProcessDue(ctx); err != nil {
+			slog.ErrorContext(ctx, "cannot process scheduled transfers", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue claims a batch of due transfers and executes them.
+// Rows are claimed with FOR UPDATE SKIP LOCKED, so several workers can run at once.
+// It returns the number of executed transfers, failed runs included.
+func (worker *ScheduledTransferWorker) ProcessDue(ctx context.Context) (int, error) {
+	scheduledTransfers, err := worker.store.ClaimDueScheduledTransfers(ctx, db.ClaimDueScheduledTransfersParams{
+		ClaimedUntil: worker.now().Add(claimLease),
+		MaxCount:     batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i, scheduledTransfer := range scheduledTransfers {
+		if err := worker.run(ctx, scheduledTransfer); err != nil {
+			return i, fmt.Errorf("cannot record run of scheduled transfer %d: %w", scheduledTransfer.ID, err)
+		}
+	}
+
+	return len(scheduledTransfers), nil
+}
+
+// run executes a claimed transfer and records the outcome
+func (worker *ScheduledTransferWorker) run(ctx context.Context, scheduledTransfer db.ScheduledTransfer) error {
+	var result db.TransferTxResult
+	err := worker.validate(ctx, scheduledTransfer)
+	if err == nil {
+		result, err = worker.store.IdempotentTransferTx(ctx, db.IdempotentTransferTxParams{
+			TransferTxParams: db.TransferTxParams{
+				FromAccountID: scheduledTransfer.FromAccountID,
+				ToAccountID:   scheduledTransfer.ToAccountID,
+				Amount:        scheduledTransfer.Amount,
+			},
+			Username:       scheduledTransfer.Owner,
+			IdempotencyKey: idempotencyKey(scheduledTransfer),
+			KeyDuration:    idempotencyKeyDuration,
+		})
+	}
+
+	arg := nextState(scheduledTransfer, err, worker.now())
+	if err == nil {
+		arg.TransferID = &result.Transfer.ID
+	} else {
+		slog.WarnContext(ctx, "scheduled transfer failed",
+			slog.Int64("scheduled_transfer_id", scheduledTransfer.ID),
+			slog.String("owner", scheduledTransfer.Owner),
+			slog.Int("failure_count", int(arg.FailureCount)),
+			slog.String("status", arg.Status),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	_, err = worker.store.RecordScheduledTransferRunTx(ctx, arg)
+	return err
+}
+
+// validate repeats the checks made when the transfer was scheduled,
+// because the owner, or the API key it was scheduled with, may have lost access to the account since then.
+// A transfer that fails them is recorded as a failed run.
+func (worker *ScheduledTransferWorker) validate(ctx context.Context, scheduledTransfer db.ScheduledTransfer) error {
+	fromAccount, err := worker.store.GetAccount(ctx, scheduledTransfer.FromAccountID)
+	if err != nil {
+		return err
+	}
+
+	if fromAccount.Owner != scheduledTransfer.Owner {
+		return errAccountNotOwned
+	}
+
+	owner, err := worker.store.GetUser(ctx, scheduledTransfer.Owner)
+	if err != nil {
+		return err
+	}
+
+	if !owner.IsEmailVerified {
+		return errEmailNotVerified
+	}
+
+	if scheduledTransfer.APIKeyID == nil {
+		return nil
+	}
+
+	apiKey, err := worker.store.GetAPIKey(ctx, *scheduledTransfer.APIKeyID)
+	if err != nil {
+		return err
+	}
+
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && worker.now().After(*apiKey.ExpiresAt)) {
+		return errAPIKeyInactive
+	}
+
+	payload := &token.Payload{
+		Username:   apiKey.Owner,
+		APIKeyID:   apiKey.ID,
+		Scopes:     apiKey.Scopes,
+		AccountIDs: apiKey.AccountIds,
+	}
+	if !payload.HasScope(token.ScopeTransfersWrite) || !payload.AllowsAccount(scheduledTransfer.FromAccountID) {
+		return errAPIKeyNotAllowed
+	}
+
+	return nil
+}
+
+// idempotencyKey identifies a single run of a scheduled transfer.
+// A failed run does not store the key, so its retry at a later time can run again.
+func idempotencyKey(scheduledTransfer db.ScheduledTransfer) string {
+	return fmt.Sprintf("scheduled_transfer:%d:%d", scheduledTransfer.ID, scheduledTransfer.NextRunAt.Unix())
+}
+
+// nextState decides when a scheduled transfer runs next given the outcome of the current run.
+// Successful runs move to the next occurrence or complete the schedule, failed runs are retried
+// with an exponential backoff and suspend the schedule after MaxFailures failures in a row.
+func nextState(scheduledTransfer db.ScheduledTransfer, runErr error, now time.Time) db.RecordScheduledTransferRunTxParams {
+	arg := db.RecordScheduledTransferRunTxParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		ScheduledFor:        scheduledTransfer.NextRunAt,
+		NextRunAt:           scheduledTransfer.NextRunAt,
+		Status:              db.ScheduledTransferActive,
+	}
+
+	if runErr != nil {
+		arg.ErrorMessage = runErr.Error()
+		arg.FailureCount = scheduledTransfer.FailureCount + 1
+
+		if arg.FailureCount >= MaxFailures {
+			arg.Status = db.ScheduledTransferSuspended
+			return arg
+		}
+
+		arg.NextRunAt = now.Add(RetryDelay << (arg.FailureCount - 1))
+		return arg
+	}
+
+	// occurrences missed while the worker was not running are skipped
+	nextRunAt, ok := NextOccurrence(scheduledTransfer.Recurrence, scheduledTransfer.StartAt, now)
+	if !ok {
+		arg.Status = db.ScheduledTransferCompleted
+		return arg
+	}
+
+	arg.NextRunAt = nextRunAt
+	return arg
+}
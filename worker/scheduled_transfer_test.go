@@ -0,0 +1,362 @@
+package worker
+
+import (
+	"context"
+	mockdb "github.com/aalug/bank-go/db/mock"
+	db "github.com/aalug/bank-go/db/sqlc"
+	"github.com/aalug/bank-go/token"
+	"github.com/aalug/bank-go/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestProcessDue(t *testing.T) {
+	now := time.Date(2023, 7, 1, 9, 30, 5, 0, time.UTC)
+	startAt := time.Date(2023, 6, 1, 9, 30, 0, 0, time.UTC)
+	transferID := utils.RandomInt(1, 1000)
+
+	scheduledTransfer := db.ScheduledTransfer{
+		ID:            utils.RandomInt(1, 1000),
+		Owner:         utils.RandomOwner(),
+		FromAccountID: utils.RandomInt(1, 1000),
+		ToAccountID:   utils.RandomInt(1, 1000),
+		Amount:        utils.RandomAmount(),
+		Recurrence:    db.RecurrenceMonthly,
+		StartAt:       startAt,
+		NextRunAt:     time.Date(2023, 7, 1, 9, 30, 0, 0, time.UTC),
+		Status:        db.ScheduledTransferActive,
+	}
+
+	testCases := []struct {
+		name       string
+		modify     func(scheduledTransfer *db.ScheduledTransfer)
+		transferOK bool
+		expected   func(scheduledTransfer db.ScheduledTransfer) db.RecordScheduledTransferRunTxParams
+	}{
+		{
+			name:       "Success Moves To Next Occurrence",
+			modify:     func(scheduledTransfer *db.ScheduledTransfer) {},
+			transferOK: true,
+			expected: func(scheduledTransfer db.ScheduledTransfer) db.RecordScheduledTransferRunTxParams {
+				return db.RecordScheduledTransferRunTxParams{
+					ScheduledTransferID: scheduledTransfer.ID,
+					ScheduledFor:        scheduledTransfer.NextRunAt,
+					TransferID:          &transferID,
+					NextRunAt:           time.Date(2023, 8, 1, 9, 30, 0, 0, time.UTC),
+					Status:              db.ScheduledTransferActive,
+				}
+			},
+		},
+		{
+			name: "Success Completes One-Time Transfer",
+			modify: func(scheduledTransfer *db.ScheduledTransfer) {
+				scheduledTransfer.Recurrence = db.RecurrenceOnce
+				scheduledTransfer.StartAt = scheduledTransfer.NextRunAt
+			},
+			transferOK: true,
+			expected: func(scheduledTransfer db.ScheduledTransfer) db.RecordScheduledTransferRunTxParams {
+				return db.RecordScheduledTransferRunTxParams{
+					ScheduledTransferID: scheduledTransfer.ID,
+					ScheduledFor:        scheduledTransfer.NextRunAt,
+					TransferID:          &transferID,
+					NextRunAt:           scheduledTransfer.NextRunAt,
+					Status:              db.ScheduledTransferCompleted,
+				}
+			},
+		},
+		{
+			name: "Success Resets Failures",
+			modify: func(scheduledTransfer *db.ScheduledTransfer) {
+				scheduledTransfer.FailureCount = 2
+			},
+			transferOK: true,
+			expected: func(scheduledTransfer db.ScheduledTransfer) db.RecordScheduledTransferRunTxParams {
+				return db.RecordScheduledTransferRunTxParams{
+					ScheduledTransferID: scheduledTransfer.ID,
+					ScheduledFor:        scheduledTransfer.NextRunAt,
+					TransferID:          &transferID,
+					NextRunAt:           time.Date(2023, 8, 1, 9, 30, 0, 0, time.UTC),
+					Status:              db.ScheduledTransferActive,
+				}
+			},
+		},
+		{
+			name: "Failure Is Retried With Backoff",
+			modify: func(scheduledTransfer *db.ScheduledTransfer) {
+				scheduledTransfer.FailureCount = 1
+			},
+			transferOK: false,
+			expected: func(scheduledTransfer db.ScheduledTransfer) db.RecordScheduledTransferRunTxParams {
+				return db.RecordScheduledTransferRunTxParams{
+					ScheduledTransferID: scheduledTransfer.ID,
+					ScheduledFor:        scheduledTransfer.NextRunAt,
+					ErrorMessage:        db.ErrInsufficientFunds.Error(),
+					NextRunAt:           now.Add(2 * RetryDelay),
+					Status:              db.ScheduledTransferActive,
+					FailureCount:        2,
+				}
+			},
+		},
+		{
+			name: "Repeated Failures Suspend",
+			modify: func(scheduledTransfer *db.ScheduledTransfer) {
+				scheduledTransfer.FailureCount = MaxFailures - 1
+			},
+			transferOK: false,
+			expected: func(scheduledTransfer db.ScheduledTransfer) db.RecordScheduledTransferRunTxParams {
+				return db.RecordScheduledTransferRunTxParams{
+					ScheduledTransferID: scheduledTransfer.ID,
+					ScheduledFor:        scheduledTransfer.NextRunAt,
+					ErrorMessage:        db.ErrInsufficientFunds.Error(),
+					NextRunAt:           scheduledTransfer.NextRunAt,
+					Status:              db.ScheduledTransferSuspended,
+					FailureCount:        MaxFailures,
+				}
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			due := scheduledTransfer
+			tc.modify(&due)
+
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().
+				ClaimDueScheduledTransfers(gomock.Any(), gomock.Eq(db.ClaimDueScheduledTransfersParams{
+					ClaimedUntil: now.Add(claimLease),
+					MaxCount:     batchSize,
+				})).
+				Times(1).
+				Return([]db.ScheduledTransfer{due}, nil)
+
+			stubValidScheduledTransfer(store, due)
+
+			transferParams := db.IdempotentTransferTxParams{
+				TransferTxParams: db.TransferTxParams{
+					FromAccountID: due.FromAccountID,
+					ToAccountID:   due.ToAccountID,
+					Amount:        due.Amount,
+				},
+				Username:       due.Owner,
+				IdempotencyKey: idempotencyKey(due),
+				KeyDuration:    idempotencyKeyDuration,
+			}
+
+			transfer := store.EXPECT().
+				IdempotentTransferTx(gomock.Any(), gomock.Eq(transferParams)).
+				Times(1)
+			if tc.transferOK {
+				transfer.Return(db.TransferTxResult{Transfer: db.Transfer{ID: transferID}}, nil)
+			} else {
+				transfer.Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			}
+
+			store.EXPECT().
+				RecordScheduledTransferRunTx(gomock.Any(), gomock.Eq(tc.expected(due))).
+				Times(1)
+
+			worker := NewScheduledTransferWorker(store, time.Minute)
+			worker.now = func() time.Time { return now }
+
+			n, err := worker.ProcessDue(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+		})
+	}
+}
+
+func TestProcessDueRevalidates(t *testing.T) {
+	now := time.Date(2023, 7, 1, 9, 30, 5, 0, time.UTC)
+	apiKeyID := utils.RandomInt(1, 1000)
+
+	scheduledTransfer := db.ScheduledTransfer{
+		ID:            utils.RandomInt(1, 1000),
+		Owner:         utils.RandomOwner(),
+		FromAccountID: utils.RandomInt(1, 1000),
+		ToAccountID:   utils.RandomInt(1, 1000),
+		Amount:        utils.RandomAmount(),
+		Recurrence:    db.RecurrenceMonthly,
+		StartAt:       time.Date(2023, 6, 1, 9, 30, 0, 0, time.UTC),
+		NextRunAt:     time.Date(2023, 7, 1, 9, 30, 0, 0, time.UTC),
+		Status:        db.ScheduledTransferActive,
+		APIKeyID:      &apiKeyID,
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		runErr     error
+	}{
+		{
+			name: "Account Of Another Owner",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(scheduledTransfer.FromAccountID)).
+					Times(1).
+					Return(db.Account{ID: scheduledTransfer.FromAccountID, Owner: utils.RandomOwner()}, nil)
+			},
+			runErr: errAccountNotOwned,
+		},
+		{
+			name: "Email Not Verified",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(scheduledTransfer.Owner)).
+					Times(1).
+					Return(db.User{Username: scheduledTransfer.Owner}, nil)
+			},
+			runErr: errEmailNotVerified,
+		},
+		{
+			name: "API Key Revoked",
+			buildStubs: func(store *mockdb.MockStore) {
+				apiKey := apiKeyOf(scheduledTransfer)
+				apiKey.RevokedAt = &now
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(apiKey, nil)
+			},
+			runErr: errAPIKeyInactive,
+		},
+		{
+			name: "API Key Expired",
+			buildStubs: func(store *mockdb.MockStore) {
+				apiKey := apiKeyOf(scheduledTransfer)
+				expiresAt := now.Add(-time.Minute)
+				apiKey.ExpiresAt = &expiresAt
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(apiKey, nil)
+			},
+			runErr: errAPIKeyInactive,
+		},
+		{
+			name: "API Key Account Removed",
+			buildStubs: func(store *mockdb.MockStore) {
+				apiKey := apiKeyOf(scheduledTransfer)
+				apiKey.AccountIds = []int64{scheduledTransfer.FromAccountID + 1}
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(apiKey, nil)
+			},
+			runErr: errAPIKeyNotAllowed,
+		},
+		{
+			name: "API Key Scope Removed",
+			buildStubs: func(store *mockdb.MockStore) {
+				apiKey := apiKeyOf(scheduledTransfer)
+				apiKey.Scopes = []string{token.ScopeAccountsRead}
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(apiKey, nil)
+			},
+			runErr: errAPIKeyNotAllowed,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().
+				ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return([]db.ScheduledTransfer{scheduledTransfer}, nil)
+
+			tc.buildStubs(store)
+			stubValidScheduledTransfer(store, scheduledTransfer)
+
+			// the transfer is not attempted, the run fails and is retried later
+			store.EXPECT().
+				IdempotentTransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+
+			store.EXPECT().
+				RecordScheduledTransferRunTx(gomock.Any(), gomock.Eq(db.RecordScheduledTransferRunTxParams{
+					ScheduledTransferID: scheduledTransfer.ID,
+					ScheduledFor:        scheduledTransfer.NextRunAt,
+					ErrorMessage:        tc.runErr.Error(),
+					NextRunAt:           now.Add(RetryDelay),
+					Status:              db.ScheduledTransferActive,
+					FailureCount:        1,
+				})).
+				Times(1)
+
+			worker := NewScheduledTransferWorker(store, time.Minute)
+			worker.now = func() time.Time { return now }
+
+			n, err := worker.ProcessDue(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+		})
+	}
+}
+
+// stubValidScheduledTransfer makes the checks repeated at run time pass,
+// stubs of a test case that are set up before take precedence
+func stubValidScheduledTransfer(store *mockdb.MockStore, scheduledTransfer db.ScheduledTransfer) {
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(scheduledTransfer.FromAccountID)).
+		AnyTimes().
+		Return(db.Account{ID: scheduledTransfer.FromAccountID, Owner: scheduledTransfer.Owner}, nil)
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(scheduledTransfer.Owner)).
+		AnyTimes().
+		Return(db.User{Username: scheduledTransfer.Owner, IsEmailVerified: true}, nil)
+
+	if scheduledTransfer.APIKeyID != nil {
+		store.EXPECT().
+			GetAPIKey(gomock.Any(), gomock.Eq(*scheduledTransfer.APIKeyID)).
+			AnyTimes().
+			Return(apiKeyOf(scheduledTransfer), nil)
+	}
+}
+
+// apiKeyOf returns an active API key the scheduled transfer was created with
+func apiKeyOf(scheduledTransfer db.ScheduledTransfer) db.APIKey {
+	return db.APIKey{
+		ID:         *scheduledTransfer.APIKeyID,
+		Owner:      scheduledTransfer.Owner,
+		Scopes:     []string{token.ScopeTransfersWrite},
+		AccountIds: []int64{scheduledTransfer.FromAccountID},
+	}
+}
+
+func TestProcessDueNothingDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.ScheduledTransfer{}, nil)
+
+	store.EXPECT().
+		IdempotentTransferTx(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	n, err := NewScheduledTransferWorker(store, time.Minute).ProcessDue(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+}